@@ -0,0 +1,81 @@
+package audit
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+)
+
+// FieldChange is a single field which differs between two values. Nested fields are joined with dots, e.g.
+// "claim_settings.support_can_type".
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   any    `json:"old"`
+	New   any    `json:"new"`
+}
+
+// DiffFields compares the JSON representations of two values field by field, prefixing each field with prefix if it
+// is not empty. Arrays are compared as a whole.
+func DiffFields(prefix string, old, new any) []FieldChange {
+	oldFields := make(map[string]any)
+	flattenJson(prefix, toJsonValue(old), oldFields)
+
+	newFields := make(map[string]any)
+	flattenJson(prefix, toJsonValue(new), newFields)
+
+	keys := make(map[string]bool)
+	for key := range oldFields {
+		keys[key] = true
+	}
+
+	for key := range newFields {
+		keys[key] = true
+	}
+
+	changes := make([]FieldChange, 0)
+	for key := range keys {
+		if !reflect.DeepEqual(oldFields[key], newFields[key]) {
+			changes = append(changes, FieldChange{
+				Field: key,
+				Old:   oldFields[key],
+				New:   newFields[key],
+			})
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+
+	return changes
+}
+
+func toJsonValue(v any) any {
+	marshalled, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+
+	var value any
+	if err := json.Unmarshal(marshalled, &value); err != nil {
+		return nil
+	}
+
+	return value
+}
+
+func flattenJson(prefix string, value any, out map[string]any) {
+	object, ok := value.(map[string]any)
+	if !ok {
+		out[prefix] = value
+		return
+	}
+
+	for key, child := range object {
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		flattenJson(key, child, out)
+	}
+}
@@ -0,0 +1,34 @@
+package audit
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffFieldsNested(t *testing.T) {
+	old := json.RawMessage(`{"ticket_limit": 5, "claim_settings": {"support_can_view": true, "support_can_type": true}, "colours": [1, 2]}`)
+	new := json.RawMessage(`{"ticket_limit": 5, "claim_settings": {"support_can_view": true, "support_can_type": false}, "colours": [1, 3], "language": "en"}`)
+
+	changes := DiffFields("", old, new)
+
+	assert.Equal(t, []FieldChange{
+		{Field: "claim_settings.support_can_type", Old: true, New: false},
+		{Field: "colours", Old: []any{float64(1), float64(2)}, New: []any{float64(1), float64(3)}},
+		{Field: "language", Old: nil, New: "en"},
+	}, changes)
+}
+
+func TestDiffFieldsPrefix(t *testing.T) {
+	type team struct {
+		Name string `json:"name"`
+	}
+
+	changes := DiffFields("billing", team{Name: "Billing"}, team{Name: "Payments"})
+	if assert.Len(t, changes, 1) {
+		assert.Equal(t, "billing.name", changes[0].Field)
+	}
+
+	assert.Empty(t, DiffFields("", team{Name: "Billing"}, team{Name: "Billing"}))
+}
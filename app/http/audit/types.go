@@ -0,0 +1,52 @@
+package audit
+
+import "github.com/TicketsBot-cloud/database"
+
+// Action and resource types for features owned by the dashboard. These are allocated from 1000 upwards, so that they
+// never collide with the types defined in the database module.
+const (
+	ActionTranscriptRedactionCreate database.AuditActionType = 1000
+	ActionTranscriptRedactionDelete database.AuditActionType = 1001
+	ActionTranscriptRedactionPolicy database.AuditActionType = 1002
+
+	ActionTranscriptRetentionUpdate database.AuditActionType = 1010
+	ActionTranscriptRetentionPurge  database.AuditActionType = 1011
+
+	ActionSlaPolicySet    database.AuditActionType = 1020
+	ActionSlaPolicyDelete database.AuditActionType = 1021
+
+	ActionSupportHoursOverrideCreate database.AuditActionType = 1030
+	ActionSupportHoursOverrideUpdate database.AuditActionType = 1031
+	ActionSupportHoursOverrideDelete database.AuditActionType = 1032
+
+	ActionSupportHoursScheduleCreate database.AuditActionType = 1040
+	ActionSupportHoursScheduleUpdate database.AuditActionType = 1041
+	ActionSupportHoursScheduleDelete database.AuditActionType = 1042
+
+	ActionConfigBackupCreate  database.AuditActionType = 1050
+	ActionConfigBackupDelete  database.AuditActionType = 1051
+	ActionConfigBackupRestore database.AuditActionType = 1052
+
+	ActionSettingsRollback database.AuditActionType = 1060
+
+	ActionPanelScheduleCreate database.AuditActionType = 1070
+	ActionPanelScheduleDelete database.AuditActionType = 1071
+	ActionPanelScheduleApply  database.AuditActionType = 1072
+
+	ActionFormBranchesUpdate database.AuditActionType = 1080
+	ActionFormTemplateCreate database.AuditActionType = 1081
+	ActionFormTemplateDelete database.AuditActionType = 1082
+	ActionFormInputsReorder  database.AuditActionType = 1083
+	ActionFormInputMove      database.AuditActionType = 1084
+)
+
+const (
+	ResourceTranscriptRedaction  database.AuditResourceType = 1000
+	ResourceTranscriptRetention  database.AuditResourceType = 1001
+	ResourceSlaPolicy            database.AuditResourceType = 1002
+	ResourceSupportHoursOverride database.AuditResourceType = 1003
+	ResourceSupportHoursSchedule database.AuditResourceType = 1004
+	ResourceConfigBackup         database.AuditResourceType = 1005
+	ResourcePanelSchedule        database.AuditResourceType = 1006
+	ResourceFormTemplate         database.AuditResourceType = 1007
+)
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"github.com/TicketsBot-cloud/dashboard/app"
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/dashboard/utils"
+	"github.com/TicketsBot-cloud/database"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/sync/errgroup"
+)
+
+type breakdownEntry struct {
+	dbclient.BreakdownRow
+	Name *string `json:"name"`
+}
+
+type breakdownResponse struct {
+	Panels []breakdownEntry `json:"panels"`
+	Labels []breakdownEntry `json:"labels"`
+}
+
+func GetBreakdown(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+
+	r, err := parseDateRange(ctx)
+	if err != nil {
+		ctx.JSON(400, utils.ErrorStr("%s", err.Error()))
+		return
+	}
+
+	var (
+		panelRows []dbclient.BreakdownRow
+		labelRows []dbclient.BreakdownRow
+		panels    []database.Panel
+		labels    []database.TicketLabel
+	)
+
+	group, _ := errgroup.WithContext(ctx)
+
+	group.Go(func() (err error) {
+		panelRows, err = dbclient.Dashboard.Analytics.GetPanelBreakdown(ctx, guildId, r.Start(), r.End())
+		return
+	})
+
+	group.Go(func() (err error) {
+		labelRows, err = dbclient.Dashboard.Analytics.GetLabelBreakdown(ctx, guildId, r.Start(), r.End())
+		return
+	})
+
+	group.Go(func() (err error) {
+		panels, err = dbclient.Client.Panel.GetByGuild(ctx, guildId)
+		return
+	})
+
+	group.Go(func() (err error) {
+		labels, err = dbclient.Client.TicketLabels.GetByGuild(ctx, guildId)
+		return
+	})
+
+	if err := group.Wait(); err != nil {
+		_ = ctx.AbortWithError(500, app.NewError(err, "Failed to fetch ticket breakdown"))
+		return
+	}
+
+	panelTitles := make(map[int]string)
+	for _, panel := range panels {
+		panelTitles[panel.PanelId] = panel.Title
+	}
+
+	labelNames := make(map[int]string)
+	for _, label := range labels {
+		labelNames[label.LabelId] = label.Name
+	}
+
+	ctx.JSON(200, breakdownResponse{
+		Panels: withNames(panelRows, panelTitles),
+		Labels: withNames(labelRows, labelNames),
+	})
+}
+
+// withNames attaches the panel or label names to each row. The name is nil for tickets without a panel, or if the
+// panel has since been deleted.
+func withNames(rows []dbclient.BreakdownRow, names map[int]string) []breakdownEntry {
+	entries := make([]breakdownEntry, len(rows))
+	for i, row := range rows {
+		entries[i] = breakdownEntry{
+			BreakdownRow: row,
+		}
+
+		if row.Id != nil {
+			if name, ok := names[*row.Id]; ok {
+				entries[i].Name = &name
+			}
+		}
+	}
+
+	return entries
+}
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	dateFormat       = "2006-01-02"
+	defaultRangeDays = 30
+	maxRangeDays     = 366
+)
+
+// dateRange is an inclusive range of calendar days in the caller's timezone
+type dateRange struct {
+	From     time.Time
+	To       time.Time
+	Location *time.Location
+}
+
+// parseDateRange reads the from, to and timezone query parameters. Both dates are inclusive and default to the last 30
+// days, and the timezone defaults to UTC.
+func parseDateRange(ctx *gin.Context) (dateRange, error) {
+	timezone := ctx.DefaultQuery("timezone", "UTC")
+
+	// Local is accepted by Go, but refers to the server's timezone and is rejected by Postgres
+	location, err := time.LoadLocation(timezone)
+	if err != nil || timezone == "Local" {
+		return dateRange{}, fmt.Errorf("Invalid timezone: %s", timezone)
+	}
+
+	now := time.Now().In(location)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, location)
+
+	to := today
+	if raw := ctx.Query("to"); raw != "" {
+		to, err = time.ParseInLocation(dateFormat, raw, location)
+		if err != nil {
+			return dateRange{}, errors.New("Invalid end date, expected YYYY-MM-DD")
+		}
+	}
+
+	from := to.AddDate(0, 0, -(defaultRangeDays - 1))
+	if raw := ctx.Query("from"); raw != "" {
+		from, err = time.ParseInLocation(dateFormat, raw, location)
+		if err != nil {
+			return dateRange{}, errors.New("Invalid start date, expected YYYY-MM-DD")
+		}
+	}
+
+	if from.After(to) {
+		return dateRange{}, errors.New("Start date must not be after the end date")
+	}
+
+	r := dateRange{
+		From:     from,
+		To:       to,
+		Location: location,
+	}
+
+	if r.dayCount() > maxRangeDays {
+		return dateRange{}, fmt.Errorf("Date range must be %d days or less", maxRangeDays)
+	}
+
+	return r, nil
+}
+
+// dayCount is the number of days in the range. Days are counted on the calendar, as days in the caller's timezone
+// are not always 24 hours long.
+func (r dateRange) dayCount() int {
+	from := time.Date(r.From.Year(), r.From.Month(), r.From.Day(), 0, 0, 0, 0, time.UTC)
+	to := time.Date(r.To.Year(), r.To.Month(), r.To.Day(), 0, 0, 0, 0, time.UTC)
+	return int(to.Sub(from)/(24*time.Hour)) + 1
+}
+
+// Start is the first instant in the range
+func (r dateRange) Start() time.Time {
+	return r.From
+}
+
+// End is the first instant after the range
+func (r dateRange) End() time.Time {
+	return r.To.AddDate(0, 0, 1)
+}
+
+func (r dateRange) Days() []string {
+	var days []string
+	for day := r.From; !day.After(r.To); day = day.AddDate(0, 0, 1) {
+		days = append(days, day.Format(dateFormat))
+	}
+
+	return days
+}
+
+// fillDays returns a count for every day in the range, including the days with no results
+func (r dateRange) fillDays(counts []dbclient.DailyCount) []dbclient.DailyCount {
+	byDay := make(map[string]int, len(counts))
+	for _, count := range counts {
+		byDay[count.Day] = count.Count
+	}
+
+	days := r.Days()
+	filled := make([]dbclient.DailyCount, len(days))
+	for i, day := range days {
+		filled[i] = dbclient.DailyCount{
+			Day:   day,
+			Count: byDay[day],
+		}
+	}
+
+	return filled
+}
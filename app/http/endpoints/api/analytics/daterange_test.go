@@ -0,0 +1,46 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func contextWithQuery(query string) *gin.Context {
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest("GET", "/?"+query, nil)
+	return ctx
+}
+
+func TestParseDateRangeAcrossDst(t *testing.T) {
+	r, err := parseDateRange(contextWithQuery("from=2025-03-29&to=2025-03-31&timezone=Europe/London"))
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, []string{"2025-03-29", "2025-03-30", "2025-03-31"}, r.Days())
+	assert.Equal(t, 3, r.dayCount())
+
+	// Clocks go forward on the 30th, so the range is an hour shorter than 3 days
+	assert.Equal(t, 71*time.Hour, r.End().Sub(r.Start()))
+}
+
+func TestParseDateRangeInvalid(t *testing.T) {
+	_, err := parseDateRange(contextWithQuery("timezone=Not/AZone"))
+	assert.Error(t, err)
+
+	_, err = parseDateRange(contextWithQuery("timezone=Local"))
+	assert.Error(t, err)
+
+	_, err = parseDateRange(contextWithQuery("from=2025-02-01&to=2025-01-01"))
+	assert.Error(t, err)
+
+	_, err = parseDateRange(contextWithQuery("from=2020-01-01&to=2025-01-01"))
+	assert.Error(t, err)
+
+	_, err = parseDateRange(contextWithQuery("from=0001-01-01&to=9999-12-31"))
+	assert.Error(t, err)
+}
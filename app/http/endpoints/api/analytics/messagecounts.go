@@ -0,0 +1,68 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/TicketsBot-cloud/archiverclient"
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/dashboard/log"
+	"github.com/TicketsBot-cloud/dashboard/utils"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// Message counts are only available from transcripts, so they are indexed lazily, a few at a time, each time the
+	// report is requested.
+	messageIndexBatchSize   = 25
+	messageIndexConcurrency = 5
+	messageIndexTimeout     = 10 * time.Second
+)
+
+type messageCoverage struct {
+	Indexed int `json:"indexed"`
+	Total   int `json:"total"`
+}
+
+// indexMessageCounts counts the messages in a batch of unindexed transcripts in the range. Failures are logged and
+// skipped, so that they are retried next time, rather than failing the whole report.
+func indexMessageCounts(ctx context.Context, guildId uint64, r dateRange) error {
+	ticketIds, err := dbclient.Dashboard.TicketMessageCounts.GetUnindexed(ctx, guildId, r.Start(), r.End(), messageIndexBatchSize)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, messageIndexTimeout)
+	defer cancel()
+
+	group, ctx := errgroup.WithContext(ctx)
+	group.SetLimit(messageIndexConcurrency)
+
+	for _, ticketId := range ticketIds {
+		ticketId := ticketId
+
+		group.Go(func() error {
+			transcript, err := utils.ArchiverClient.Get(ctx, guildId, ticketId)
+			if err != nil && !errors.Is(err, archiverclient.ErrNotFound) {
+				log.Logger.Warn("Failed to fetch transcript to count messages", zap.Error(err), zap.Uint64("guild_id", guildId), zap.Int("ticket_id", ticketId))
+				return nil
+			}
+
+			// A missing transcript is indexed with no messages, so it is not fetched again
+			counts := make(map[uint64]int)
+			for _, message := range transcript.Messages {
+				counts[message.AuthorId]++
+			}
+
+			if err := dbclient.Dashboard.TicketMessageCounts.Set(ctx, guildId, ticketId, counts); err != nil {
+				log.Logger.Warn("Failed to store message counts", zap.Error(err), zap.Uint64("guild_id", guildId), zap.Int("ticket_id", ticketId))
+			}
+
+			return nil
+		})
+	}
+
+	return group.Wait()
+}
@@ -0,0 +1,26 @@
+package api
+
+import (
+	"github.com/TicketsBot-cloud/dashboard/app"
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/dashboard/utils"
+	"github.com/gin-gonic/gin"
+)
+
+func GetResponseTimes(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+
+	r, err := parseDateRange(ctx)
+	if err != nil {
+		ctx.JSON(400, utils.ErrorStr("%s", err.Error()))
+		return
+	}
+
+	stats, err := dbclient.Dashboard.Analytics.GetResponseTimes(ctx, guildId, r.Start(), r.End())
+	if err != nil {
+		_ = ctx.AbortWithError(500, app.NewError(err, "Failed to fetch response times"))
+		return
+	}
+
+	ctx.JSON(200, stats)
+}
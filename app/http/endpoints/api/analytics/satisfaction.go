@@ -0,0 +1,315 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/TicketsBot-cloud/dashboard/app"
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/dashboard/rpc/cache"
+	"github.com/TicketsBot-cloud/dashboard/utils"
+	"github.com/TicketsBot-cloud/database"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	minRating = 1
+	maxRating = 5
+
+	feedbackPageLimit = 20
+)
+
+type ratingBreakdownEntry struct {
+	dbclient.RatingBreakdownRow
+	Name *string `json:"name"`
+}
+
+// claimerRatingEntry encodes the claimer's user ID as a string, like other user IDs
+type claimerRatingEntry struct {
+	ratingBreakdownEntry
+	Id *uint64 `json:"id,string"`
+}
+
+type satisfactionResponse struct {
+	// Average is nil if there were no ratings
+	Average      *float64               `json:"average"`
+	Count        int                    `json:"count"`
+	Low          int                    `json:"low"`
+	Distribution []dbclient.RatingCount `json:"distribution"`
+	Trend        []dbclient.DailyRating `json:"trend"`
+	Panels       []ratingBreakdownEntry `json:"panels"`
+	Labels       []ratingBreakdownEntry `json:"labels"`
+	Claimers     []claimerRatingEntry   `json:"claimers"`
+}
+
+func GetSatisfaction(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+
+	r, err := parseDateRange(ctx)
+	if err != nil {
+		ctx.JSON(400, utils.ErrorStr("%s", err.Error()))
+		return
+	}
+
+	var (
+		distribution []dbclient.RatingCount
+		trend        []dbclient.DailyRating
+		panelRows    []dbclient.RatingBreakdownRow
+		labelRows    []dbclient.RatingBreakdownRow
+		claimerRows  []dbclient.RatingBreakdownRow
+		panels       []database.Panel
+		labels       []database.TicketLabel
+	)
+
+	group, _ := errgroup.WithContext(ctx)
+
+	group.Go(func() (err error) {
+		distribution, err = dbclient.Dashboard.Analytics.GetRatingDistribution(ctx, guildId, r.Start(), r.End())
+		return
+	})
+
+	group.Go(func() (err error) {
+		trend, err = dbclient.Dashboard.Analytics.GetRatingsPerDay(ctx, guildId, r.Start(), r.End(), r.Location.String())
+		return
+	})
+
+	group.Go(func() (err error) {
+		panelRows, err = dbclient.Dashboard.Analytics.GetRatingsByPanel(ctx, guildId, r.Start(), r.End())
+		return
+	})
+
+	group.Go(func() (err error) {
+		labelRows, err = dbclient.Dashboard.Analytics.GetRatingsByLabel(ctx, guildId, r.Start(), r.End())
+		return
+	})
+
+	group.Go(func() (err error) {
+		claimerRows, err = dbclient.Dashboard.Analytics.GetRatingsByClaimer(ctx, guildId, r.Start(), r.End())
+		return
+	})
+
+	group.Go(func() (err error) {
+		panels, err = dbclient.Client.Panel.GetByGuild(ctx, guildId)
+		return
+	})
+
+	group.Go(func() (err error) {
+		labels, err = dbclient.Client.TicketLabels.GetByGuild(ctx, guildId)
+		return
+	})
+
+	if err := group.Wait(); err != nil {
+		_ = ctx.AbortWithError(500, app.NewError(err, "Failed to fetch ratings"))
+		return
+	}
+
+	panelTitles := make(map[uint64]string)
+	for _, panel := range panels {
+		panelTitles[uint64(panel.PanelId)] = panel.Title
+	}
+
+	labelNames := make(map[uint64]string)
+	for _, label := range labels {
+		labelNames[uint64(label.LabelId)] = label.Name
+	}
+
+	claimerIds := make([]uint64, 0, len(claimerRows))
+	for _, row := range claimerRows {
+		if row.Id != nil {
+			claimerIds = append(claimerIds, *row.Id)
+		}
+	}
+
+	users, err := cache.Instance.GetUsers(ctx, claimerIds)
+	if err != nil {
+		_ = ctx.AbortWithError(500, app.NewError(err, "Failed to fetch user information from cache"))
+		return
+	}
+
+	usernames := make(map[uint64]string, len(users))
+	for userId, user := range users {
+		usernames[userId] = user.Username
+	}
+
+	distribution = fillDistribution(distribution)
+	average, count, low := summariseRatings(distribution)
+
+	ctx.JSON(200, satisfactionResponse{
+		Average:      average,
+		Count:        count,
+		Low:          low,
+		Distribution: distribution,
+		Trend:        r.fillRatingDays(trend),
+		Panels:       withRatingNames(panelRows, panelTitles),
+		Labels:       withRatingNames(labelRows, labelNames),
+		Claimers:     withClaimerIds(withRatingNames(claimerRows, usernames)),
+	})
+}
+
+type feedbackEntry struct {
+	dbclient.SurveyFeedback
+	Username *string `json:"username"`
+}
+
+type paginatedFeedback struct {
+	Feedback    []feedbackEntry `json:"feedback"`
+	TotalCount  int             `json:"total_count"`
+	TotalPages  int             `json:"total_pages"`
+	CurrentPage int             `json:"current_page"`
+}
+
+// GetSurveyFeedback lists exit survey responses. Setting low_only restricts the results to low rated tickets, which
+// are otherwise flagged with low = true.
+func GetSurveyFeedback(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+
+	r, err := parseDateRange(ctx)
+	if err != nil {
+		ctx.JSON(400, utils.ErrorStr("%s", err.Error()))
+		return
+	}
+
+	page := 1
+	if raw, ok := ctx.GetQuery("page"); ok {
+		page, err = strconv.Atoi(raw)
+		if err != nil || page < 1 {
+			ctx.JSON(400, utils.ErrorStr("Invalid page"))
+			return
+		}
+	}
+
+	filter := dbclient.SurveyFeedbackFilter{
+		Limit:  feedbackPageLimit,
+		Offset: (page - 1) * feedbackPageLimit,
+	}
+
+	if raw, ok := ctx.GetQuery("panel_id"); ok {
+		panelId, err := strconv.Atoi(raw)
+		if err != nil {
+			ctx.JSON(400, utils.ErrorStr("Invalid panel ID"))
+			return
+		}
+
+		filter.PanelId = &panelId
+	}
+
+	if ctx.Query("low_only") == "true" {
+		filter.MaxRating = utils.Ptr(dbclient.LowRatingThreshold)
+	}
+
+	feedback, total, err := dbclient.Dashboard.Analytics.GetSurveyFeedback(ctx, guildId, r.Start(), r.End(), filter)
+	if err != nil {
+		_ = ctx.AbortWithError(500, app.NewError(err, "Failed to fetch exit survey responses"))
+		return
+	}
+
+	userIds := make([]uint64, len(feedback))
+	for i, row := range feedback {
+		userIds[i] = row.UserId
+	}
+
+	users, err := cache.Instance.GetUsers(ctx, userIds)
+	if err != nil {
+		_ = ctx.AbortWithError(500, app.NewError(err, "Failed to fetch user information from cache"))
+		return
+	}
+
+	entries := make([]feedbackEntry, len(feedback))
+	for i, row := range feedback {
+		entries[i] = feedbackEntry{SurveyFeedback: row}
+
+		if user, ok := users[row.UserId]; ok {
+			entries[i].Username = utils.Ptr(user.Username)
+		}
+	}
+
+	ctx.JSON(200, paginatedFeedback{
+		Feedback:    entries,
+		TotalCount:  total,
+		TotalPages:  (total + feedbackPageLimit - 1) / feedbackPageLimit,
+		CurrentPage: page,
+	})
+}
+
+// fillDistribution returns a count for every possible rating, including those which were never given
+func fillDistribution(counts []dbclient.RatingCount) []dbclient.RatingCount {
+	byRating := make(map[int]int, len(counts))
+	for _, count := range counts {
+		byRating[count.Rating] = count.Count
+	}
+
+	filled := make([]dbclient.RatingCount, 0, maxRating-minRating+1)
+	for rating := minRating; rating <= maxRating; rating++ {
+		filled = append(filled, dbclient.RatingCount{
+			Rating: rating,
+			Count:  byRating[rating],
+		})
+	}
+
+	return filled
+}
+
+func summariseRatings(distribution []dbclient.RatingCount) (average *float64, count, low int) {
+	var sum int
+	for _, row := range distribution {
+		sum += row.Rating * row.Count
+		count += row.Count
+
+		if row.Rating <= dbclient.LowRatingThreshold {
+			low += row.Count
+		}
+	}
+
+	if count > 0 {
+		average = utils.Ptr(float64(sum) / float64(count))
+	}
+
+	return
+}
+
+// fillRatingDays returns the ratings for every day in the range, with a nil average on days with no ratings
+func (r dateRange) fillRatingDays(ratings []dbclient.DailyRating) []dbclient.DailyRating {
+	byDay := make(map[string]dbclient.DailyRating, len(ratings))
+	for _, rating := range ratings {
+		byDay[rating.Day] = rating
+	}
+
+	days := r.Days()
+	filled := make([]dbclient.DailyRating, len(days))
+	for i, day := range days {
+		filled[i] = byDay[day]
+		filled[i].Day = day
+	}
+
+	return filled
+}
+
+// withRatingNames attaches the panel, label or claimer names to each row
+func withRatingNames(rows []dbclient.RatingBreakdownRow, names map[uint64]string) []ratingBreakdownEntry {
+	entries := make([]ratingBreakdownEntry, len(rows))
+	for i, row := range rows {
+		entries[i] = ratingBreakdownEntry{
+			RatingBreakdownRow: row,
+		}
+
+		if row.Id != nil {
+			if name, ok := names[*row.Id]; ok {
+				entries[i].Name = &name
+			}
+		}
+	}
+
+	return entries
+}
+
+func withClaimerIds(entries []ratingBreakdownEntry) []claimerRatingEntry {
+	claimers := make([]claimerRatingEntry, len(entries))
+	for i, entry := range entries {
+		claimers[i] = claimerRatingEntry{
+			ratingBreakdownEntry: entry,
+			Id:                   entry.Id,
+		}
+	}
+
+	return claimers
+}
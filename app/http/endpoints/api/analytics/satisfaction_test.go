@@ -0,0 +1,52 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSummariseRatings(t *testing.T) {
+	distribution := fillDistribution([]dbclient.RatingCount{
+		{Rating: 1, Count: 1},
+		{Rating: 2, Count: 1},
+		{Rating: 5, Count: 2},
+	})
+
+	assert.Len(t, distribution, 5)
+	assert.Equal(t, 0, distribution[2].Count)
+
+	average, count, low := summariseRatings(distribution)
+	if assert.NotNil(t, average) {
+		assert.InDelta(t, 3.25, *average, 0.001)
+	}
+
+	assert.Equal(t, 4, count)
+	assert.Equal(t, 2, low)
+}
+
+func TestSummariseRatingsEmpty(t *testing.T) {
+	average, count, _ := summariseRatings(fillDistribution(nil))
+	assert.Nil(t, average)
+	assert.Equal(t, 0, count)
+}
+
+func TestRatingBreakdownIdEncoding(t *testing.T) {
+	id := uint64(123456789012345678)
+	entries := withRatingNames([]dbclient.RatingBreakdownRow{{Id: &id, Average: 4, Count: 1}}, nil)
+
+	panel, err := json.Marshal(entries[0])
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	claimer, err := json.Marshal(withClaimerIds(entries)[0])
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Contains(t, string(panel), `"id":123456789012345678`)
+	assert.Contains(t, string(claimer), `"id":"123456789012345678"`)
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"github.com/TicketsBot-cloud/dashboard/app"
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/dashboard/utils"
+	"github.com/TicketsBot-cloud/database"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/sync/errgroup"
+)
+
+const slaBreachLimit = 100
+
+type slaBreachCountEntry struct {
+	dbclient.SlaBreachCount
+	Name *string `json:"name"`
+}
+
+type slaReportResponse struct {
+	FirstResponse int                   `json:"first_response"`
+	Resolution    int                   `json:"resolution"`
+	Panels        []slaBreachCountEntry `json:"panels"`
+	// Breaches holds the most recent breaches in the range, up to slaBreachLimit
+	Breaches []dbclient.SlaBreach `json:"breaches"`
+}
+
+func GetSlaReport(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+
+	r, err := parseDateRange(ctx)
+	if err != nil {
+		ctx.JSON(400, utils.ErrorStr("%s", err.Error()))
+		return
+	}
+
+	var (
+		counts   []dbclient.SlaBreachCount
+		breaches []dbclient.SlaBreach
+		panels   []database.Panel
+	)
+
+	group, _ := errgroup.WithContext(ctx)
+
+	group.Go(func() (err error) {
+		counts, err = dbclient.Dashboard.SlaBreaches.GetCounts(ctx, guildId, r.Start(), r.End())
+		return
+	})
+
+	group.Go(func() (err error) {
+		breaches, err = dbclient.Dashboard.SlaBreaches.GetByGuild(ctx, guildId, r.Start(), r.End(), slaBreachLimit)
+		return
+	})
+
+	group.Go(func() (err error) {
+		panels, err = dbclient.Client.Panel.GetByGuild(ctx, guildId)
+		return
+	})
+
+	if err := group.Wait(); err != nil {
+		_ = ctx.AbortWithError(500, app.NewError(err, "Failed to fetch SLA breaches"))
+		return
+	}
+
+	panelTitles := make(map[int]string)
+	for _, panel := range panels {
+		panelTitles[panel.PanelId] = panel.Title
+	}
+
+	response := slaReportResponse{
+		Panels:   make([]slaBreachCountEntry, len(counts)),
+		Breaches: breaches,
+	}
+
+	for i, count := range counts {
+		response.FirstResponse += count.FirstResponse
+		response.Resolution += count.Resolution
+
+		response.Panels[i] = slaBreachCountEntry{SlaBreachCount: count}
+		if title, ok := panelTitles[count.PanelId]; ok {
+			response.Panels[i].Name = &title
+		}
+	}
+
+	ctx.JSON(200, response)
+}
@@ -0,0 +1,237 @@
+package api
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/TicketsBot-cloud/dashboard/app"
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/dashboard/rpc/cache"
+	"github.com/TicketsBot-cloud/dashboard/utils"
+	cache2 "github.com/TicketsBot-cloud/gdl/cache"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/sync/errgroup"
+)
+
+type staffEntry struct {
+	dbclient.StaffStats
+	Username *string `json:"username"`
+}
+
+type staffResponse struct {
+	Staff           []staffEntry    `json:"staff"`
+	MessageCoverage messageCoverage `json:"message_coverage"`
+}
+
+func GetStaffLeaderboard(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+
+	r, err := parseDateRange(ctx)
+	if err != nil {
+		ctx.JSON(400, utils.ErrorStr("%s", err.Error()))
+		return
+	}
+
+	format := ctx.DefaultQuery("format", "json")
+	if format != "json" && format != "csv" {
+		ctx.JSON(400, utils.ErrorStr("Invalid format: must be json or csv"))
+		return
+	}
+
+	var teamId *int
+	if raw, ok := ctx.GetQuery("team_id"); ok {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			ctx.JSON(400, utils.ErrorStr("Invalid team ID"))
+			return
+		}
+
+		exists, err := dbclient.Client.SupportTeam.Exists(ctx, parsed, guildId)
+		if err != nil {
+			_ = ctx.AbortWithError(500, app.NewError(err, "Failed to fetch support team"))
+			return
+		}
+
+		if !exists {
+			ctx.JSON(404, utils.ErrorStr("Support team with provided ID not found"))
+			return
+		}
+
+		teamId = &parsed
+	}
+
+	if err := indexMessageCounts(ctx, guildId, r); err != nil {
+		_ = ctx.AbortWithError(500, app.NewError(err, "Failed to count messages"))
+		return
+	}
+
+	var (
+		stats    []dbclient.StaffStats
+		coverage messageCoverage
+	)
+
+	group, _ := errgroup.WithContext(ctx)
+
+	group.Go(func() (err error) {
+		stats, err = dbclient.Dashboard.Analytics.GetStaffStats(ctx, guildId, r.Start(), r.End())
+		return
+	})
+
+	group.Go(func() (err error) {
+		coverage.Indexed, coverage.Total, err = dbclient.Dashboard.TicketMessageCounts.GetCoverage(ctx, guildId, r.Start(), r.End())
+		return
+	})
+
+	if err := group.Wait(); err != nil {
+		_ = ctx.AbortWithError(500, app.NewError(err, "Failed to fetch staff statistics"))
+		return
+	}
+
+	if teamId != nil {
+		stats, err = filterByTeam(ctx, guildId, *teamId, stats)
+		if err != nil {
+			_ = ctx.AbortWithError(500, app.NewError(err, "Failed to fetch support team members"))
+			return
+		}
+	}
+
+	sort.SliceStable(stats, func(i, j int) bool {
+		if stats[i].Closed != stats[j].Closed {
+			return stats[i].Closed > stats[j].Closed
+		}
+
+		return stats[i].Claimed > stats[j].Claimed
+	})
+
+	userIds := make([]uint64, len(stats))
+	for i, row := range stats {
+		userIds[i] = row.UserId
+	}
+
+	users, err := cache.Instance.GetUsers(ctx, userIds)
+	if err != nil {
+		_ = ctx.AbortWithError(500, app.NewError(err, "Failed to fetch user information from cache"))
+		return
+	}
+
+	entries := make([]staffEntry, len(stats))
+	for i, row := range stats {
+		entries[i] = staffEntry{StaffStats: row}
+
+		if user, ok := users[row.UserId]; ok {
+			entries[i].Username = utils.Ptr(user.Username)
+		}
+	}
+
+	if format == "csv" {
+		writeStaffCsv(ctx, r, entries)
+		return
+	}
+
+	ctx.JSON(200, staffResponse{
+		Staff:           entries,
+		MessageCoverage: coverage,
+	})
+}
+
+// filterByTeam keeps staff who are members of the team, either directly or through one of the team's roles
+func filterByTeam(ctx *gin.Context, guildId uint64, teamId int, stats []dbclient.StaffStats) ([]dbclient.StaffStats, error) {
+	var (
+		userIds []uint64
+		roleIds []uint64
+	)
+
+	group, _ := errgroup.WithContext(ctx)
+
+	group.Go(func() (err error) {
+		userIds, err = dbclient.Client.SupportTeamMembers.Get(ctx, teamId)
+		return
+	})
+
+	group.Go(func() (err error) {
+		roleIds, err = dbclient.Client.SupportTeamRoles.Get(ctx, teamId)
+		return
+	})
+
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	members := utils.ToSet(userIds)
+	roles := utils.ToSet(roleIds)
+
+	filtered := make([]dbclient.StaffStats, 0, len(stats))
+	for _, row := range stats {
+		if members.Contains(row.UserId) {
+			filtered = append(filtered, row)
+			continue
+		}
+
+		if roles.Size() == 0 {
+			continue
+		}
+
+		member, err := cache.Instance.GetMember(ctx, guildId, row.UserId)
+		if err != nil {
+			if errors.Is(err, cache2.ErrNotFound) {
+				continue // Member has likely left the server
+			}
+
+			return nil, err
+		}
+
+		for _, roleId := range member.Roles {
+			if roles.Contains(roleId) {
+				filtered = append(filtered, row)
+				break
+			}
+		}
+	}
+
+	return filtered, nil
+}
+
+func writeStaffCsv(ctx *gin.Context, r dateRange, entries []staffEntry) {
+	filename := fmt.Sprintf("staff-%s-to-%s.csv", r.From.Format(dateFormat), r.To.Format(dateFormat))
+
+	ctx.Header("Content-Type", "text/csv")
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	ctx.Status(200)
+
+	w := csv.NewWriter(ctx.Writer)
+	_ = w.Write([]string{"user_id", "username", "claimed", "closed", "messages", "average_rating", "ratings", "first_response_median_seconds"})
+
+	for _, entry := range entries {
+		_ = w.Write([]string{
+			strconv.FormatUint(entry.UserId, 10),
+			stringOrEmpty(entry.Username),
+			strconv.Itoa(entry.Claimed),
+			strconv.Itoa(entry.Closed),
+			strconv.Itoa(entry.Messages),
+			floatOrEmpty(entry.AverageRating, 2),
+			strconv.Itoa(entry.Ratings),
+			floatOrEmpty(entry.FirstResponseMedian, 0),
+		})
+	}
+
+	w.Flush()
+}
+
+func stringOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
+
+func floatOrEmpty(f *float64, precision int) string {
+	if f == nil {
+		return ""
+	}
+
+	return strconv.FormatFloat(*f, 'f', precision, 64)
+}
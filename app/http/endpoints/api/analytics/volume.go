@@ -0,0 +1,73 @@
+package api
+
+import (
+	"github.com/TicketsBot-cloud/dashboard/app"
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/dashboard/utils"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/sync/errgroup"
+)
+
+type volumeResponse struct {
+	Opened []dbclient.DailyCount `json:"opened"`
+	Closed []dbclient.DailyCount `json:"closed"`
+	// Backlog is the number of tickets open at the end of each day
+	Backlog []dbclient.DailyCount `json:"backlog"`
+}
+
+func GetVolume(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+
+	r, err := parseDateRange(ctx)
+	if err != nil {
+		ctx.JSON(400, utils.ErrorStr("%s", err.Error()))
+		return
+	}
+
+	var (
+		opened         []dbclient.DailyCount
+		closed         []dbclient.DailyCount
+		initialBacklog int
+	)
+
+	group, _ := errgroup.WithContext(ctx)
+
+	group.Go(func() (err error) {
+		opened, err = dbclient.Dashboard.Analytics.GetOpenedPerDay(ctx, guildId, r.Start(), r.End(), r.Location.String())
+		return
+	})
+
+	group.Go(func() (err error) {
+		closed, err = dbclient.Dashboard.Analytics.GetClosedPerDay(ctx, guildId, r.Start(), r.End(), r.Location.String())
+		return
+	})
+
+	group.Go(func() (err error) {
+		initialBacklog, err = dbclient.Dashboard.Analytics.GetBacklogAt(ctx, guildId, r.Start())
+		return
+	})
+
+	if err := group.Wait(); err != nil {
+		_ = ctx.AbortWithError(500, app.NewError(err, "Failed to fetch ticket volume"))
+		return
+	}
+
+	opened = r.fillDays(opened)
+	closed = r.fillDays(closed)
+
+	backlog := make([]dbclient.DailyCount, len(opened))
+	count := initialBacklog
+	for i := range opened {
+		count += opened[i].Count - closed[i].Count
+		backlog[i] = dbclient.DailyCount{
+			Day:   opened[i].Day,
+			Count: count,
+		}
+	}
+
+	ctx.JSON(200, volumeResponse{
+		Opened:  opened,
+		Closed:  closed,
+		Backlog: backlog,
+	})
+}
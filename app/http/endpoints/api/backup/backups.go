@@ -0,0 +1,138 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/TicketsBot-cloud/dashboard/app"
+	"github.com/TicketsBot-cloud/dashboard/app/http/audit"
+	"github.com/TicketsBot-cloud/dashboard/backup"
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/dashboard/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// Automatic snapshots are pruned by the sweeper, but manual backups are kept until deleted
+const maxManualBackups = 25
+
+func ListBackups(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+
+	backups, err := dbclient.Dashboard.ConfigBackups.GetByGuild(c, guildId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch backups"))
+		return
+	}
+
+	c.JSON(http.StatusOK, backups)
+}
+
+func CreateBackup(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+	userId := c.Keys["userid"].(uint64)
+
+	count, err := dbclient.Dashboard.ConfigBackups.GetManualCount(c, guildId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch backups"))
+		return
+	}
+
+	if count >= maxManualBackups {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("You can only keep %d manual backups. Please delete an old backup first.", maxManualBackups))
+		return
+	}
+
+	document, err := backup.Build(c, guildId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to build backup"))
+		return
+	}
+
+	created, err := backup.Save(c, guildId, &userId, document)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to save backup"))
+		return
+	}
+
+	audit.Log(audit.LogEntry{
+		GuildId:      audit.Uint64Ptr(guildId),
+		UserId:       userId,
+		ActionType:   audit.ActionConfigBackupCreate,
+		ResourceType: audit.ResourceConfigBackup,
+		ResourceId:   audit.StringPtr(strconv.Itoa(created.Id)),
+	})
+
+	c.JSON(http.StatusOK, created)
+}
+
+// GetBackup returns a backup including its document, which can be downloaded and later restored to any guild
+func GetBackup(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+
+	backupId, err := strconv.Atoi(c.Param("backupid"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("Invalid backup ID"))
+		return
+	}
+
+	stored, ok, err := dbclient.Dashboard.ConfigBackups.Get(c, guildId, backupId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch backup"))
+		return
+	}
+
+	if !ok {
+		c.JSON(http.StatusNotFound, utils.ErrorStr("Backup not found"))
+		return
+	}
+
+	c.JSON(http.StatusOK, stored)
+}
+
+func DeleteBackup(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+	userId := c.Keys["userid"].(uint64)
+
+	backupId, err := strconv.Atoi(c.Param("backupid"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("Invalid backup ID"))
+		return
+	}
+
+	deleted, err := dbclient.Dashboard.ConfigBackups.Delete(c, guildId, backupId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to delete backup"))
+		return
+	}
+
+	if !deleted {
+		c.JSON(http.StatusNotFound, utils.ErrorStr("Backup not found"))
+		return
+	}
+
+	audit.Log(audit.LogEntry{
+		GuildId:      audit.Uint64Ptr(guildId),
+		UserId:       userId,
+		ActionType:   audit.ActionConfigBackupDelete,
+		ResourceType: audit.ResourceConfigBackup,
+		ResourceId:   audit.StringPtr(strconv.Itoa(backupId)),
+	})
+
+	c.Status(http.StatusNoContent)
+}
+
+// loadBackupDocument fetches and decodes one of the guild's stored backups
+func loadBackupDocument(c *gin.Context, guildId uint64, backupId int) (backup.Document, bool, error) {
+	stored, ok, err := dbclient.Dashboard.ConfigBackups.Get(c, guildId, backupId)
+	if err != nil || !ok {
+		return backup.Document{}, ok, err
+	}
+
+	var document backup.Document
+	if err := json.Unmarshal(stored.Data, &document); err != nil {
+		return backup.Document{}, false, err
+	}
+
+	return document, true, nil
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/TicketsBot-cloud/dashboard/app"
+	"github.com/TicketsBot-cloud/dashboard/app/http/audit"
+	"github.com/TicketsBot-cloud/dashboard/app/http/validation"
+	"github.com/TicketsBot-cloud/dashboard/backup"
+	"github.com/TicketsBot-cloud/dashboard/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// restoreBody restores either one of the guild's stored backups, or an uploaded document. Sections defaults to every
+// section of the document. Backups don't contain integration secret values, so IntegrationSecrets holds them for each
+// integration that needs activating, keyed by integration ID and then secret name.
+type restoreBody struct {
+	BackupId           *int                      `json:"backup_id"`
+	Document           *backup.Document          `json:"document"`
+	Sections           []string                  `json:"sections"`
+	IntegrationSecrets map[int]map[string]string `json:"integration_secrets"`
+	DryRun             bool                      `json:"dry_run"`
+}
+
+func RestoreBackup(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+	userId := c.Keys["userid"].(uint64)
+
+	var body restoreBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("Invalid request data. Please check your input and try again."))
+		return
+	}
+
+	if (body.BackupId == nil) == (body.Document == nil) {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("Either a backup ID or a backup document must be provided"))
+		return
+	}
+
+	sections, err := backup.ParseSections(body.Sections)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("%s", err.Error()))
+		return
+	}
+
+	var document backup.Document
+	if body.BackupId != nil {
+		var ok bool
+		document, ok, err = loadBackupDocument(c, guildId, *body.BackupId)
+		if err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch backup"))
+			return
+		}
+
+		if !ok {
+			c.JSON(http.StatusNotFound, utils.ErrorStr("Backup not found"))
+			return
+		}
+	} else {
+		document = *body.Document
+	}
+
+	result, err := backup.Restore(c, guildId, userId, document, sections, body.IntegrationSecrets, body.DryRun)
+	if err != nil {
+		var validationError *validation.InvalidInputError
+		if errors.As(err, &validationError) {
+			c.JSON(http.StatusBadRequest, utils.ErrorStr("%s", validationError.Error()))
+			return
+		}
+
+		if len(result.Applied) == 0 {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to restore backup"))
+			return
+		}
+
+		// Some sections have already been restored, so record them, and say which they were
+		logRestore(guildId, userId, body.BackupId, result)
+
+		applied := make([]string, len(result.Applied))
+		for i, section := range result.Applied {
+			applied[i] = string(section)
+		}
+
+		message := fmt.Sprintf("Failed to restore backup. Only the following sections were restored: %s", strings.Join(applied, ", "))
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, message))
+		return
+	}
+
+	if !body.DryRun {
+		logRestore(guildId, userId, body.BackupId, result)
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+func logRestore(guildId, userId uint64, backupId *int, result backup.RestoreResult) {
+	var resourceId *string
+	if backupId != nil {
+		resourceId = audit.StringPtr(strconv.Itoa(*backupId))
+	}
+
+	audit.Log(audit.LogEntry{
+		GuildId:      audit.Uint64Ptr(guildId),
+		UserId:       userId,
+		ActionType:   audit.ActionConfigBackupRestore,
+		ResourceType: audit.ResourceConfigBackup,
+		ResourceId:   resourceId,
+		NewData:      result,
+	})
+}
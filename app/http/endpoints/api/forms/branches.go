@@ -0,0 +1,218 @@
+package forms
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/TicketsBot-cloud/dashboard/app"
+	"github.com/TicketsBot-cloud/dashboard/app/http/audit"
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/dashboard/utils"
+	"github.com/TicketsBot-cloud/database"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+const maxFormBranches = 10
+
+type (
+	updateBranchesBody struct {
+		Branches []branchBody `json:"branches" validate:"dive"`
+	}
+
+	branchBody struct {
+		InputId      int      `json:"input_id" validate:"required"`
+		Values       []string `json:"values" validate:"required,min=1,max=25,dive,required,max=100"`
+		TargetFormId int      `json:"target_form_id" validate:"required"`
+	}
+)
+
+func GetBranches(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+
+	formId, ok := guildFormId(c, guildId)
+	if !ok {
+		return
+	}
+
+	branches, err := dbclient.Dashboard.FormBranches.GetByForm(c, formId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch form branches from database"))
+		return
+	}
+
+	c.JSON(200, branches)
+}
+
+// UpdateBranches replaces the follow-up forms which the user is sent on to after submitting the form. Branches must
+// depend on one of the form's select, radio group or checkbox group inputs, and must not lead back to the form.
+func UpdateBranches(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+	userId := c.Keys["userid"].(uint64)
+
+	formId, ok := guildFormId(c, guildId)
+	if !ok {
+		return
+	}
+
+	var data updateBranchesBody
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(400, utils.ErrorStr("Invalid request data. Please check your input and try again."))
+		return
+	}
+
+	if err := validate.Struct(data); err != nil {
+		var validationErrors validator.ValidationErrors
+		if !errors.As(err, &validationErrors) {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Form branch validation failed unexpectedly"))
+			return
+		}
+
+		formatted := "Your input contained the following errors:\n" + utils.FormatValidationErrors(validationErrors)
+		c.JSON(400, utils.ErrorStr("%s", formatted))
+		return
+	}
+
+	if len(data.Branches) > maxFormBranches {
+		c.JSON(400, utils.ErrorStr("Forms can have at most %d branches (current: %d branches)", maxFormBranches, len(data.Branches)))
+		return
+	}
+
+	inputs, err := dbclient.Client.FormInput.GetInputs(c, formId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch form inputs from database"))
+		return
+	}
+
+	options, err := dbclient.Client.FormInputOption.GetOptionsByForm(c, formId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch form input options from database"))
+		return
+	}
+
+	guildForms, err := dbclient.Client.Forms.GetForms(c, guildId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to load forms"))
+		return
+	}
+
+	guildBranches, err := dbclient.Dashboard.FormBranches.GetByGuild(c, guildId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch form branches from database"))
+		return
+	}
+
+	titles := make(map[int]string)
+	for _, form := range guildForms {
+		titles[form.Id] = form.Title
+	}
+
+	definitions := toCreateBody(NewFormInputDefinitions(inputs, options, nil)).Create
+
+	branches := make([]dbclient.FormBranch, len(data.Branches))
+	for i, branch := range data.Branches {
+		index := -1
+		for j, input := range inputs {
+			if input.Id == branch.InputId {
+				index = j
+				break
+			}
+		}
+
+		if index == -1 {
+			c.JSON(400, utils.ErrorStr("Input #%d (used by branch %d) not found in form #%d", branch.InputId, i+1, formId))
+			return
+		}
+
+		if err := validateSourceValues(definitions[index], branch.Values); err != nil {
+			c.JSON(400, utils.ErrorStr("Branch %d can never be followed: %v", i+1, err))
+			return
+		}
+
+		if branch.TargetFormId == formId {
+			c.JSON(400, utils.ErrorStr("Branch %d cannot lead back to the same form", i+1))
+			return
+		}
+
+		if _, ok := titles[branch.TargetFormId]; !ok {
+			c.JSON(400, utils.ErrorStr("Form #%d (used by branch %d) not found", branch.TargetFormId, i+1))
+			return
+		}
+
+		branches[i] = dbclient.FormBranch{
+			FormId:       formId,
+			InputId:      branch.InputId,
+			Values:       branch.Values,
+			TargetFormId: branch.TargetFormId,
+		}
+	}
+
+	if err := validateBranchOrder(branches); err != nil {
+		c.JSON(400, utils.ErrorStr("%v", err))
+		return
+	}
+
+	// Check that the follow-up forms, and any forms they branch to in turn, never lead back to this form
+	edges := make(map[int][]int)
+	for id, formBranches := range guildBranches {
+		for _, branch := range formBranches {
+			edges[id] = append(edges[id], branch.TargetFormId)
+		}
+	}
+
+	edges[formId] = nil
+	for _, branch := range branches {
+		edges[formId] = append(edges[formId], branch.TargetFormId)
+	}
+
+	if cycle := findBranchCycle(formId, edges); cycle != nil {
+		c.JSON(400, utils.ErrorStr("These branches would create a loop between forms: %s", formatBranchCycle(cycle, titles)))
+		return
+	}
+
+	if err := dbclient.Dashboard.FormBranches.Replace(c, formId, branches); err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to save form branches to database"))
+		return
+	}
+
+	audit.Log(audit.LogEntry{
+		GuildId:      audit.Uint64Ptr(guildId),
+		UserId:       userId,
+		ActionType:   audit.ActionFormBranchesUpdate,
+		ResourceType: database.AuditResourceForm,
+		ResourceId:   audit.StringPtr(strconv.Itoa(formId)),
+		OldData:      guildBranches[formId],
+		NewData:      branches,
+	})
+
+	c.JSON(200, branches)
+}
+
+// guildFormId parses the form_id parameter, writing an error response and returning false if the form does not exist
+// or belongs to another guild
+func guildFormId(c *gin.Context, guildId uint64) (int, bool) {
+	formId, err := strconv.Atoi(c.Param("form_id"))
+	if err != nil {
+		c.JSON(400, utils.ErrorStr("Invalid form ID provided: %s", c.Param("form_id")))
+		return 0, false
+	}
+
+	form, ok, err := dbclient.Client.Forms.Get(c, formId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch form from database"))
+		return 0, false
+	}
+
+	if !ok {
+		c.JSON(404, utils.ErrorStr("Form #%d not found", formId))
+		return 0, false
+	}
+
+	if form.GuildId != guildId {
+		c.JSON(403, utils.ErrorStr("Form #%d does not belong to guild %d", formId, guildId))
+		return 0, false
+	}
+
+	return formId, true
+}
@@ -0,0 +1,136 @@
+package forms
+
+import (
+	"github.com/TicketsBot-cloud/dashboard/utils"
+	"github.com/TicketsBot-cloud/gdl/objects/interaction/component"
+)
+
+// builtInTemplate is a form template available to every guild. Its ID is a fixed key, rather than a number, so that it
+// can't be confused with a guild's saved templates.
+type builtInTemplate struct {
+	Id     string
+	Name   string
+	Title  string
+	Inputs []FormInputDefinition
+}
+
+func shortText(label string, placeholder string, required bool) FormInputDefinition {
+	return FormInputDefinition{
+		Label:       label,
+		Placeholder: utils.Ptr(placeholder),
+		Type:        4,
+		Style:       component.TextStyleShort,
+		Required:    required,
+		MaxLength:   100,
+	}
+}
+
+func paragraph(label string, placeholder string, required bool) FormInputDefinition {
+	return FormInputDefinition{
+		Label:       label,
+		Placeholder: utils.Ptr(placeholder),
+		Type:        4,
+		Style:       component.TextStyleParagraph,
+		Required:    required,
+		MaxLength:   1024,
+	}
+}
+
+func stringSelect(label string, options ...FormInputOptionDefinition) FormInputDefinition {
+	return FormInputDefinition{
+		Label:     label,
+		Type:      3,
+		Required:  true,
+		MinLength: 1,
+		MaxLength: 1,
+		Options:   options,
+	}
+}
+
+func option(label, value string) FormInputOptionDefinition {
+	return FormInputOptionDefinition{
+		Label: label,
+		Value: value,
+	}
+}
+
+func onlyIf(input FormInputDefinition, sourcePosition int, values ...string) FormInputDefinition {
+	input.Condition = &FormInputConditionDefinition{
+		SourcePosition: sourcePosition,
+		Values:         values,
+	}
+
+	return input
+}
+
+var builtInTemplates = []builtInTemplate{
+	{
+		Id:    "bug_report",
+		Name:  "Bug report",
+		Title: "Bug Report",
+		Inputs: []FormInputDefinition{
+			shortText("Summary", "A short description of the bug", true),
+			paragraph("Steps to reproduce", "1. Go to...\n2. Click on...\n3. See the error", true),
+			paragraph("Expected behaviour", "What did you expect to happen?", true),
+			paragraph("Actual behaviour", "What happened instead?", true),
+			shortText("Version or platform", "e.g. Windows 11, Android, v2.3.1", false),
+		},
+	},
+	{
+		Id:    "staff_application",
+		Name:  "Staff application",
+		Title: "Staff Application",
+		Inputs: []FormInputDefinition{
+			stringSelect("Role",
+				option("Moderator", "moderator"),
+				option("Support", "support"),
+				option("Developer", "developer"),
+			),
+			shortText("Age", "How old are you?", true),
+			paragraph("Why do you want to join the team?", "Tell us about yourself and your motivation", true),
+			onlyIf(paragraph("Moderation experience", "Which communities have you moderated before?", true), 1, "moderator", "support"),
+			onlyIf(shortText("Portfolio or GitHub", "A link to your previous work", true), 1, "developer"),
+		},
+	},
+	{
+		Id:    "appeal",
+		Name:  "Ban or mute appeal",
+		Title: "Appeal",
+		Inputs: []FormInputDefinition{
+			stringSelect("Punishment",
+				option("Ban", "ban"),
+				option("Mute", "mute"),
+				option("Warning", "warning"),
+			),
+			shortText("When were you punished?", "An approximate date is fine", false),
+			paragraph("Why were you punished?", "In your own words", true),
+			paragraph("Why should we reconsider?", "What would you do differently?", true),
+		},
+	},
+	{
+		Id:    "purchase_issue",
+		Name:  "Purchase issue",
+		Title: "Purchase Issue",
+		Inputs: []FormInputDefinition{
+			stringSelect("Issue",
+				option("Item not received", "not_received"),
+				option("Charged incorrectly", "incorrect_charge"),
+				option("Refund request", "refund"),
+				option("Other", "other"),
+			),
+			shortText("Order or transaction ID", "Found in your receipt email", true),
+			shortText("Purchase date", "e.g. 2024-01-31", false),
+			paragraph("Details", "Anything else we should know", true),
+		},
+	},
+}
+
+func findBuiltInTemplate(id string) (builtInTemplate, bool) {
+	for _, template := range builtInTemplates {
+		if template.Id == id {
+			return template, true
+		}
+	}
+
+	return builtInTemplate{}, false
+}
@@ -0,0 +1,13 @@
+package forms
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuiltInTemplatesAreValid(t *testing.T) {
+	for _, template := range builtInTemplates {
+		assert.NoError(t, ValidateFormDefinition(template.Title, template.Inputs), template.Id)
+	}
+}
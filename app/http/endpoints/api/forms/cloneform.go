@@ -0,0 +1,168 @@
+package forms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/TicketsBot-cloud/dashboard/app"
+	"github.com/TicketsBot-cloud/dashboard/app/http/audit"
+	"github.com/TicketsBot-cloud/dashboard/app/http/validation"
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/dashboard/utils"
+	"github.com/TicketsBot-cloud/database"
+	"github.com/gin-gonic/gin"
+)
+
+type cloneFormBody struct {
+	Title *string `json:"title"`
+}
+
+// CloneForm creates a copy of a form, including all of its inputs, their options and conditions, and the form's
+// branches. The copy keeps the title of the original unless a new one is given.
+func CloneForm(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+	userId := c.Keys["userid"].(uint64)
+
+	formId, err := strconv.Atoi(c.Param("form_id"))
+	if err != nil {
+		c.JSON(400, utils.ErrorStr("Invalid form ID provided: %s", c.Param("form_id")))
+		return
+	}
+
+	var data cloneFormBody
+	if err := c.ShouldBindJSON(&data); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(400, utils.ErrorStr("Invalid request data. Please check your input and try again."))
+		return
+	}
+
+	form, ok, err := dbclient.Client.Forms.Get(c, formId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch form from database"))
+		return
+	}
+
+	if !ok {
+		c.JSON(404, utils.ErrorStr("Form #%d not found", formId))
+		return
+	}
+
+	if form.GuildId != guildId {
+		c.JSON(403, utils.ErrorStr("Form #%d does not belong to guild %d", formId, guildId))
+		return
+	}
+
+	inputs, err := dbclient.Client.FormInput.GetInputs(c, formId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch form inputs from database"))
+		return
+	}
+
+	options, err := dbclient.Client.FormInputOption.GetOptionsByForm(c, formId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch form input options from database"))
+		return
+	}
+
+	conditions, err := dbclient.Dashboard.FormInputConditions.GetByForm(c, formId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch form input conditions from database"))
+		return
+	}
+
+	branches, err := dbclient.Dashboard.FormBranches.GetByForm(c, formId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch form branches from database"))
+		return
+	}
+
+	title := form.Title
+	if data.Title != nil {
+		title = *data.Title
+	}
+
+	definitions := NewFormInputDefinitions(inputs, options, conditions)
+	if err := ValidateFormDefinition(title, definitions); err != nil {
+		var validationError *validation.InvalidInputError
+		if errors.As(err, &validationError) {
+			c.JSON(400, utils.ErrorStr("%s", validationError.Error()))
+		} else {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Form validation failed unexpectedly"))
+		}
+
+		return
+	}
+
+	clone, err := CreateFormFromDefinition(c, guildId, title, definitions)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to create form in database"))
+		return
+	}
+
+	clonedBranches, err := cloneBranches(c, inputs, clone.Id, branches)
+	if err != nil {
+		// Don't leave a partial copy behind
+		_ = dbclient.Client.Forms.Delete(c, clone.Id)
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to copy form branches"))
+		return
+	}
+
+	audit.Log(audit.LogEntry{
+		GuildId:      audit.Uint64Ptr(guildId),
+		UserId:       userId,
+		ActionType:   database.AuditActionFormCreate,
+		ResourceType: database.AuditResourceForm,
+		ResourceId:   audit.StringPtr(strconv.Itoa(clone.Id)),
+		NewData: map[string]interface{}{
+			"title":    title,
+			"inputs":   definitions,
+			"branches": clonedBranches,
+		},
+		Metadata: map[string]interface{}{
+			"cloned_from": formId,
+		},
+	})
+
+	c.JSON(200, clone)
+}
+
+// cloneBranches copies the branches to the clone, replacing the IDs of the original inputs with those of the copies,
+// which are in the same positions
+func cloneBranches(ctx context.Context, inputs []database.FormInput, cloneId int, branches []dbclient.FormBranch) ([]dbclient.FormBranch, error) {
+	if len(branches) == 0 {
+		return branches, nil
+	}
+
+	clonedInputs, err := dbclient.Client.FormInput.GetInputs(ctx, cloneId)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(clonedInputs) != len(inputs) {
+		return nil, fmt.Errorf("form #%d has %d inputs, expected %d", cloneId, len(clonedInputs), len(inputs))
+	}
+
+	inputIds := make(map[int]int)
+	for i, input := range inputs {
+		inputIds[input.Id] = clonedInputs[i].Id
+	}
+
+	cloned := make([]dbclient.FormBranch, len(branches))
+	for i, branch := range branches {
+		cloned[i] = dbclient.FormBranch{
+			FormId:       cloneId,
+			InputId:      inputIds[branch.InputId],
+			Values:       branch.Values,
+			TargetFormId: branch.TargetFormId,
+		}
+	}
+
+	if err := dbclient.Dashboard.FormBranches.Replace(ctx, cloneId, cloned); err != nil {
+		return nil, err
+	}
+
+	return cloned, nil
+}
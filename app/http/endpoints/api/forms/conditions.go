@@ -0,0 +1,143 @@
+package forms
+
+import (
+	"fmt"
+	"strings"
+
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+)
+
+// Inputs which can be used as the source of a condition or branch, as they have a fixed set of values
+var conditionSourceTypes = map[int]bool{
+	3:  true, // String select
+	21: true, // Radio group
+	22: true, // Checkbox group
+}
+
+// inputCondition only shows the input if the input at SourcePosition has any of the values. Positions are used rather
+// than IDs, so that conditions can refer to inputs created in the same request.
+type inputCondition struct {
+	SourcePosition int      `json:"source_position" validate:"required,min=1,max=5"`
+	Values         []string `json:"values" validate:"required,min=1,max=25,dive,required,max=100"`
+}
+
+// validateConditions checks that each condition depends on an earlier input in the form which has a fixed set of
+// values, and that the condition can be met. Requiring the source to come first rules out cycles.
+func validateConditions(inputs []inputCreateBody) error {
+	byPosition := make(map[int]inputCreateBody)
+	for _, input := range inputs {
+		byPosition[input.Position] = input
+	}
+
+	for _, input := range inputs {
+		if input.Condition == nil {
+			continue
+		}
+
+		source, ok := byPosition[input.Condition.SourcePosition]
+		if !ok {
+			return fmt.Errorf("Input \"%s\" depends on input %d, which does not exist", input.Label, input.Condition.SourcePosition)
+		}
+
+		if source.Position == input.Position {
+			return fmt.Errorf("Input \"%s\" cannot depend on itself", input.Label)
+		}
+
+		if source.Position > input.Position {
+			return fmt.Errorf("Input \"%s\" can only depend on inputs which come before it", input.Label)
+		}
+
+		if err := validateSourceValues(source, input.Condition.Values); err != nil {
+			return fmt.Errorf("Input \"%s\" can never be shown: %w", input.Label, err)
+		}
+	}
+
+	return nil
+}
+
+// validateSourceValues checks that the source input could take each of the values
+func validateSourceValues(source inputCreateBody, values []string) error {
+	if !conditionSourceTypes[source.Type] {
+		return fmt.Errorf("\"%s\" is not a select, radio group or checkbox group input", source.Label)
+	}
+
+	for _, value := range values {
+		found := false
+		for _, option := range source.Options {
+			if option.Value == value {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("\"%s\" is not an option of \"%s\"", value, source.Label)
+		}
+	}
+
+	return nil
+}
+
+// validateBranchOrder rejects branches which can never be followed, because earlier branches on the same input already
+// match all of their values
+func validateBranchOrder(branches []dbclient.FormBranch) error {
+	covered := make(map[int]map[string]bool)
+	for i, branch := range branches {
+		values, ok := covered[branch.InputId]
+		if !ok {
+			values = make(map[string]bool)
+			covered[branch.InputId] = values
+		}
+
+		reachable := false
+		for _, value := range branch.Values {
+			if !values[value] {
+				reachable = true
+				values[value] = true
+			}
+		}
+
+		if !reachable {
+			return fmt.Errorf("Branch %d can never be followed, as earlier branches match all of its values", i+1)
+		}
+	}
+
+	return nil
+}
+
+// findBranchCycle returns the forms making up a cycle which passes through formId, if following the branches between
+// forms could lead back to it. edges holds the target form IDs of each form's branches.
+func findBranchCycle(formId int, edges map[int][]int) []int {
+	visited := make(map[int]bool)
+
+	var visit func(current int, path []int) []int
+	visit = func(current int, path []int) []int {
+		for _, target := range edges[current] {
+			if target == formId {
+				return append(path, target)
+			}
+
+			if visited[target] {
+				continue
+			}
+
+			visited[target] = true
+			if cycle := visit(target, append(path, target)); cycle != nil {
+				return cycle
+			}
+		}
+
+		return nil
+	}
+
+	return visit(formId, []int{formId})
+}
+
+func formatBranchCycle(cycle []int, titles map[int]string) string {
+	names := make([]string, len(cycle))
+	for i, formId := range cycle {
+		names[i] = fmt.Sprintf("\"%s\"", titles[formId])
+	}
+
+	return strings.Join(names, " → ")
+}
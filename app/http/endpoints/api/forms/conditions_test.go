@@ -0,0 +1,91 @@
+package forms
+
+import (
+	"testing"
+
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func roleSelect() inputCreateBody {
+	return inputCreateBody{
+		Label:    "Role",
+		Type:     3,
+		Position: 1,
+		Options: []inputOption{
+			{Label: "Moderator", Value: "moderator"},
+			{Label: "Developer", Value: "developer"},
+		},
+	}
+}
+
+func TestValidateConditions(t *testing.T) {
+	followUp := inputCreateBody{
+		Label:     "GitHub profile",
+		Type:      4,
+		Position:  2,
+		Condition: &inputCondition{SourcePosition: 1, Values: []string{"developer"}},
+	}
+
+	assert.NoError(t, validateConditions([]inputCreateBody{roleSelect(), followUp}))
+
+	followUp.Condition.Values = []string{"admin"}
+	assert.Error(t, validateConditions([]inputCreateBody{roleSelect(), followUp}), "value is not an option")
+
+	followUp.Condition = &inputCondition{SourcePosition: 2, Values: []string{"developer"}}
+	assert.Error(t, validateConditions([]inputCreateBody{roleSelect(), followUp}), "depends on itself")
+
+	followUp.Condition = &inputCondition{SourcePosition: 3, Values: []string{"developer"}}
+	assert.Error(t, validateConditions([]inputCreateBody{roleSelect(), followUp}), "source does not exist")
+}
+
+func TestValidateConditionsRejectsLaterAndTextSources(t *testing.T) {
+	source := roleSelect()
+	source.Position = 2
+
+	dependent := inputCreateBody{
+		Label:     "Experience",
+		Type:      4,
+		Position:  1,
+		Condition: &inputCondition{SourcePosition: 2, Values: []string{"developer"}},
+	}
+
+	assert.Error(t, validateConditions([]inputCreateBody{dependent, source}), "source comes after the input")
+
+	text := inputCreateBody{Label: "Name", Type: 4, Position: 1}
+	dependent.Position = 2
+	dependent.Condition.SourcePosition = 1
+	assert.Error(t, validateConditions([]inputCreateBody{text, dependent}), "source has no fixed values")
+}
+
+func TestValidateBranchOrder(t *testing.T) {
+	assert.NoError(t, validateBranchOrder([]dbclient.FormBranch{
+		{InputId: 1, Values: []string{"moderator"}, TargetFormId: 2},
+		{InputId: 1, Values: []string{"developer"}, TargetFormId: 3},
+	}))
+
+	assert.Error(t, validateBranchOrder([]dbclient.FormBranch{
+		{InputId: 1, Values: []string{"moderator", "developer"}, TargetFormId: 2},
+		{InputId: 1, Values: []string{"developer"}, TargetFormId: 3},
+	}))
+}
+
+func TestFindBranchCycle(t *testing.T) {
+	assert.Nil(t, findBranchCycle(1, map[int][]int{
+		1: {2, 3},
+		2: {3},
+	}))
+
+	assert.Equal(t, []int{1, 2, 3, 1}, findBranchCycle(1, map[int][]int{
+		1: {2},
+		2: {3},
+		3: {1},
+	}))
+
+	// Cycles which don't pass through the form being updated already existed, and are not its concern
+	assert.Nil(t, findBranchCycle(1, map[int][]int{
+		1: {2},
+		2: {3},
+		3: {2},
+	}))
+}
@@ -14,8 +14,11 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// createFormBody creates an empty form, or one with the inputs of the template with the given ID. The title is
+// optional when using a template.
 type createFormBody struct {
-	Title string `json:"title"`
+	Title      string  `json:"title"`
+	TemplateId *string `json:"template_id"`
 }
 
 func CreateForm(c *gin.Context) {
@@ -28,6 +31,11 @@ func CreateForm(c *gin.Context) {
 		return
 	}
 
+	if data.TemplateId != nil {
+		createFormFromTemplate(c, guildId, userId, data)
+		return
+	}
+
 	// Validate title is not empty or whitespace-only
 	if len(strings.TrimSpace(data.Title)) == 0 {
 		c.JSON(400, utils.ErrorStr("Form title cannot be empty"))
@@ -0,0 +1,202 @@
+package forms
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/TicketsBot-cloud/dashboard/app/http/validation"
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/dashboard/utils"
+	"github.com/TicketsBot-cloud/database"
+	"github.com/TicketsBot-cloud/gdl/objects/interaction/component"
+	"github.com/go-playground/validator/v10"
+)
+
+// FormInputDefinition describes an input independently of any existing form, so that a whole form can be created in
+// one call, e.g. when importing panels from another guild. Inputs are positioned in the order they are given.
+type FormInputDefinition struct {
+	Label       string                        `json:"label"`
+	Description *string                       `json:"description,omitempty"`
+	Placeholder *string                       `json:"placeholder,omitempty"`
+	Type        int                           `json:"type"`
+	Style       component.TextStyleTypes      `json:"style"`
+	Required    bool                          `json:"required"`
+	MinLength   uint16                        `json:"min_length"`
+	MaxLength   uint16                        `json:"max_length"`
+	Options     []FormInputOptionDefinition   `json:"options,omitempty"`
+	Condition   *FormInputConditionDefinition `json:"condition,omitempty"`
+}
+
+type FormInputOptionDefinition struct {
+	Label       string  `json:"label"`
+	Description *string `json:"description,omitempty"`
+	Value       string  `json:"value"`
+}
+
+// FormInputConditionDefinition only shows the input if the input at SourcePosition, counting from 1 in the order the
+// inputs are given, has any of the values
+type FormInputConditionDefinition struct {
+	SourcePosition int      `json:"source_position"`
+	Values         []string `json:"values"`
+}
+
+// NewFormInputDefinitions converts a form's saved inputs, keyed by input ID in the case of the options and conditions
+func NewFormInputDefinitions(inputs []database.FormInput, options map[int][]database.FormInputOption, conditions map[int]dbclient.FormInputCondition) []FormInputDefinition {
+	positions := make(map[int]int)
+	for i, input := range inputs {
+		positions[input.Id] = i + 1
+	}
+
+	definitions := make([]FormInputDefinition, len(inputs))
+	for i, input := range inputs {
+		definition := FormInputDefinition{
+			Label:       input.Label,
+			Description: input.Description,
+			Placeholder: input.Placeholder,
+			Type:        input.Type,
+			Style:       component.TextStyleTypes(input.Style),
+			Required:    input.Required,
+		}
+
+		if input.MinLength != nil {
+			definition.MinLength = *input.MinLength
+		}
+
+		if input.MaxLength != nil {
+			definition.MaxLength = *input.MaxLength
+		}
+
+		for _, option := range options[input.Id] {
+			definition.Options = append(definition.Options, FormInputOptionDefinition{
+				Label:       option.Label,
+				Description: option.Description,
+				Value:       option.Value,
+			})
+		}
+
+		if condition, ok := conditions[input.Id]; ok {
+			definition.Condition = &FormInputConditionDefinition{
+				SourcePosition: positions[condition.SourceInputId],
+				Values:         condition.Values,
+			}
+		}
+
+		definitions[i] = definition
+	}
+
+	return definitions
+}
+
+func toCreateBody(inputs []FormInputDefinition) updateInputsBody {
+	body := updateInputsBody{
+		Create: make([]inputCreateBody, len(inputs)),
+	}
+
+	for i, input := range inputs {
+		options := make([]inputOption, len(input.Options))
+		for j, option := range input.Options {
+			options[j] = inputOption{
+				Label:       option.Label,
+				Description: option.Description,
+				Value:       option.Value,
+			}
+		}
+
+		var condition *inputCondition
+		if input.Condition != nil {
+			condition = &inputCondition{
+				SourcePosition: input.Condition.SourcePosition,
+				Values:         input.Condition.Values,
+			}
+		}
+
+		body.Create[i] = inputCreateBody{
+			Label:       input.Label,
+			Description: input.Description,
+			Placeholder: input.Placeholder,
+			Type:        input.Type,
+			Position:    i + 1,
+			Style:       input.Style,
+			Required:    input.Required,
+			MinLength:   input.MinLength,
+			MaxLength:   input.MaxLength,
+			Options:     options,
+			Condition:   condition,
+		}
+	}
+
+	return body
+}
+
+// ValidateFormDefinition applies the same rules as CreateForm and UpdateInputs, returning a
+// *validation.InvalidInputError if the form is invalid
+func ValidateFormDefinition(title string, inputs []FormInputDefinition) error {
+	if len(strings.TrimSpace(title)) == 0 {
+		return validation.NewInvalidInputError("Form title cannot be empty")
+	}
+
+	if utf8.RuneCountInString(title) > 45 {
+		return validation.NewInvalidInputErrorf("Form title must be 45 characters or less (current: %d characters)", utf8.RuneCountInString(title))
+	}
+
+	if len(inputs) == 0 || len(inputs) > 5 {
+		return validation.NewInvalidInputErrorf("Forms must have between 1 and 5 inputs (current: %d inputs)", len(inputs))
+	}
+
+	body := toCreateBody(inputs)
+	if err := validate.Struct(body); err != nil {
+		var validationErrors validator.ValidationErrors
+		if !errors.As(err, &validationErrors) {
+			return err
+		}
+
+		return validation.NewInvalidInputError("Your input contained the following errors:\n" + utils.FormatValidationErrors(validationErrors))
+	}
+
+	optionTypes := map[int]string{
+		3:  "String select",
+		21: "Radio group",
+		22: "Checkbox group",
+	}
+
+	for _, input := range body.Create {
+		if err := validateInputOptions(input, optionTypes); err != nil {
+			return validation.NewInvalidInputError(err.Error())
+		}
+	}
+
+	if err := validateConditions(body.Create); err != nil {
+		return validation.NewInvalidInputError(err.Error())
+	}
+
+	return nil
+}
+
+// CreateFormFromDefinition creates a form along with its inputs. The definition must already have been validated with
+// ValidateFormDefinition.
+func CreateFormFromDefinition(ctx context.Context, guildId uint64, title string, inputs []FormInputDefinition) (database.Form, error) {
+	customId, err := utils.RandString(30)
+	if err != nil {
+		return database.Form{}, err
+	}
+
+	id, err := dbclient.Client.Forms.Create(ctx, guildId, title, customId)
+	if err != nil {
+		return database.Form{}, err
+	}
+
+	if err := saveInputs(ctx, id, toCreateBody(inputs), nil); err != nil {
+		// Don't leave an empty form behind
+		_ = dbclient.Client.Forms.Delete(ctx, id)
+		return database.Form{}, err
+	}
+
+	return database.Form{
+		Id:       id,
+		GuildId:  guildId,
+		Title:    title,
+		CustomId: customId,
+	}, nil
+}
@@ -11,12 +11,14 @@ import (
 
 type embeddedFormInput struct {
 	database.FormInput
-	Options []database.FormInputOption `json:"options"`
+	Options   []database.FormInputOption   `json:"options"`
+	Condition *dbclient.FormInputCondition `json:"condition"`
 }
 
 type embeddedForm struct {
 	database.Form
-	Inputs []embeddedFormInput `json:"inputs"`
+	Inputs   []embeddedFormInput   `json:"inputs"`
+	Branches []dbclient.FormBranch `json:"branches"`
 }
 
 func GetForms(c *gin.Context) {
@@ -40,6 +42,18 @@ func GetForms(c *gin.Context) {
 		return
 	}
 
+	conditions, err := dbclient.Dashboard.FormInputConditions.GetByGuild(c, guildId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to load forms"))
+		return
+	}
+
+	branches, err := dbclient.Dashboard.FormBranches.GetByGuild(c, guildId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to load forms"))
+		return
+	}
+
 	data := make([]embeddedForm, len(forms))
 	for i, form := range forms {
 		formInputs, ok := inputs[form.Id]
@@ -53,11 +67,21 @@ func GetForms(c *gin.Context) {
 				FormInput: input,
 				Options:   options[input.Id],
 			}
+
+			if condition, ok := conditions[input.Id]; ok {
+				inputs[j].Condition = &condition
+			}
+		}
+
+		formBranches, ok := branches[form.Id]
+		if !ok {
+			formBranches = make([]dbclient.FormBranch, 0)
 		}
 
 		data[i] = embeddedForm{
-			Form:   form,
-			Inputs: inputs,
+			Form:     form,
+			Inputs:   inputs,
+			Branches: formBranches,
 		}
 	}
 
@@ -0,0 +1,309 @@
+package forms
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/TicketsBot-cloud/dashboard/app"
+	"github.com/TicketsBot-cloud/dashboard/app/http/audit"
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/dashboard/utils"
+	"github.com/TicketsBot-cloud/database"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+)
+
+type (
+	reorderInputsBody struct {
+		InputIds []int `json:"input_ids" validate:"required,min=1,max=5"`
+	}
+
+	moveInputBody struct {
+		FormId   int  `json:"form_id" validate:"required"`
+		Position *int `json:"position" validate:"omitempty,min=1,max=5"`
+	}
+)
+
+// ReorderInputs sets the order of the form's inputs, without having to resubmit them. Every input must be included.
+func ReorderInputs(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+	userId := c.Keys["userid"].(uint64)
+
+	formId, ok := guildFormId(c, guildId)
+	if !ok {
+		return
+	}
+
+	var data reorderInputsBody
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(400, utils.ErrorStr("Invalid request data. Please check your input and try again."))
+		return
+	}
+
+	if err := validate.Struct(data); err != nil {
+		var validationErrors validator.ValidationErrors
+		if !errors.As(err, &validationErrors) {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Form input validation failed unexpectedly"))
+			return
+		}
+
+		formatted := "Your input contained the following errors:\n" + utils.FormatValidationErrors(validationErrors)
+		c.JSON(400, utils.ErrorStr("%s", formatted))
+		return
+	}
+
+	inputs, err := dbclient.Client.FormInput.GetInputs(c, formId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch form inputs from database"))
+		return
+	}
+
+	conditions, err := dbclient.Dashboard.FormInputConditions.GetByForm(c, formId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch form input conditions from database"))
+		return
+	}
+
+	if err := validateInputOrder(inputs, conditions, data.InputIds); err != nil {
+		c.JSON(400, utils.ErrorStr("%v", err))
+		return
+	}
+
+	if err := reorderInputs(c, formId, data.InputIds); err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to save form inputs to database"))
+		return
+	}
+
+	oldOrder := make([]int, len(inputs))
+	for i, input := range inputs {
+		oldOrder[i] = input.Id
+	}
+
+	audit.Log(audit.LogEntry{
+		GuildId:      audit.Uint64Ptr(guildId),
+		UserId:       userId,
+		ActionType:   audit.ActionFormInputsReorder,
+		ResourceType: database.AuditResourceFormInput,
+		ResourceId:   audit.StringPtr(strconv.Itoa(formId)),
+		OldData:      oldOrder,
+		NewData:      data.InputIds,
+	})
+
+	c.Status(204)
+}
+
+// MoveInput moves an input, along with its options, to another of the guild's forms. The input is added to the end of
+// the target form, unless a position is given.
+func MoveInput(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+	userId := c.Keys["userid"].(uint64)
+
+	formId, ok := guildFormId(c, guildId)
+	if !ok {
+		return
+	}
+
+	inputId, err := strconv.Atoi(c.Param("input_id"))
+	if err != nil {
+		c.JSON(400, utils.ErrorStr("Invalid input ID provided: %s", c.Param("input_id")))
+		return
+	}
+
+	var data moveInputBody
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(400, utils.ErrorStr("Invalid request data. Please check your input and try again."))
+		return
+	}
+
+	if err := validate.Struct(data); err != nil {
+		var validationErrors validator.ValidationErrors
+		if !errors.As(err, &validationErrors) {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Form input validation failed unexpectedly"))
+			return
+		}
+
+		formatted := "Your input contained the following errors:\n" + utils.FormatValidationErrors(validationErrors)
+		c.JSON(400, utils.ErrorStr("%s", formatted))
+		return
+	}
+
+	if data.FormId == formId {
+		c.JSON(400, utils.ErrorStr("Input #%d is already in form #%d", inputId, formId))
+		return
+	}
+
+	inputs, err := dbclient.Client.FormInput.GetInputs(c, formId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch form inputs from database"))
+		return
+	}
+
+	input := utils.FindMap(inputs, inputId, idMapper)
+	if input == nil {
+		c.JSON(404, utils.ErrorStr("Input #%d not found in form #%d", inputId, formId))
+		return
+	}
+
+	if len(inputs) <= 1 {
+		c.JSON(400, utils.ErrorStr("Forms must have between 1 and 5 inputs, so the last input cannot be moved"))
+		return
+	}
+
+	targetForm, ok, err := dbclient.Client.Forms.Get(c, data.FormId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch form from database"))
+		return
+	}
+
+	if !ok || targetForm.GuildId != guildId {
+		c.JSON(404, utils.ErrorStr("Form #%d not found", data.FormId))
+		return
+	}
+
+	targetInputs, err := dbclient.Client.FormInput.GetInputs(c, targetForm.Id)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch form inputs from database"))
+		return
+	}
+
+	if len(targetInputs) >= 5 {
+		c.JSON(400, utils.ErrorStr("Form #%d already has the maximum of 5 inputs", targetForm.Id))
+		return
+	}
+
+	position := len(targetInputs) + 1
+	if data.Position != nil {
+		if *data.Position > position {
+			c.JSON(400, utils.ErrorStr("Position must be between 1 and %d", position))
+			return
+		}
+
+		position = *data.Position
+	}
+
+	conditions, err := dbclient.Dashboard.FormInputConditions.GetByForm(c, formId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch form input conditions from database"))
+		return
+	}
+
+	branches, err := dbclient.Dashboard.FormBranches.GetByForm(c, formId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch form branches from database"))
+		return
+	}
+
+	if err := validateInputMove(*input, conditions, branches); err != nil {
+		c.JSON(400, utils.ErrorStr("%v", err))
+		return
+	}
+
+	if err := moveInput(c, inputId, formId, targetForm.Id, position); err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to save form inputs to database"))
+		return
+	}
+
+	audit.Log(audit.LogEntry{
+		GuildId:      audit.Uint64Ptr(guildId),
+		UserId:       userId,
+		ActionType:   audit.ActionFormInputMove,
+		ResourceType: database.AuditResourceFormInput,
+		ResourceId:   audit.StringPtr(strconv.Itoa(inputId)),
+		OldData:      input,
+		NewData: map[string]interface{}{
+			"form_id":  targetForm.Id,
+			"position": position,
+		},
+	})
+
+	c.Status(204)
+}
+
+// validateInputOrder checks that inputIds contains each of the form's inputs exactly once, and that conditions still
+// depend on inputs which come before them
+func validateInputOrder(inputs []database.FormInput, conditions map[int]dbclient.FormInputCondition, inputIds []int) error {
+	if len(inputIds) != len(inputs) {
+		return fmt.Errorf("All %d inputs must be included in the new order (found %d)", len(inputs), len(inputIds))
+	}
+
+	positions := make(map[int]int)
+	for i, id := range inputIds {
+		if !utils.ExistsMap(inputs, id, idMapper) {
+			return fmt.Errorf("Input #%d not found in form", id)
+		}
+
+		if _, ok := positions[id]; ok {
+			return fmt.Errorf("Input #%d is included more than once", id)
+		}
+
+		positions[id] = i + 1
+	}
+
+	for _, input := range inputs {
+		condition, ok := conditions[input.Id]
+		if !ok {
+			continue
+		}
+
+		if positions[condition.SourceInputId] > positions[input.Id] {
+			return fmt.Errorf("Input \"%s\" can only depend on inputs which come before it", input.Label)
+		}
+	}
+
+	return nil
+}
+
+// validateInputMove checks that the input isn't linked to the rest of its form by a condition or branch, which would
+// no longer hold once it has been moved
+func validateInputMove(input database.FormInput, conditions map[int]dbclient.FormInputCondition, branches []dbclient.FormBranch) error {
+	if _, ok := conditions[input.Id]; ok {
+		return fmt.Errorf("Input \"%s\" depends on another input, and cannot be moved until its condition is removed", input.Label)
+	}
+
+	for _, condition := range conditions {
+		if condition.SourceInputId == input.Id {
+			return fmt.Errorf("Other inputs depend on input \"%s\", so it cannot be moved until their conditions are removed", input.Label)
+		}
+	}
+
+	for i, branch := range branches {
+		if branch.InputId == input.Id {
+			return fmt.Errorf("Input \"%s\" is used by branch %d, and cannot be moved until the branch is removed", input.Label, i+1)
+		}
+	}
+
+	return nil
+}
+
+func reorderInputs(ctx context.Context, formId int, inputIds []int) error {
+	tx, err := dbclient.Client.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(context.Background())
+
+	if err := dbclient.Dashboard.FormInputPositions.ReorderTx(ctx, tx, formId, inputIds); err != nil {
+		return err
+	}
+
+	return tx.Commit(context.Background())
+}
+
+func moveInput(ctx context.Context, inputId, formId, targetFormId, position int) error {
+	tx, err := dbclient.Client.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(context.Background())
+
+	if err := dbclient.Dashboard.FormInputPositions.MoveTx(ctx, tx, inputId, formId, targetFormId, position); err != nil {
+		return err
+	}
+
+	return tx.Commit(context.Background())
+}
@@ -0,0 +1,27 @@
+package forms
+
+import (
+	"testing"
+
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateInputOrder(t *testing.T) {
+	inputs := []database.FormInput{
+		{Id: 1, Label: "Role"},
+		{Id: 2, Label: "Experience"},
+		{Id: 3, Label: "Age"},
+	}
+
+	conditions := map[int]dbclient.FormInputCondition{
+		2: {InputId: 2, SourceInputId: 1, Values: []string{"moderator"}},
+	}
+
+	assert.NoError(t, validateInputOrder(inputs, conditions, []int{3, 1, 2}))
+	assert.Error(t, validateInputOrder(inputs, conditions, []int{2, 1, 3}), "condition source after input")
+	assert.Error(t, validateInputOrder(inputs, conditions, []int{1, 2}), "missing input")
+	assert.Error(t, validateInputOrder(inputs, conditions, []int{1, 2, 2}), "repeated input")
+	assert.Error(t, validateInputOrder(inputs, conditions, []int{1, 2, 4}), "unknown input")
+}
@@ -0,0 +1,297 @@
+package forms
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TicketsBot-cloud/dashboard/app"
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/dashboard/utils"
+	"github.com/TicketsBot-cloud/database"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	responsesPageLimit = 50
+	maxExportResponses = 10000
+	responseDateFormat = "2006-01-02"
+)
+
+type formResponse struct {
+	TicketId    int               `json:"ticket_id"`
+	UserId      uint64            `json:"user_id,string"`
+	SubmittedAt time.Time         `json:"submitted_at"`
+	Answers     map[string]string `json:"answers"`
+}
+
+type paginatedFormResponses struct {
+	Inputs      []string       `json:"inputs"`
+	Responses   []formResponse `json:"responses"`
+	TotalCount  int            `json:"total_count"`
+	TotalPages  int            `json:"total_pages"`
+	CurrentPage int            `json:"current_page"`
+}
+
+// GetResponses lists the answers given to a form used as an exit survey, a page at a time and most recent first.
+// Answers are keyed by input label. Answers given when opening a ticket are not stored, so other forms are rejected.
+func GetResponses(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+
+	formId, ok := exitSurveyFormId(c, guildId)
+	if !ok {
+		return
+	}
+
+	page := 1
+	if raw := c.Query("page"); raw != "" {
+		var err error
+		page, err = strconv.Atoi(raw)
+		if err != nil || page < 1 {
+			c.JSON(400, utils.ErrorStr("Invalid page number"))
+			return
+		}
+	}
+
+	columns, responses, total, ok := searchResponses(c, guildId, formId, responsesPageLimit, (page-1)*responsesPageLimit)
+	if !ok {
+		return
+	}
+
+	totalPages := (total + responsesPageLimit - 1) / responsesPageLimit
+	if totalPages == 0 {
+		totalPages = 1 // At least 1 page even if empty
+	}
+
+	c.JSON(200, paginatedFormResponses{
+		Inputs:      columns,
+		Responses:   responses,
+		TotalCount:  total,
+		TotalPages:  totalPages,
+		CurrentPage: page,
+	})
+}
+
+// ExportResponses returns the form's responses matching the same filters as GetResponses at once, as a CSV or JSON
+// file, for processing in a spreadsheet. At most maxExportResponses are included: the X-Total-Count header holds the
+// number of matching responses, and X-Truncated is set to true if some were left out.
+func ExportResponses(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+
+	formId, ok := exitSurveyFormId(c, guildId)
+	if !ok {
+		return
+	}
+
+	format := c.DefaultQuery("format", "csv")
+	if format != "json" && format != "csv" {
+		c.JSON(400, utils.ErrorStr("Invalid format: must be json or csv"))
+		return
+	}
+
+	columns, responses, total, ok := searchResponses(c, guildId, formId, maxExportResponses, 0)
+	if !ok {
+		return
+	}
+
+	c.Header("Access-Control-Expose-Headers", "Content-Disposition, X-Total-Count, X-Truncated")
+	c.Header("X-Total-Count", strconv.Itoa(total))
+	c.Header("X-Truncated", strconv.FormatBool(total > len(responses)))
+
+	filename := fmt.Sprintf("form-%d-responses.%s", formId, format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	if format == "csv" {
+		writeResponsesCsv(c, columns, responses)
+	} else {
+		c.JSON(200, responses)
+	}
+}
+
+// exitSurveyFormId reads the form ID from the request, writing an error response and returning false unless the form
+// belongs to the guild and is used as an exit survey
+func exitSurveyFormId(c *gin.Context, guildId uint64) (int, bool) {
+	formId, ok := guildFormId(c, guildId)
+	if !ok {
+		return 0, false
+	}
+
+	isExitSurvey, err := dbclient.Dashboard.FormResponses.IsExitSurvey(c, guildId, formId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch form from database"))
+		return 0, false
+	}
+
+	if !isExitSurvey {
+		c.JSON(400, utils.ErrorStr("Responses are only available for forms used as an exit survey. Answers given when opening a ticket are not stored, so they cannot be listed."))
+		return 0, false
+	}
+
+	return formId, true
+}
+
+// searchResponses returns the input labels in form order, and the responses matching the request's filters. It writes
+// an error response and returns false if the request is invalid or the search fails.
+func searchResponses(c *gin.Context, guildId uint64, formId, limit, offset int) ([]string, []formResponse, int, bool) {
+	query, err := parseResponseQuery(c, guildId, formId)
+	if err != nil {
+		c.JSON(400, utils.ErrorStr("%s", err.Error()))
+		return nil, nil, 0, false
+	}
+
+	query.Limit = limit
+	query.Offset = offset
+
+	inputs, err := dbclient.Client.FormInput.GetInputs(c, formId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch form inputs from database"))
+		return nil, nil, 0, false
+	}
+
+	if query.InputId != nil && !utils.ExistsMap(inputs, *query.InputId, idMapper) {
+		c.JSON(400, utils.ErrorStr("Input #%d not found in form #%d", *query.InputId, formId))
+		return nil, nil, 0, false
+	}
+
+	responses, total, err := dbclient.Dashboard.FormResponses.Search(c, query)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch form responses from database"))
+		return nil, nil, 0, false
+	}
+
+	labels := inputLabels(inputs)
+	columns := make([]string, len(inputs))
+	for i, input := range inputs {
+		columns[i] = labels[input.Id]
+	}
+
+	wrapped := make([]formResponse, len(responses))
+	for i, response := range responses {
+		answers := make(map[string]string)
+		for inputId, answer := range response.Answers {
+			// Answers to inputs which have since been deleted are removed along with the input
+			if label, ok := labels[inputId]; ok {
+				answers[label] = answer
+			}
+		}
+
+		wrapped[i] = formResponse{
+			TicketId:    response.TicketId,
+			UserId:      response.UserId,
+			SubmittedAt: response.SubmittedAt,
+			Answers:     answers,
+		}
+	}
+
+	return columns, wrapped, total, true
+}
+
+// parseResponseQuery reads the from and to dates, which are inclusive and in UTC, and the input_id and answer filters
+func parseResponseQuery(c *gin.Context, guildId uint64, formId int) (dbclient.FormResponseQuery, error) {
+	query := dbclient.FormResponseQuery{
+		GuildId: guildId,
+		FormId:  formId,
+	}
+
+	if raw := c.Query("from"); raw != "" {
+		from, err := time.Parse(responseDateFormat, raw)
+		if err != nil {
+			return query, errors.New("Invalid start date, expected YYYY-MM-DD")
+		}
+
+		query.From = &from
+	}
+
+	if raw := c.Query("to"); raw != "" {
+		to, err := time.Parse(responseDateFormat, raw)
+		if err != nil {
+			return query, errors.New("Invalid end date, expected YYYY-MM-DD")
+		}
+
+		to = to.AddDate(0, 0, 1)
+		query.To = &to
+	}
+
+	if query.From != nil && query.To != nil && !query.From.Before(*query.To) {
+		return query, errors.New("Start date must not be after the end date")
+	}
+
+	if raw := c.Query("input_id"); raw != "" {
+		inputId, err := strconv.Atoi(raw)
+		if err != nil {
+			return query, errors.New("Invalid input ID")
+		}
+
+		query.InputId = &inputId
+	}
+
+	if answer := c.Query("answer"); answer != "" {
+		query.Answer = &answer
+	} else if query.InputId != nil {
+		return query, errors.New("An answer must be provided when filtering by input")
+	}
+
+	return query, nil
+}
+
+// inputLabels returns the label of each input, keyed by input ID. Labels don't have to be unique, so repeated labels
+// are numbered to keep each input's answers apart.
+func inputLabels(inputs []database.FormInput) map[int]string {
+	labels := make(map[int]string)
+	seen := make(map[string]int)
+	for _, input := range inputs {
+		seen[input.Label]++
+
+		label := input.Label
+		if seen[input.Label] > 1 {
+			label = fmt.Sprintf("%s (%d)", input.Label, seen[input.Label])
+		}
+
+		labels[input.Id] = label
+	}
+
+	return labels
+}
+
+func writeResponsesCsv(c *gin.Context, columns []string, responses []formResponse) {
+	c.Header("Content-Type", "text/csv")
+	c.Status(200)
+
+	w := csv.NewWriter(c.Writer)
+	header := []string{"ticket_id", "user_id", "submitted_at"}
+	for _, column := range columns {
+		header = append(header, escapeCsvFormula(column))
+	}
+
+	_ = w.Write(header)
+
+	for _, response := range responses {
+		record := []string{
+			strconv.Itoa(response.TicketId),
+			strconv.FormatUint(response.UserId, 10),
+			response.SubmittedAt.UTC().Format(time.RFC3339),
+		}
+
+		for _, column := range columns {
+			record = append(record, escapeCsvFormula(response.Answers[column]))
+		}
+
+		_ = w.Write(record)
+	}
+
+	w.Flush()
+}
+
+// escapeCsvFormula prefixes values which a spreadsheet would evaluate as a formula with a single quote, as labels and
+// answers are written by users
+func escapeCsvFormula(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+
+	return value
+}
@@ -0,0 +1,26 @@
+package forms
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEscapeCsvFormula(t *testing.T) {
+	tests := map[string]string{
+		"":                 "",
+		"Hello":            "Hello",
+		"=SUM(A1:A2)":      "'=SUM(A1:A2)",
+		"+1 555 0100":      "'+1 555 0100",
+		"-5":               "'-5",
+		"@mention":         "'@mention",
+		"\tindented":       "'\tindented",
+		"\rreturn":         "'\rreturn",
+		"a=b":              "a=b",
+		"'already escaped": "'already escaped",
+	}
+
+	for value, expected := range tests {
+		assert.Equal(t, expected, escapeCsvFormula(value), value)
+	}
+}
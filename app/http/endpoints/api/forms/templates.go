@@ -0,0 +1,331 @@
+package forms
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/TicketsBot-cloud/dashboard/app"
+	"github.com/TicketsBot-cloud/dashboard/app/http/audit"
+	"github.com/TicketsBot-cloud/dashboard/app/http/validation"
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/dashboard/utils"
+	"github.com/TicketsBot-cloud/database"
+	"github.com/gin-gonic/gin"
+)
+
+const maxFormTemplates = 25
+
+// formTemplateResponse describes both built-in and saved templates. Saved templates are identified by their numeric ID.
+// Templates don't hold branches, as they lead to the guild's other forms, so BranchesOmitted reports how many of the
+// form's branches were left out when a template is saved from a form.
+type formTemplateResponse struct {
+	Id              string                `json:"id"`
+	Name            string                `json:"name"`
+	Title           string                `json:"title"`
+	BuiltIn         bool                  `json:"built_in"`
+	Inputs          []FormInputDefinition `json:"inputs"`
+	BranchesOmitted int                   `json:"branches_omitted,omitempty"`
+}
+
+// createTemplateBody saves either an existing form, or the given title and inputs, as a template
+type createTemplateBody struct {
+	Name   string                `json:"name"`
+	FormId *int                  `json:"form_id"`
+	Title  *string               `json:"title"`
+	Inputs []FormInputDefinition `json:"inputs"`
+}
+
+// ListTemplates returns the built-in templates, followed by those saved by the guild
+func ListTemplates(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+
+	saved, err := dbclient.Dashboard.FormTemplates.GetByGuild(c, guildId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to load form templates"))
+		return
+	}
+
+	templates := make([]formTemplateResponse, 0, len(builtInTemplates)+len(saved))
+	for _, template := range builtInTemplates {
+		templates = append(templates, formTemplateResponse{
+			Id:      template.Id,
+			Name:    template.Name,
+			Title:   template.Title,
+			BuiltIn: true,
+			Inputs:  template.Inputs,
+		})
+	}
+
+	for _, template := range saved {
+		var inputs []FormInputDefinition
+		if err := json.Unmarshal(template.Inputs, &inputs); err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to load form templates"))
+			return
+		}
+
+		templates = append(templates, formTemplateResponse{
+			Id:     strconv.Itoa(template.Id),
+			Name:   template.Name,
+			Title:  template.Title,
+			Inputs: inputs,
+		})
+	}
+
+	c.JSON(200, templates)
+}
+
+func CreateTemplate(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+	userId := c.Keys["userid"].(uint64)
+
+	var data createTemplateBody
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(400, utils.ErrorStr("Invalid request data. Please check your input and try again."))
+		return
+	}
+
+	if len(strings.TrimSpace(data.Name)) == 0 {
+		c.JSON(400, utils.ErrorStr("Template name cannot be empty"))
+		return
+	}
+
+	if utf8.RuneCountInString(data.Name) > 45 {
+		c.JSON(400, utils.ErrorStr("Template name must be 45 characters or less (current: %d characters)", utf8.RuneCountInString(data.Name)))
+		return
+	}
+
+	var title string
+	var branchesOmitted int
+	inputs := data.Inputs
+	if data.FormId != nil {
+		form, ok, err := dbclient.Client.Forms.Get(c, *data.FormId)
+		if err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch form from database"))
+			return
+		}
+
+		if !ok || form.GuildId != guildId {
+			c.JSON(404, utils.ErrorStr("Form #%d not found", *data.FormId))
+			return
+		}
+
+		formInputs, err := dbclient.Client.FormInput.GetInputs(c, form.Id)
+		if err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch form inputs from database"))
+			return
+		}
+
+		options, err := dbclient.Client.FormInputOption.GetOptionsByForm(c, form.Id)
+		if err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch form input options from database"))
+			return
+		}
+
+		conditions, err := dbclient.Dashboard.FormInputConditions.GetByForm(c, form.Id)
+		if err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch form input conditions from database"))
+			return
+		}
+
+		branches, err := dbclient.Dashboard.FormBranches.GetByForm(c, form.Id)
+		if err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch form branches from database"))
+			return
+		}
+
+		title = form.Title
+		inputs = NewFormInputDefinitions(formInputs, options, conditions)
+		branchesOmitted = len(branches)
+	}
+
+	if data.Title != nil {
+		title = *data.Title
+	}
+
+	if err := ValidateFormDefinition(title, inputs); err != nil {
+		var validationError *validation.InvalidInputError
+		if errors.As(err, &validationError) {
+			c.JSON(400, utils.ErrorStr("%s", validationError.Error()))
+		} else {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Form validation failed unexpectedly"))
+		}
+
+		return
+	}
+
+	count, err := dbclient.Dashboard.FormTemplates.GetCount(c, guildId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to load form templates"))
+		return
+	}
+
+	if count >= maxFormTemplates {
+		c.JSON(400, utils.ErrorStr("Guilds can have at most %d saved form templates", maxFormTemplates))
+		return
+	}
+
+	encoded, err := json.Marshal(inputs)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to save form template"))
+		return
+	}
+
+	template, created, err := dbclient.Dashboard.FormTemplates.Create(c, dbclient.FormTemplate{
+		GuildId:   guildId,
+		Name:      data.Name,
+		Title:     title,
+		Inputs:    encoded,
+		CreatedBy: userId,
+	})
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to save form template"))
+		return
+	}
+
+	if !created {
+		c.JSON(http.StatusConflict, utils.ErrorStr("A template named \"%s\" already exists", data.Name))
+		return
+	}
+
+	audit.Log(audit.LogEntry{
+		GuildId:      audit.Uint64Ptr(guildId),
+		UserId:       userId,
+		ActionType:   audit.ActionFormTemplateCreate,
+		ResourceType: audit.ResourceFormTemplate,
+		ResourceId:   audit.StringPtr(strconv.Itoa(template.Id)),
+		NewData:      template,
+	})
+
+	c.JSON(200, formTemplateResponse{
+		Id:              strconv.Itoa(template.Id),
+		Name:            template.Name,
+		Title:           template.Title,
+		Inputs:          inputs,
+		BranchesOmitted: branchesOmitted,
+	})
+}
+
+func DeleteTemplate(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+	userId := c.Keys["userid"].(uint64)
+
+	if _, ok := findBuiltInTemplate(c.Param("template_id")); ok {
+		c.JSON(400, utils.ErrorStr("Built-in templates cannot be deleted"))
+		return
+	}
+
+	templateId, err := strconv.Atoi(c.Param("template_id"))
+	if err != nil {
+		c.JSON(400, utils.ErrorStr("Invalid template ID provided: %s", c.Param("template_id")))
+		return
+	}
+
+	template, ok, err := dbclient.Dashboard.FormTemplates.Get(c, guildId, templateId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to load form templates"))
+		return
+	}
+
+	if !ok {
+		c.JSON(404, utils.ErrorStr("Template #%d not found", templateId))
+		return
+	}
+
+	if err := dbclient.Dashboard.FormTemplates.Delete(c, guildId, templateId); err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to delete form template"))
+		return
+	}
+
+	audit.Log(audit.LogEntry{
+		GuildId:      audit.Uint64Ptr(guildId),
+		UserId:       userId,
+		ActionType:   audit.ActionFormTemplateDelete,
+		ResourceType: audit.ResourceFormTemplate,
+		ResourceId:   audit.StringPtr(strconv.Itoa(templateId)),
+		OldData:      template,
+	})
+
+	c.Status(204)
+}
+
+// createFormFromTemplate creates a form with the template's inputs, using the title in the request body if one is
+// given, and the template's title otherwise
+func createFormFromTemplate(c *gin.Context, guildId, userId uint64, data createFormBody) {
+	title, inputs, ok, err := loadTemplate(c, guildId, *data.TemplateId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to load form templates"))
+		return
+	}
+
+	if !ok {
+		c.JSON(404, utils.ErrorStr("Template %s not found", *data.TemplateId))
+		return
+	}
+
+	if data.Title != "" {
+		title = data.Title
+	}
+
+	// Saved templates were validated when they were created, but the rules may have changed since
+	if err := ValidateFormDefinition(title, inputs); err != nil {
+		var validationError *validation.InvalidInputError
+		if errors.As(err, &validationError) {
+			c.JSON(400, utils.ErrorStr("%s", validationError.Error()))
+		} else {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Form validation failed unexpectedly"))
+		}
+
+		return
+	}
+
+	form, err := CreateFormFromDefinition(c, guildId, title, inputs)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to create form in database"))
+		return
+	}
+
+	audit.Log(audit.LogEntry{
+		GuildId:      audit.Uint64Ptr(guildId),
+		UserId:       userId,
+		ActionType:   database.AuditActionFormCreate,
+		ResourceType: database.AuditResourceForm,
+		ResourceId:   audit.StringPtr(strconv.Itoa(form.Id)),
+		NewData: map[string]interface{}{
+			"title":  title,
+			"inputs": inputs,
+		},
+		Metadata: map[string]interface{}{
+			"template_id": *data.TemplateId,
+		},
+	})
+
+	c.JSON(200, form)
+}
+
+// loadTemplate returns the title and inputs of a built-in template, or one saved by the guild
+func loadTemplate(c *gin.Context, guildId uint64, templateId string) (string, []FormInputDefinition, bool, error) {
+	if template, ok := findBuiltInTemplate(templateId); ok {
+		return template.Title, template.Inputs, true, nil
+	}
+
+	id, err := strconv.Atoi(templateId)
+	if err != nil {
+		return "", nil, false, nil
+	}
+
+	template, ok, err := dbclient.Dashboard.FormTemplates.Get(c, guildId, id)
+	if err != nil || !ok {
+		return "", nil, false, err
+	}
+
+	var inputs []FormInputDefinition
+	if err := json.Unmarshal(template.Inputs, &inputs); err != nil {
+		return "", nil, false, err
+	}
+
+	return template.Title, inputs, true, nil
+}
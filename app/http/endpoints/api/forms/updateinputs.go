@@ -37,6 +37,7 @@ type (
 		MinLength   uint16                   `json:"min_length" validate:"min=0,max=1024"` // validator interprets 0 as not set
 		MaxLength   uint16                   `json:"max_length" validate:"min=0,max=1024"`
 		Options     []inputOption            `json:"options,omitempty" validate:"omitempty,dive,required,min=1,max=25"`
+		Condition   *inputCondition          `json:"condition,omitempty" validate:"omitempty"`
 	}
 
 	inputOption struct {
@@ -77,7 +78,7 @@ func UpdateInputs(c *gin.Context) {
 		}
 
 		formatted := "Your input contained the following errors:\n" + utils.FormatValidationErrors(validationErrors)
-		c.JSON(400, utils.ErrorStr(formatted))
+		c.JSON(400, utils.ErrorStr("%s", formatted))
 		return
 	}
 
@@ -182,6 +183,23 @@ func UpdateInputs(c *gin.Context) {
 		}
 	}
 
+	if err := validateConditions(data.inputs()); err != nil {
+		c.JSON(400, utils.ErrorStr("%v", err))
+		return
+	}
+
+	// Branches refer to inputs by ID, so they must still be possible with the new inputs
+	branches, err := dbclient.Dashboard.FormBranches.GetByForm(c, formId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch form branches from database"))
+		return
+	}
+
+	if err := validateBranchInputs(data, branches); err != nil {
+		c.JSON(400, utils.ErrorStr("%v", err))
+		return
+	}
+
 	if err := saveInputs(c, formId, data, existingInputs); err != nil {
 		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to save form inputs to database"))
 		return
@@ -199,6 +217,37 @@ func UpdateInputs(c *gin.Context) {
 	c.Status(204)
 }
 
+// inputs returns the inputs the form will have once the update has been applied
+func (b updateInputsBody) inputs() []inputCreateBody {
+	inputs := make([]inputCreateBody, 0, len(b.Create)+len(b.Update))
+	inputs = append(inputs, b.Create...)
+	for _, input := range b.Update {
+		inputs = append(inputs, input.inputCreateBody)
+	}
+
+	return inputs
+}
+
+// validateBranchInputs checks that the inputs used by the form's branches are kept, and can still take the values
+func validateBranchInputs(body updateInputsBody, branches []dbclient.FormBranch) error {
+	for i, branch := range branches {
+		if utils.Exists(body.Delete, branch.InputId) {
+			return fmt.Errorf("Input #%d is used by branch %d, and cannot be deleted until the branch is removed", branch.InputId, i+1)
+		}
+
+		input := utils.FindMap(body.Update, branch.InputId, idMapperBody)
+		if input == nil {
+			continue
+		}
+
+		if err := validateSourceValues(input.inputCreateBody, branch.Values); err != nil {
+			return fmt.Errorf("Branch %d can no longer be followed: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
 func idMapper(input database.FormInput) int {
 	return input.Id
 }
@@ -302,6 +351,9 @@ func saveInputs(ctx context.Context, formId int, data updateInputsBody, existing
 
 	defer tx.Rollback(context.Background())
 
+	// Conditions refer to their source by position, so record the ID of the input at each position
+	inputIds := make(map[int]int)
+
 	for _, id := range data.Delete {
 		if err := dbclient.Client.FormInput.DeleteTx(ctx, tx, id, formId); err != nil {
 			return err
@@ -383,6 +435,8 @@ func saveInputs(ctx context.Context, formId int, data updateInputsBody, existing
 			return err
 		}
 
+		inputIds[input.Position] = input.Id
+
 		if wrapped.Type == 3 || wrapped.Type == 21 || wrapped.Type == 22 { // String Select, RadioGroup, CheckboxGroup
 			// Delete existing options
 			options, err := dbclient.Client.FormInputOption.GetOptions(ctx, wrapped.Id)
@@ -488,6 +542,8 @@ func saveInputs(ctx context.Context, formId int, data updateInputsBody, existing
 			return err
 		}
 
+		inputIds[input.Position] = formInputId
+
 		if input.Type == 3 || input.Type == 21 || input.Type == 22 { // String Select, RadioGroup, CheckboxGroup
 			for i, opt := range input.Options {
 				option := database.FormInputOption{
@@ -505,5 +561,20 @@ func saveInputs(ctx context.Context, formId int, data updateInputsBody, existing
 		}
 	}
 
+	var conditions []dbclient.FormInputCondition
+	for _, input := range data.inputs() {
+		if input.Condition != nil {
+			conditions = append(conditions, dbclient.FormInputCondition{
+				InputId:       inputIds[input.Position],
+				SourceInputId: inputIds[input.Condition.SourcePosition],
+				Values:        input.Condition.Values,
+			})
+		}
+	}
+
+	if err := dbclient.Dashboard.FormInputConditions.ReplaceTx(ctx, tx, formId, conditions); err != nil {
+		return err
+	}
+
 	return tx.Commit(context.Background())
 }
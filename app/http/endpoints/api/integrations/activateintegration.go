@@ -1,7 +1,9 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -18,21 +20,25 @@ type activateIntegrationBody struct {
 	Secrets map[string]string `json:"secrets"`
 }
 
+// MaxActiveIntegrations is the number of integrations a guild can have active at once
+const MaxActiveIntegrations = 5
+
+// ActivationError is a reason that an integration cannot be activated, which can be shown to the user. InternalError
+// is the reason given by the integration's validation server, if it rejected the secret values.
+type ActivationError struct {
+	StatusCode    int
+	Message       string
+	InternalError string
+}
+
+func (e *ActivationError) Error() string {
+	return e.Message
+}
+
 func ActivateIntegrationHandler(ctx *gin.Context) {
 	userId := ctx.Keys["userid"].(uint64)
 	guildId := ctx.Keys["guildid"].(uint64)
 
-	activeCount, err := dbclient.Client.CustomIntegrationGuilds.GetGuildIntegrationCount(ctx, guildId)
-	if err != nil {
-		ctx.JSON(500, utils.ErrorStr("Failed to process request. Please try again."))
-		return
-	}
-
-	if activeCount >= 5 {
-		ctx.JSON(400, utils.ErrorStr("You can only have 5 integrations active at once"))
-		return
-	}
-
 	integrationId, err := strconv.Atoi(ctx.Param("integrationid"))
 	if err != nil {
 		ctx.JSON(400, utils.ErrorStr("Invalid integration ID"))
@@ -56,37 +62,91 @@ func ActivateIntegrationHandler(ctx *gin.Context) {
 		return
 	}
 
+	if err := Activate(ctx, integration, guildId, userId, data.Secrets); err != nil {
+		var activationError *ActivationError
+		if !errors.As(err, &activationError) {
+			ctx.JSON(500, utils.ErrorStr("Failed to process request. Please try again."))
+		} else if len(activationError.InternalError) > 0 {
+			ctx.JSON(activationError.StatusCode, gin.H{
+				"success":        false,
+				"error":          activationError.Message,
+				"internal_error": activationError.InternalError,
+			})
+		} else {
+			ctx.JSON(activationError.StatusCode, utils.ErrorStr("%s", activationError.Message))
+		}
+
+		return
+	}
+
+	audit.Log(audit.LogEntry{
+		GuildId:      audit.Uint64Ptr(guildId),
+		UserId:       userId,
+		ActionType:   dbmodel.AuditActionGuildIntegrationActivate,
+		ResourceType: dbmodel.AuditResourceGuildIntegration,
+		ResourceId:   audit.StringPtr(strconv.Itoa(integrationId)),
+	})
+	ctx.Status(204)
+}
+
+// Activate activates the integration in the guild, after checking the guild's integration limit, that the user may
+// activate it, and that the secret values are valid, including with the integration's validation server. An
+// *ActivationError is returned if the integration cannot be activated.
+func Activate(ctx context.Context, integration dbmodel.CustomIntegration, guildId, userId uint64, values map[string]string) error {
+	activeCount, err := dbclient.Client.CustomIntegrationGuilds.GetGuildIntegrationCount(ctx, guildId)
+	if err != nil {
+		return err
+	}
+
+	if activeCount >= MaxActiveIntegrations {
+		return &ActivationError{
+			StatusCode: 400,
+			Message:    fmt.Sprintf("You can only have %d integrations active at once", MaxActiveIntegrations),
+		}
+	}
+
 	// Check the integration is public or the user created it
-	canActivate, err := dbclient.Client.CustomIntegrationGuilds.CanActivate(ctx, integrationId, userId)
+	canActivate, err := dbclient.Client.CustomIntegrationGuilds.CanActivate(ctx, integration.Id, userId)
 	if err != nil {
-		ctx.JSON(500, utils.ErrorStr("Failed to process request. Please try again."))
-		return
+		return err
 	}
 
 	if !canActivate {
-		ctx.JSON(403, utils.ErrorStr("You do not have permission to activate this integration"))
-		return
+		return &ActivationError{
+			StatusCode: 403,
+			Message:    "You do not have permission to activate this integration",
+		}
+	}
+
+	secretMap, err := resolveSecretValues(ctx, integration.Id, values)
+	if err != nil {
+		return err
+	}
+
+	if err := validateSecretValues(ctx, integration, values); err != nil {
+		return err
 	}
 
-	// Check the secret values are valid
+	return dbclient.Client.CustomIntegrationGuilds.AddToGuildWithSecrets(ctx, integration.Id, guildId, secretMap)
+}
+
+// resolveSecretValues checks that a value has been given for each of the integration's secrets, and returns the values
+// keyed by secret ID
+func resolveSecretValues(ctx context.Context, integrationId int, values map[string]string) (map[int]string, error) {
 	secrets, err := dbclient.Client.CustomIntegrationSecrets.GetByIntegration(ctx, integrationId)
 	if err != nil {
-		ctx.JSON(500, utils.ErrorStr("Failed to process request. Please try again."))
-		return
+		return nil, err
 	}
 
-	if len(secrets) != len(data.Secrets) {
-		ctx.JSON(400, utils.ErrorStr("Invalid secret values"))
-		return
+	if len(secrets) != len(values) {
+		return nil, &ActivationError{StatusCode: 400, Message: "Invalid secret values"}
 	}
 
 	// Since we've checked the length, we can just iterate over the secrets, and they're guaranteed to be correct
 	secretMap := make(map[int]string)
-	secretValues := make(map[string]string)
-	for secretName, value := range data.Secrets {
+	for secretName, value := range values {
 		if len(value) == 0 || len(value) > 255 {
-			ctx.JSON(400, utils.ErrorStr("Secret values must be between 1 and 255 characters"))
-			return
+			return nil, &ActivationError{StatusCode: 400, Message: "Secret values must be between 1 and 255 characters"}
 		}
 
 		found := false
@@ -96,86 +156,69 @@ func ActivateIntegrationHandler(ctx *gin.Context) {
 			if secret.Name == secretName {
 				found = true
 				secretMap[secret.Id] = value
-				secretValues[secret.Name] = value
 				break inner
 			}
 		}
 
 		if !found {
-			ctx.JSON(400, utils.ErrorStr("Invalid secret values"))
-			return
+			return nil, &ActivationError{StatusCode: 400, Message: "Invalid secret values"}
 		}
 	}
 
-	// Validate secrets
-	if integration.Public && integration.Approved && integration.ValidationUrl != nil {
-		integrationHeaders, err := dbclient.Client.CustomIntegrationHeaders.GetByIntegration(ctx, integrationId)
-		if err != nil {
-			ctx.JSON(500, utils.ErrorStr("Failed to process request. Please try again."))
-			return
-		}
-
-		headers := make(map[string]string)
-		for _, header := range integrationHeaders {
-			value := header.Value
-			for key, secret := range secretValues {
-				value = strings.ReplaceAll(value, fmt.Sprintf("%%%s%%", key), secret)
-			}
+	return secretMap, nil
+}
 
-			headers[header.Name] = value
-		}
+// validateSecretValues sends the secret values to the integration's validation server, if it has one
+func validateSecretValues(ctx context.Context, integration dbmodel.CustomIntegration, secretValues map[string]string) error {
+	if !integration.Public || !integration.Approved || integration.ValidationUrl == nil {
+		return nil
+	}
 
-		res, statusCode, err := utils.SecureProxyClient.DoRequest(http.MethodPost, *integration.ValidationUrl, headers, secretValues)
-		if err != nil {
-			if statusCode == http.StatusRequestTimeout {
-				ctx.JSON(400, utils.ErrorStr("Secret validation server did not respond in time (contact the integration author)"))
-				return
-			} else {
-				ctx.JSON(500, utils.ErrorStr("Failed to process request. Please try again."))
-				return
-			}
-		}
+	integrationHeaders, err := dbclient.Client.CustomIntegrationHeaders.GetByIntegration(ctx, integration.Id)
+	if err != nil {
+		return err
+	}
 
-		type validationResponse struct {
-			Error string `json:"error"`
+	headers := make(map[string]string)
+	for _, header := range integrationHeaders {
+		value := header.Value
+		for key, secret := range secretValues {
+			value = strings.ReplaceAll(value, fmt.Sprintf("%%%s%%", key), secret)
 		}
 
-		var useClientError bool
-		var parsed validationResponse
-		if err := json.Unmarshal(res, &parsed); err == nil {
-			useClientError = len(parsed.Error) > 0
+		headers[header.Name] = value
+	}
 
-			if len(parsed.Error) > 255 {
-				parsed.Error = parsed.Error[:255]
+	res, statusCode, err := utils.SecureProxyClient.DoRequest(http.MethodPost, *integration.ValidationUrl, headers, secretValues)
+	if err != nil {
+		if statusCode == http.StatusRequestTimeout {
+			return &ActivationError{
+				StatusCode: 400,
+				Message:    "Secret validation server did not respond in time (contact the integration author)",
 			}
 		}
 
-		if statusCode > 299 {
-			if useClientError {
-				ctx.JSON(400, gin.H{
-					"success":        false,
-					"error":          "Integration rejected the secret values (contact the integration author for help)",
-					"internal_error": parsed.Error,
-				})
-			} else {
-				ctx.JSON(400, utils.ErrorStr("Integration rejected the secret values (contact the integration author for help)"))
-			}
+		return err
+	}
+
+	type validationResponse struct {
+		Error string `json:"error"`
+	}
 
-			return
+	var parsed validationResponse
+	if err := json.Unmarshal(res, &parsed); err == nil {
+		if len(parsed.Error) > 255 {
+			parsed.Error = parsed.Error[:255]
 		}
 	}
 
-	if err := dbclient.Client.CustomIntegrationGuilds.AddToGuildWithSecrets(ctx, integrationId, guildId, secretMap); err != nil {
-		ctx.JSON(500, utils.ErrorStr("Failed to serialize data. Please try again."))
-		return
+	if statusCode > 299 {
+		return &ActivationError{
+			StatusCode:    400,
+			Message:       "Integration rejected the secret values (contact the integration author for help)",
+			InternalError: parsed.Error,
+		}
 	}
 
-	audit.Log(audit.LogEntry{
-		GuildId:      audit.Uint64Ptr(guildId),
-		UserId:       userId,
-		ActionType:   dbmodel.AuditActionGuildIntegrationActivate,
-		ResourceType: dbmodel.AuditResourceGuildIntegration,
-		ResourceId:   audit.StringPtr(strconv.Itoa(integrationId)),
-	})
-	ctx.Status(204)
+	return nil
 }
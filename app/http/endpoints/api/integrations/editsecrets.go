@@ -1,6 +1,7 @@
 package api
 
 import (
+	"errors"
 	"strconv"
 
 	"github.com/TicketsBot-cloud/dashboard/app/http/audit"
@@ -38,40 +39,16 @@ func UpdateIntegrationSecretsHandler(ctx *gin.Context) {
 		return
 	}
 	// Check the secret values are valid
-	secrets, err := dbclient.Client.CustomIntegrationSecrets.GetByIntegration(ctx, integrationId)
+	secretMap, err := resolveSecretValues(ctx, integrationId, data.Secrets)
 	if err != nil {
-		ctx.JSON(500, utils.ErrorStr("Invalid request data. Please check your input and try again."))
-		return
-	}
-
-	if len(secrets) != len(data.Secrets) {
-		ctx.JSON(400, utils.ErrorStr("Invalid secret values"))
-		return
-	}
-
-	// Since we've checked the length, we can just iterate over the secrets and they're guaranteed to be correct
-	secretMap := make(map[int]string)
-	for secretName, value := range data.Secrets {
-		if len(value) == 0 || len(value) > 255 {
-			ctx.JSON(400, utils.ErrorStr("Secret values must be between 1 and 255 characters"))
-			return
+		var activationError *ActivationError
+		if errors.As(err, &activationError) {
+			ctx.JSON(activationError.StatusCode, utils.ErrorStr("%s", activationError.Message))
+		} else {
+			ctx.JSON(500, utils.ErrorStr("Invalid request data. Please check your input and try again."))
 		}
 
-		found := false
-
-	inner:
-		for _, secret := range secrets {
-			if secret.Name == secretName {
-				found = true
-				secretMap[secret.Id] = value
-				break inner
-			}
-		}
-
-		if !found {
-			ctx.JSON(400, utils.ErrorStr("Invalid secret values"))
-			return
-		}
+		return
 	}
 
 	if err := dbclient.Client.CustomIntegrationSecretValues.UpdateAll(ctx, guildId, integrationId, secretMap); err != nil {
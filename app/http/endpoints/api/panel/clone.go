@@ -0,0 +1,428 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/TicketsBot-cloud/common/premium"
+	"github.com/TicketsBot-cloud/dashboard/app"
+	"github.com/TicketsBot-cloud/dashboard/app/http/audit"
+	"github.com/TicketsBot-cloud/dashboard/app/http/validation"
+	"github.com/TicketsBot-cloud/dashboard/botcontext"
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/dashboard/log"
+	"github.com/TicketsBot-cloud/dashboard/rpc"
+	"github.com/TicketsBot-cloud/dashboard/utils"
+	"github.com/TicketsBot-cloud/dashboard/utils/types"
+	"github.com/TicketsBot-cloud/database"
+	"github.com/TicketsBot-cloud/gdl/objects/interaction/component"
+	"github.com/TicketsBot-cloud/gdl/rest/request"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// cloneBody is accepted by ClonePanel and CloneMultiPanel. If no channel is given, the copy is sent to the same
+// channel as the original.
+type cloneBody struct {
+	ChannelId *uint64 `json:"channel_id,string"`
+}
+
+// ClonePanel creates a copy of a panel, including its mentions, teams, access control rules, ticket permissions and
+// support hours, and sends a new panel message for it
+func ClonePanel(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+	userId := c.Keys["userid"].(uint64)
+
+	panelId, err := strconv.Atoi(c.Param("panelid"))
+	if err != nil {
+		c.JSON(400, utils.ErrorStr("Invalid panel ID provided: %s", c.Param("panelid")))
+		return
+	}
+
+	var body cloneBody
+	if err := c.ShouldBindJSON(&body); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(400, utils.ErrorStr("Invalid request data. Please check your input and try again."))
+		return
+	}
+
+	source, err := dbclient.Client.Panel.GetByIdWithWelcomeMessage(c, guildId, panelId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch panel from database"))
+		return
+	}
+
+	if source == nil {
+		c.JSON(404, utils.ErrorStr("Panel not found: %d", panelId))
+		return
+	}
+
+	botContext, err := botcontext.ContextForGuild(guildId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Unable to connect to Discord. Please try again later."))
+		return
+	}
+
+	// Check panel quota
+	premiumTier, err := rpc.PremiumClient.GetTierByGuildId(c, guildId, false, botContext.Token, botContext.RateLimiter)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to verify premium status"))
+		return
+	}
+
+	if premiumTier == premium.None {
+		panels, err := dbclient.Client.Panel.GetByGuild(c, guildId)
+		if err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch existing panels"))
+			return
+		}
+
+		if len(panels) >= freePanelLimit {
+			c.JSON(402, utils.ErrorStr("Panel quota exceeded: You have %d/%d panels. Purchase premium to unlock more panels.", len(panels), freePanelLimit))
+			return
+		}
+	}
+
+	ctx, cancel := app.DefaultContext()
+	defer cancel()
+
+	data, err := panelToBody(ctx, *source)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch panel configuration from database"))
+		return
+	}
+
+	if body.ChannelId != nil {
+		data.ChannelId = *body.ChannelId
+	}
+
+	ApplyPanelDefaults(&data)
+
+	channels, err := botContext.GetGuildChannels(ctx, guildId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch guild channels from Discord"))
+		return
+	}
+
+	roles, err := botContext.GetGuildRoles(ctx, guildId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Unable to load roles from Discord. Please try again."))
+		return
+	}
+
+	// The original may have been saved before a rule was introduced, or may reference a channel or role that has since
+	// been deleted, so the copy is validated in the same way as a new panel
+	data, err = validatePanelForCreate(PanelValidationContext{
+		Data:       data,
+		GuildId:    guildId,
+		IsPremium:  premiumTier > premium.None,
+		BotContext: botContext,
+		Channels:   channels,
+		Roles:      roles,
+	})
+	if err != nil {
+		var validationError *validation.InvalidInputError
+		if errors.As(err, &validationError) {
+			c.JSON(400, utils.ErrorStr("%s", validationError.Error()))
+		} else {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Panel validation failed unexpectedly"))
+		}
+
+		return
+	}
+
+	panel, err := createPanel(c, botContext, guildId, data, premiumTier > premium.None, roles)
+	if err != nil {
+		var validationError *validation.InvalidInputError
+		var unwrapped request.RestError
+		if errors.As(err, &validationError) {
+			c.JSON(400, utils.ErrorStr("%s", validationError.Error()))
+		} else if errors.As(err, &unwrapped) {
+			if unwrapped.StatusCode == http.StatusForbidden {
+				c.JSON(400, utils.ErrorStr("Bot does not have permission to send messages in channel %d", data.ChannelId))
+			} else {
+				c.JSON(400, utils.ErrorStr("Failed to send panel message to channel %d: %s", data.ChannelId, unwrapped.ApiError.Message))
+			}
+		} else {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to save panel to database"))
+		}
+
+		return
+	}
+
+	// Free servers can only have support hours on a single panel, which the original must already be. The panel has
+	// been created by now, so if the support hours can't be copied, the clone still succeeds, without them.
+	response := gin.H{
+		"success":  true,
+		"panel_id": panel.PanelId,
+	}
+
+	supportHoursCopied, err := cloneSupportHours(ctx, panelId, panel.PanelId, premiumTier > premium.None)
+	if err != nil {
+		log.Logger.Error("Failed to copy support hours", zap.Int("panel_id", panel.PanelId), zap.Error(err))
+		response["support_hours_error"] = "Failed to copy support hours. Please set them on the new panel manually."
+	}
+
+	response["support_hours_copied"] = supportHoursCopied
+
+	audit.Log(audit.LogEntry{
+		GuildId:      audit.Uint64Ptr(guildId),
+		UserId:       userId,
+		ActionType:   database.AuditActionPanelCreate,
+		ResourceType: database.AuditResourcePanel,
+		ResourceId:   audit.StringPtr(strconv.Itoa(panel.PanelId)),
+		NewData:      data,
+		Metadata: map[string]interface{}{
+			"cloned_from": panelId,
+		},
+	})
+
+	c.JSON(200, response)
+}
+
+// CloneMultiPanel creates a copy of a multi-panel, containing the same sub-panels, and sends a new message for it
+func CloneMultiPanel(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+	userId := c.Keys["userid"].(uint64)
+
+	multiPanelId, err := strconv.Atoi(c.Param("panelid"))
+	if err != nil {
+		c.JSON(400, utils.ErrorStr("Invalid panel ID provided: %s", c.Param("panelid")))
+		return
+	}
+
+	var body cloneBody
+	if err := c.ShouldBindJSON(&body); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(400, utils.ErrorStr("Invalid request data. Please check your input and try again."))
+		return
+	}
+
+	source, ok, err := dbclient.Client.MultiPanels.Get(c, multiPanelId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch multi-panel from database"))
+		return
+	}
+
+	if !ok || source.GuildId != guildId {
+		c.JSON(404, utils.ErrorStr("No panel with the provided ID found"))
+		return
+	}
+
+	targets, err := dbclient.Client.MultiPanelTargets.GetPanels(c, multiPanelId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch multi-panel from database"))
+		return
+	}
+
+	rows, err := dbclient.Dashboard.MultiPanelRows.Get(c, multiPanelId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch multi-panel from database"))
+		return
+	}
+
+	data := multiPanelCreateData{
+		ChannelId:             source.ChannelId,
+		SelectMenu:            source.SelectMenu,
+		SelectMenuPlaceholder: source.SelectMenuPlaceholder,
+		Panels:                make([]panelConfiguration, len(targets)),
+	}
+
+	if body.ChannelId != nil {
+		data.ChannelId = *body.ChannelId
+	}
+
+	if source.Embed != nil {
+		data.Embed = types.NewCustomEmbed(source.Embed.CustomEmbed, source.Embed.Fields)
+	}
+
+	for i, target := range targets {
+		data.Panels[i] = panelConfiguration{
+			PanelId:         target.PanelId,
+			CustomEmojiName: target.CustomEmojiName,
+			CustomEmojiId:   target.CustomEmojiId,
+			CustomLabel:     target.CustomLabel,
+			Description:     target.Description,
+		}
+
+		if row, ok := rows[target.PanelId]; ok {
+			data.Panels[i].Row = &row
+		}
+	}
+
+	errs, err := data.collectErrors(guildId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "An error occurred while validating the panel"))
+		return
+	}
+
+	if len(errs) > 0 {
+		c.JSON(400, utils.ErrorStr("%s", errs[0].Error()))
+		return
+	}
+
+	panels, err := data.validatePanels(guildId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "An error occurred while validating the panel"))
+		return
+	}
+
+	botContext, err := botcontext.ContextForGuild(guildId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Unable to connect to Discord. Please try again later."))
+		return
+	}
+
+	premiumTier, err := rpc.PremiumClient.GetTierByGuildId(c, guildId, true, botContext.Token, botContext.RateLimiter)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to create multi-panel"))
+		return
+	}
+
+	multiPanel, err := data.create(c, botContext, guildId, panels, premiumTier > premium.None)
+	if err != nil {
+		var unwrapped request.RestError
+		if errors.As(err, &unwrapped) && unwrapped.StatusCode == 403 {
+			c.JSON(http.StatusBadRequest, utils.ErrorStr("I do not have permission to send messages in the provided channel"))
+		} else {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to create multi-panel"))
+		}
+
+		return
+	}
+
+	audit.Log(audit.LogEntry{
+		GuildId:      audit.Uint64Ptr(guildId),
+		UserId:       userId,
+		ActionType:   database.AuditActionMultiPanelCreate,
+		ResourceType: database.AuditResourceMultiPanel,
+		ResourceId:   audit.StringPtr(strconv.Itoa(multiPanel.Id)),
+		NewData:      data,
+		Metadata: map[string]interface{}{
+			"cloned_from": multiPanelId,
+		},
+	})
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    multiPanel,
+	})
+}
+
+// panelToBody converts a saved panel back into the body accepted by CreatePanel
+func panelToBody(ctx context.Context, p database.PanelWithWelcomeMessage) (panelBody, error) {
+	data := panelBody{
+		ChannelId:                 p.ChannelId,
+		Title:                     p.Title,
+		Content:                   p.Content,
+		Colour:                    uint32(p.Colour),
+		CategoryId:                p.TargetCategory,
+		Emoji:                     types.NewEmoji(p.EmojiName, p.EmojiId),
+		Mentions:                  make([]string, 0),
+		WithDefaultTeam:           p.WithDefaultTeam,
+		ImageUrl:                  p.ImageUrl,
+		ThumbnailUrl:              p.ThumbnailUrl,
+		ButtonStyle:               component.ButtonStyle(p.ButtonStyle),
+		ButtonLabel:               p.ButtonLabel,
+		FormId:                    p.FormId,
+		NamingScheme:              p.NamingScheme,
+		Disabled:                  p.Disabled,
+		ExitSurveyFormId:          p.ExitSurveyFormId,
+		PendingCategory:           p.PendingCategory,
+		DeleteMentions:            p.DeleteMentions,
+		TranscriptChannelId:       p.TranscriptChannelId,
+		UseThreads:                p.UseThreads,
+		TicketNotificationChannel: p.TicketNotificationChannel,
+		CooldownSeconds:           p.CooldownSeconds,
+		TicketLimit:               p.TicketLimit,
+		HideCloseButton:           p.HideCloseButton,
+		HideCloseWithReasonButton: p.HideCloseWithReasonButton,
+		HideClaimButton:           p.HideClaimButton,
+	}
+
+	if p.WelcomeMessage != nil {
+		fields, err := dbclient.Client.EmbedFields.GetFieldsForEmbed(ctx, p.WelcomeMessage.Id)
+		if err != nil {
+			return panelBody{}, err
+		}
+
+		data.WelcomeMessage = types.NewCustomEmbed(p.WelcomeMessage, fields)
+	}
+
+	extras, err := loadPanelExtras(ctx, p.PanelId)
+	if err != nil {
+		return panelBody{}, err
+	}
+
+	if extras.MentionUser {
+		data.Mentions = append(data.Mentions, "user")
+	}
+
+	if extras.MentionHere {
+		data.Mentions = append(data.Mentions, "here")
+	}
+
+	for _, roleId := range extras.MentionRoles {
+		data.Mentions = append(data.Mentions, strconv.FormatUint(roleId, 10))
+	}
+
+	data.Teams = extras.TeamIds
+	data.TicketPermissions = extras.TicketPermissions
+
+	if data.AccessControlList, err = dbclient.Client.PanelAccessControlRules.GetAll(ctx, p.PanelId); err != nil {
+		return panelBody{}, err
+	}
+
+	return data, nil
+}
+
+// cloneSupportHours copies the support hours of one panel to another, returning false if the source has none, or if
+// they could not be copied because the server does not have premium. If copying fails, any hours already copied are
+// removed again.
+func cloneSupportHours(ctx context.Context, sourceId, targetId int, isPremium bool) (bool, error) {
+	copied, err := copySupportHours(ctx, sourceId, targetId, isPremium)
+	if err != nil {
+		if deleteErr := dbclient.Client.PanelSupportHours.DeleteByPanelId(ctx, targetId); deleteErr != nil {
+			return false, errors.Join(err, deleteErr)
+		}
+
+		return false, err
+	}
+
+	return copied, nil
+}
+
+func copySupportHours(ctx context.Context, sourceId, targetId int, isPremium bool) (bool, error) {
+	hours, err := dbclient.Dashboard.AppliedHoursOverrides.GetWeeklyHours(ctx, sourceId)
+	if err != nil {
+		return false, err
+	}
+
+	if len(hours) == 0 || !isPremium {
+		return false, nil
+	}
+
+	for _, hour := range hours {
+		hour.Id = 0
+		hour.PanelId = targetId
+
+		if _, err := dbclient.Client.PanelSupportHours.Upsert(ctx, hour); err != nil {
+			return false, err
+		}
+	}
+
+	settings, ok, err := dbclient.Client.PanelSupportHoursSettings.Get(ctx, sourceId)
+	if err != nil {
+		return false, err
+	}
+
+	if ok {
+		settings.PanelId = targetId
+		if err := dbclient.Client.PanelSupportHoursSettings.Set(ctx, settings); err != nil {
+			return false, err
+		}
+	}
+
+	return true, nil
+}
@@ -0,0 +1,490 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/TicketsBot-cloud/dashboard/app"
+	"github.com/TicketsBot-cloud/dashboard/app/http/endpoints/api/forms"
+	"github.com/TicketsBot-cloud/dashboard/botcontext"
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/dashboard/utils"
+	"github.com/TicketsBot-cloud/dashboard/utils/types"
+	"github.com/TicketsBot-cloud/database"
+	"github.com/TicketsBot-cloud/gdl/objects/interaction/component"
+	"github.com/gin-gonic/gin"
+)
+
+const panelExportVersion = 1
+
+// panelExportDocument is a portable copy of a guild's panel configuration. Channels, roles and teams are referenced by
+// name, since their IDs differ between guilds, while forms and panels are referenced by the Ref of another entry in the
+// same document.
+type panelExportDocument struct {
+	Version     int                  `json:"version"`
+	ExportedAt  time.Time            `json:"exported_at"`
+	Forms       []exportedForm       `json:"forms"`
+	Panels      []exportedPanel      `json:"panels"`
+	MultiPanels []exportedMultiPanel `json:"multi_panels"`
+}
+
+type exportedForm struct {
+	Ref    string                      `json:"ref"`
+	Title  string                      `json:"title"`
+	Inputs []forms.FormInputDefinition `json:"inputs"`
+}
+
+// exportedEmoji omits the ID of custom emojis, which are instead matched by name in the target guild
+type exportedEmoji struct {
+	Name   string `json:"name"`
+	Custom bool   `json:"custom"`
+}
+
+type exportedAccessRule struct {
+	Role   string                       `json:"role"`
+	Action database.AccessControlAction `json:"action"`
+}
+
+type exportedSupportHours struct {
+	Timezone            string                `json:"timezone"`
+	Hours               []supportHoursPayload `json:"hours"`
+	OutOfHoursBehaviour string                `json:"out_of_hours_behaviour"`
+	OutOfHoursTitle     string                `json:"out_of_hours_title"`
+	OutOfHoursMessage   string                `json:"out_of_hours_message"`
+	OutOfHoursColour    int                   `json:"out_of_hours_colour"`
+}
+
+type exportedPanel struct {
+	Ref                       string                     `json:"ref"`
+	Channel                   string                     `json:"channel"`
+	Category                  string                     `json:"category"`
+	Title                     string                     `json:"title"`
+	Content                   string                     `json:"content"`
+	Colour                    uint32                     `json:"colour"`
+	Emoji                     *exportedEmoji             `json:"emoji"`
+	WelcomeMessage            *types.CustomEmbed         `json:"welcome_message"`
+	MentionUser               bool                       `json:"mention_user"`
+	MentionHere               bool                       `json:"mention_here"`
+	MentionRoles              []string                   `json:"mention_roles"`
+	DefaultTeam               bool                       `json:"default_team"`
+	Teams                     []string                   `json:"teams"`
+	ImageUrl                  *string                    `json:"image_url"`
+	ThumbnailUrl              *string                    `json:"thumbnail_url"`
+	ButtonStyle               component.ButtonStyle      `json:"button_style"`
+	ButtonLabel               string                     `json:"button_label"`
+	Form                      *string                    `json:"form"`
+	ExitSurveyForm            *string                    `json:"exit_survey_form"`
+	NamingScheme              *string                    `json:"naming_scheme"`
+	Disabled                  bool                       `json:"disabled"`
+	AccessControlList         []exportedAccessRule       `json:"access_control_list"`
+	PendingCategory           *string                    `json:"pending_category"`
+	DeleteMentions            bool                       `json:"delete_mentions"`
+	TranscriptChannel         *string                    `json:"transcript_channel"`
+	UseThreads                bool                       `json:"use_threads"`
+	TicketNotificationChannel *string                    `json:"ticket_notification_channel"`
+	CooldownSeconds           int                        `json:"cooldown_seconds"`
+	TicketLimit               *uint8                     `json:"ticket_limit"`
+	HideCloseButton           bool                       `json:"hide_close_button"`
+	HideCloseWithReasonButton bool                       `json:"hide_close_with_reason_button"`
+	HideClaimButton           bool                       `json:"hide_claim_button"`
+	TicketPermissions         database.TicketPermissions `json:"ticket_permissions"`
+	SupportHours              *exportedSupportHours      `json:"support_hours"`
+}
+
+type exportedMultiPanel struct {
+	Channel               string                     `json:"channel"`
+	SelectMenu            bool                       `json:"select_menu"`
+	SelectMenuPlaceholder *string                    `json:"select_menu_placeholder"`
+	Embed                 *types.CustomEmbed         `json:"embed"`
+	Panels                []exportedMultiPanelTarget `json:"panels"`
+}
+
+type exportedMultiPanelTarget struct {
+	Panel       string         `json:"panel"`
+	CustomLabel *string        `json:"custom_label"`
+	Description *string        `json:"description"`
+	CustomEmoji *exportedEmoji `json:"custom_emoji"`
+	Row         *int           `json:"row,omitempty"`
+}
+
+// ExportPanels exports all of a guild's panels, multi-panels and forms, or a single panel and its forms if the
+// panel_id query parameter is given
+func ExportPanels(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+
+	var panelId *int
+	if raw, ok := c.GetQuery("panel_id"); ok {
+		id, err := strconv.Atoi(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, utils.ErrorStr("Invalid panel ID provided: %s", raw))
+			return
+		}
+
+		panelId = &id
+	}
+
+	botContext, err := botcontext.ContextForGuild(guildId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Unable to connect to Discord. Please try again later."))
+		return
+	}
+
+	document, found, err := buildPanelExport(c, botContext, guildId, panelId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to export panels"))
+		return
+	}
+
+	if !found {
+		c.JSON(http.StatusNotFound, utils.ErrorStr("Panel not found: %d", *panelId))
+		return
+	}
+
+	c.JSON(http.StatusOK, document)
+}
+
+// buildPanelExport returns false if a panel ID was given, but the panel does not belong to the guild
+func buildPanelExport(ctx context.Context, botContext *botcontext.BotContext, guildId uint64, panelId *int) (panelExportDocument, bool, error) {
+	channels, err := botContext.GetGuildChannels(ctx, guildId)
+	if err != nil {
+		return panelExportDocument{}, false, err
+	}
+
+	roles, err := botContext.GetGuildRoles(ctx, guildId)
+	if err != nil {
+		return panelExportDocument{}, false, err
+	}
+
+	teams, err := dbclient.Client.SupportTeam.Get(ctx, guildId)
+	if err != nil {
+		return panelExportDocument{}, false, err
+	}
+
+	channelNames := make(map[uint64]string)
+	for _, ch := range channels {
+		channelNames[ch.Id] = ch.Name
+	}
+
+	roleNames := make(map[uint64]string)
+	for _, role := range roles {
+		roleNames[role.Id] = role.Name
+	}
+
+	teamNames := make(map[int]string)
+	for _, team := range teams {
+		teamNames[team.Id] = team.Name
+	}
+
+	// Deleted channels are still exported, so that they are reported as unmapped on import rather than silently dropped
+	channelName := func(id uint64) string {
+		if name, ok := channelNames[id]; ok {
+			return name
+		}
+
+		return fmt.Sprintf("deleted-channel-%d", id)
+	}
+
+	optionalChannelName := func(id *uint64) *string {
+		if id == nil {
+			return nil
+		}
+
+		return utils.Ptr(channelName(*id))
+	}
+
+	allPanels, err := dbclient.Client.Panel.GetByGuildWithWelcomeMessage(ctx, guildId)
+	if err != nil {
+		return panelExportDocument{}, false, err
+	}
+
+	panels := allPanels
+	if panelId != nil {
+		panels = nil
+		for _, p := range allPanels {
+			if p.PanelId == *panelId {
+				panels = append(panels, p)
+			}
+		}
+
+		if len(panels) == 0 {
+			return panelExportDocument{}, false, nil
+		}
+	}
+
+	accessControlLists, err := dbclient.Client.PanelAccessControlRules.GetAllForGuild(ctx, guildId)
+	if err != nil {
+		return panelExportDocument{}, false, err
+	}
+
+	embedFields, err := dbclient.Client.EmbedFields.GetAllFieldsForPanels(ctx, guildId)
+	if err != nil {
+		return panelExportDocument{}, false, err
+	}
+
+	guildForms, err := dbclient.Client.Forms.GetForms(ctx, guildId)
+	if err != nil {
+		return panelExportDocument{}, false, err
+	}
+
+	inputs, err := dbclient.Client.FormInput.GetInputsForGuild(ctx, guildId)
+	if err != nil {
+		return panelExportDocument{}, false, err
+	}
+
+	options, err := dbclient.Client.FormInputOption.GetAllOptionsByGuild(ctx, guildId)
+	if err != nil {
+		return panelExportDocument{}, false, err
+	}
+
+	conditions, err := dbclient.Dashboard.FormInputConditions.GetByGuild(ctx, guildId)
+	if err != nil {
+		return panelExportDocument{}, false, err
+	}
+
+	document := panelExportDocument{
+		Version:     panelExportVersion,
+		ExportedAt:  time.Now(),
+		Forms:       make([]exportedForm, 0),
+		Panels:      make([]exportedPanel, 0, len(panels)),
+		MultiPanels: make([]exportedMultiPanel, 0),
+	}
+
+	// When exporting a single panel, only the forms it uses are included
+	formRefs := make(map[int]string)
+	formRef := func(formId *int) *string {
+		if formId == nil {
+			return nil
+		}
+
+		if ref, ok := formRefs[*formId]; ok {
+			return &ref
+		}
+
+		for _, form := range guildForms {
+			if form.Id == *formId {
+				ref := fmt.Sprintf("form-%d", len(document.Forms)+1)
+				formRefs[form.Id] = ref
+
+				document.Forms = append(document.Forms, exportedForm{
+					Ref:    ref,
+					Title:  form.Title,
+					Inputs: forms.NewFormInputDefinitions(inputs[form.Id], options, conditions),
+				})
+
+				return &ref
+			}
+		}
+
+		return nil
+	}
+
+	if panelId == nil {
+		for _, form := range guildForms {
+			formRef(&form.Id)
+		}
+	}
+
+	panelRefs := make(map[int]string)
+	for i, p := range panels {
+		ref := fmt.Sprintf("panel-%d", i+1)
+		panelRefs[p.PanelId] = ref
+
+		exported := exportedPanel{
+			Ref:                       ref,
+			Channel:                   channelName(p.ChannelId),
+			Category:                  channelName(p.TargetCategory),
+			Title:                     p.Title,
+			Content:                   p.Content,
+			Colour:                    uint32(p.Colour),
+			DefaultTeam:               p.WithDefaultTeam,
+			MentionRoles:              make([]string, 0),
+			Teams:                     make([]string, 0),
+			ImageUrl:                  p.ImageUrl,
+			ThumbnailUrl:              p.ThumbnailUrl,
+			ButtonStyle:               component.ButtonStyle(p.ButtonStyle),
+			ButtonLabel:               p.ButtonLabel,
+			Form:                      formRef(p.FormId),
+			ExitSurveyForm:            formRef(p.ExitSurveyFormId),
+			NamingScheme:              p.NamingScheme,
+			Disabled:                  p.Disabled,
+			AccessControlList:         make([]exportedAccessRule, 0),
+			PendingCategory:           optionalChannelName(p.PendingCategory),
+			DeleteMentions:            p.DeleteMentions,
+			TranscriptChannel:         optionalChannelName(p.TranscriptChannelId),
+			UseThreads:                p.UseThreads,
+			TicketNotificationChannel: optionalChannelName(p.TicketNotificationChannel),
+			CooldownSeconds:           p.CooldownSeconds,
+			TicketLimit:               p.TicketLimit,
+			HideCloseButton:           p.HideCloseButton,
+			HideCloseWithReasonButton: p.HideCloseWithReasonButton,
+			HideClaimButton:           p.HideClaimButton,
+		}
+
+		if p.EmojiName != nil && *p.EmojiName != "" {
+			exported.Emoji = &exportedEmoji{
+				Name:   *p.EmojiName,
+				Custom: p.EmojiId != nil,
+			}
+		}
+
+		if p.WelcomeMessage != nil {
+			exported.WelcomeMessage = types.NewCustomEmbed(p.WelcomeMessage, embedFields[p.WelcomeMessage.Id])
+		}
+
+		extras, err := loadPanelExtras(ctx, p.PanelId)
+		if err != nil {
+			return panelExportDocument{}, false, err
+		}
+
+		exported.MentionUser = extras.MentionUser
+		exported.MentionHere = extras.MentionHere
+		exported.TicketPermissions = extras.TicketPermissions
+
+		for _, roleId := range extras.MentionRoles {
+			if name, ok := roleNames[roleId]; ok {
+				exported.MentionRoles = append(exported.MentionRoles, name)
+			}
+		}
+
+		for _, teamId := range extras.TeamIds {
+			if name, ok := teamNames[teamId]; ok {
+				exported.Teams = append(exported.Teams, name)
+			}
+		}
+
+		for _, rule := range accessControlLists[p.PanelId] {
+			name, ok := roleNames[rule.RoleId]
+			if !ok {
+				name = fmt.Sprintf("deleted-role-%d", rule.RoleId)
+			}
+
+			exported.AccessControlList = append(exported.AccessControlList, exportedAccessRule{
+				Role:   name,
+				Action: rule.Action,
+			})
+		}
+
+		hours, err := dbclient.Dashboard.AppliedHoursOverrides.GetWeeklyHours(ctx, p.PanelId)
+		if err != nil {
+			return panelExportDocument{}, false, err
+		}
+
+		if len(hours) > 0 {
+			settings, _, err := dbclient.Client.PanelSupportHoursSettings.Get(ctx, p.PanelId)
+			if err != nil {
+				return panelExportDocument{}, false, err
+			}
+
+			payloads := make([]supportHoursPayload, len(hours))
+			for j, hour := range hours {
+				payloads[j] = supportHoursPayload{
+					DayOfWeek: hour.DayOfWeek,
+					StartTime: hour.StartTime.Format("15:04:05"),
+					EndTime:   hour.EndTime.Format("15:04:05"),
+					Enabled:   hour.Enabled,
+				}
+			}
+
+			exported.SupportHours = &exportedSupportHours{
+				Timezone:            hours[0].Timezone,
+				Hours:               payloads,
+				OutOfHoursBehaviour: string(settings.OutOfHoursBehaviour),
+				OutOfHoursTitle:     settings.OutOfHoursTitle,
+				OutOfHoursMessage:   settings.OutOfHoursMessage,
+				OutOfHoursColour:    settings.OutOfHoursColour,
+			}
+		}
+
+		document.Panels = append(document.Panels, exported)
+	}
+
+	// A multi-panel needs at least 2 panels, so there is never one to export alongside a single panel
+	if panelId != nil {
+		return document, true, nil
+	}
+
+	multiPanels, err := dbclient.Client.MultiPanels.GetByGuild(ctx, guildId)
+	if err != nil {
+		return panelExportDocument{}, false, err
+	}
+
+	for _, multiPanel := range multiPanels {
+		targets, err := dbclient.Client.MultiPanelTargets.GetPanels(ctx, multiPanel.Id)
+		if err != nil {
+			return panelExportDocument{}, false, err
+		}
+
+		rows, err := dbclient.Dashboard.MultiPanelRows.Get(ctx, multiPanel.Id)
+		if err != nil {
+			return panelExportDocument{}, false, err
+		}
+
+		exported := exportedMultiPanel{
+			Channel:               channelName(multiPanel.ChannelId),
+			SelectMenu:            multiPanel.SelectMenu,
+			SelectMenuPlaceholder: multiPanel.SelectMenuPlaceholder,
+			Panels:                make([]exportedMultiPanelTarget, len(targets)),
+		}
+
+		if multiPanel.Embed != nil {
+			exported.Embed = types.NewCustomEmbed(multiPanel.Embed.CustomEmbed, multiPanel.Embed.Fields)
+		}
+
+		for i, target := range targets {
+			exported.Panels[i] = exportedMultiPanelTarget{
+				Panel:       panelRefs[target.PanelId],
+				CustomLabel: target.CustomLabel,
+				Description: target.Description,
+			}
+
+			if row, ok := rows[target.PanelId]; ok {
+				exported.Panels[i].Row = &row
+			}
+
+			if target.CustomEmojiName != nil && *target.CustomEmojiName != "" {
+				exported.Panels[i].CustomEmoji = &exportedEmoji{
+					Name:   *target.CustomEmojiName,
+					Custom: target.CustomEmojiId != nil,
+				}
+			}
+		}
+
+		document.MultiPanels = append(document.MultiPanels, exported)
+	}
+
+	return document, true, nil
+}
+
+// panelExtras are the settings of a panel which are stored outside of the panel's own row, and which are copied when
+// it is cloned or exported
+type panelExtras struct {
+	MentionUser       bool
+	MentionHere       bool
+	MentionRoles      []uint64
+	TeamIds           []int
+	TicketPermissions database.TicketPermissions
+}
+
+func loadPanelExtras(ctx context.Context, panelId int) (extras panelExtras, err error) {
+	if extras.MentionUser, err = dbclient.Client.PanelUserMention.ShouldMentionUser(ctx, panelId); err != nil {
+		return
+	}
+
+	if extras.MentionHere, err = dbclient.Client.PanelHereMention.ShouldMentionHere(ctx, panelId); err != nil {
+		return
+	}
+
+	if extras.MentionRoles, err = dbclient.Client.PanelRoleMentions.GetRoles(ctx, panelId); err != nil {
+		return
+	}
+
+	if extras.TeamIds, err = dbclient.Client.PanelTeams.GetTeamIds(ctx, panelId); err != nil {
+		return
+	}
+
+	extras.TicketPermissions, err = dbclient.Client.PanelTicketPermissions.Get(ctx, panelId)
+	return
+}
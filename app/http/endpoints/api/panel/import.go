@@ -0,0 +1,882 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/TicketsBot-cloud/common/premium"
+	"github.com/TicketsBot-cloud/dashboard/app"
+	"github.com/TicketsBot-cloud/dashboard/app/http/audit"
+	"github.com/TicketsBot-cloud/dashboard/app/http/endpoints/api/forms"
+	"github.com/TicketsBot-cloud/dashboard/app/http/validation"
+	"github.com/TicketsBot-cloud/dashboard/botcontext"
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/dashboard/log"
+	"github.com/TicketsBot-cloud/dashboard/rpc"
+	"github.com/TicketsBot-cloud/dashboard/utils"
+	"github.com/TicketsBot-cloud/dashboard/utils/types"
+	"github.com/TicketsBot-cloud/database"
+	"github.com/TicketsBot-cloud/gdl/objects/channel"
+	"github.com/TicketsBot-cloud/gdl/objects/guild"
+	"github.com/TicketsBot-cloud/gdl/objects/guild/emoji"
+	"github.com/TicketsBot-cloud/gdl/rest/request"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"go.uber.org/zap"
+)
+
+type panelImportBody struct {
+	Document panelExportDocument `json:"document"`
+	Mappings importMappings      `json:"mappings"`
+}
+
+// importMappings overrides matching by name, for references that are named differently, or ambiguously, in the target
+// guild. Keys are the names used in the document.
+type importMappings struct {
+	Channels map[string]string `json:"channels"`
+	Roles    map[string]string `json:"roles"`
+	Teams    map[string]int    `json:"teams"`
+}
+
+type referenceKind string
+
+const (
+	referenceChannel  referenceKind = "channel"
+	referenceCategory referenceKind = "category"
+	referenceRole     referenceKind = "role"
+	referenceTeam     referenceKind = "team"
+	referenceEmoji    referenceKind = "emoji"
+	referenceForm     referenceKind = "form"
+	referencePanel    referenceKind = "panel"
+)
+
+// unmappedReference is a reference that could not be resolved in the target guild. If it is not required, the import
+// goes ahead without it, e.g. a role is left out of the panel's mentions.
+type unmappedReference struct {
+	Kind     referenceKind `json:"kind"`
+	Name     string        `json:"name"`
+	UsedBy   string        `json:"used_by"`
+	Reason   string        `json:"reason"`
+	Required bool          `json:"required"`
+}
+
+type importError struct {
+	Ref     string `json:"ref"`
+	Message string `json:"message"`
+}
+
+type importReport struct {
+	Unmapped []unmappedReference `json:"unmapped"`
+	Errors   []importError       `json:"errors"`
+}
+
+func (r *importReport) blocked() bool {
+	if len(r.Errors) > 0 {
+		return true
+	}
+
+	for _, reference := range r.Unmapped {
+		if reference.Required {
+			return true
+		}
+	}
+
+	return false
+}
+
+// addError records validation errors against the document entry, and returns any other error as-is
+func (r *importReport) addError(ref string, err error) error {
+	var validationError *validation.InvalidInputError
+	if !errors.As(err, &validationError) {
+		return err
+	}
+
+	r.Errors = append(r.Errors, importError{
+		Ref:     ref,
+		Message: validationError.Error(),
+	})
+
+	return nil
+}
+
+// importResolver maps the named references in an export document onto the target guild
+type importResolver struct {
+	report   *importReport
+	mappings importMappings
+	channels []channel.Channel
+	roles    []guild.Role
+	teams    []database.SupportTeam
+	emojis   []emoji.Emoji
+	formRefs map[string]bool
+}
+
+func (r *importResolver) unmapped(kind referenceKind, name, usedBy, reason string, required bool) {
+	r.report.Unmapped = append(r.report.Unmapped, unmappedReference{
+		Kind:     kind,
+		Name:     name,
+		UsedBy:   usedBy,
+		Reason:   reason,
+		Required: required,
+	})
+}
+
+func (r *importResolver) unique(kind referenceKind, name, usedBy string, required bool, matches []uint64) (uint64, bool) {
+	switch len(matches) {
+	case 1:
+		return matches[0], true
+	case 0:
+		r.unmapped(kind, name, usedBy, "Not found in this server", required)
+	default:
+		r.unmapped(kind, name, usedBy, "More than one match in this server, add a mapping to choose one", required)
+	}
+
+	return 0, false
+}
+
+func (r *importResolver) mapped(kind referenceKind, mappings map[string]string, name, usedBy string, required bool) (uint64, bool, bool) {
+	raw, ok := mappings[name]
+	if !ok {
+		return 0, false, false
+	}
+
+	id, err := strconv.ParseUint(raw, 10, 64)
+	if err != nil {
+		r.unmapped(kind, name, usedBy, "Invalid ID in mapping", required)
+		return 0, false, true
+	}
+
+	return id, true, true
+}
+
+func (r *importResolver) channel(kind referenceKind, name, usedBy string, required bool) (uint64, bool) {
+	if id, ok, found := r.mapped(kind, r.mappings.Channels, name, usedBy, required); found {
+		return id, ok
+	}
+
+	var matches []uint64
+	for _, ch := range r.channels {
+		if !strings.EqualFold(ch.Name, name) {
+			continue
+		}
+
+		if kind == referenceCategory && ch.Type == channel.ChannelTypeGuildCategory {
+			matches = append(matches, ch.Id)
+		} else if kind == referenceChannel && (ch.Type == channel.ChannelTypeGuildText || ch.Type == channel.ChannelTypeGuildNews) {
+			matches = append(matches, ch.Id)
+		}
+	}
+
+	return r.unique(kind, name, usedBy, required, matches)
+}
+
+// optionalChannel resolves a channel that the document may not reference at all
+func (r *importResolver) optionalChannel(kind referenceKind, name *string, usedBy string) *uint64 {
+	if name == nil {
+		return nil
+	}
+
+	if id, ok := r.channel(kind, *name, usedBy, false); ok {
+		return &id
+	}
+
+	return nil
+}
+
+func (r *importResolver) role(name, usedBy string, required bool) (uint64, bool) {
+	if id, ok, found := r.mapped(referenceRole, r.mappings.Roles, name, usedBy, required); found {
+		return id, ok
+	}
+
+	var matches []uint64
+	for _, role := range r.roles {
+		if strings.EqualFold(role.Name, name) {
+			matches = append(matches, role.Id)
+		}
+	}
+
+	return r.unique(referenceRole, name, usedBy, required, matches)
+}
+
+func (r *importResolver) team(name, usedBy string) (int, bool) {
+	if id, ok := r.mappings.Teams[name]; ok {
+		return id, true
+	}
+
+	var matches []int
+	for _, team := range r.teams {
+		if strings.EqualFold(team.Name, name) {
+			matches = append(matches, team.Id)
+		}
+	}
+
+	switch len(matches) {
+	case 1:
+		return matches[0], true
+	case 0:
+		r.unmapped(referenceTeam, name, usedBy, "Not found in this server", false)
+	default:
+		r.unmapped(referenceTeam, name, usedBy, "More than one match in this server, add a mapping to choose one", false)
+	}
+
+	return 0, false
+}
+
+// emoji matches custom emojis by name, leaving the button without an emoji if there is no match
+func (r *importResolver) emoji(e *exportedEmoji, usedBy string) types.Emoji {
+	if e == nil {
+		return types.Emoji{}
+	}
+
+	if !e.Custom {
+		return types.NewEmoji(&e.Name, nil)
+	}
+
+	for _, guildEmoji := range r.emojis {
+		if guildEmoji.Name == e.Name && guildEmoji.Id.Value != 0 {
+			return types.NewEmoji(&e.Name, &guildEmoji.Id.Value)
+		}
+	}
+
+	r.unmapped(referenceEmoji, e.Name, usedBy, "Not found in this server", false)
+	return types.Emoji{}
+}
+
+func (r *importResolver) form(ref *string, usedBy string) bool {
+	if ref == nil || r.formRefs[*ref] {
+		return true
+	}
+
+	r.unmapped(referenceForm, *ref, usedBy, "Not included in the document", true)
+	return false
+}
+
+// toPanelBody returns false if a required reference could not be resolved. Forms are left unset, since they are only
+// created once the whole document has been validated.
+func (r *importResolver) toPanelBody(p exportedPanel) (panelBody, bool) {
+	data := panelBody{
+		Title:                     p.Title,
+		Content:                   p.Content,
+		Colour:                    p.Colour,
+		Emoji:                     r.emoji(p.Emoji, p.Ref),
+		WelcomeMessage:            p.WelcomeMessage,
+		Mentions:                  make([]string, 0),
+		WithDefaultTeam:           p.DefaultTeam,
+		Teams:                     make([]int, 0),
+		ImageUrl:                  p.ImageUrl,
+		ThumbnailUrl:              p.ThumbnailUrl,
+		ButtonStyle:               p.ButtonStyle,
+		ButtonLabel:               p.ButtonLabel,
+		NamingScheme:              p.NamingScheme,
+		Disabled:                  p.Disabled,
+		PendingCategory:           r.optionalChannel(referenceCategory, p.PendingCategory, p.Ref),
+		DeleteMentions:            p.DeleteMentions,
+		TranscriptChannelId:       r.optionalChannel(referenceChannel, p.TranscriptChannel, p.Ref),
+		UseThreads:                p.UseThreads,
+		TicketNotificationChannel: r.optionalChannel(referenceChannel, p.TicketNotificationChannel, p.Ref),
+		CooldownSeconds:           p.CooldownSeconds,
+		TicketLimit:               p.TicketLimit,
+		HideCloseButton:           p.HideCloseButton,
+		HideCloseWithReasonButton: p.HideCloseWithReasonButton,
+		HideClaimButton:           p.HideClaimButton,
+		TicketPermissions:         p.TicketPermissions,
+	}
+
+	channelId, channelOk := r.channel(referenceChannel, p.Channel, p.Ref, true)
+	categoryId, categoryOk := r.channel(referenceCategory, p.Category, p.Ref, true)
+	data.ChannelId = channelId
+	data.CategoryId = categoryId
+	ok := channelOk && categoryOk
+
+	if p.MentionUser {
+		data.Mentions = append(data.Mentions, "user")
+	}
+
+	if p.MentionHere {
+		data.Mentions = append(data.Mentions, "here")
+	}
+
+	for _, name := range p.MentionRoles {
+		if roleId, found := r.role(name, p.Ref, false); found {
+			data.Mentions = append(data.Mentions, strconv.FormatUint(roleId, 10))
+		}
+	}
+
+	for _, name := range p.Teams {
+		if teamId, found := r.team(name, p.Ref); found {
+			data.Teams = append(data.Teams, teamId)
+		}
+	}
+
+	// Leaving out a rule could let users open tickets who were meant to be denied, so every role must be found
+	for _, rule := range p.AccessControlList {
+		roleId, found := r.role(rule.Role, p.Ref, true)
+		if !found {
+			ok = false
+			continue
+		}
+
+		data.AccessControlList = append(data.AccessControlList, database.PanelAccessControlRule{
+			RoleId: roleId,
+			Action: rule.Action,
+		})
+	}
+
+	formOk := r.form(p.Form, p.Ref)
+	exitSurveyOk := r.form(p.ExitSurveyForm, p.Ref)
+
+	return data, ok && formOk && exitSurveyOk
+}
+
+// validate checks the hours in the same way as SetSupportHours
+func (h exportedSupportHours) validate() ([]dbclient.SupportHoursWindow, database.PanelSupportHoursSettings, error) {
+	if !database.IsValidTimezone(h.Timezone) {
+		return nil, database.PanelSupportHoursSettings{}, validation.NewInvalidInputErrorf("Invalid timezone: %s", h.Timezone)
+	}
+
+	windows, err := parseSupportHours(h.Hours)
+	if err != nil {
+		return nil, database.PanelSupportHoursSettings{}, validation.NewInvalidInputError(err.Error())
+	}
+
+	behaviour := h.OutOfHoursBehaviour
+	if behaviour == "" {
+		behaviour = string(database.OutOfHoursBehaviourBlockCreation)
+	}
+
+	if behaviour != string(database.OutOfHoursBehaviourBlockCreation) && behaviour != string(database.OutOfHoursBehaviourAllowWithWarning) {
+		return nil, database.PanelSupportHoursSettings{}, validation.NewInvalidInputError("Invalid out_of_hours_behaviour: must be 'block_creation' or 'allow_with_warning'")
+	}
+
+	if len(h.OutOfHoursMessage) > 500 {
+		return nil, database.PanelSupportHoursSettings{}, validation.NewInvalidInputError("Out of hours message must be 500 characters or less")
+	}
+
+	if len(h.OutOfHoursTitle) > 100 {
+		return nil, database.PanelSupportHoursSettings{}, validation.NewInvalidInputError("Out of hours title must be 100 characters or less")
+	}
+
+	return windows, database.PanelSupportHoursSettings{
+		OutOfHoursBehaviour: database.OutOfHoursBehaviour(behaviour),
+		OutOfHoursTitle:     h.OutOfHoursTitle,
+		OutOfHoursMessage:   h.OutOfHoursMessage,
+		OutOfHoursColour:    h.OutOfHoursColour,
+	}, nil
+}
+
+// importedPanel is a panel from the document that has passed validation
+type importedPanel struct {
+	Ref        string
+	Data       panelBody
+	Form       *string
+	ExitSurvey *string
+	Hours      []dbclient.SupportHoursWindow
+	Timezone   string
+	Settings   database.PanelSupportHoursSettings
+}
+
+// importedMultiPanel is a multi-panel from the document that has passed validation
+type importedMultiPanel struct {
+	Data      multiPanelCreateData
+	PanelRefs []string
+}
+
+// ImportPanels creates the forms, panels and multi-panels in an export document, mapping its references onto this
+// guild. Nothing is created unless every entry is valid and every required reference can be mapped; otherwise, the
+// problems are returned so that the document or mappings can be fixed.
+func ImportPanels(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+	userId := c.Keys["userid"].(uint64)
+
+	var body panelImportBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("Invalid request data. Please check your input and try again."))
+		return
+	}
+
+	document := body.Document
+	if document.Version != panelExportVersion {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("Unsupported export version: %d", document.Version))
+		return
+	}
+
+	botContext, err := botcontext.ContextForGuild(guildId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Unable to connect to Discord. Please try again later."))
+		return
+	}
+
+	premiumTier, err := rpc.PremiumClient.GetTierByGuildId(c, guildId, false, botContext.Token, botContext.RateLimiter)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to verify premium status"))
+		return
+	}
+
+	existingPanels, err := dbclient.Client.Panel.GetByGuild(c, guildId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch existing panels"))
+		return
+	}
+
+	if premiumTier == premium.None && len(existingPanels)+len(document.Panels) > freePanelLimit {
+		c.JSON(402, utils.ErrorStr("Panel quota exceeded: importing %d panels would exceed the limit of %d panels. Purchase premium to unlock more panels.", len(document.Panels), freePanelLimit))
+		return
+	}
+
+	ctx, cancel := app.DefaultContext()
+	defer cancel()
+
+	channels, err := botContext.GetGuildChannels(ctx, guildId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch guild channels from Discord"))
+		return
+	}
+
+	roles, err := botContext.GetGuildRoles(ctx, guildId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Unable to load roles from Discord. Please try again."))
+		return
+	}
+
+	emojis, err := botContext.GetGuildEmojis(ctx, guildId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Unable to load emojis from Discord. Please try again."))
+		return
+	}
+
+	teams, err := dbclient.Client.SupportTeam.Get(c, guildId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch support teams"))
+		return
+	}
+
+	report := importReport{
+		Unmapped: make([]unmappedReference, 0),
+		Errors:   make([]importError, 0),
+	}
+
+	resolver := importResolver{
+		report:   &report,
+		mappings: body.Mappings,
+		channels: channels,
+		roles:    roles,
+		teams:    teams,
+		emojis:   emojis,
+		formRefs: make(map[string]bool),
+	}
+
+	for _, form := range document.Forms {
+		if resolver.formRefs[form.Ref] {
+			report.Errors = append(report.Errors, importError{Ref: form.Ref, Message: "Duplicate ref"})
+			continue
+		}
+
+		resolver.formRefs[form.Ref] = true
+
+		if err := forms.ValidateFormDefinition(form.Title, form.Inputs); err != nil {
+			if err := report.addError(form.Ref, err); err != nil {
+				_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Form validation failed unexpectedly"))
+				return
+			}
+		}
+	}
+
+	panels := make([]importedPanel, 0, len(document.Panels))
+	panelsByRef := make(map[string]exportedPanel)
+	for _, p := range document.Panels {
+		if _, ok := panelsByRef[p.Ref]; ok {
+			report.Errors = append(report.Errors, importError{Ref: p.Ref, Message: "Duplicate ref"})
+			continue
+		}
+
+		panelsByRef[p.Ref] = p
+
+		data, ok := resolver.toPanelBody(p)
+		if !ok {
+			continue
+		}
+
+		ApplyPanelDefaults(&data)
+
+		data, err := validatePanelForCreate(PanelValidationContext{
+			Data:       data,
+			GuildId:    guildId,
+			IsPremium:  premiumTier > premium.None,
+			BotContext: botContext,
+			Channels:   channels,
+			Roles:      roles,
+		})
+		if err != nil {
+			if err := report.addError(p.Ref, err); err != nil {
+				_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Panel validation failed unexpectedly"))
+				return
+			}
+
+			continue
+		}
+
+		imported := importedPanel{
+			Ref:        p.Ref,
+			Data:       data,
+			Form:       p.Form,
+			ExitSurvey: p.ExitSurveyForm,
+		}
+
+		if p.SupportHours != nil {
+			imported.Hours, imported.Settings, err = p.SupportHours.validate()
+			if err != nil {
+				_ = report.addError(p.Ref, err)
+				continue
+			}
+
+			imported.Timezone = p.SupportHours.Timezone
+		}
+
+		panels = append(panels, imported)
+	}
+
+	// Free servers can only have support hours on a single panel
+	if premiumTier == premium.None {
+		withHours := 0
+		for _, panel := range existingPanels {
+			hours, err := dbclient.Dashboard.AppliedHoursOverrides.GetWeeklyHours(c, panel.PanelId)
+			if err != nil {
+				_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to process request"))
+				return
+			}
+
+			if len(hours) > 0 {
+				withHours++
+			}
+		}
+
+		for _, panel := range panels {
+			if panel.Hours != nil {
+				withHours++
+			}
+		}
+
+		if withHours > 1 {
+			report.Errors = append(report.Errors, importError{
+				Message: "Free users can only configure support hours on one panel. Upgrade to premium for unlimited support hours.",
+			})
+		}
+	}
+
+	multiPanels := make([]importedMultiPanel, 0, len(document.MultiPanels))
+	for i, multiPanel := range document.MultiPanels {
+		ref := fmt.Sprintf("multi-panel-%d", i+1)
+
+		imported := importedMultiPanel{
+			Data: multiPanelCreateData{
+				SelectMenu:            multiPanel.SelectMenu,
+				SelectMenuPlaceholder: multiPanel.SelectMenuPlaceholder,
+				Embed:                 multiPanel.Embed,
+				Panels:                make([]panelConfiguration, len(multiPanel.Panels)),
+			},
+			PanelRefs: make([]string, len(multiPanel.Panels)),
+		}
+
+		channelId, ok := resolver.channel(referenceChannel, multiPanel.Channel, ref, true)
+		imported.Data.ChannelId = channelId
+
+		if len(multiPanel.Panels) < minMultiPanelPanels || len(multiPanel.Panels) > maxMultiPanelPanels {
+			report.Errors = append(report.Errors, importError{
+				Ref:     ref,
+				Message: fmt.Sprintf("A multi-panel must contain between %d and %d sub-panels", minMultiPanelPanels, maxMultiPanelPanels),
+			})
+			continue
+		}
+
+		seenRefs := make(map[string]bool)
+		for j, target := range multiPanel.Panels {
+			if seenRefs[target.Panel] {
+				report.Errors = append(report.Errors, importError{Ref: ref, Message: fmt.Sprintf("Panel '%s' is included more than once", target.Panel)})
+				ok = false
+				continue
+			}
+
+			seenRefs[target.Panel] = true
+
+			panel, found := panelsByRef[target.Panel]
+			if !found {
+				resolver.unmapped(referencePanel, target.Panel, ref, "Not included in the document", true)
+				ok = false
+				continue
+			}
+
+			if multiPanel.SelectMenu && getEffectiveLabelForValidation(panel.ButtonLabel, target.CustomLabel) == "" {
+				report.Errors = append(report.Errors, importError{
+					Ref:     ref,
+					Message: fmt.Sprintf("Panel '%s' must have a label when using dropdown mode", panel.Title),
+				})
+			}
+
+			config := panelConfiguration{
+				CustomLabel: target.CustomLabel,
+				Description: target.Description,
+				Row:         target.Row,
+			}
+
+			if target.CustomEmoji != nil {
+				emoji := resolver.emoji(target.CustomEmoji, ref)
+				if emoji.Name != "" {
+					config.CustomEmojiName = &emoji.Name
+					config.CustomEmojiId = emoji.Id
+				}
+			}
+
+			imported.Data.Panels[j] = config
+			imported.PanelRefs[j] = target.Panel
+		}
+
+		if !ok {
+			continue
+		}
+
+		if err := validateEmbed(imported.Data.Embed); err != nil {
+			_ = report.addError(ref, err)
+			continue
+		}
+
+		if err := imported.Data.validateLayout(); err != nil {
+			_ = report.addError(ref, err)
+			continue
+		}
+
+		if err := validate.Struct(imported.Data); err != nil {
+			var validationErrors validator.ValidationErrors
+			if !errors.As(err, &validationErrors) {
+				_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "An error occurred while validating the multi-panel"))
+				return
+			}
+
+			report.Errors = append(report.Errors, importError{
+				Ref:     ref,
+				Message: "Your input contained the following errors:\n" + utils.FormatValidationErrors(validationErrors),
+			})
+			continue
+		}
+
+		multiPanels = append(multiPanels, imported)
+	}
+
+	if report.blocked() {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"success":  false,
+			"error":    "The document could not be imported into this server",
+			"unmapped": report.Unmapped,
+			"errors":   report.Errors,
+		})
+		return
+	}
+
+	// Everything has been validated, so now create it all. If anything fails, whatever has already been created is
+	// removed again, so that the import can be retried. Audit log entries are only recorded once the import succeeds.
+	created := &createdResources{}
+	var auditEntries []audit.LogEntry
+
+	formIds := make(map[string]int)
+	for _, exported := range document.Forms {
+		form, err := forms.CreateFormFromDefinition(c, guildId, exported.Title, exported.Inputs)
+		if err != nil {
+			abortImport(c, botContext, guildId, created, err, exported.Ref, nil)
+			return
+		}
+
+		created.addForm(form.Id)
+		formIds[exported.Ref] = form.Id
+
+		auditEntries = append(auditEntries, audit.LogEntry{
+			GuildId:      audit.Uint64Ptr(guildId),
+			UserId:       userId,
+			ActionType:   database.AuditActionFormCreate,
+			ResourceType: database.AuditResourceForm,
+			ResourceId:   audit.StringPtr(strconv.Itoa(form.Id)),
+			NewData:      exported,
+		})
+	}
+
+	createdPanels := make(map[string]database.Panel)
+	panelIds := make(map[string]int)
+	for _, imported := range panels {
+		data := imported.Data
+		if imported.Form != nil {
+			data.FormId = utils.Ptr(formIds[*imported.Form])
+		}
+
+		if imported.ExitSurvey != nil {
+			data.ExitSurveyFormId = utils.Ptr(formIds[*imported.ExitSurvey])
+		}
+
+		panel, err := createPanel(c, botContext, guildId, data, premiumTier > premium.None, roles)
+		if err != nil {
+			abortImport(c, botContext, guildId, created, err, imported.Ref, &data.ChannelId)
+			return
+		}
+
+		created.addPanel(panel)
+
+		if imported.Hours != nil {
+			if err := saveImportedSupportHours(c, panel.PanelId, imported); err != nil {
+				abortImport(c, botContext, guildId, created, err, imported.Ref, nil)
+				return
+			}
+		}
+
+		createdPanels[imported.Ref] = panel
+		panelIds[imported.Ref] = panel.PanelId
+
+		auditEntries = append(auditEntries, audit.LogEntry{
+			GuildId:      audit.Uint64Ptr(guildId),
+			UserId:       userId,
+			ActionType:   database.AuditActionPanelCreate,
+			ResourceType: database.AuditResourcePanel,
+			ResourceId:   audit.StringPtr(strconv.Itoa(panel.PanelId)),
+			NewData:      data,
+		})
+	}
+
+	multiPanelIds := make([]int, 0, len(multiPanels))
+	for i, imported := range multiPanels {
+		ref := fmt.Sprintf("multi-panel-%d", i+1)
+
+		panelsWithCustom := make([]database.PanelWithCustomization, len(imported.PanelRefs))
+		for j, panelRef := range imported.PanelRefs {
+			config := imported.Data.Panels[j]
+			config.PanelId = createdPanels[panelRef].PanelId
+			imported.Data.Panels[j] = config
+
+			panelsWithCustom[j] = database.PanelWithCustomization{
+				Panel:           createdPanels[panelRef],
+				CustomLabel:     config.CustomLabel,
+				Description:     config.Description,
+				CustomEmojiName: config.CustomEmojiName,
+				CustomEmojiId:   config.CustomEmojiId,
+			}
+		}
+
+		messageData := imported.Data.IntoMessageData(premiumTier > premium.None)
+		messageId, err := messageData.send(botContext, panelsWithCustom)
+		if err != nil {
+			abortImport(c, botContext, guildId, created, err, ref, &imported.Data.ChannelId)
+			return
+		}
+
+		dbEmbed, dbEmbedFields := imported.Data.Embed.IntoDatabaseStruct()
+		multiPanel := database.MultiPanel{
+			MessageId:             messageId,
+			ChannelId:             imported.Data.ChannelId,
+			GuildId:               guildId,
+			SelectMenu:            imported.Data.SelectMenu,
+			SelectMenuPlaceholder: imported.Data.SelectMenuPlaceholder,
+			Embed: &database.CustomEmbedWithFields{
+				CustomEmbed: dbEmbed,
+				Fields:      dbEmbedFields,
+			},
+		}
+
+		created.addMultiPanel(multiPanel)
+
+		multiPanel.Id, err = dbclient.Client.MultiPanels.Create(c, multiPanel)
+		if err != nil {
+			abortImport(c, botContext, guildId, created, err, ref, nil)
+			return
+		}
+
+		created.setMultiPanelId(messageId, multiPanel.Id)
+
+		for position, config := range imported.Data.Panels {
+			if err := dbclient.Client.MultiPanelTargets.Insert(c, multiPanel.Id, config.PanelId, position, config.CustomLabel, config.Description, config.CustomEmojiName, config.CustomEmojiId); err != nil {
+				abortImport(c, botContext, guildId, created, err, ref, nil)
+				return
+			}
+		}
+
+		if err := dbclient.Dashboard.MultiPanelRows.Replace(c, multiPanel.Id, imported.Data.rows()); err != nil {
+			abortImport(c, botContext, guildId, created, err, ref, nil)
+			return
+		}
+
+		multiPanelIds = append(multiPanelIds, multiPanel.Id)
+
+		auditEntries = append(auditEntries, audit.LogEntry{
+			GuildId:      audit.Uint64Ptr(guildId),
+			UserId:       userId,
+			ActionType:   database.AuditActionMultiPanelCreate,
+			ResourceType: database.AuditResourceMultiPanel,
+			ResourceId:   audit.StringPtr(strconv.Itoa(multiPanel.Id)),
+			NewData:      imported.Data,
+		})
+	}
+
+	for _, entry := range auditEntries {
+		audit.Log(entry)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success":      true,
+		"forms":        formIds,
+		"panels":       panelIds,
+		"multi_panels": multiPanelIds,
+		"unmapped":     report.Unmapped,
+	})
+}
+
+func saveImportedSupportHours(ctx context.Context, panelId int, imported importedPanel) error {
+	for _, window := range imported.Hours {
+		if _, err := dbclient.Client.PanelSupportHours.Upsert(ctx, database.PanelSupportHours{
+			PanelId:   panelId,
+			DayOfWeek: window.DayOfWeek,
+			StartTime: window.StartTime,
+			EndTime:   window.EndTime,
+			Enabled:   window.Enabled,
+			Timezone:  imported.Timezone,
+		}); err != nil {
+			return err
+		}
+	}
+
+	settings := imported.Settings
+	settings.PanelId = panelId
+	return dbclient.Client.PanelSupportHoursSettings.Set(ctx, settings)
+}
+
+// abortImport removes everything the import has created so far, then writes the response for the error, such as the
+// bot lacking permission to send a panel message. channelId is set if the error came from sending a panel message. If
+// anything could not be removed, the response lists it, so that it can be deleted before the import is retried.
+func abortImport(c *gin.Context, botContext *botcontext.BotContext, guildId uint64, created *createdResources, err error, ref string, channelId *uint64) {
+	rollbackErr := created.rollback(c, botContext, guildId)
+	if rollbackErr != nil {
+		log.Logger.Error("Failed to roll back panel import", zap.Uint64("guild_id", guildId), zap.String("left_behind", created.String()), zap.Error(rollbackErr))
+	}
+
+	var unwrapped request.RestError
+	if channelId == nil || !errors.As(err, &unwrapped) {
+		message := fmt.Sprintf("Failed to import %s", ref)
+		if rollbackErr != nil {
+			message = fmt.Sprintf("%s, and the following could not be removed: %s. Delete them before retrying.", message, created)
+		}
+
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, message))
+		return
+	}
+
+	var message string
+	if unwrapped.StatusCode == http.StatusForbidden {
+		message = fmt.Sprintf("Failed to import %s: bot does not have permission to send messages in channel %d", ref, *channelId)
+	} else {
+		message = fmt.Sprintf("Failed to import %s: failed to send message to channel %d: %s", ref, *channelId, unwrapped.ApiError.Message)
+	}
+
+	response := utils.ErrorStr("%s", message)
+	response["rolled_back"] = rollbackErr == nil
+	if rollbackErr != nil {
+		response["left_behind"] = created
+	}
+
+	c.JSON(http.StatusBadRequest, response)
+}
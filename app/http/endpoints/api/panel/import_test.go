@@ -0,0 +1,115 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/TicketsBot-cloud/database"
+	"github.com/TicketsBot-cloud/gdl/objects/channel"
+	"github.com/TicketsBot-cloud/gdl/objects/guild"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestResolver(mappings importMappings) (*importResolver, *importReport) {
+	report := &importReport{}
+
+	return &importResolver{
+		report:   report,
+		mappings: mappings,
+		channels: []channel.Channel{
+			{Id: 1, Name: "support", Type: channel.ChannelTypeGuildText},
+			{Id: 2, Name: "Tickets", Type: channel.ChannelTypeGuildCategory},
+			{Id: 3, Name: "tickets", Type: channel.ChannelTypeGuildText},
+		},
+		roles: []guild.Role{
+			{Id: 100, Name: "@everyone"},
+			{Id: 101, Name: "Staff"},
+			{Id: 102, Name: "staff"},
+		},
+		teams: []database.SupportTeam{
+			{Id: 7, Name: "Billing"},
+		},
+		formRefs: map[string]bool{"form-1": true},
+	}, report
+}
+
+func TestResolveChannelByNameAndType(t *testing.T) {
+	resolver, report := newTestResolver(importMappings{})
+
+	id, ok := resolver.channel(referenceCategory, "tickets", "panel-1", true)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(2), id)
+
+	id, ok = resolver.channel(referenceChannel, "tickets", "panel-1", true)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(3), id)
+
+	_, ok = resolver.channel(referenceChannel, "missing", "panel-1", false)
+	assert.False(t, ok)
+
+	if assert.Len(t, report.Unmapped, 1) {
+		assert.False(t, report.Unmapped[0].Required)
+	}
+
+	assert.False(t, report.blocked())
+}
+
+func TestResolveAmbiguousRoleWithMapping(t *testing.T) {
+	resolver, report := newTestResolver(importMappings{})
+
+	_, ok := resolver.role("Staff", "panel-1", true)
+	assert.False(t, ok)
+	assert.True(t, report.blocked())
+
+	resolver, report = newTestResolver(importMappings{Roles: map[string]string{"Staff": "102"}})
+
+	id, ok := resolver.role("Staff", "panel-1", true)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(102), id)
+	assert.Empty(t, report.Unmapped)
+}
+
+func TestToPanelBody(t *testing.T) {
+	resolver, report := newTestResolver(importMappings{})
+
+	form := "form-1"
+	data, ok := resolver.toPanelBody(exportedPanel{
+		Ref:          "panel-1",
+		Channel:      "support",
+		Category:     "Tickets",
+		MentionUser:  true,
+		MentionRoles: []string{"Missing"},
+		Teams:        []string{"billing", "Sales"},
+		Form:         &form,
+		AccessControlList: []exportedAccessRule{
+			{Role: "@everyone", Action: database.AccessControlActionAllow},
+		},
+	})
+
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1), data.ChannelId)
+	assert.Equal(t, uint64(2), data.CategoryId)
+	assert.Equal(t, []string{"user"}, data.Mentions)
+	assert.Equal(t, []int{7}, data.Teams)
+	assert.Equal(t, uint64(100), data.AccessControlList[0].RoleId)
+
+	// The missing mention role and team are dropped, rather than blocking the import
+	assert.Len(t, report.Unmapped, 2)
+	assert.False(t, report.blocked())
+}
+
+func TestToPanelBodyMissingAccessControlRole(t *testing.T) {
+	resolver, report := newTestResolver(importMappings{})
+
+	_, ok := resolver.toPanelBody(exportedPanel{
+		Ref:      "panel-1",
+		Channel:  "support",
+		Category: "Tickets",
+		AccessControlList: []exportedAccessRule{
+			{Role: "@everyone", Action: database.AccessControlActionDeny},
+			{Role: "Members", Action: database.AccessControlActionAllow},
+		},
+	})
+
+	assert.False(t, ok)
+	assert.True(t, report.blocked())
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/TicketsBot-cloud/common/premium"
 	"github.com/TicketsBot-cloud/dashboard/app"
 	"github.com/TicketsBot-cloud/dashboard/app/http/audit"
+	"github.com/TicketsBot-cloud/dashboard/app/http/validation"
 	"github.com/TicketsBot-cloud/dashboard/botcontext"
 	dbclient "github.com/TicketsBot-cloud/dashboard/database"
 	"github.com/TicketsBot-cloud/dashboard/rpc"
@@ -23,12 +24,21 @@ import (
 	"golang.org/x/sync/errgroup"
 )
 
+// Discord allows at most 5 action rows of 5 buttons on a message, and 25 options in a select menu
+const (
+	maxActionRows       = 5
+	maxButtonsPerRow    = 5
+	minMultiPanelPanels = 2
+	maxMultiPanelPanels = 25
+)
+
 type panelConfiguration struct {
 	PanelId         int     `json:"panel_id"`
 	CustomEmojiName *string `json:"custom_emoji_name" validate:"omitempty,max=32"`
 	CustomEmojiId   *uint64 `json:"custom_emoji_id,string"`
 	CustomLabel     *string `json:"custom_label" validate:"omitempty,max=80"`
 	Description     *string `json:"description" validate:"omitempty,max=100"`
+	Row             *int    `json:"row,omitempty" validate:"omitempty,min=0,max=4"`
 }
 
 func getEffectiveLabelForValidation(buttonLabel string, customLabel *string) string {
@@ -52,10 +62,39 @@ func (d *multiPanelCreateData) IntoMessageData(isPremium bool) multiPanelMessage
 		ChannelId:             d.ChannelId,
 		SelectMenu:            d.SelectMenu,
 		SelectMenuPlaceholder: d.SelectMenuPlaceholder,
+		Rows:                  d.rows(),
 		Embed:                 d.Embed.IntoDiscordEmbed(),
 	}
 }
 
+// rows returns the action row of each sub-panel which has been assigned one, keyed by panel ID
+func (d *multiPanelCreateData) rows() map[int]int {
+	rows := make(map[int]int)
+	for _, config := range d.Panels {
+		if config.Row != nil {
+			rows[config.PanelId] = *config.Row
+		}
+	}
+
+	return rows
+}
+
+// withCustomization combines the sub-panels returned by validatePanels with their configurations
+func (d *multiPanelCreateData) withCustomization(panels []database.Panel) []database.PanelWithCustomization {
+	panelsWithCustom := make([]database.PanelWithCustomization, len(panels))
+	for i, panel := range panels {
+		panelsWithCustom[i] = database.PanelWithCustomization{
+			Panel:           panel,
+			CustomLabel:     d.Panels[i].CustomLabel,
+			Description:     d.Panels[i].Description,
+			CustomEmojiName: d.Panels[i].CustomEmojiName,
+			CustomEmojiId:   d.Panels[i].CustomEmojiId,
+		}
+	}
+
+	return panelsWithCustom
+}
+
 func MultiPanelCreate(c *gin.Context) {
 	guildId := c.Keys["guildid"].(uint64)
 	userId := c.Keys["userid"].(uint64)
@@ -66,6 +105,18 @@ func MultiPanelCreate(c *gin.Context) {
 		return
 	}
 
+	if c.Query("dry_run") == "true" {
+		errs, err := data.collectErrors(guildId)
+		if err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "An error occurred while validating the panel"))
+			return
+		}
+
+		result := validation.NewDryRunResult(errs)
+		c.JSON(result.StatusCode(), result)
+		return
+	}
+
 	if err := validate.Struct(data); err != nil {
 		var validationErrors validator.ValidationErrors
 		if ok := errors.As(err, &validationErrors); !ok {
@@ -119,20 +170,7 @@ func MultiPanelCreate(c *gin.Context) {
 		return
 	}
 
-	// Create PanelWithCustomization by combining panels with their configurations
-	panelsWithCustom := make([]database.PanelWithCustomization, len(panels))
-	for i, panel := range panels {
-		panelsWithCustom[i] = database.PanelWithCustomization{
-			Panel:           panel,
-			CustomLabel:     data.Panels[i].CustomLabel,
-			Description:     data.Panels[i].Description,
-			CustomEmojiName: data.Panels[i].CustomEmojiName,
-			CustomEmojiId:   data.Panels[i].CustomEmojiId,
-		}
-	}
-
-	messageData := data.IntoMessageData(premiumTier > premium.None)
-	messageId, err := messageData.send(botContext, panelsWithCustom)
+	multiPanel, err := data.create(c, botContext, guildId, panels, premiumTier > premium.None)
 	if err != nil {
 		var unwrapped request.RestError
 		if errors.As(err, &unwrapped); unwrapped.StatusCode == 403 {
@@ -144,23 +182,45 @@ func MultiPanelCreate(c *gin.Context) {
 		return
 	}
 
-	dbEmbed, dbEmbedFields := data.Embed.IntoDatabaseStruct()
+	audit.Log(audit.LogEntry{
+		GuildId:      audit.Uint64Ptr(guildId),
+		UserId:       userId,
+		ActionType:   database.AuditActionMultiPanelCreate,
+		ResourceType: database.AuditResourceMultiPanel,
+		ResourceId:   audit.StringPtr(fmt.Sprintf("%d", multiPanel.Id)),
+		NewData:      data,
+	})
+	c.JSON(200, gin.H{
+		"success": true,
+		"data":    multiPanel,
+	})
+}
+
+// create sends the multi-panel message and saves the multi-panel along with its sub-panels, which must be those
+// returned by doValidations. If the message could not be sent, the request.RestError is returned as-is.
+func (d *multiPanelCreateData) create(ctx context.Context, botContext *botcontext.BotContext, guildId uint64, panels []database.Panel, isPremium bool) (database.MultiPanel, error) {
+	messageData := d.IntoMessageData(isPremium)
+	messageId, err := messageData.send(botContext, d.withCustomization(panels))
+	if err != nil {
+		return database.MultiPanel{}, err
+	}
+
+	dbEmbed, dbEmbedFields := d.Embed.IntoDatabaseStruct()
 	multiPanel := database.MultiPanel{
 		MessageId:             messageId,
-		ChannelId:             data.ChannelId,
+		ChannelId:             d.ChannelId,
 		GuildId:               guildId,
-		SelectMenu:            data.SelectMenu,
-		SelectMenuPlaceholder: data.SelectMenuPlaceholder,
+		SelectMenu:            d.SelectMenu,
+		SelectMenuPlaceholder: d.SelectMenuPlaceholder,
 		Embed: &database.CustomEmbedWithFields{
 			CustomEmbed: dbEmbed,
 			Fields:      dbEmbedFields,
 		},
 	}
 
-	multiPanel.Id, err = dbclient.Client.MultiPanels.Create(c, multiPanel)
+	multiPanel.Id, err = dbclient.Client.MultiPanels.Create(ctx, multiPanel)
 	if err != nil {
-		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to create multi-panel"))
-		return
+		return database.MultiPanel{}, err
 	}
 
 	group, _ := errgroup.WithContext(context.Background())
@@ -170,7 +230,7 @@ func MultiPanelCreate(c *gin.Context) {
 
 		// Find matching panel config by panel_id
 		var panelConfig *panelConfiguration
-		for _, cfg := range data.Panels {
+		for _, cfg := range d.Panels {
 			if cfg.PanelId == panel.PanelId {
 				panelConfig = &cfg
 				break
@@ -179,30 +239,22 @@ func MultiPanelCreate(c *gin.Context) {
 
 		group.Go(func() error {
 			if panelConfig != nil {
-				return dbclient.Client.MultiPanelTargets.Insert(c, multiPanel.Id, panel.PanelId, i, panelConfig.CustomLabel, panelConfig.Description, panelConfig.CustomEmojiName, panelConfig.CustomEmojiId)
+				return dbclient.Client.MultiPanelTargets.Insert(ctx, multiPanel.Id, panel.PanelId, i, panelConfig.CustomLabel, panelConfig.Description, panelConfig.CustomEmojiName, panelConfig.CustomEmojiId)
 			} else {
-				return dbclient.Client.MultiPanelTargets.Insert(c, multiPanel.Id, panel.PanelId, i, nil, nil, nil, nil)
+				return dbclient.Client.MultiPanelTargets.Insert(ctx, multiPanel.Id, panel.PanelId, i, nil, nil, nil, nil)
 			}
 		})
 	}
 
 	if err := group.Wait(); err != nil {
-		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to create multi-panel"))
-		return
+		return database.MultiPanel{}, err
 	}
 
-	audit.Log(audit.LogEntry{
-		GuildId:      audit.Uint64Ptr(guildId),
-		UserId:       userId,
-		ActionType:   database.AuditActionMultiPanelCreate,
-		ResourceType: database.AuditResourceMultiPanel,
-		ResourceId:   audit.StringPtr(fmt.Sprintf("%d", multiPanel.Id)),
-		NewData:      data,
-	})
-	c.JSON(200, gin.H{
-		"success": true,
-		"data":    multiPanel,
-	})
+	if err := dbclient.Dashboard.MultiPanelRows.Replace(ctx, multiPanel.Id, d.rows()); err != nil {
+		return database.MultiPanel{}, err
+	}
+
+	return multiPanel, nil
 }
 
 func (d *multiPanelCreateData) doValidations(guildId uint64) (panels []database.Panel, err error) {
@@ -210,6 +262,10 @@ func (d *multiPanelCreateData) doValidations(guildId uint64) (panels []database.
 		return nil, err
 	}
 
+	if err := d.validateLayout(); err != nil {
+		return nil, err
+	}
+
 	group, _ := errgroup.WithContext(context.Background())
 
 	group.Go(d.validateChannel(guildId))
@@ -239,7 +295,7 @@ func (d *multiPanelCreateData) validateChannel(guildId uint64) func() error {
 		}
 
 		if !valid {
-			return errors.New("channel does not exist")
+			return validation.NewInvalidInputError("channel does not exist")
 		}
 
 		return nil
@@ -247,13 +303,13 @@ func (d *multiPanelCreateData) validateChannel(guildId uint64) func() error {
 }
 
 func (d *multiPanelCreateData) validatePanels(guildId uint64) (panels []database.Panel, err error) {
-	if len(d.Panels) < 2 {
-		err = errors.New("a multi-panel must contain at least 2 sub-panels")
+	if len(d.Panels) < minMultiPanelPanels {
+		err = validation.NewInvalidInputErrorf("a multi-panel must contain at least %d sub-panels", minMultiPanelPanels)
 		return
 	}
 
-	if len(d.Panels) > 15 {
-		err = errors.New("multi-panels cannot contain more than 15 sub-panels")
+	if len(d.Panels) > maxMultiPanelPanels {
+		err = validation.NewInvalidInputErrorf("multi-panels cannot contain more than %d sub-panels", maxMultiPanelPanels)
 		return
 	}
 
@@ -262,6 +318,16 @@ func (d *multiPanelCreateData) validatePanels(guildId uint64) (panels []database
 		return nil, err
 	}
 
+	// The sub-panel's custom ID is used as the button or option ID, so each must be unique
+	seen := make(map[int]bool)
+	for _, panelConfig := range d.Panels {
+		if seen[panelConfig.PanelId] {
+			return nil, validation.NewInvalidInputErrorf("panel %d is included more than once", panelConfig.PanelId)
+		}
+
+		seen[panelConfig.PanelId] = true
+	}
+
 	for _, panelConfig := range d.Panels {
 		var valid bool
 		// find panel struct
@@ -273,9 +339,105 @@ func (d *multiPanelCreateData) validatePanels(guildId uint64) (panels []database
 		}
 
 		if !valid {
-			return nil, errors.New("invalid panel ID")
+			return nil, validation.NewInvalidInputErrorf("invalid panel ID: %d", panelConfig.PanelId)
 		}
 	}
 
 	return
 }
+
+// validateLayout checks that the sub-panels' buttons fit in the message. Rows can only be assigned in button mode,
+// and must be assigned to every sub-panel or none of them.
+func (d *multiPanelCreateData) validateLayout() error {
+	counts := make([]int, maxActionRows)
+	assigned := 0
+	for _, config := range d.Panels {
+		if config.Row == nil {
+			continue
+		}
+
+		row := *config.Row
+		if row < 0 || row >= maxActionRows {
+			return validation.NewInvalidInputErrorf("rows must be between 0 and %d", maxActionRows-1)
+		}
+
+		counts[row]++
+		if counts[row] > maxButtonsPerRow {
+			return validation.NewInvalidInputErrorf("row %d cannot contain more than %d buttons", row, maxButtonsPerRow)
+		}
+
+		assigned++
+	}
+
+	if assigned == 0 {
+		return nil
+	}
+
+	if d.SelectMenu {
+		return validation.NewInvalidInputError("rows can only be assigned when using buttons")
+	}
+
+	if assigned != len(d.Panels) {
+		return validation.NewInvalidInputError("either every sub-panel or none of them must be assigned a row")
+	}
+
+	return nil
+}
+
+// collectErrors runs every check performed by MultiPanelCreate before the message is sent, returning all input errors
+// at once, for dry runs. Any other error, such as failing to read the channel cache, is returned as err.
+func (d *multiPanelCreateData) collectErrors(guildId uint64) ([]*validation.InvalidInputError, error) {
+	errs, err := structValidationErrors(validate.Struct(d))
+	if err != nil {
+		return nil, err
+	}
+
+	if errs == nil {
+		errs = make([]*validation.InvalidInputError, 0)
+	}
+
+	if d.Embed == nil {
+		errs = append(errs, validation.NewFieldError("embed", "Your embed message does not contain any content"))
+	} else if err := validateEmbed(d.Embed); err != nil {
+		errs = append(errs, validation.NewFieldError("embed", err.Error()))
+	}
+
+	var inputError *validation.InvalidInputError
+	if err := d.validateLayout(); err != nil {
+		if !errors.As(err, &inputError) {
+			return nil, err
+		}
+
+		errs = append(errs, validation.NewFieldError("panels", inputError.Message))
+	}
+
+	if err := d.validateChannel(guildId)(); err != nil {
+		if !errors.As(err, &inputError) {
+			return nil, err
+		}
+
+		errs = append(errs, validation.NewFieldError("channel_id", inputError.Message))
+	}
+
+	panels, err := d.validatePanels(guildId)
+	if err != nil {
+		if !errors.As(err, &inputError) {
+			return nil, err
+		}
+
+		return append(errs, validation.NewFieldError("panels", inputError.Message)), nil
+	}
+
+	if d.SelectMenu {
+		for i, panel := range panels {
+			if getEffectiveLabelForValidation(panel.ButtonLabel, d.Panels[i].CustomLabel) == "" {
+				errs = append(errs, validation.NewFieldError(
+					fmt.Sprintf("panels[%d].custom_label", i),
+					fmt.Sprintf("Panel '%s' must have a label when using dropdown mode. Please add a custom label or ensure the panel has a button label.", panel.Title),
+				))
+			}
+		}
+	}
+
+	return errs, nil
+}
@@ -0,0 +1,190 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/TicketsBot-cloud/common/premium"
+	"github.com/TicketsBot-cloud/dashboard/app"
+	"github.com/TicketsBot-cloud/dashboard/app/http/audit"
+	"github.com/TicketsBot-cloud/dashboard/botcontext"
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/dashboard/rpc"
+	"github.com/TicketsBot-cloud/dashboard/utils"
+	"github.com/TicketsBot-cloud/database"
+	"github.com/TicketsBot-cloud/gdl/rest/request"
+	"github.com/gin-gonic/gin"
+)
+
+// multiPanelLayoutEntry places a sub-panel in the multi-panel. The order of the entries is the order of the buttons or
+// select menu options.
+type multiPanelLayoutEntry struct {
+	PanelId int  `json:"panel_id"`
+	Row     *int `json:"row"`
+}
+
+type multiPanelLayoutBody struct {
+	Panels []multiPanelLayoutEntry `json:"panels" binding:"required"`
+}
+
+// UpdateMultiPanelLayout reorders a multi-panel's sub-panels and assigns their buttons to rows, without changing
+// anything else about the multi-panel, and then edits its message
+func UpdateMultiPanelLayout(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+	userId := c.Keys["userid"].(uint64)
+
+	multiPanelId, err := strconv.Atoi(c.Param("panelid"))
+	if err != nil {
+		c.JSON(400, utils.ErrorStr("Invalid panel ID provided: %s", c.Param("panelid")))
+		return
+	}
+
+	var body multiPanelLayoutBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(400, utils.ErrorStr("Invalid request data. Please check your input and try again."))
+		return
+	}
+
+	multiPanel, ok, err := dbclient.Client.MultiPanels.Get(c, multiPanelId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch multi-panel from database"))
+		return
+	}
+
+	if !ok || multiPanel.GuildId != guildId {
+		c.JSON(404, utils.ErrorStr("No panel with the provided ID found"))
+		return
+	}
+
+	targets, err := dbclient.Client.MultiPanelTargets.GetPanels(c, multiPanelId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch multi-panel from database"))
+		return
+	}
+
+	oldRows, err := dbclient.Dashboard.MultiPanelRows.Get(c, multiPanelId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch multi-panel from database"))
+		return
+	}
+
+	// The layout can only rearrange the existing sub-panels: adding or removing them is done by updating the multi-panel
+	if len(body.Panels) != len(targets) {
+		c.JSON(400, utils.ErrorStr("The layout must contain each of the multi-panel's sub-panels exactly once"))
+		return
+	}
+
+	targetsById := make(map[int]database.PanelWithCustomization)
+	for _, target := range targets {
+		targetsById[target.PanelId] = target
+	}
+
+	data := multiPanelCreateData{
+		SelectMenu: multiPanel.SelectMenu,
+		Panels:     make([]panelConfiguration, len(body.Panels)),
+	}
+
+	ordered := make([]database.PanelWithCustomization, len(body.Panels))
+	for i, entry := range body.Panels {
+		target, ok := targetsById[entry.PanelId]
+		if !ok {
+			c.JSON(400, utils.ErrorStr("The layout must contain each of the multi-panel's sub-panels exactly once"))
+			return
+		}
+
+		delete(targetsById, entry.PanelId)
+
+		ordered[i] = target
+		data.Panels[i] = panelConfiguration{
+			PanelId:         target.PanelId,
+			CustomEmojiName: target.CustomEmojiName,
+			CustomEmojiId:   target.CustomEmojiId,
+			CustomLabel:     target.CustomLabel,
+			Description:     target.Description,
+			Row:             entry.Row,
+		}
+	}
+
+	if err := data.validateLayout(); err != nil {
+		c.JSON(400, utils.ErrorStr("%s", err.Error()))
+		return
+	}
+
+	layout := make([]dbclient.MultiPanelTarget, len(data.Panels))
+	for i, config := range data.Panels {
+		layout[i] = dbclient.MultiPanelTarget{
+			PanelId:         config.PanelId,
+			CustomLabel:     config.CustomLabel,
+			Description:     config.Description,
+			CustomEmojiName: config.CustomEmojiName,
+			CustomEmojiId:   config.CustomEmojiId,
+		}
+	}
+
+	rows := data.rows()
+	if err := dbclient.Dashboard.MultiPanelRows.ReplaceLayout(c, multiPanelId, layout, rows); err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to update multi-panel"))
+		return
+	}
+
+	botContext, err := botcontext.ContextForGuild(guildId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Unable to connect to Discord. Please try again later."))
+		return
+	}
+
+	premiumTier, err := rpc.PremiumClient.GetTierByGuildId(c, guildId, true, botContext.Token, botContext.RateLimiter)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to update multi-panel"))
+		return
+	}
+
+	messageData := multiPanelIntoMessageData(multiPanel, rows, premiumTier > premium.None)
+	if err := messageData.edit(botContext, multiPanel.MessageId, ordered); err != nil {
+		var unwrapped request.RestError
+		if isUnknownMessage(err) {
+			messageId, err := messageData.send(botContext, ordered)
+			if err != nil {
+				if errors.As(err, &unwrapped) && unwrapped.StatusCode == 403 {
+					c.JSON(http.StatusBadRequest, utils.ErrorStr("I do not have permission to send messages in the provided channel"))
+				} else {
+					_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to update multi-panel"))
+				}
+
+				return
+			}
+
+			if err := dbclient.Client.MultiPanels.UpdateMessageId(c, multiPanelId, messageId); err != nil {
+				_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to update multi-panel"))
+				return
+			}
+		} else if errors.As(err, &unwrapped) && unwrapped.StatusCode == 403 {
+			c.JSON(http.StatusBadRequest, utils.ErrorStr("I do not have permission to edit messages in the provided channel"))
+			return
+		} else {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to update multi-panel"))
+			return
+		}
+	}
+
+	oldLayout := make([]multiPanelLayoutEntry, len(targets))
+	for i, target := range targets {
+		oldLayout[i] = multiPanelLayoutEntry{PanelId: target.PanelId}
+		if row, ok := oldRows[target.PanelId]; ok {
+			oldLayout[i].Row = &row
+		}
+	}
+
+	audit.Log(audit.LogEntry{
+		GuildId:      audit.Uint64Ptr(guildId),
+		UserId:       userId,
+		ActionType:   database.AuditActionMultiPanelUpdate,
+		ResourceType: database.AuditResourceMultiPanel,
+		ResourceId:   audit.StringPtr(strconv.Itoa(multiPanelId)),
+		OldData:      oldLayout,
+		NewData:      body.Panels,
+	})
+
+	c.JSON(200, utils.SuccessResponse)
+}
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/TicketsBot-cloud/database"
+	"github.com/TicketsBot-cloud/gdl/objects/interaction/component"
+	"github.com/stretchr/testify/assert"
+)
+
+func testLayout(rows ...*int) []panelConfiguration {
+	panels := make([]panelConfiguration, len(rows))
+	for i, row := range rows {
+		panels[i] = panelConfiguration{PanelId: i + 1, Row: row}
+	}
+
+	return panels
+}
+
+func rowPtr(row int) *int {
+	return &row
+}
+
+func TestValidateLayout(t *testing.T) {
+	sixInRow := make([]*int, maxButtonsPerRow+1)
+	for i := range sixInRow {
+		sixInRow[i] = rowPtr(2)
+	}
+
+	tests := []struct {
+		name       string
+		selectMenu bool
+		panels     []panelConfiguration
+		err        string
+	}{
+		{
+			name:   "no rows",
+			panels: testLayout(nil, nil, nil),
+		},
+		{
+			name:   "every panel assigned",
+			panels: testLayout(rowPtr(0), rowPtr(4), rowPtr(0)),
+		},
+		{
+			name:   "row out of range",
+			panels: testLayout(rowPtr(0), rowPtr(maxActionRows)),
+			err:    "rows must be between 0 and 4",
+		},
+		{
+			name:   "negative row",
+			panels: testLayout(rowPtr(-1), rowPtr(0)),
+			err:    "rows must be between 0 and 4",
+		},
+		{
+			name:   "full row",
+			panels: testLayout(sixInRow...),
+			err:    "row 2 cannot contain more than 5 buttons",
+		},
+		{
+			name:   "partial assignment",
+			panels: testLayout(rowPtr(0), nil, rowPtr(1)),
+			err:    "either every sub-panel or none of them must be assigned a row",
+		},
+		{
+			name:       "select menu",
+			selectMenu: true,
+			panels:     testLayout(rowPtr(0), rowPtr(1)),
+			err:        "rows can only be assigned when using buttons",
+		},
+		{
+			name:       "select menu without rows",
+			selectMenu: true,
+			panels:     testLayout(nil, nil),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			data := multiPanelCreateData{SelectMenu: test.selectMenu, Panels: test.panels}
+
+			err := data.validateLayout()
+			if test.err == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.err)
+			}
+		})
+	}
+}
+
+func TestGroupButtons(t *testing.T) {
+	panels := make([]database.PanelWithCustomization, 4)
+	buttons := make([]component.Component, 4)
+	for i := range panels {
+		panels[i].PanelId = i + 1
+		buttons[i] = component.BuildButton(component.Button{CustomId: string(rune('a' + i))})
+	}
+
+	// Rows are rendered in order, without gaps, and the panels keep their order within each row
+	data := multiPanelMessageData{Rows: map[int]int{1: 3, 2: 1, 3: 3, 4: 1}}
+	expected := []component.Component{
+		component.BuildActionRow(buttons[1], buttons[3]),
+		component.BuildActionRow(buttons[0], buttons[2]),
+	}
+
+	assert.Equal(t, expected, data.groupButtons(panels, buttons))
+}
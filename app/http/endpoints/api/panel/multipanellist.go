@@ -17,6 +17,7 @@ func MultiPanelList(ctx *gin.Context) {
 		Description     *string `json:"description"`
 		CustomEmojiName *string `json:"custom_emoji_name"`
 		CustomEmojiId   *uint64 `json:"custom_emoji_id,string"`
+		Row             *int    `json:"row,omitempty"`
 	}
 
 	type multiPanelResponse struct {
@@ -66,6 +67,11 @@ func MultiPanelList(ctx *gin.Context) {
 				return err
 			}
 
+			rows, err := dbclient.Dashboard.MultiPanelRows.Get(ctx, multiPanel.Id)
+			if err != nil {
+				return err
+			}
+
 			configs := make([]panelConfiguration, len(panels))
 			for i, panel := range panels {
 				configs[i] = panelConfiguration{
@@ -75,6 +81,10 @@ func MultiPanelList(ctx *gin.Context) {
 					CustomEmojiName: panel.CustomEmojiName,
 					CustomEmojiId:   panel.CustomEmojiId,
 				}
+
+				if row, ok := rows[panel.PanelId]; ok {
+					configs[i].Row = &row
+				}
 			}
 
 			data[i].Panels = configs
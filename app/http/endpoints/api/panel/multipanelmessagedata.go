@@ -23,10 +23,13 @@ type multiPanelMessageData struct {
 	SelectMenu            bool
 	SelectMenuPlaceholder *string
 
+	// Rows holds the action row of each sub-panel's button, keyed by panel ID. If empty, buttons are placed 5 to a row.
+	Rows map[int]int
+
 	Embed *embed.Embed
 }
 
-func multiPanelIntoMessageData(panel database.MultiPanel, isPremium bool) multiPanelMessageData {
+func multiPanelIntoMessageData(panel database.MultiPanel, rows map[int]int, isPremium bool) multiPanelMessageData {
 	return multiPanelMessageData{
 		IsPremium: isPremium,
 
@@ -34,6 +37,7 @@ func multiPanelIntoMessageData(panel database.MultiPanel, isPremium bool) multiP
 
 		SelectMenu:            panel.SelectMenu,
 		SelectMenuPlaceholder: panel.SelectMenuPlaceholder,
+		Rows:                  rows,
 		Embed:                 types.NewCustomEmbed(panel.Embed.CustomEmbed, panel.Embed.Fields).IntoDiscordEmbed(),
 	}
 }
@@ -68,7 +72,8 @@ func getEffectiveEmojiId(panel database.Panel, customEmojiName *string, customEm
 	return panel.EmojiId
 }
 
-func (d *multiPanelMessageData) send(ctx *botcontext.BotContext, panels []database.PanelWithCustomization) (uint64, error) {
+// buildMessage builds the multi-panel message exactly as it is sent to Discord
+func (d *multiPanelMessageData) buildMessage(panels []database.PanelWithCustomization) rest.CreateMessageData {
 	if !d.IsPremium {
 		d.Embed.SetFooter(fmt.Sprintf("Powered by %s", config.Conf.Bot.PoweredBy), config.Conf.Bot.IconUrl)
 	}
@@ -125,120 +130,77 @@ func (d *multiPanelMessageData) send(ctx *botcontext.BotContext, panels []databa
 			})
 		}
 
-		var rows []component.Component
-		for i := 0; i <= int(math.Ceil(float64(len(buttons)/5))); i++ {
-			lb := i * 5
-			ub := lb + 5
-
-			if ub >= len(buttons) {
-				ub = len(buttons)
-			}
-
-			if lb >= ub {
-				break
-			}
-
-			row := component.BuildActionRow(buttons[lb:ub]...)
-			rows = append(rows, row)
+		if len(d.Rows) > 0 {
+			components = d.groupButtons(panels, buttons)
+		} else {
+			components = chunkButtons(buttons)
 		}
-
-		components = rows
 	}
 
-	data := rest.CreateMessageData{
+	return rest.CreateMessageData{
 		Embeds:     []*embed.Embed{d.Embed},
 		Components: components,
 	}
-
-	// TODO: Use proper context
-	msg, err := rest.CreateMessage(context.Background(), ctx.Token, ctx.RateLimiter, d.ChannelId, data)
-	if err != nil {
-		return 0, err
-	}
-
-	return msg.Id, nil
 }
 
-func (d *multiPanelMessageData) edit(ctx *botcontext.BotContext, messageId uint64, panels []database.PanelWithCustomization) error {
-	if !d.IsPremium {
-		d.Embed.SetFooter(fmt.Sprintf("Powered by %s", config.Conf.Bot.PoweredBy), config.Conf.Bot.IconUrl)
+// groupButtons places each button in the action row assigned to its panel, keeping the order of the panels within
+// each row. Empty rows are skipped.
+func (d *multiPanelMessageData) groupButtons(panels []database.PanelWithCustomization, buttons []component.Component) []component.Component {
+	grouped := make([][]component.Component, maxActionRows)
+	for i, pwc := range panels {
+		row := d.Rows[pwc.PanelId]
+		grouped[row] = append(grouped[row], buttons[i])
 	}
 
-	var components []component.Component
-	if d.SelectMenu {
-		options := make([]component.SelectOption, len(panels))
-		for i, pwc := range panels {
-			effectiveEmojiName := getEffectiveEmoji(pwc.Panel, pwc.CustomEmojiName, pwc.CustomEmojiId)
-			effectiveEmojiId := getEffectiveEmojiId(pwc.Panel, pwc.CustomEmojiName, pwc.CustomEmojiId)
-			emoji := types.NewEmoji(effectiveEmojiName, effectiveEmojiId).IntoGdl()
-
-			options[i] = component.SelectOption{
-				Label:       getEffectiveLabel(pwc.Panel, pwc.CustomLabel),
-				Value:       pwc.CustomId,
-				Description: pwc.Description,
-				Emoji:       emoji,
-			}
+	var rows []component.Component
+	for _, row := range grouped {
+		if len(row) > 0 {
+			rows = append(rows, component.BuildActionRow(row...))
 		}
+	}
 
-		var placeholder string
-		if d.SelectMenuPlaceholder == nil {
-			placeholder = "Select a topic..."
-		} else {
-			placeholder = *d.SelectMenuPlaceholder
-		}
+	return rows
+}
 
-		components = []component.Component{
-			component.BuildActionRow(
-				component.BuildSelectMenu(
-					component.SelectMenu{
-						CustomId:    "multipanel",
-						Options:     options,
-						Placeholder: placeholder,
-						MinValues:   utils.IntPtr(1),
-						MaxValues:   utils.IntPtr(1),
-						Disabled:    false,
-					}),
-			),
-		}
-	} else {
-		buttons := make([]component.Component, len(panels))
-		for i, pwc := range panels {
-			effectiveEmojiName := getEffectiveEmoji(pwc.Panel, pwc.CustomEmojiName, pwc.CustomEmojiId)
-			effectiveEmojiId := getEffectiveEmojiId(pwc.Panel, pwc.CustomEmojiName, pwc.CustomEmojiId)
-			emoji := types.NewEmoji(effectiveEmojiName, effectiveEmojiId).IntoGdl()
+// chunkButtons places the buttons 5 to a row
+func chunkButtons(buttons []component.Component) []component.Component {
+	var rows []component.Component
+	for i := 0; i <= int(math.Ceil(float64(len(buttons)/5))); i++ {
+		lb := i * 5
+		ub := lb + 5
 
-			buttons[i] = component.BuildButton(component.Button{
-				Label:    getEffectiveLabel(pwc.Panel, pwc.CustomLabel),
-				CustomId: pwc.CustomId,
-				Style:    component.ButtonStyle(pwc.ButtonStyle),
-				Emoji:    emoji,
-				Disabled: pwc.Disabled,
-			})
+		if ub >= len(buttons) {
+			ub = len(buttons)
 		}
 
-		var rows []component.Component
-		for i := 0; i <= int(math.Ceil(float64(len(buttons)/5))); i++ {
-			lb := i * 5
-			ub := lb + 5
+		if lb >= ub {
+			break
+		}
 
-			if ub >= len(buttons) {
-				ub = len(buttons)
-			}
+		row := component.BuildActionRow(buttons[lb:ub]...)
+		rows = append(rows, row)
+	}
 
-			if lb >= ub {
-				break
-			}
+	return rows
+}
 
-			row := component.BuildActionRow(buttons[lb:ub]...)
-			rows = append(rows, row)
-		}
+func (d *multiPanelMessageData) send(ctx *botcontext.BotContext, panels []database.PanelWithCustomization) (uint64, error) {
+	data := d.buildMessage(panels)
 
-		components = rows
+	// TODO: Use proper context
+	msg, err := rest.CreateMessage(context.Background(), ctx.Token, ctx.RateLimiter, d.ChannelId, data)
+	if err != nil {
+		return 0, err
 	}
 
+	return msg.Id, nil
+}
+
+func (d *multiPanelMessageData) edit(ctx *botcontext.BotContext, messageId uint64, panels []database.PanelWithCustomization) error {
+	message := d.buildMessage(panels)
 	data := rest.EditMessageData{
-		Embeds:     []*embed.Embed{d.Embed},
-		Components: components,
+		Embeds:     message.Embeds,
+		Components: message.Components,
 	}
 
 	_, err := rest.EditMessage(context.Background(), ctx.Token, ctx.RateLimiter, d.ChannelId, messageId, data)
@@ -54,16 +54,6 @@ func MultiPanelResend(ctx *gin.Context) {
 		return
 	}
 
-	// delete old message
-	// TODO: Use proper context
-	if err := rest.DeleteMessage(context.Background(), botContext.Token, botContext.RateLimiter, multiPanel.ChannelId, multiPanel.MessageId); err != nil {
-		var unwrapped request.RestError
-		if errors.As(err, &unwrapped) && !unwrapped.IsClientError() {
-			ctx.JSON(500, utils.ErrorStr("Failed to send message. Please try again."))
-			return
-		}
-	}
-
 	// get premium status
 	premiumTier, err := rpc.PremiumClient.GetTierByGuildId(ctx, guildId, true, botContext.Token, botContext.RateLimiter)
 	if err != nil {
@@ -71,16 +61,7 @@ func MultiPanelResend(ctx *gin.Context) {
 		return
 	}
 
-	panels, err := dbclient.Client.MultiPanelTargets.GetPanels(ctx, multiPanel.Id)
-	if err != nil {
-		ctx.JSON(500, utils.ErrorStr("Failed to send message. Please try again."))
-		return
-	}
-
-	// send new message
-	messageData := multiPanelIntoMessageData(multiPanel, premiumTier > premium.None)
-	messageId, err := messageData.send(botContext, panels)
-	if err != nil {
+	if err := resendMultiPanel(ctx, botContext, multiPanel, premiumTier > premium.None); err != nil {
 		var unwrapped request.RestError
 		if errors.As(err, &unwrapped) && unwrapped.StatusCode == 403 {
 			ctx.JSON(500, utils.ErrorStr("I do not have permission to send messages in the provided channel"))
@@ -91,11 +72,6 @@ func MultiPanelResend(ctx *gin.Context) {
 		return
 	}
 
-	if err = dbclient.Client.MultiPanels.UpdateMessageId(ctx, multiPanel.Id, messageId); err != nil {
-		ctx.JSON(500, utils.ErrorStr("Failed to send message. Please try again."))
-		return
-	}
-
 	audit.Log(audit.LogEntry{
 		GuildId:      audit.Uint64Ptr(guildId),
 		UserId:       userId,
@@ -107,3 +83,34 @@ func MultiPanelResend(ctx *gin.Context) {
 		"success": true,
 	})
 }
+
+// resendMultiPanel deletes the multi-panel's message, if it still exists, and sends a new one in its place. If the
+// new message could not be sent, the request.RestError is returned as-is.
+func resendMultiPanel(ctx context.Context, botContext *botcontext.BotContext, multiPanel database.MultiPanel, isPremium bool) error {
+	// TODO: Use proper context
+	if err := rest.DeleteMessage(context.Background(), botContext.Token, botContext.RateLimiter, multiPanel.ChannelId, multiPanel.MessageId); err != nil {
+		var unwrapped request.RestError
+		if errors.As(err, &unwrapped) && !unwrapped.IsClientError() {
+			return err
+		}
+	}
+
+	panels, err := dbclient.Client.MultiPanelTargets.GetPanels(ctx, multiPanel.Id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := dbclient.Dashboard.MultiPanelRows.Get(ctx, multiPanel.Id)
+	if err != nil {
+		return err
+	}
+
+	// send new message
+	messageData := multiPanelIntoMessageData(multiPanel, rows, isPremium)
+	messageId, err := messageData.send(botContext, panels)
+	if err != nil {
+		return err
+	}
+
+	return dbclient.Client.MultiPanels.UpdateMessageId(ctx, multiPanel.Id, messageId)
+}
@@ -257,6 +257,11 @@ func MultiPanelUpdate(c *gin.Context) {
 		return
 	}
 
+	if err := dbclient.Dashboard.MultiPanelRows.Replace(c, multiPanel.Id, data.rows()); err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to update multi-panel"))
+		return
+	}
+
 	audit.Log(audit.LogEntry{
 		GuildId:      audit.Uint64Ptr(guildId),
 		UserId:       userId,
@@ -16,6 +16,7 @@ import (
 	"github.com/TicketsBot-cloud/dashboard/utils"
 	"github.com/TicketsBot-cloud/dashboard/utils/types"
 	"github.com/TicketsBot-cloud/database"
+	"github.com/TicketsBot-cloud/gdl/objects/guild"
 	"github.com/TicketsBot-cloud/gdl/objects/guild/emoji"
 	"github.com/TicketsBot-cloud/gdl/objects/interaction/component"
 	"github.com/TicketsBot-cloud/gdl/rest/request"
@@ -97,6 +98,7 @@ func CreatePanel(c *gin.Context) {
 	}
 
 	data.MessageId = 0
+	dryRun := c.Query("dry_run") == "true"
 
 	// Check panel quota
 	premiumTier, err := rpc.PremiumClient.GetTierByGuildId(c, guildId, false, botContext.Token, botContext.RateLimiter)
@@ -105,6 +107,7 @@ func CreatePanel(c *gin.Context) {
 		return
 	}
 
+	var quotaError *validation.InvalidInputError
 	if premiumTier == premium.None {
 		panels, err := dbclient.Client.Panel.GetByGuild(c, guildId)
 		if err != nil {
@@ -113,8 +116,12 @@ func CreatePanel(c *gin.Context) {
 		}
 
 		if len(panels) >= freePanelLimit {
-			c.JSON(402, utils.ErrorStr("Panel quota exceeded: You have %d/%d panels. Purchase premium to unlock more panels.", len(panels), freePanelLimit))
-			return
+			if !dryRun {
+				c.JSON(402, utils.ErrorStr("Panel quota exceeded: You have %d/%d panels. Purchase premium to unlock more panels.", len(panels), freePanelLimit))
+				return
+			}
+
+			quotaError = validation.NewInvalidInputErrorf("Panel quota exceeded: You have %d/%d panels. Purchase premium to unlock more panels.", len(panels), freePanelLimit)
 		}
 	}
 
@@ -146,57 +153,108 @@ func CreatePanel(c *gin.Context) {
 		Roles:      roles,
 	}
 
-	if err := ValidatePanelBody(validationContext); err != nil {
-		var validationError *validation.InvalidInputError
-		if errors.As(err, &validationError) {
-			c.JSON(400, utils.ErrorStr(validationError.Error()))
-		} else {
+	if dryRun {
+		errs, err := collectPanelErrors(validationContext)
+		if err != nil {
 			_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Panel validation failed unexpectedly"))
+			return
 		}
 
-		return
-	}
-
-	if !data.UseThreads {
-		data.TicketNotificationChannel = nil
-	}
-
-	// Do tag validation
-	if err := validate.Struct(data); err != nil {
-		var validationErrors validator.ValidationErrors
-		if ok := errors.As(err, &validationErrors); !ok {
-			c.JSON(500, utils.ErrorStr("An error occurred while validating the panel structure"))
-			return
+		if quotaError != nil {
+			errs = append([]*validation.InvalidInputError{quotaError}, errs...)
 		}
 
-		formatted := "Your input contained the following errors:\n" + utils.FormatValidationErrors(validationErrors)
-		c.JSON(400, utils.ErrorStr(formatted))
+		result := validation.NewDryRunResult(errs)
+		c.JSON(result.StatusCode(), result)
 		return
 	}
 
-	customId, err := utils.RandString(30)
+	data, err = validatePanelForCreate(validationContext)
 	if err != nil {
-		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to generate unique panel ID"))
+		var validationError *validation.InvalidInputError
+		if errors.As(err, &validationError) {
+			c.JSON(400, utils.ErrorStr("%s", validationError.Error()))
+		} else {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Panel validation failed unexpectedly"))
+		}
+
 		return
 	}
 
-	messageData := data.IntoPanelMessageData(customId, premiumTier > premium.None)
-	msgId, err := messageData.send(botContext)
+	panel, err := createPanel(c, botContext, guildId, data, premiumTier > premium.None, roles)
 	if err != nil {
+		var validationError *validation.InvalidInputError
 		var unwrapped request.RestError
-		if errors.As(err, &unwrapped) {
+		if errors.As(err, &validationError) {
+			c.JSON(400, utils.ErrorStr("%s", validationError.Error()))
+		} else if errors.As(err, &unwrapped) {
 			if unwrapped.StatusCode == http.StatusForbidden {
 				c.JSON(400, utils.ErrorStr("Bot does not have permission to send messages in channel %d", data.ChannelId))
 			} else {
 				c.JSON(400, utils.ErrorStr("Failed to send panel message to channel %d: %s", data.ChannelId, unwrapped.ApiError.Message))
 			}
 		} else {
-			_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to send panel message to Discord"))
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to save panel to database"))
 		}
 
 		return
 	}
 
+	audit.Log(audit.LogEntry{
+		GuildId:      audit.Uint64Ptr(guildId),
+		UserId:       userId,
+		ActionType:   database.AuditActionPanelCreate,
+		ResourceType: database.AuditResourcePanel,
+		ResourceId:   audit.StringPtr(strconv.Itoa(panel.PanelId)),
+		NewData:      data,
+	})
+
+	c.JSON(200, gin.H{
+		"success":  true,
+		"panel_id": panel.PanelId,
+	})
+}
+
+// createPanel sends the panel message and saves the panel, returning it with its new ID. The body must already have
+// been validated with ValidatePanelBody. If the message could not be sent, the request.RestError is returned as-is.
+func createPanel(ctx context.Context, botContext *botcontext.BotContext, guildId uint64, data panelBody, isPremium bool, roles []guild.Role) (database.Panel, error) {
+	createOptions := panelCreateOptions{
+		TeamIds:            data.Teams,             // Already validated
+		AccessControlRules: data.AccessControlList, // Already validated
+	}
+
+	// insert role mention data
+	// string is role ID or "user" to mention the ticket opener or "here" to mention @here
+	validRoles := utils.ToSet(utils.Map(roles, utils.RoleToId))
+
+	for _, mention := range data.Mentions {
+		if mention == "user" {
+			createOptions.ShouldMentionUser = true
+		} else if mention == "here" {
+			createOptions.ShouldMentionHere = true
+		} else {
+			roleId, err := strconv.ParseUint(mention, 10, 64)
+			if err != nil {
+				return database.Panel{}, validation.NewInvalidInputErrorf("Invalid role ID in mentions: %s", mention)
+			}
+
+			if validRoles.Contains(roleId) {
+				createOptions.RoleMentions = append(createOptions.RoleMentions, roleId)
+			}
+		}
+	}
+
+	customId, err := utils.RandString(30)
+	if err != nil {
+		return database.Panel{}, err
+	}
+
+	messageData := data.IntoPanelMessageData(customId, isPremium)
+	msgId, err := messageData.send(botContext)
+	if err != nil {
+		return database.Panel{}, err
+	}
+
 	var emojiId *uint64
 	var emojiName *string
 	{
@@ -216,10 +274,9 @@ func CreatePanel(c *gin.Context) {
 		embed, fields := data.WelcomeMessage.IntoDatabaseStruct()
 		embed.GuildId = guildId
 
-		id, err := dbclient.Client.Embeds.CreateWithFields(c, embed, fields)
+		id, err := dbclient.Client.Embeds.CreateWithFields(ctx, embed, fields)
 		if err != nil {
-			_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to save welcome message embed to database"))
-			return
+			return database.Panel{}, err
 		}
 
 		welcomeMessageEmbed = &id
@@ -265,59 +322,16 @@ func CreatePanel(c *gin.Context) {
 		HideClaimButton:           data.HideClaimButton,
 	}
 
-
-	createOptions := panelCreateOptions{
-		TeamIds:            data.Teams,             // Already validated
-		AccessControlRules: data.AccessControlList, // Already validated
-	}
-
-	// insert role mention data
-	// string is role ID or "user" to mention the ticket opener or "here" to mention @here
-	validRoles := utils.ToSet(utils.Map(roles, utils.RoleToId))
-
-	var roleMentions []uint64
-	for _, mention := range data.Mentions {
-		if mention == "user" {
-			createOptions.ShouldMentionUser = true
-		} else if mention == "here" {
-			createOptions.ShouldMentionHere = true
-		} else {
-			roleId, err := strconv.ParseUint(mention, 10, 64)
-			if err != nil {
-				c.JSON(400, utils.ErrorStr("Invalid role ID in mentions: %s", mention))
-				return
-			}
-
-			if validRoles.Contains(roleId) {
-				createOptions.RoleMentions = append(roleMentions, roleId)
-			}
-		}
-	}
-
-	panelId, err := storePanel(c, panel, createOptions)
+	panel.PanelId, err = storePanel(ctx, panel, createOptions)
 	if err != nil {
-		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to save panel to database"))
-		return
+		return database.Panel{}, err
 	}
 
-	if err := dbclient.Client.PanelTicketPermissions.Set(c, panelId, data.TicketPermissions); err != nil {
-		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to save panel ticket permissions"))
-		return
+	if err := dbclient.Client.PanelTicketPermissions.Set(ctx, panel.PanelId, data.TicketPermissions); err != nil {
+		return database.Panel{}, err
 	}
 
-	audit.Log(audit.LogEntry{
-		GuildId:      audit.Uint64Ptr(guildId),
-		UserId:       userId,
-		ActionType:   database.AuditActionPanelCreate,
-		ResourceType: database.AuditResourcePanel,
-		ResourceId:   audit.StringPtr(strconv.Itoa(panelId)),
-		NewData:      data,
-	})
-
-	c.JSON(200, gin.H{
-		"success":  true,
-		"panel_id": panelId,
-	})
+	return panel, nil
 }
 
 // DB functions
@@ -101,7 +101,13 @@ func DeletePanel(c *gin.Context) {
 			return
 		}
 
-		messageData := multiPanelIntoMessageData(multiPanel, premiumTier > premium.None)
+		rows, err := database.Dashboard.MultiPanelRows.Get(c, multiPanel.Id)
+		if err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to delete panel"))
+			return
+		}
+
+		messageData := multiPanelIntoMessageData(multiPanel, rows, premiumTier > premium.None)
 		messageId, err := messageData.send(botContext, panels)
 		if err != nil {
 			var unwrapped request.RestError
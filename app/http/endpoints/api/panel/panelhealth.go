@@ -0,0 +1,441 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/TicketsBot-cloud/common/premium"
+	"github.com/TicketsBot-cloud/dashboard/app"
+	"github.com/TicketsBot-cloud/dashboard/app/http/audit"
+	"github.com/TicketsBot-cloud/dashboard/botcontext"
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/dashboard/rpc"
+	"github.com/TicketsBot-cloud/dashboard/utils"
+	"github.com/TicketsBot-cloud/database"
+	"github.com/TicketsBot-cloud/gdl/objects/channel/embed"
+	"github.com/TicketsBot-cloud/gdl/objects/channel/message"
+	"github.com/TicketsBot-cloud/gdl/objects/interaction/component"
+	"github.com/TicketsBot-cloud/gdl/rest"
+	"github.com/TicketsBot-cloud/gdl/rest/request"
+	"github.com/gin-gonic/gin"
+)
+
+// Panel health statuses, from least to most severe
+const (
+	PanelHealthOk = "ok"
+	// PanelHealthEdited means the message exists, but its embed no longer matches the stored configuration
+	PanelHealthEdited = "edited"
+	// PanelHealthBrokenComponents means the message's buttons or select menu no longer match the stored
+	// configuration, so they may not open tickets
+	PanelHealthBrokenComponents = "broken_components"
+	// PanelHealthMissing means the message was deleted, or the bot can no longer read it
+	PanelHealthMissing = "missing"
+	// PanelHealthOrphaned means the channel the panel was sent in was deleted. It cannot be repaired by resending, and
+	// must be updated with a new channel instead.
+	PanelHealthOrphaned = "orphaned"
+)
+
+// healthRefreshCooldown is how long after a check the panels can be checked again on request, as each check fetches
+// every panel message from Discord
+const healthRefreshCooldown = time.Minute
+
+type repairResult struct {
+	Kind    string `json:"kind"`
+	PanelId int    `json:"panel_id"`
+	Error   string `json:"error,omitempty"`
+}
+
+// GetPanelHealth returns the result of the most recent check of the guild's panels. If the guild has not been
+// checked yet, or refresh=true is passed, the panels are checked now. Refreshes are refused until
+// healthRefreshCooldown has passed since the last check.
+func GetPanelHealth(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+
+	report, ok, err := dbclient.Dashboard.PanelHealth.Get(c, guildId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to load panel health"))
+		return
+	}
+
+	refresh := c.Query("refresh") == "true"
+	if ok && refresh {
+		if wait := healthRefreshCooldown - time.Since(report.CheckedAt); wait > 0 {
+			c.JSON(http.StatusTooManyRequests, utils.ErrorStr("Panels were checked less than a minute ago. Please try again in %d seconds.", int(wait.Seconds())+1))
+			return
+		}
+	}
+
+	if !ok || refresh {
+		report, err = CheckHealth(c, guildId)
+		if err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to check panel messages"))
+			return
+		}
+	}
+
+	c.JSON(200, report)
+}
+
+// RepairPanels checks the guild's panels, and resends every panel which is missing, edited or has broken components.
+// Orphaned panels are skipped, as they have no channel to be resent to.
+func RepairPanels(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+	userId := c.Keys["userid"].(uint64)
+
+	botContext, err := botcontext.ContextForGuild(guildId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Unable to connect to Discord. Please try again later."))
+		return
+	}
+
+	report, premiumTier, err := checkHealth(c, botContext, guildId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to check panel messages"))
+		return
+	}
+
+	repaired := make([]repairResult, 0)
+	failed := make([]repairResult, 0)
+	for i, health := range report.Panels {
+		if health.Status == PanelHealthOk || health.Status == PanelHealthOrphaned {
+			continue
+		}
+
+		result := repairResult{Kind: health.Kind, PanelId: health.PanelId}
+		if err := repairPanel(c, botContext, guildId, health, premiumTier > premium.None); err != nil {
+			var unwrapped request.RestError
+			if errors.As(err, &unwrapped) && unwrapped.StatusCode == 403 {
+				result.Error = "I do not have permission to send messages in the panel's channel"
+			} else {
+				result.Error = "Failed to send message"
+			}
+
+			failed = append(failed, result)
+			continue
+		}
+
+		report.Panels[i].Status = PanelHealthOk
+		report.Panels[i].Problems = make([]string, 0)
+		repaired = append(repaired, result)
+
+		actionType, resourceType := database.AuditActionPanelResend, database.AuditResourcePanel
+		if health.Kind == dbclient.PanelKindMultiPanel {
+			actionType, resourceType = database.AuditActionMultiPanelResend, database.AuditResourceMultiPanel
+		}
+
+		audit.Log(audit.LogEntry{
+			GuildId:      audit.Uint64Ptr(guildId),
+			UserId:       userId,
+			ActionType:   actionType,
+			ResourceType: resourceType,
+			ResourceId:   audit.StringPtr(strconv.Itoa(health.PanelId)),
+			Metadata:     health,
+		})
+	}
+
+	// The new message IDs are not reflected in the report, but the next check will pick them up
+	if err := dbclient.Dashboard.PanelHealth.Set(c, guildId, report); err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to save panel health"))
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success":  len(failed) == 0,
+		"repaired": repaired,
+		"failed":   failed,
+		"report":   report,
+	})
+}
+
+func repairPanel(ctx context.Context, botContext *botcontext.BotContext, guildId uint64, health dbclient.PanelHealth, isPremium bool) error {
+	if health.Kind == dbclient.PanelKindMultiPanel {
+		multiPanel, ok, err := dbclient.Client.MultiPanels.Get(ctx, health.PanelId)
+		if err != nil {
+			return err
+		}
+
+		if !ok || multiPanel.GuildId != guildId {
+			return fmt.Errorf("multi-panel %d no longer exists", health.PanelId)
+		}
+
+		return resendMultiPanel(ctx, botContext, multiPanel, isPremium)
+	}
+
+	panel, err := dbclient.Client.Panel.GetById(ctx, health.PanelId)
+	if err != nil {
+		return err
+	}
+
+	if panel.PanelId == 0 || panel.GuildId != guildId {
+		return fmt.Errorf("panel %d no longer exists", health.PanelId)
+	}
+
+	return resendPanel(ctx, botContext, panel, isPremium)
+}
+
+// CheckHealth verifies that the message of each of the guild's panels and multi-panels still exists and matches its
+// stored configuration, and saves the results
+func CheckHealth(ctx context.Context, guildId uint64) (dbclient.PanelHealthReport, error) {
+	botContext, err := botcontext.ContextForGuild(guildId)
+	if err != nil {
+		return dbclient.PanelHealthReport{}, err
+	}
+
+	report, _, err := checkHealth(ctx, botContext, guildId)
+	if err != nil {
+		return dbclient.PanelHealthReport{}, err
+	}
+
+	if err := dbclient.Dashboard.PanelHealth.Set(ctx, guildId, report); err != nil {
+		return dbclient.PanelHealthReport{}, err
+	}
+
+	return report, nil
+}
+
+func checkHealth(ctx context.Context, botContext *botcontext.BotContext, guildId uint64) (dbclient.PanelHealthReport, premium.PremiumTier, error) {
+	report := dbclient.PanelHealthReport{
+		CheckedAt: time.Now(),
+		Panels:    make([]dbclient.PanelHealth, 0),
+	}
+
+	panels, err := dbclient.Client.Panel.GetByGuild(ctx, guildId)
+	if err != nil {
+		return dbclient.PanelHealthReport{}, premium.None, err
+	}
+
+	multiPanels, err := dbclient.Client.MultiPanels.GetByGuild(ctx, guildId)
+	if err != nil {
+		return dbclient.PanelHealthReport{}, premium.None, err
+	}
+
+	if len(panels) == 0 && len(multiPanels) == 0 {
+		return report, premium.None, nil
+	}
+
+	premiumTier, err := rpc.PremiumClient.GetTierByGuildId(ctx, guildId, true, botContext.Token, botContext.RateLimiter)
+	if err != nil {
+		return dbclient.PanelHealthReport{}, premium.None, err
+	}
+
+	channels, err := botContext.GetGuildChannels(ctx, guildId)
+	if err != nil {
+		return dbclient.PanelHealthReport{}, premium.None, err
+	}
+
+	channelIds := make(map[uint64]bool)
+	for _, ch := range channels {
+		channelIds[ch.Id] = true
+	}
+
+	fetchMessage := func(ctx context.Context, channelId, messageId uint64) (message.Message, error) {
+		return rest.GetChannelMessage(ctx, botContext.Token, botContext.RateLimiter, channelId, messageId)
+	}
+
+	for _, panel := range panels {
+		messageData := panelIntoMessageData(panel, premiumTier > premium.None)
+
+		health, err := checkMessage(ctx, fetchMessage, channelIds, panel.ChannelId, panel.MessageId, messageData.buildMessage())
+		if err != nil {
+			return dbclient.PanelHealthReport{}, premium.None, err
+		}
+
+		health.Kind = dbclient.PanelKindPanel
+		health.PanelId = panel.PanelId
+		report.Panels = append(report.Panels, health)
+	}
+
+	for _, multiPanel := range multiPanels {
+		targets, err := dbclient.Client.MultiPanelTargets.GetPanels(ctx, multiPanel.Id)
+		if err != nil {
+			return dbclient.PanelHealthReport{}, premium.None, err
+		}
+
+		rows, err := dbclient.Dashboard.MultiPanelRows.Get(ctx, multiPanel.Id)
+		if err != nil {
+			return dbclient.PanelHealthReport{}, premium.None, err
+		}
+
+		messageData := multiPanelIntoMessageData(multiPanel, rows, premiumTier > premium.None)
+
+		health, err := checkMessage(ctx, fetchMessage, channelIds, multiPanel.ChannelId, multiPanel.MessageId, messageData.buildMessage(targets))
+		if err != nil {
+			return dbclient.PanelHealthReport{}, premium.None, err
+		}
+
+		health.Kind = dbclient.PanelKindMultiPanel
+		health.PanelId = multiPanel.Id
+		report.Panels = append(report.Panels, health)
+	}
+
+	return report, premiumTier, nil
+}
+
+// messageFetcher fetches a message from Discord, and is replaced in tests
+type messageFetcher func(ctx context.Context, channelId, messageId uint64) (message.Message, error)
+
+func checkMessage(ctx context.Context, fetchMessage messageFetcher, channelIds map[uint64]bool, channelId, messageId uint64, expected rest.CreateMessageData) (dbclient.PanelHealth, error) {
+	health := dbclient.PanelHealth{
+		ChannelId: channelId,
+		MessageId: messageId,
+		Status:    PanelHealthOk,
+		Problems:  make([]string, 0),
+	}
+
+	if !channelIds[channelId] {
+		health.Status = PanelHealthOrphaned
+		health.Problems = append(health.Problems, "channel")
+		return health, nil
+	}
+
+	msg, err := fetchMessage(ctx, channelId, messageId)
+	if err != nil {
+		var unwrapped request.RestError
+		if errors.As(err, &unwrapped) && unwrapped.IsClientError() {
+			health.Status = PanelHealthMissing
+			health.Problems = append(health.Problems, "message")
+			return health, nil
+		}
+
+		return dbclient.PanelHealth{}, err
+	}
+
+	if componentsDiffer(expected.Components, msg.Components) {
+		health.Status = PanelHealthBrokenComponents
+		health.Problems = append(health.Problems, "components")
+	}
+
+	if embedProblems := diffEmbed(expected.Embeds, msg); len(embedProblems) > 0 {
+		if health.Status == PanelHealthOk {
+			health.Status = PanelHealthEdited
+		}
+
+		health.Problems = append(health.Problems, embedProblems...)
+	}
+
+	return health, nil
+}
+
+func diffEmbed(expected []*embed.Embed, msg message.Message) []string {
+	if len(expected) == 0 {
+		return nil
+	}
+
+	if len(msg.Embeds) == 0 {
+		return []string{"embed"}
+	}
+
+	want, got := expected[0], msg.Embeds[0]
+
+	var problems []string
+	if !sameText(want.Title, got.Title) {
+		problems = append(problems, "embed.title")
+	}
+
+	if !sameText(want.Description, got.Description) {
+		problems = append(problems, "embed.description")
+	}
+
+	if want.Color != got.Color {
+		problems = append(problems, "embed.colour")
+	}
+
+	if imageUrl(want.Image) != imageUrl(got.Image) {
+		problems = append(problems, "embed.image")
+	}
+
+	if thumbnailUrl(want.Thumbnail) != thumbnailUrl(got.Thumbnail) {
+		problems = append(problems, "embed.thumbnail")
+	}
+
+	if footerText(want.Footer) != footerText(got.Footer) {
+		problems = append(problems, "embed.footer")
+	}
+
+	if len(want.Fields) != len(got.Fields) {
+		problems = append(problems, "embed.fields")
+	} else {
+		for i := range want.Fields {
+			if !sameText(want.Fields[i].Name, got.Fields[i].Name) || !sameText(want.Fields[i].Value, got.Fields[i].Value) {
+				problems = append(problems, "embed.fields")
+				break
+			}
+		}
+	}
+
+	return problems
+}
+
+// Discord trims whitespace from embed text, so it is ignored when comparing
+func sameText(a, b string) bool {
+	return strings.TrimSpace(a) == strings.TrimSpace(b)
+}
+
+func imageUrl(image *embed.EmbedImage) string {
+	if image == nil {
+		return ""
+	}
+
+	return image.Url
+}
+
+func thumbnailUrl(thumbnail *embed.EmbedThumbnail) string {
+	if thumbnail == nil {
+		return ""
+	}
+
+	return thumbnail.Url
+}
+
+func footerText(footer *embed.EmbedFooter) string {
+	if footer == nil {
+		return ""
+	}
+
+	return strings.TrimSpace(footer.Text)
+}
+
+// componentsDiffer compares the parts of the components which affect whether they open the right ticket: custom IDs,
+// labels, styles, whether they are disabled, and select menu options. Emojis are ignored, as Discord may resolve them
+// differently to how they were sent.
+func componentsDiffer(expected, actual []component.Component) bool {
+	want, got := componentSignatures(expected), componentSignatures(actual)
+	if len(want) != len(got) {
+		return true
+	}
+
+	for i := range want {
+		if want[i] != got[i] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func componentSignatures(components []component.Component) []string {
+	var signatures []string
+	for _, c := range components {
+		switch data := c.ComponentData.(type) {
+		case component.ActionRow:
+			signatures = append(signatures, "row")
+			signatures = append(signatures, componentSignatures(data.Components)...)
+		case component.Button:
+			signatures = append(signatures, fmt.Sprintf("button:%s:%s:%d:%t", data.CustomId, data.Label, data.Style, data.Disabled))
+		case component.SelectMenu:
+			signatures = append(signatures, fmt.Sprintf("select:%s:%s:%t", data.CustomId, data.Placeholder, data.Disabled))
+			for _, option := range data.Options {
+				signatures = append(signatures, fmt.Sprintf("option:%s:%s:%s", option.Value, option.Label, utils.ValueOrZero(option.Description)))
+			}
+		default:
+			signatures = append(signatures, fmt.Sprintf("component:%d", c.Type))
+		}
+	}
+
+	return signatures
+}
@@ -0,0 +1,211 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/TicketsBot-cloud/gdl/objects/channel/embed"
+	"github.com/TicketsBot-cloud/gdl/objects/channel/message"
+	"github.com/TicketsBot-cloud/gdl/objects/guild/emoji"
+	"github.com/TicketsBot-cloud/gdl/objects/interaction/component"
+	"github.com/TicketsBot-cloud/gdl/rest"
+	"github.com/TicketsBot-cloud/gdl/rest/request"
+	"github.com/stretchr/testify/assert"
+)
+
+func testButtons(label string) []component.Component {
+	return []component.Component{
+		component.BuildActionRow(component.BuildButton(component.Button{
+			Label:    label,
+			CustomId: "panel-1",
+			Style:    component.ButtonStylePrimary,
+		})),
+	}
+}
+
+func testEmbed(title string) embed.Embed {
+	return embed.Embed{
+		Title:       title,
+		Description: "Click below to open a ticket",
+		Color:       0x2ecc71,
+		Fields:      []*embed.EmbedField{{Name: "Hours", Value: "9-5"}},
+	}
+}
+
+func TestComponentsDiffer(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected []component.Component
+		actual   []component.Component
+		differ   bool
+	}{
+		{
+			name:     "same",
+			expected: testButtons("Open"),
+			actual:   testButtons("Open"),
+		},
+		{
+			name:     "changed label",
+			expected: testButtons("Open"),
+			actual:   testButtons("Close"),
+			differ:   true,
+		},
+		{
+			name:     "removed",
+			expected: testButtons("Open"),
+			actual:   nil,
+			differ:   true,
+		},
+		{
+			name: "ignores emojis",
+			expected: []component.Component{
+				component.BuildActionRow(component.BuildButton(component.Button{Label: "Open", CustomId: "panel-1"})),
+			},
+			actual: []component.Component{
+				component.BuildActionRow(component.BuildButton(component.Button{Label: "Open", CustomId: "panel-1", Emoji: &emoji.Emoji{Name: "🎫"}})),
+			},
+		},
+		{
+			name: "select menu options",
+			expected: []component.Component{
+				component.BuildActionRow(component.BuildSelectMenu(component.SelectMenu{
+					CustomId: "multi-1",
+					Options:  []component.SelectOption{{Label: "Billing", Value: "panel-1"}},
+				})),
+			},
+			actual: []component.Component{
+				component.BuildActionRow(component.BuildSelectMenu(component.SelectMenu{
+					CustomId: "multi-1",
+					Options:  []component.SelectOption{{Label: "Billing", Value: "panel-2"}},
+				})),
+			},
+			differ: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.differ, componentsDiffer(test.expected, test.actual))
+		})
+	}
+}
+
+func TestDiffEmbed(t *testing.T) {
+	expected := testEmbed("Support")
+
+	tests := []struct {
+		name     string
+		actual   []embed.Embed
+		problems []string
+	}{
+		{
+			name:   "same",
+			actual: []embed.Embed{expected},
+		},
+		{
+			name:   "whitespace is trimmed",
+			actual: []embed.Embed{testEmbed(" Support ")},
+		},
+		{
+			name:     "missing",
+			actual:   nil,
+			problems: []string{"embed"},
+		},
+		{
+			name: "edited",
+			actual: []embed.Embed{func() embed.Embed {
+				e := testEmbed("Help")
+				e.Color = 0
+				e.Fields = nil
+				return e
+			}()},
+			problems: []string{"embed.title", "embed.colour", "embed.fields"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			problems := diffEmbed([]*embed.Embed{&expected}, message.Message{Embeds: test.actual})
+			assert.Equal(t, test.problems, problems)
+		})
+	}
+}
+
+func TestCheckMessage(t *testing.T) {
+	expectedEmbed := testEmbed("Support")
+	expected := rest.CreateMessageData{
+		Embeds:     []*embed.Embed{&expectedEmbed},
+		Components: testButtons("Open"),
+	}
+
+	channelIds := map[uint64]bool{1: true}
+
+	tests := []struct {
+		name      string
+		channelId uint64
+		msg       message.Message
+		err       error
+		status    string
+		problems  []string
+	}{
+		{
+			name:      "ok",
+			channelId: 1,
+			msg:       message.Message{Embeds: []embed.Embed{testEmbed("Support")}, Components: testButtons("Open")},
+			status:    PanelHealthOk,
+			problems:  []string{},
+		},
+		{
+			name:      "edited",
+			channelId: 1,
+			msg:       message.Message{Embeds: []embed.Embed{testEmbed("Help")}, Components: testButtons("Open")},
+			status:    PanelHealthEdited,
+			problems:  []string{"embed.title"},
+		},
+		{
+			name:      "broken components",
+			channelId: 1,
+			msg:       message.Message{Embeds: []embed.Embed{testEmbed("Help")}, Components: nil},
+			status:    PanelHealthBrokenComponents,
+			problems:  []string{"components", "embed.title"},
+		},
+		{
+			name:      "missing",
+			channelId: 1,
+			err:       request.RestError{StatusCode: 404},
+			status:    PanelHealthMissing,
+			problems:  []string{"message"},
+		},
+		{
+			name:      "orphaned",
+			channelId: 2,
+			status:    PanelHealthOrphaned,
+			problems:  []string{"channel"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fetch := func(ctx context.Context, channelId, messageId uint64) (message.Message, error) {
+				return test.msg, test.err
+			}
+
+			health, err := checkMessage(context.Background(), fetch, channelIds, test.channelId, 10, expected)
+			assert.NoError(t, err)
+			assert.Equal(t, test.status, health.Status)
+			assert.Equal(t, test.problems, health.Problems)
+			assert.Equal(t, test.channelId, health.ChannelId)
+			assert.Equal(t, uint64(10), health.MessageId)
+		})
+	}
+
+	t.Run("server error", func(t *testing.T) {
+		fetch := func(ctx context.Context, channelId, messageId uint64) (message.Message, error) {
+			return message.Message{}, errors.New("discord unavailable")
+		}
+
+		_, err := checkMessage(context.Background(), fetch, channelIds, 1, 10, expected)
+		assert.Error(t, err)
+	})
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/TicketsBot-cloud/dashboard/app"
 	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/dashboard/supporthours"
 	"github.com/TicketsBot-cloud/dashboard/utils/types"
 	"github.com/TicketsBot-cloud/database"
 	"github.com/gin-gonic/gin"
@@ -26,6 +28,7 @@ func ListPanels(c *gin.Context) {
 		HasSupportHours              bool                              `json:"has_support_hours"`
 		IsCurrentlyActive            bool                              `json:"is_currently_active"`
 		TicketPermissions            database.TicketPermissions        `json:"ticket_permissions"`
+		Usage                        panelUsage                        `json:"usage"`
 	}
 
 	guildId := c.Keys["guildid"].(uint64)
@@ -48,6 +51,22 @@ func ListPanels(c *gin.Context) {
 		return
 	}
 
+	// Usage covers the same default period as the usage endpoints, and is loaded for every panel at once
+	usageEnd := time.Now()
+	usageStart := usageEnd.AddDate(0, 0, -defaultUsageDays)
+
+	usages, err := loadPanelUsages(c, guildId, usageStart, usageEnd)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to load panels"))
+		return
+	}
+
+	openTimes, err := dbclient.Dashboard.Analytics.GetOpenTimesByPanel(c, guildId, usageStart, usageEnd)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to load panels"))
+		return
+	}
+
 	wrapped := make([]panelResponse, len(panels))
 
 	// we will need to lookup role mentions
@@ -118,21 +137,25 @@ func ListPanels(c *gin.Context) {
 			}
 
 			// Check if panel has support hours configured
-			supportHours, err := dbclient.Client.PanelSupportHours.GetByPanelId(c, p.PanelId)
+			supportHours, err := dbclient.Dashboard.AppliedHoursOverrides.GetWeeklyHours(c, p.PanelId)
 			if err != nil {
 				return err
 			}
 
 			hasSupportHours := len(supportHours) > 0
-			isCurrentlyActive := true // Default to active if no hours configured
 
-			if hasSupportHours {
-				isCurrentlyActive, err = dbclient.Client.PanelSupportHours.IsActiveNow(c, p.PanelId)
-				if err != nil {
-					return err
-				}
+			// Overrides can close a panel without weekly hours, e.g. for a holiday
+			overrides, err := dbclient.Dashboard.SupportHoursOverrides.GetByPanel(c, p.PanelId)
+			if err != nil {
+				return err
 			}
 
+			schedule := supporthours.FromPanelHours(supportHours).WithOverrides(supporthours.FromOverrideRows(overrides))
+			isCurrentlyActive := schedule.IsOpen(time.Now())
+
+			usage := usages.get(p.PanelId)
+			usage.OpenedOutsideSupportHours = countOpenedOutsideHours(schedule, openTimes[p.PanelId])
+
 			wrapped[i] = panelResponse{
 				Panel:                        p.Panel,
 				WelcomeMessage:               welcomeMessage,
@@ -145,6 +168,7 @@ func ListPanels(c *gin.Context) {
 				HasSupportHours:              hasSupportHours,
 				IsCurrentlyActive:            isCurrentlyActive,
 				TicketPermissions:            ticketPerms,
+				Usage:                        usage,
 			}
 
 			return nil
@@ -58,7 +58,8 @@ func panelIntoMessageData(panel database.Panel, isPremium bool) panelMessageData
 	}
 }
 
-func (p *panelMessageData) send(c *botcontext.BotContext) (uint64, error) {
+// buildMessage builds the panel message exactly as it is sent to Discord
+func (p *panelMessageData) buildMessage() rest.CreateMessageData {
 	e := embed.NewEmbed().
 		SetTitle(p.Title).
 		SetDescription(p.Content).
@@ -76,7 +77,7 @@ func (p *panelMessageData) send(c *botcontext.BotContext) (uint64, error) {
 		e.SetFooter(fmt.Sprintf("Powered by %s", config.Conf.Bot.PoweredBy), config.Conf.Bot.IconUrl)
 	}
 
-	data := rest.CreateMessageData{
+	return rest.CreateMessageData{
 		Embeds: []*embed.Embed{e},
 		Components: []component.Component{
 			component.BuildActionRow(component.BuildButton(component.Button{
@@ -89,6 +90,10 @@ func (p *panelMessageData) send(c *botcontext.BotContext) (uint64, error) {
 			})),
 		},
 	}
+}
+
+func (p *panelMessageData) send(c *botcontext.BotContext) (uint64, error) {
+	data := p.buildMessage()
 
 	ctx, cancel := app.DefaultContext()
 	defer cancel()
@@ -102,35 +107,10 @@ func (p *panelMessageData) send(c *botcontext.BotContext) (uint64, error) {
 }
 
 func (p *panelMessageData) edit(c *botcontext.BotContext, messageId uint64) error {
-	e := embed.NewEmbed().
-		SetTitle(p.Title).
-		SetDescription(p.Content).
-		SetColor(p.Colour)
-
-	if p.ImageUrl != nil {
-		e.SetImage(*p.ImageUrl)
-	}
-
-	if p.ThumbnailUrl != nil {
-		e.SetThumbnail(*p.ThumbnailUrl)
-	}
-
-	if !p.IsPremium {
-		e.SetFooter(fmt.Sprintf("Powered by %s", config.Conf.Bot.PoweredBy), config.Conf.Bot.IconUrl)
-	}
-
+	message := p.buildMessage()
 	data := rest.EditMessageData{
-		Embeds:  []*embed.Embed{e},
-		Components: []component.Component{
-			component.BuildActionRow(component.BuildButton(component.Button{
-				Label:    p.ButtonLabel,
-				CustomId: p.CustomId,
-				Style:    p.ButtonStyle,
-				Emoji:    p.Emoji,
-				Url:      nil,
-				Disabled: p.ButtonDisabled,
-			})),
-		},
+		Embeds:     message.Embeds,
+		Components: message.Components,
 	}
 
 	ctx, cancel := app.DefaultContext()
@@ -0,0 +1,205 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/TicketsBot-cloud/common/premium"
+	"github.com/TicketsBot-cloud/dashboard/app"
+	"github.com/TicketsBot-cloud/dashboard/app/http/validation"
+	"github.com/TicketsBot-cloud/dashboard/botcontext"
+	"github.com/TicketsBot-cloud/dashboard/rpc"
+	"github.com/TicketsBot-cloud/dashboard/utils"
+	"github.com/TicketsBot-cloud/gdl/objects/guild/emoji"
+	"github.com/TicketsBot-cloud/gdl/objects/interaction/component"
+	"github.com/gin-gonic/gin"
+)
+
+// previewCustomId stands in for the random custom ID that a panel is given when it is created
+const previewCustomId = "preview"
+
+// PreviewPanel returns the message that would be sent for an unsaved panel, without sending it. The panel is checked
+// as it would be when created, and any errors are returned alongside the preview, as with a dry run.
+func PreviewPanel(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+
+	botContext, err := botcontext.ContextForGuild(guildId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Unable to connect to Discord. Please try again later."))
+		return
+	}
+
+	var data panelBody
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(400, utils.ErrorStr("Invalid request data. Please check your input and try again."))
+		return
+	}
+
+	// Premium from voting doesn't apply to new panels, so must not apply to their preview either
+	premiumTier, err := rpc.PremiumClient.GetTierByGuildId(c, guildId, false, botContext.Token, botContext.RateLimiter)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to verify premium status"))
+		return
+	}
+
+	ApplyPanelDefaults(&data)
+
+	ctx, cancel := app.DefaultContext()
+	defer cancel()
+
+	channels, err := botContext.GetGuildChannels(ctx, guildId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch guild channels from Discord"))
+		return
+	}
+
+	roles, err := botContext.GetGuildRoles(ctx, guildId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Unable to load roles from Discord. Please try again."))
+		return
+	}
+
+	errs, err := collectPanelErrors(PanelValidationContext{
+		Data:       data,
+		GuildId:    guildId,
+		IsPremium:  premiumTier > premium.None,
+		BotContext: botContext,
+		Channels:   channels,
+		Roles:      roles,
+	})
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Panel validation failed unexpectedly"))
+		return
+	}
+
+	if errs == nil {
+		errs = make([]*validation.InvalidInputError, 0)
+	}
+
+	messageData := data.IntoPanelMessageData(previewCustomId, premiumTier > premium.None)
+	payload := messageData.buildMessage()
+
+	if err := resolvePreviewEmojis(c, botContext, guildId, payload.Components); err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to load emojis from Discord"))
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"payload": payload,
+		"errors":  errs,
+	})
+}
+
+// PreviewMultiPanel returns the message that would be sent for an unsaved multi-panel, without sending it
+func PreviewMultiPanel(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+
+	botContext, err := botcontext.ContextForGuild(guildId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Unable to connect to Discord. Please try again later."))
+		return
+	}
+
+	var data multiPanelCreateData
+	if err := c.ShouldBindJSON(&data); err != nil {
+		c.JSON(400, utils.ErrorStr("Invalid request data. Please check your input and try again."))
+		return
+	}
+
+	if data.Embed == nil {
+		c.JSON(400, utils.ErrorStr("Your embed message does not contain any content"))
+		return
+	}
+
+	// Rows are used to index the message's action rows, so must be checked before building it
+	if err := data.validateLayout(); err != nil {
+		c.JSON(400, utils.ErrorStr("%s", err.Error()))
+		return
+	}
+
+	panels, err := data.validatePanels(guildId)
+	if err != nil {
+		var validationError *validation.InvalidInputError
+		if errors.As(err, &validationError) {
+			c.JSON(400, utils.ErrorStr("%s", validationError.Error()))
+		} else {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch panels"))
+		}
+
+		return
+	}
+
+	premiumTier, err := rpc.PremiumClient.GetTierByGuildId(c, guildId, true, botContext.Token, botContext.RateLimiter)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to verify premium status"))
+		return
+	}
+
+	messageData := data.IntoMessageData(premiumTier > premium.None)
+	payload := messageData.buildMessage(data.withCustomization(panels))
+
+	if err := resolvePreviewEmojis(c, botContext, guildId, payload.Components); err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to load emojis from Discord"))
+		return
+	}
+
+	c.JSON(200, gin.H{
+		"success": true,
+		"payload": payload,
+	})
+}
+
+// resolvePreviewEmojis marks the animated custom emojis of buttons and select options. Only the ID and name of an
+// emoji are stored, so without this, the preview could not tell whether to render an emoji as animated. Emojis that
+// no longer exist are left as-is, as Discord would also fail to render them.
+func resolvePreviewEmojis(ctx context.Context, botContext *botcontext.BotContext, guildId uint64, components []component.Component) error {
+	var emojis []*emoji.Emoji
+	for _, row := range components {
+		actionRow, ok := row.ComponentData.(component.ActionRow)
+		if !ok {
+			continue
+		}
+
+		for _, child := range actionRow.Components {
+			switch data := child.ComponentData.(type) {
+			case component.Button:
+				emojis = append(emojis, data.Emoji)
+			case component.SelectMenu:
+				for _, option := range data.Options {
+					emojis = append(emojis, option.Emoji)
+				}
+			}
+		}
+	}
+
+	var custom []*emoji.Emoji
+	for _, e := range emojis {
+		if e != nil && e.Id.Value != 0 {
+			custom = append(custom, e)
+		}
+	}
+
+	if len(custom) == 0 {
+		return nil
+	}
+
+	guildEmojis, err := botContext.GetGuildEmojis(ctx, guildId)
+	if err != nil {
+		return err
+	}
+
+	byId := make(map[uint64]emoji.Emoji)
+	for _, e := range guildEmojis {
+		byId[e.Id.Value] = e
+	}
+
+	for _, e := range custom {
+		if resolved, ok := byId[e.Id.Value]; ok {
+			e.Animated = resolved.Animated
+		}
+	}
+
+	return nil
+}
@@ -57,25 +57,13 @@ func ResendPanel(ctx *gin.Context) {
 		return
 	}
 
-	// delete old message
-	// TODO: Use proper context
-	if err := rest.DeleteMessage(context.Background(), botContext.Token, botContext.RateLimiter, panel.ChannelId, panel.MessageId); err != nil {
-		var unwrapped request.RestError
-		if errors.As(err, &unwrapped) && !unwrapped.IsClientError() {
-			ctx.JSON(500, utils.ErrorStr("Failed to send message. Please try again."))
-			return
-		}
-	}
-
 	premiumTier, err := rpc.PremiumClient.GetTierByGuildId(ctx, guildId, true, botContext.Token, botContext.RateLimiter)
 	if err != nil {
 		ctx.JSON(500, utils.ErrorStr("Unable to verify premium status. Please try again."))
 		return
 	}
 
-	messageData := panelIntoMessageData(panel, premiumTier > premium.None)
-	msgId, err := messageData.send(botContext)
-	if err != nil {
+	if err := resendPanel(ctx, botContext, panel, premiumTier > premium.None); err != nil {
 		var unwrapped request.RestError
 		if errors.As(err, &unwrapped) && unwrapped.StatusCode == 403 {
 			ctx.JSON(500, utils.ErrorStr("I do not have permission to send messages in the provided channel"))
@@ -86,11 +74,6 @@ func ResendPanel(ctx *gin.Context) {
 		return
 	}
 
-	if err = dbclient.Client.Panel.UpdateMessageId(ctx, panel.PanelId, msgId); err != nil {
-		ctx.JSON(500, utils.ErrorStr("Failed to send message. Please try again."))
-		return
-	}
-
 	audit.Log(audit.LogEntry{
 		GuildId:      audit.Uint64Ptr(guildId),
 		UserId:       userId,
@@ -100,3 +83,23 @@ func ResendPanel(ctx *gin.Context) {
 	})
 	ctx.JSON(200, utils.SuccessResponse)
 }
+
+// resendPanel deletes the panel's message, if it still exists, and sends a new one in its place. If the new message
+// could not be sent, the request.RestError is returned as-is.
+func resendPanel(ctx context.Context, botContext *botcontext.BotContext, panel database.Panel, isPremium bool) error {
+	// TODO: Use proper context
+	if err := rest.DeleteMessage(context.Background(), botContext.Token, botContext.RateLimiter, panel.ChannelId, panel.MessageId); err != nil {
+		var unwrapped request.RestError
+		if errors.As(err, &unwrapped) && !unwrapped.IsClientError() {
+			return err
+		}
+	}
+
+	messageData := panelIntoMessageData(panel, isPremium)
+	msgId, err := messageData.send(botContext)
+	if err != nil {
+		return err
+	}
+
+	return dbclient.Client.Panel.UpdateMessageId(ctx, panel.PanelId, msgId)
+}
@@ -0,0 +1,303 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/TicketsBot-cloud/common/premium"
+	"github.com/TicketsBot-cloud/dashboard/app"
+	"github.com/TicketsBot-cloud/dashboard/app/http/audit"
+	"github.com/TicketsBot-cloud/dashboard/botcontext"
+	"github.com/TicketsBot-cloud/dashboard/config"
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/dashboard/rpc"
+	"github.com/TicketsBot-cloud/dashboard/utils"
+	"github.com/TicketsBot-cloud/database"
+	"github.com/TicketsBot-cloud/gdl/rest/request"
+	"github.com/gin-gonic/gin"
+)
+
+const maxPanelSchedules = 50
+
+// panelScheduleBody schedules a panel to be enabled or disabled at a future time
+type panelScheduleBody struct {
+	Disabled *bool     `json:"disabled" binding:"required"`
+	RunAt    time.Time `json:"run_at" binding:"required"`
+}
+
+// ListGuildPanelSchedules returns the pending schedules for all of the guild's panels
+func ListGuildPanelSchedules(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+
+	schedules, err := dbclient.Dashboard.PanelStateSchedules.GetByGuild(c, guildId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to process request"))
+		return
+	}
+
+	c.JSON(http.StatusOK, schedules)
+}
+
+func ListPanelSchedules(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+
+	panelId, ok := guildPanelId(c, guildId)
+	if !ok {
+		return
+	}
+
+	schedules, err := dbclient.Dashboard.PanelStateSchedules.GetByPanel(c, panelId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to process request"))
+		return
+	}
+
+	c.JSON(http.StatusOK, schedules)
+}
+
+func CreatePanelSchedule(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+	userId := c.Keys["userid"].(uint64)
+
+	panelId, err := strconv.Atoi(c.Param("panelid"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("Invalid panel ID provided: %s", c.Param("panelid")))
+		return
+	}
+
+	var body panelScheduleBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("Invalid request body: disabled and run_at are required"))
+		return
+	}
+
+	if !body.RunAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("Schedules must run in the future"))
+		return
+	}
+
+	panel, err := dbclient.Client.Panel.GetById(c, panelId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch panel"))
+		return
+	}
+
+	if panel.GuildId != guildId {
+		c.JSON(http.StatusNotFound, utils.ErrorStr("Panel not found: %d", panelId))
+		return
+	}
+
+	if panel.ForceDisabled {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("This panel is disabled and cannot be modified: please reactivate premium to re-enable it"))
+		return
+	}
+
+	count, err := dbclient.Dashboard.PanelStateSchedules.GetCount(c, panelId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to process request"))
+		return
+	}
+
+	if count >= maxPanelSchedules {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("Panels can have at most %d pending schedules", maxPanelSchedules))
+		return
+	}
+
+	schedule, created, err := dbclient.Dashboard.PanelStateSchedules.Create(c, dbclient.PanelStateSchedule{
+		GuildId:   guildId,
+		PanelId:   panelId,
+		Disabled:  *body.Disabled,
+		RunAt:     body.RunAt,
+		CreatedBy: userId,
+	})
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to process request"))
+		return
+	}
+
+	if !created {
+		c.JSON(http.StatusConflict, utils.ErrorStr("This panel already has a schedule at %s", body.RunAt.Format(time.RFC3339)))
+		return
+	}
+
+	audit.Log(audit.LogEntry{
+		GuildId:      audit.Uint64Ptr(guildId),
+		UserId:       userId,
+		ActionType:   audit.ActionPanelScheduleCreate,
+		ResourceType: audit.ResourcePanelSchedule,
+		ResourceId:   audit.StringPtr(strconv.Itoa(schedule.Id)),
+		NewData:      schedule,
+	})
+
+	c.JSON(http.StatusOK, schedule)
+}
+
+func DeletePanelSchedule(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+	userId := c.Keys["userid"].(uint64)
+
+	panelId, ok := guildPanelId(c, guildId)
+	if !ok {
+		return
+	}
+
+	scheduleId, err := strconv.Atoi(c.Param("scheduleid"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("Invalid schedule ID provided: %s", c.Param("scheduleid")))
+		return
+	}
+
+	existing, ok, err := dbclient.Dashboard.PanelStateSchedules.Get(c, panelId, scheduleId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to process request"))
+		return
+	}
+
+	if !ok {
+		c.JSON(http.StatusNotFound, utils.ErrorStr("Schedule not found: %d", scheduleId))
+		return
+	}
+
+	if err := dbclient.Dashboard.PanelStateSchedules.Delete(c, panelId, existing.Id); err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to process request"))
+		return
+	}
+
+	audit.Log(audit.LogEntry{
+		GuildId:      audit.Uint64Ptr(guildId),
+		UserId:       userId,
+		ActionType:   audit.ActionPanelScheduleDelete,
+		ResourceType: audit.ResourcePanelSchedule,
+		ResourceId:   audit.StringPtr(strconv.Itoa(existing.Id)),
+		OldData:      existing,
+	})
+
+	c.JSON(http.StatusOK, utils.SuccessResponse)
+}
+
+// ApplyPanelSchedule enables or disables the panel, edits the panel's message and those of the multi-panels containing
+// it, and then removes the schedule. Panels which have been disabled for exceeding the free panel limit are left
+// untouched. The messages of panels which are already in the scheduled state are still refreshed, as an earlier
+// attempt may have saved the state but failed to edit them, in which case the schedule was kept to be retried.
+func ApplyPanelSchedule(ctx context.Context, schedule dbclient.PanelStateSchedule) error {
+	panel, err := dbclient.Client.Panel.GetById(ctx, schedule.PanelId)
+	if err != nil {
+		return err
+	}
+
+	if panel.ForceDisabled {
+		return dbclient.Dashboard.PanelStateSchedules.Delete(ctx, schedule.PanelId, schedule.Id)
+	}
+
+	if panel.Disabled != schedule.Disabled {
+		if err := dbclient.Dashboard.PanelStateSchedules.SetPanelDisabled(ctx, panel.PanelId, schedule.Disabled); err != nil {
+			return err
+		}
+
+		audit.Log(audit.LogEntry{
+			GuildId:      audit.Uint64Ptr(schedule.GuildId),
+			UserId:       config.Conf.Bot.Id,
+			ActionType:   audit.ActionPanelScheduleApply,
+			ResourceType: database.AuditResourcePanel,
+			ResourceId:   audit.StringPtr(strconv.Itoa(panel.PanelId)),
+			OldData:      map[string]interface{}{"disabled": panel.Disabled},
+			NewData:      map[string]interface{}{"disabled": schedule.Disabled},
+			Metadata:     schedule,
+		})
+
+		panel.Disabled = schedule.Disabled
+	}
+
+	botContext, err := botcontext.ContextForGuild(schedule.GuildId)
+	if err != nil {
+		return err
+	}
+
+	premiumTier, err := rpc.PremiumClient.GetTierByGuildId(ctx, schedule.GuildId, true, botContext.Token, botContext.RateLimiter)
+	if err != nil {
+		return err
+	}
+
+	if err := refreshPanelMessages(ctx, botContext, panel, premiumTier > premium.None); err != nil {
+		// If Discord rejected the edit, e.g. because the bot can no longer access the channel, retrying won't help, so
+		// the schedule is removed anyway. Panel health will report the message as out of date.
+		var unwrapped request.RestError
+		if errors.As(err, &unwrapped) && unwrapped.IsClientError() {
+			if deleteErr := dbclient.Dashboard.PanelStateSchedules.Delete(ctx, schedule.PanelId, schedule.Id); deleteErr != nil {
+				return errors.Join(err, deleteErr)
+			}
+		}
+
+		return err
+	}
+
+	return dbclient.Dashboard.PanelStateSchedules.Delete(ctx, schedule.PanelId, schedule.Id)
+}
+
+// refreshPanelMessages edits the panel's message, and those of the multi-panels containing it, to match the stored
+// configuration. Messages which have been deleted are sent again.
+func refreshPanelMessages(ctx context.Context, botContext *botcontext.BotContext, panel database.Panel, isPremium bool) error {
+	messageData := panelIntoMessageData(panel, isPremium)
+	if err := messageData.edit(botContext, panel.MessageId); err != nil {
+		if !isUnknownMessage(err) {
+			return err
+		}
+
+		messageId, err := messageData.send(botContext)
+		if err != nil {
+			return err
+		}
+
+		if err := dbclient.Client.Panel.UpdateMessageId(ctx, panel.PanelId, messageId); err != nil {
+			return err
+		}
+	}
+
+	multiPanels, err := dbclient.Client.MultiPanelTargets.GetMultiPanels(ctx, panel.PanelId)
+	if err != nil {
+		return err
+	}
+
+	for i, multiPanel := range multiPanels {
+		// Only update 5 multi-panels maximum, as in UpdatePanel
+		if i >= 5 {
+			break
+		}
+
+		panels, err := dbclient.Client.MultiPanelTargets.GetPanels(ctx, multiPanel.Id)
+		if err != nil {
+			return err
+		}
+
+		rows, err := dbclient.Dashboard.MultiPanelRows.Get(ctx, multiPanel.Id)
+		if err != nil {
+			return err
+		}
+
+		messageData := multiPanelIntoMessageData(multiPanel, rows, isPremium)
+		if err := messageData.edit(botContext, multiPanel.MessageId, panels); err != nil {
+			if !isUnknownMessage(err) {
+				return err
+			}
+
+			messageId, err := messageData.send(botContext, panels)
+			if err != nil {
+				return err
+			}
+
+			if err := dbclient.Client.MultiPanels.UpdateMessageId(ctx, multiPanel.Id, messageId); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func isUnknownMessage(err error) bool {
+	var unwrapped request.RestError
+	return errors.As(err, &unwrapped) && unwrapped.StatusCode == http.StatusNotFound
+}
@@ -97,6 +97,18 @@ func UpdatePanel(c *gin.Context) {
 		Roles:      roles,
 	}
 
+	if c.Query("dry_run") == "true" {
+		errs, err := collectPanelErrors(validationContext)
+		if err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to update panel"))
+			return
+		}
+
+		result := validation.NewDryRunResult(errs)
+		c.JSON(result.StatusCode(), result)
+		return
+	}
+
 	if err := ValidatePanelBody(validationContext); err != nil {
 		var validationError *validation.InvalidInputError
 		if errors.As(err, &validationError) {
@@ -357,7 +369,13 @@ func UpdatePanel(c *gin.Context) {
 			return
 		}
 
-		messageData := multiPanelIntoMessageData(multiPanel, premiumTier > premium.None)
+		rows, err := dbclient.Dashboard.MultiPanelRows.Get(c, multiPanel.Id)
+		if err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to update panel"))
+			return
+		}
+
+		messageData := multiPanelIntoMessageData(multiPanel, rows, premiumTier > premium.None)
 
 		// Try to edit message first
 		var messageId uint64
@@ -0,0 +1,206 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/TicketsBot-cloud/dashboard/app"
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/dashboard/supporthours"
+	"github.com/TicketsBot-cloud/dashboard/utils"
+	"github.com/TicketsBot-cloud/database"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	defaultUsageDays = 30
+	maxUsageDays     = 366
+)
+
+// panelUsage holds statistics for the tickets opened with a panel. AverageResolutionTime is in seconds, and is nil if
+// none of the tickets have been closed.
+//
+// Button clicks, attempts blocked by a cooldown or ticket limit, and forms abandoned before being submitted are not
+// included. Those interactions are only ever seen by the bot, which does not record them anywhere the dashboard can
+// read, so they can only be counted once the bot stores them.
+type panelUsage struct {
+	PanelId                   int      `json:"panel_id"`
+	TicketsOpened             int      `json:"tickets_opened"`
+	TicketsClosed             int      `json:"tickets_closed"`
+	OpenedOutsideSupportHours int      `json:"opened_outside_support_hours"`
+	AverageResolutionTime     *float64 `json:"average_resolution_time"`
+}
+
+// panelUsages holds the usage of each of a guild's panels, except for tickets opened outside of support hours, which
+// depend on each panel's schedule
+type panelUsages map[int]panelUsage
+
+func loadPanelUsages(ctx context.Context, guildId uint64, start, end time.Time) (panelUsages, error) {
+	ticketStats, err := dbclient.Dashboard.Analytics.GetPanelTicketStats(ctx, guildId, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	usages := make(panelUsages)
+	for panelId, stats := range ticketStats {
+		usages[panelId] = panelUsage{
+			PanelId:               panelId,
+			TicketsOpened:         stats.Opened,
+			TicketsClosed:         stats.Closed,
+			AverageResolutionTime: stats.AverageResolution,
+		}
+	}
+
+	return usages, nil
+}
+
+func (u panelUsages) get(panelId int) panelUsage {
+	usage, ok := u[panelId]
+	if !ok {
+		usage = panelUsage{PanelId: panelId}
+	}
+
+	return usage
+}
+
+// countOpenedOutsideHours returns the number of the open times at which support was unavailable. Tickets are checked
+// against the panel's current schedule, as past schedules are not kept.
+func countOpenedOutsideHours(schedule supporthours.Schedule, openTimes []time.Time) int {
+	if schedule.IsAlwaysOpen() {
+		return 0
+	}
+
+	var count int
+	for _, openTime := range openTimes {
+		if !schedule.IsOpen(openTime) {
+			count++
+		}
+	}
+
+	return count
+}
+
+// usageRange reads the days query parameter, which defaults to the last 30 days
+func usageRange(c *gin.Context) (time.Time, time.Time, bool) {
+	days := defaultUsageDays
+	if raw := c.Query("days"); raw != "" {
+		var err error
+		days, err = strconv.Atoi(raw)
+		if err != nil || days < 1 || days > maxUsageDays {
+			c.JSON(http.StatusBadRequest, utils.ErrorStr("days must be a number between 1 and %d", maxUsageDays))
+			return time.Time{}, time.Time{}, false
+		}
+	}
+
+	end := time.Now()
+	return end.AddDate(0, 0, -days), end, true
+}
+
+// GetPanelsUsage returns the usage of each of the guild's panels
+func GetPanelsUsage(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+
+	start, end, ok := usageRange(c)
+	if !ok {
+		return
+	}
+
+	var (
+		panels    []database.Panel
+		usages    panelUsages
+		openTimes map[int][]time.Time
+	)
+
+	group, _ := errgroup.WithContext(c)
+
+	group.Go(func() (err error) {
+		panels, err = dbclient.Client.Panel.GetByGuild(c, guildId)
+		return
+	})
+
+	group.Go(func() (err error) {
+		usages, err = loadPanelUsages(c, guildId, start, end)
+		return
+	})
+
+	group.Go(func() (err error) {
+		openTimes, err = dbclient.Dashboard.Analytics.GetOpenTimesByPanel(c, guildId, start, end)
+		return
+	})
+
+	if err := group.Wait(); err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to load panel usage"))
+		return
+	}
+
+	response := make([]panelUsage, len(panels))
+
+	group, _ = errgroup.WithContext(c)
+	for i, panel := range panels {
+		usage := usages.get(panel.PanelId)
+
+		// Schedules only need to be loaded for panels which have had tickets opened
+		if len(openTimes[panel.PanelId]) == 0 {
+			response[i] = usage
+			continue
+		}
+
+		group.Go(func() error {
+			schedule, err := supporthours.Load(c, panel.PanelId)
+			if err != nil {
+				return err
+			}
+
+			usage.OpenedOutsideSupportHours = countOpenedOutsideHours(schedule, openTimes[panel.PanelId])
+			response[i] = usage
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to load panel usage"))
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func GetPanelUsage(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+
+	panelId, ok := guildPanelId(c, guildId)
+	if !ok {
+		return
+	}
+
+	start, end, ok := usageRange(c)
+	if !ok {
+		return
+	}
+
+	usages, err := loadPanelUsages(c, guildId, start, end)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to load panel usage"))
+		return
+	}
+
+	schedule, err := supporthours.Load(c, panelId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to load panel usage"))
+		return
+	}
+
+	openTimes, err := dbclient.Dashboard.Analytics.GetPanelOpenTimes(c, guildId, panelId, start, end)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to load panel usage"))
+		return
+	}
+
+	usage := usages.get(panelId)
+	usage.OpenedOutsideSupportHours = countOpenedOutsideHours(schedule, openTimes)
+
+	c.JSON(http.StatusOK, usage)
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/TicketsBot-cloud/dashboard/botcontext"
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/database"
+	"github.com/TicketsBot-cloud/gdl/rest"
+	"github.com/TicketsBot-cloud/gdl/rest/request"
+)
+
+// createdResources records the forms, panels and multi-panels created by a request which creates several at once, so
+// that they can be removed again if a later step fails, rather than being duplicated when the request is retried
+type createdResources struct {
+	FormIds       []int `json:"forms"`
+	PanelIds      []int `json:"panels"`
+	MultiPanelIds []int `json:"multi_panels"`
+
+	panels      []database.Panel
+	multiPanels []database.MultiPanel
+}
+
+func (r *createdResources) addForm(formId int) {
+	r.FormIds = append(r.FormIds, formId)
+}
+
+func (r *createdResources) addPanel(panel database.Panel) {
+	r.panels = append(r.panels, panel)
+	r.PanelIds = append(r.PanelIds, panel.PanelId)
+}
+
+// addMultiPanel records a multi-panel as soon as its message has been sent. Its ID is 0 until it has been stored.
+func (r *createdResources) addMultiPanel(multiPanel database.MultiPanel) {
+	r.multiPanels = append(r.multiPanels, multiPanel)
+}
+
+func (r *createdResources) setMultiPanelId(messageId uint64, multiPanelId int) {
+	for i, multiPanel := range r.multiPanels {
+		if multiPanel.MessageId == messageId {
+			r.multiPanels[i].Id = multiPanelId
+			r.MultiPanelIds = append(r.MultiPanelIds, multiPanelId)
+		}
+	}
+}
+
+// rollback deletes everything that was created, along with the panel messages. Anything that could not be deleted is
+// kept in the record, so that it can be reported.
+func (r *createdResources) rollback(ctx context.Context, botContext *botcontext.BotContext, guildId uint64) error {
+	var errs []error
+
+	var remainingMultiPanels []database.MultiPanel
+	r.MultiPanelIds = nil
+	for _, multiPanel := range r.multiPanels {
+		if err := deleteMessage(ctx, botContext, multiPanel.ChannelId, multiPanel.MessageId); err != nil {
+			errs = append(errs, err)
+		}
+
+		if multiPanel.Id == 0 {
+			continue
+		}
+
+		if _, err := dbclient.Client.MultiPanels.Delete(ctx, guildId, multiPanel.Id); err != nil {
+			errs = append(errs, err)
+			remainingMultiPanels = append(remainingMultiPanels, multiPanel)
+			r.MultiPanelIds = append(r.MultiPanelIds, multiPanel.Id)
+		}
+	}
+
+	r.multiPanels = remainingMultiPanels
+
+	var remainingPanels []database.Panel
+	r.PanelIds = nil
+	for _, panel := range r.panels {
+		if err := deleteMessage(ctx, botContext, panel.ChannelId, panel.MessageId); err != nil {
+			errs = append(errs, err)
+		}
+
+		if err := deletePanelRow(ctx, panel); err != nil {
+			errs = append(errs, err)
+			remainingPanels = append(remainingPanels, panel)
+			r.PanelIds = append(r.PanelIds, panel.PanelId)
+		}
+	}
+
+	r.panels = remainingPanels
+
+	var remainingForms []int
+	for _, formId := range r.FormIds {
+		if err := dbclient.Client.Forms.Delete(ctx, formId); err != nil {
+			errs = append(errs, err)
+			remainingForms = append(remainingForms, formId)
+		}
+	}
+
+	r.FormIds = remainingForms
+
+	return errors.Join(errs...)
+}
+
+// String lists the resources which were not removed, for error messages
+func (r *createdResources) String() string {
+	return fmt.Sprintf("forms %v, panels %v, multi-panels %v", r.FormIds, r.PanelIds, r.MultiPanelIds)
+}
+
+func deletePanelRow(ctx context.Context, panel database.Panel) error {
+	if panel.WelcomeMessageEmbed != nil {
+		if err := dbclient.Client.Embeds.Delete(ctx, *panel.WelcomeMessageEmbed); err != nil {
+			return err
+		}
+	}
+
+	return dbclient.Client.Panel.Delete(ctx, panel.PanelId)
+}
+
+// deleteMessage deletes a panel message, treating a message which has already been deleted as a success
+func deleteMessage(ctx context.Context, botContext *botcontext.BotContext, channelId, messageId uint64) error {
+	if err := rest.DeleteMessage(ctx, botContext.Token, botContext.RateLimiter, channelId, messageId); err != nil {
+		var unwrapped request.RestError
+		if !errors.As(err, &unwrapped) || unwrapped.StatusCode != http.StatusNotFound {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,171 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/TicketsBot-cloud/dashboard/app"
+	"github.com/TicketsBot-cloud/dashboard/app/http/audit"
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/dashboard/utils"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxFirstResponseMinutes = 7 * 24 * 60
+	maxResolutionHours      = 90 * 24
+)
+
+type slaPolicyBody struct {
+	FirstResponseMinutes *int `json:"first_response_minutes"`
+	ResolutionHours      *int `json:"resolution_hours"`
+	SupportHoursOnly     bool `json:"support_hours_only"`
+}
+
+func GetSlaPolicy(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+
+	panelId, ok := guildPanelId(c, guildId)
+	if !ok {
+		return
+	}
+
+	policy, ok, err := dbclient.Dashboard.SlaPolicies.Get(c, panelId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch SLA policy"))
+		return
+	}
+
+	if !ok {
+		c.JSON(http.StatusNotFound, utils.ErrorStr("Panel %d does not have an SLA policy", panelId))
+		return
+	}
+
+	c.JSON(http.StatusOK, policy)
+}
+
+func SetSlaPolicy(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+	userId := c.Keys["userid"].(uint64)
+
+	panelId, ok := guildPanelId(c, guildId)
+	if !ok {
+		return
+	}
+
+	var body slaPolicyBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("Invalid request body"))
+		return
+	}
+
+	if body.FirstResponseMinutes == nil && body.ResolutionHours == nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("An SLA policy must have a first response or resolution target"))
+		return
+	}
+
+	if body.FirstResponseMinutes != nil && (*body.FirstResponseMinutes < 1 || *body.FirstResponseMinutes > maxFirstResponseMinutes) {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("First response target must be between 1 and %d minutes", maxFirstResponseMinutes))
+		return
+	}
+
+	if body.ResolutionHours != nil && (*body.ResolutionHours < 1 || *body.ResolutionHours > maxResolutionHours) {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("Resolution target must be between 1 and %d hours", maxResolutionHours))
+		return
+	}
+
+	oldPolicy, existed, err := dbclient.Dashboard.SlaPolicies.Get(c, panelId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch SLA policy"))
+		return
+	}
+
+	policy := dbclient.SlaPolicy{
+		PanelId:              panelId,
+		FirstResponseMinutes: body.FirstResponseMinutes,
+		ResolutionHours:      body.ResolutionHours,
+		SupportHoursOnly:     body.SupportHoursOnly,
+	}
+
+	if err := dbclient.Dashboard.SlaPolicies.Set(c, guildId, policy); err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to save SLA policy"))
+		return
+	}
+
+	entry := audit.LogEntry{
+		GuildId:      audit.Uint64Ptr(guildId),
+		UserId:       userId,
+		ActionType:   audit.ActionSlaPolicySet,
+		ResourceType: audit.ResourceSlaPolicy,
+		ResourceId:   audit.StringPtr(strconv.Itoa(panelId)),
+		NewData:      policy,
+	}
+
+	if existed {
+		entry.OldData = oldPolicy
+	}
+
+	audit.Log(entry)
+
+	c.JSON(http.StatusOK, policy)
+}
+
+func DeleteSlaPolicy(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+	userId := c.Keys["userid"].(uint64)
+
+	panelId, ok := guildPanelId(c, guildId)
+	if !ok {
+		return
+	}
+
+	oldPolicy, existed, err := dbclient.Dashboard.SlaPolicies.Get(c, panelId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch SLA policy"))
+		return
+	}
+
+	if !existed {
+		c.JSON(http.StatusNotFound, utils.ErrorStr("Panel %d does not have an SLA policy", panelId))
+		return
+	}
+
+	if err := dbclient.Dashboard.SlaPolicies.Delete(c, panelId); err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to delete SLA policy"))
+		return
+	}
+
+	audit.Log(audit.LogEntry{
+		GuildId:      audit.Uint64Ptr(guildId),
+		UserId:       userId,
+		ActionType:   audit.ActionSlaPolicyDelete,
+		ResourceType: audit.ResourceSlaPolicy,
+		ResourceId:   audit.StringPtr(strconv.Itoa(panelId)),
+		OldData:      oldPolicy,
+	})
+
+	c.JSON(http.StatusOK, utils.SuccessResponse)
+}
+
+// guildPanelId parses the panel ID from the path and checks that the panel belongs to the guild, writing an error
+// response if not
+func guildPanelId(c *gin.Context, guildId uint64) (int, bool) {
+	panelId, err := strconv.Atoi(c.Param("panelid"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("Invalid panel ID provided: %s", c.Param("panelid")))
+		return 0, false
+	}
+
+	panel, err := dbclient.Client.Panel.GetById(c, panelId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch panel"))
+		return 0, false
+	}
+
+	if panel.GuildId != guildId {
+		c.JSON(http.StatusNotFound, utils.ErrorStr("Panel not found: %d", panelId))
+		return 0, false
+	}
+
+	return panelId, true
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/TicketsBot-cloud/dashboard/botcontext"
 	dbclient "github.com/TicketsBot-cloud/dashboard/database"
 	"github.com/TicketsBot-cloud/dashboard/rpc"
+	"github.com/TicketsBot-cloud/dashboard/supporthours"
 	"github.com/TicketsBot-cloud/dashboard/utils"
 	"github.com/TicketsBot-cloud/database"
 	"github.com/gin-gonic/gin"
@@ -19,6 +20,8 @@ import (
 
 // supportHoursResponse represents the API response format for support hours
 type supportHoursResponse struct {
+	// ScheduleId is set if the panel follows a shared schedule, rather than having its own hours
+	ScheduleId          *int                     `json:"schedule_id"`
 	Timezone            string                   `json:"timezone"`
 	Hours               []supportHoursHourConfig `json:"hours"`
 	OutOfHoursBehaviour string                   `json:"out_of_hours_behaviour"`
@@ -63,7 +66,7 @@ func GetSupportHours(c *gin.Context) {
 		return
 	}
 
-	hours, err := dbclient.Client.PanelSupportHours.GetByPanelId(c, panelId)
+	hours, err := dbclient.Dashboard.AppliedHoursOverrides.GetWeeklyHours(c, panelId)
 	if err != nil {
 		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to process request"))
 		return
@@ -76,6 +79,12 @@ func GetSupportHours(c *gin.Context) {
 		return
 	}
 
+	scheduleId, err := dbclient.Dashboard.SupportHoursSchedules.GetPanelSchedule(c, panelId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to process request"))
+		return
+	}
+
 	// Convert to response format
 	var timezone string = "Europe/London"
 	var hourConfigs []supportHoursHourConfig
@@ -108,6 +117,7 @@ func GetSupportHours(c *gin.Context) {
 	}
 
 	response := supportHoursResponse{
+		ScheduleId:          scheduleId,
 		Timezone:            timezone,
 		Hours:               hourConfigs,
 		OutOfHoursBehaviour: outOfHoursBehaviour,
@@ -127,10 +137,12 @@ type supportHoursPayload struct {
 	Enabled   bool   `json:"enabled"`
 }
 
-// supportHoursRequestBody represents the API request format for support hours
+// supportHoursRequestBody represents the API request format for support hours. If ScheduleId is set, the panel follows
+// that shared schedule, and Timezone and Hours are ignored.
 type supportHoursRequestBody struct {
-	Timezone            string                `json:"timezone" binding:"required"`
-	Hours               []supportHoursPayload `json:"hours" binding:"required"`
+	ScheduleId          *int                  `json:"schedule_id"`
+	Timezone            string                `json:"timezone"`
+	Hours               []supportHoursPayload `json:"hours"`
 	OutOfHoursBehaviour string                `json:"out_of_hours_behaviour"`
 	OutOfHoursTitle     string                `json:"out_of_hours_title"`
 	OutOfHoursMessage   string                `json:"out_of_hours_message"`
@@ -176,7 +188,7 @@ func SetSupportHours(c *gin.Context) {
 				continue // Skip the current panel we're setting hours for
 			}
 
-			hours, err := dbclient.Client.PanelSupportHours.GetByPanelId(c, panel.PanelId)
+			hours, err := dbclient.Dashboard.AppliedHoursOverrides.GetWeeklyHours(c, panel.PanelId)
 			if err != nil {
 				_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to process request"))
 				return
@@ -206,19 +218,46 @@ func SetSupportHours(c *gin.Context) {
 	}
 
 	var requestBody supportHoursRequestBody
-	if err := c.ShouldBindJSON(&requestBody); err != nil {
+	if err := c.ShouldBindJSON(&requestBody); err != nil || (requestBody.ScheduleId == nil && (requestBody.Timezone == "" || requestBody.Hours == nil)) {
 		c.JSON(http.StatusBadRequest, utils.ErrorStr("Invalid request body: timezone and hours are required"))
 		return
 	}
 
-	// Validate timezone
-	if !database.IsValidTimezone(requestBody.Timezone) {
-		c.JSON(http.StatusBadRequest, utils.ErrorStr(fmt.Sprintf("Invalid timezone: %s", requestBody.Timezone)))
-		return
+	var schedule dbclient.SupportHoursSchedule
+	var windows []dbclient.SupportHoursWindow
+	if requestBody.ScheduleId != nil {
+		var ok bool
+		schedule, ok, err = dbclient.Dashboard.SupportHoursSchedules.Get(c, guildId, *requestBody.ScheduleId)
+		if err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to process request"))
+			return
+		}
+
+		if !ok {
+			c.JSON(http.StatusNotFound, utils.ErrorStr("Support hours schedule not found: %d", *requestBody.ScheduleId))
+			return
+		}
+
+		// Record the schedule's hours in the audit log, rather than whatever was sent alongside the ID
+		requestBody.Timezone = schedule.Timezone
+		requestBody.Hours = toSupportHoursPayloads(schedule.Hours)
+	} else {
+		// Validate timezone
+		if !database.IsValidTimezone(requestBody.Timezone) {
+			c.JSON(http.StatusBadRequest, utils.ErrorStr(fmt.Sprintf("Invalid timezone: %s", requestBody.Timezone)))
+			return
+		}
+
+		// Validate all hours before replacing the existing ones
+		windows, err = parseSupportHours(requestBody.Hours)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, utils.ErrorStr("%s", err.Error()))
+			return
+		}
 	}
 
 	// Fetch existing data for audit log
-	oldHours, err := dbclient.Client.PanelSupportHours.GetByPanelId(c, panelId)
+	oldHours, err := dbclient.Dashboard.AppliedHoursOverrides.GetWeeklyHours(c, panelId)
 	if err != nil {
 		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to process request"))
 		return
@@ -230,49 +269,50 @@ func SetSupportHours(c *gin.Context) {
 		return
 	}
 
-	// Delete existing hours first
-	if err := dbclient.Client.PanelSupportHours.DeleteByPanelId(c, panelId); err != nil {
-		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to parse request data"))
-		return
-	}
-
-	// Convert request to database format and save
-	for _, req := range requestBody.Hours {
-		// Validate day of week
-		if req.DayOfWeek < 0 || req.DayOfWeek > 6 {
-			c.JSON(http.StatusBadRequest, utils.ErrorStr("Invalid day of week"))
+	if requestBody.ScheduleId != nil {
+		if err := dbclient.Dashboard.SupportHoursSchedules.LinkPanel(c, panelId, schedule); err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to process request"))
 			return
 		}
-
-		// Parse times - expecting HH:MM:SS format
-		startTime, err := time.Parse("15:04:05", req.StartTime)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, utils.ErrorStr("Invalid start time format. Please try again."))
+	} else {
+		// Inline hours replace any shared schedule the panel was following
+		if err := dbclient.Dashboard.SupportHoursSchedules.UnlinkPanel(c, panelId); err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to process request"))
 			return
 		}
 
-		endTime, err := time.Parse("15:04:05", req.EndTime)
-		if err != nil {
-			c.JSON(http.StatusBadRequest, utils.ErrorStr("Invalid end time format. Please try again."))
+		// The new hours replace any weekly hours kept while an override is applied
+		if err := dbclient.Dashboard.AppliedHoursOverrides.Clear(c, panelId); err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to process request"))
 			return
 		}
 
-		// Create database record
-		supportHours := database.PanelSupportHours{
-			PanelId:   panelId,
-			DayOfWeek: req.DayOfWeek,
-			StartTime: startTime,
-			EndTime:   endTime,
-			Enabled:   req.Enabled,
-			Timezone:  requestBody.Timezone,
+		// Delete existing hours first
+		if err := dbclient.Client.PanelSupportHours.DeleteByPanelId(c, panelId); err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to parse request data"))
+			return
 		}
 
-		if _, err := dbclient.Client.PanelSupportHours.Upsert(c, supportHours); err != nil {
-			_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to process request"))
-			return
+		for _, window := range windows {
+			// Create database record
+			supportHours := database.PanelSupportHours{
+				PanelId:   panelId,
+				DayOfWeek: window.DayOfWeek,
+				StartTime: window.StartTime,
+				EndTime:   window.EndTime,
+				Enabled:   window.Enabled,
+				Timezone:  requestBody.Timezone,
+			}
+
+			if _, err := dbclient.Client.PanelSupportHours.Upsert(c, supportHours); err != nil {
+				_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to process request"))
+				return
+			}
 		}
 	}
 
+	applySupportHoursOverride(c, panelId)
+
 	// Validate and save support hours settings
 	behaviour := requestBody.OutOfHoursBehaviour
 	if behaviour == "" {
@@ -378,7 +418,7 @@ func DeleteSupportHours(c *gin.Context) {
 	}
 
 	// Fetch existing data for audit log
-	oldHoursDelete, err := dbclient.Client.PanelSupportHours.GetByPanelId(c, panelId)
+	oldHoursDelete, err := dbclient.Dashboard.AppliedHoursOverrides.GetWeeklyHours(c, panelId)
 	if err != nil {
 		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to process request"))
 		return
@@ -390,11 +430,23 @@ func DeleteSupportHours(c *gin.Context) {
 		return
 	}
 
+	if err := dbclient.Dashboard.SupportHoursSchedules.UnlinkPanel(c, panelId); err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to process request"))
+		return
+	}
+
+	if err := dbclient.Dashboard.AppliedHoursOverrides.Clear(c, panelId); err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to process request"))
+		return
+	}
+
 	if err := dbclient.Client.PanelSupportHours.DeleteByPanelId(c, panelId); err != nil {
 		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to process request"))
 		return
 	}
 
+	applySupportHoursOverride(c, panelId)
+
 	// Also delete associated settings
 	if err := dbclient.Client.PanelSupportHoursSettings.Delete(c, panelId); err != nil {
 		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to process request"))
@@ -417,6 +469,16 @@ func DeleteSupportHours(c *gin.Context) {
 	c.JSON(http.StatusOK, utils.SuccessResponse)
 }
 
+// panelActiveResponse describes whether a panel is currently accepting tickets. The out of hours fields are only set
+// while the panel is inactive, and a date override's message replaces the configured out of hours message.
+type panelActiveResponse struct {
+	Active              bool                          `json:"active"`
+	Override            *supportHoursOverrideResponse `json:"override"`
+	OutOfHoursBehaviour *string                       `json:"out_of_hours_behaviour,omitempty"`
+	OutOfHoursTitle     string                        `json:"out_of_hours_title,omitempty"`
+	OutOfHoursMessage   string                        `json:"out_of_hours_message,omitempty"`
+}
+
 func IsPanelActive(c *gin.Context) {
 	guildId := c.Keys["guildid"].(uint64)
 
@@ -439,12 +501,55 @@ func IsPanelActive(c *gin.Context) {
 		return
 	}
 
-	// Check if panel is currently active based on support hours
-	isActive, err := dbclient.Client.PanelSupportHours.IsActiveNow(c, panelId)
+	hours, err := dbclient.Dashboard.AppliedHoursOverrides.GetWeeklyHours(c, panelId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to process request"))
+		return
+	}
+
+	overrides, err := dbclient.Dashboard.SupportHoursOverrides.GetByPanel(c, panelId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to process request"))
+		return
+	}
+
+	settings, settingsExist, err := dbclient.Client.PanelSupportHoursSettings.Get(c, panelId)
 	if err != nil {
 		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to process request"))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"active": isActive})
+	// Check if panel is currently active based on support hours and overrides
+	now := time.Now()
+	schedule := supporthours.FromPanelHours(hours).WithOverrides(supporthours.FromOverrideRows(overrides))
+
+	response := panelActiveResponse{
+		Active: schedule.IsOpen(now),
+	}
+
+	if override, ok := schedule.OverrideOn(now); ok {
+		for _, row := range overrides {
+			if row.Id == override.Id {
+				response.Override = utils.Ptr(newSupportHoursOverrideResponse(row))
+				break
+			}
+		}
+	}
+
+	if !response.Active {
+		behaviour := database.OutOfHoursBehaviourBlockCreation
+		if settingsExist {
+			behaviour = settings.OutOfHoursBehaviour
+			response.OutOfHoursTitle = settings.OutOfHoursTitle
+			response.OutOfHoursMessage = settings.OutOfHoursMessage
+		}
+
+		response.OutOfHoursBehaviour = utils.Ptr(string(behaviour))
+
+		if response.Override != nil && response.Override.Message != nil {
+			response.OutOfHoursMessage = *response.Override.Message
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
 }
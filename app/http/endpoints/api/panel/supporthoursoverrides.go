@@ -0,0 +1,313 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/TicketsBot-cloud/dashboard/app"
+	"github.com/TicketsBot-cloud/dashboard/app/http/audit"
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/dashboard/log"
+	"github.com/TicketsBot-cloud/dashboard/supporthours"
+	"github.com/TicketsBot-cloud/dashboard/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const maxSupportHoursOverrides = 100
+
+// supportHoursOverrideBody is a date-specific override of a panel's support hours. Dates are in the panel's timezone,
+// and custom hours must be given unless the panel is closed for the day.
+type supportHoursOverrideBody struct {
+	Date      string  `json:"date" binding:"required"`
+	Recurring bool    `json:"recurring"`
+	Closed    bool    `json:"closed"`
+	StartTime *string `json:"start_time"`
+	EndTime   *string `json:"end_time"`
+	Label     string  `json:"label" binding:"required"`
+	Message   *string `json:"message"`
+}
+
+// supportHoursOverrideResponse formats dates and times the same way they are accepted
+type supportHoursOverrideResponse struct {
+	Id        int     `json:"id"`
+	Date      string  `json:"date"`
+	Recurring bool    `json:"recurring"`
+	Closed    bool    `json:"closed"`
+	StartTime *string `json:"start_time"`
+	EndTime   *string `json:"end_time"`
+	Label     string  `json:"label"`
+	Message   *string `json:"message"`
+}
+
+func newSupportHoursOverrideResponse(override dbclient.SupportHoursOverride) supportHoursOverrideResponse {
+	response := supportHoursOverrideResponse{
+		Id:        override.Id,
+		Date:      override.Date.Format("2006-01-02"),
+		Recurring: override.Recurring,
+		Closed:    override.Closed,
+		Label:     override.Label,
+		Message:   override.Message,
+	}
+
+	if override.StartTime != nil {
+		response.StartTime = utils.Ptr(override.StartTime.Format("15:04:05"))
+	}
+
+	if override.EndTime != nil {
+		response.EndTime = utils.Ptr(override.EndTime.Format("15:04:05"))
+	}
+
+	return response
+}
+
+func (b supportHoursOverrideBody) toDatabase(panelId int) (dbclient.SupportHoursOverride, error) {
+	date, err := time.Parse("2006-01-02", b.Date)
+	if err != nil {
+		return dbclient.SupportHoursOverride{}, errors.New("Invalid date, expected YYYY-MM-DD")
+	}
+
+	if len(b.Label) == 0 || len(b.Label) > 100 {
+		return dbclient.SupportHoursOverride{}, errors.New("Label must be between 1 and 100 characters")
+	}
+
+	if b.Message != nil && len(*b.Message) > 500 {
+		return dbclient.SupportHoursOverride{}, errors.New("Message must be 500 characters or less")
+	}
+
+	override := dbclient.SupportHoursOverride{
+		PanelId:   panelId,
+		Date:      date,
+		Recurring: b.Recurring,
+		Closed:    b.Closed,
+		Label:     b.Label,
+		Message:   b.Message,
+	}
+
+	if b.Closed {
+		return override, nil
+	}
+
+	if b.StartTime == nil || b.EndTime == nil {
+		return dbclient.SupportHoursOverride{}, errors.New("Custom hours require a start and end time")
+	}
+
+	startTime, err := time.Parse("15:04:05", *b.StartTime)
+	if err != nil {
+		return dbclient.SupportHoursOverride{}, errors.New("Invalid start time format. Please try again.")
+	}
+
+	endTime, err := time.Parse("15:04:05", *b.EndTime)
+	if err != nil {
+		return dbclient.SupportHoursOverride{}, errors.New("Invalid end time format. Please try again.")
+	}
+
+	if !endTime.After(startTime) {
+		return dbclient.SupportHoursOverride{}, errors.New("End time must be after start time")
+	}
+
+	override.StartTime = &startTime
+	override.EndTime = &endTime
+
+	return override, nil
+}
+
+func ListSupportHoursOverrides(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+
+	panelId, ok := guildPanelId(c, guildId)
+	if !ok {
+		return
+	}
+
+	overrides, err := dbclient.Dashboard.SupportHoursOverrides.GetByPanel(c, panelId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to process request"))
+		return
+	}
+
+	response := make([]supportHoursOverrideResponse, len(overrides))
+	for i, override := range overrides {
+		response[i] = newSupportHoursOverrideResponse(override)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func CreateSupportHoursOverride(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+	userId := c.Keys["userid"].(uint64)
+
+	panelId, ok := guildPanelId(c, guildId)
+	if !ok {
+		return
+	}
+
+	var body supportHoursOverrideBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("Invalid request body: date and label are required"))
+		return
+	}
+
+	override, err := body.toDatabase(panelId)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("%s", err.Error()))
+		return
+	}
+
+	count, err := dbclient.Dashboard.SupportHoursOverrides.GetCount(c, panelId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to process request"))
+		return
+	}
+
+	if count >= maxSupportHoursOverrides {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("Panels can have at most %d support hours overrides", maxSupportHoursOverrides))
+		return
+	}
+
+	id, created, err := dbclient.Dashboard.SupportHoursOverrides.Create(c, override)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to process request"))
+		return
+	}
+
+	if !created {
+		c.JSON(http.StatusConflict, utils.ErrorStr("This panel already has an override on %s", body.Date))
+		return
+	}
+
+	override.Id = id
+
+	applySupportHoursOverride(c, panelId)
+
+	audit.Log(audit.LogEntry{
+		GuildId:      audit.Uint64Ptr(guildId),
+		UserId:       userId,
+		ActionType:   audit.ActionSupportHoursOverrideCreate,
+		ResourceType: audit.ResourceSupportHoursOverride,
+		ResourceId:   audit.StringPtr(strconv.Itoa(id)),
+		NewData:      override,
+	})
+
+	c.JSON(http.StatusOK, newSupportHoursOverrideResponse(override))
+}
+
+func UpdateSupportHoursOverride(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+	userId := c.Keys["userid"].(uint64)
+
+	panelId, ok := guildPanelId(c, guildId)
+	if !ok {
+		return
+	}
+
+	existing, ok := getSupportHoursOverride(c, panelId)
+	if !ok {
+		return
+	}
+
+	var body supportHoursOverrideBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("Invalid request body: date and label are required"))
+		return
+	}
+
+	override, err := body.toDatabase(panelId)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("%s", err.Error()))
+		return
+	}
+
+	override.Id = existing.Id
+
+	updated, err := dbclient.Dashboard.SupportHoursOverrides.Update(c, override)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to process request"))
+		return
+	}
+
+	if !updated {
+		c.JSON(http.StatusConflict, utils.ErrorStr("This panel already has an override on %s", body.Date))
+		return
+	}
+
+	applySupportHoursOverride(c, panelId)
+
+	audit.Log(audit.LogEntry{
+		GuildId:      audit.Uint64Ptr(guildId),
+		UserId:       userId,
+		ActionType:   audit.ActionSupportHoursOverrideUpdate,
+		ResourceType: audit.ResourceSupportHoursOverride,
+		ResourceId:   audit.StringPtr(strconv.Itoa(existing.Id)),
+		OldData:      existing,
+		NewData:      override,
+	})
+
+	c.JSON(http.StatusOK, newSupportHoursOverrideResponse(override))
+}
+
+func DeleteSupportHoursOverride(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+	userId := c.Keys["userid"].(uint64)
+
+	panelId, ok := guildPanelId(c, guildId)
+	if !ok {
+		return
+	}
+
+	existing, ok := getSupportHoursOverride(c, panelId)
+	if !ok {
+		return
+	}
+
+	if err := dbclient.Dashboard.SupportHoursOverrides.Delete(c, panelId, existing.Id); err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to process request"))
+		return
+	}
+
+	applySupportHoursOverride(c, panelId)
+
+	audit.Log(audit.LogEntry{
+		GuildId:      audit.Uint64Ptr(guildId),
+		UserId:       userId,
+		ActionType:   audit.ActionSupportHoursOverrideDelete,
+		ResourceType: audit.ResourceSupportHoursOverride,
+		ResourceId:   audit.StringPtr(strconv.Itoa(existing.Id)),
+		OldData:      existing,
+	})
+
+	c.JSON(http.StatusOK, utils.SuccessResponse)
+}
+
+// applySupportHoursOverride brings the hours read by the bot in line with the panel's overrides straight away, rather
+// than waiting for the sweeper, which retries on its next run if this fails
+func applySupportHoursOverride(ctx context.Context, panelId int) {
+	if err := supporthours.ApplyOverride(ctx, panelId, time.Now()); err != nil {
+		log.Logger.Error("Failed to apply support hours override", zap.Int("panel_id", panelId), zap.Error(err))
+	}
+}
+
+func getSupportHoursOverride(c *gin.Context, panelId int) (dbclient.SupportHoursOverride, bool) {
+	overrideId, err := strconv.Atoi(c.Param("overrideid"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("Invalid override ID provided: %s", c.Param("overrideid")))
+		return dbclient.SupportHoursOverride{}, false
+	}
+
+	override, ok, err := dbclient.Dashboard.SupportHoursOverrides.Get(c, panelId, overrideId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to process request"))
+		return dbclient.SupportHoursOverride{}, false
+	}
+
+	if !ok {
+		c.JSON(http.StatusNotFound, utils.ErrorStr("Support hours override not found: %d", overrideId))
+		return dbclient.SupportHoursOverride{}, false
+	}
+
+	return override, true
+}
@@ -0,0 +1,202 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/TicketsBot-cloud/dashboard/app"
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/dashboard/supporthours"
+	"github.com/TicketsBot-cloud/dashboard/utils"
+	"github.com/TicketsBot-cloud/database"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPreviewDays = 7
+	maxPreviewDays     = 60
+)
+
+// previewTime is an instant formatted in both the panel's timezone and the caller's, so that UTC offsets, and with them
+// daylight saving changes, are visible
+type previewTime struct {
+	Panel  string `json:"panel"`
+	Caller string `json:"caller"`
+}
+
+type previewInterval struct {
+	Start previewTime `json:"start"`
+	End   previewTime `json:"end"`
+}
+
+type previewOverride struct {
+	Date   string `json:"date"`
+	Label  string `json:"label"`
+	Closed bool   `json:"closed"`
+}
+
+type supportHoursPreviewResponse struct {
+	PanelTimezone  string            `json:"panel_timezone"`
+	CallerTimezone string            `json:"caller_timezone"`
+	AlwaysOpen     bool              `json:"always_open"`
+	OpenNow        bool              `json:"open_now"`
+	NextOpen       *previewTime      `json:"next_open"`
+	NextClose      *previewTime      `json:"next_close"`
+	Intervals      []previewInterval `json:"intervals"`
+	Overrides      []previewOverride `json:"overrides"`
+}
+
+// PreviewSupportHours lists when a panel will be open over the next few days. A GET previews the saved configuration,
+// while a POST previews an unsaved body in the SetSupportHours format. Either way, the panel's saved date overrides
+// are applied.
+func PreviewSupportHours(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+
+	panelId, ok := guildPanelId(c, guildId)
+	if !ok {
+		return
+	}
+
+	days := defaultPreviewDays
+	if raw, ok := c.GetQuery("days"); ok {
+		var err error
+		days, err = strconv.Atoi(raw)
+		if err != nil || days < 1 || days > maxPreviewDays {
+			c.JSON(http.StatusBadRequest, utils.ErrorStr("Days must be between 1 and %d", maxPreviewDays))
+			return
+		}
+	}
+
+	var hours []database.PanelSupportHours
+	if c.Request.Method == http.MethodPost {
+		if hours, ok = previewHoursFromBody(c, guildId, panelId); !ok {
+			return
+		}
+	} else {
+		var err error
+		hours, err = dbclient.Dashboard.AppliedHoursOverrides.GetWeeklyHours(c, panelId)
+		if err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to process request"))
+			return
+		}
+	}
+
+	overrides, err := dbclient.Dashboard.SupportHoursOverrides.GetByPanel(c, panelId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to process request"))
+		return
+	}
+
+	schedule := supporthours.FromPanelHours(hours).WithOverrides(supporthours.FromOverrideRows(overrides))
+
+	callerLocation := schedule.Location
+	if timezone := c.Query("timezone"); timezone != "" {
+		// Local is accepted by Go, but refers to the server's timezone rather than the caller's
+		if !database.IsValidTimezone(timezone) || timezone == "Local" {
+			c.JSON(http.StatusBadRequest, utils.ErrorStr("Invalid timezone: %s", timezone))
+			return
+		}
+
+		callerLocation, _ = time.LoadLocation(timezone)
+	}
+
+	format := func(t time.Time) previewTime {
+		return previewTime{
+			Panel:  t.In(schedule.Location).Format(time.RFC3339),
+			Caller: t.In(callerLocation).Format(time.RFC3339),
+		}
+	}
+
+	now := time.Now()
+	end := now.AddDate(0, 0, days)
+
+	response := supportHoursPreviewResponse{
+		PanelTimezone:  schedule.Location.String(),
+		CallerTimezone: callerLocation.String(),
+		AlwaysOpen:     schedule.IsAlwaysOpen(),
+		OpenNow:        schedule.IsOpen(now),
+		Intervals:      make([]previewInterval, 0),
+		Overrides:      make([]previewOverride, 0),
+	}
+
+	if next, ok := schedule.NextOpen(now); ok {
+		response.NextOpen = utils.Ptr(format(next))
+	}
+
+	if next, ok := schedule.NextClose(now); ok {
+		response.NextClose = utils.Ptr(format(next))
+	}
+
+	for _, interval := range schedule.Intervals(now, end) {
+		response.Intervals = append(response.Intervals, previewInterval{
+			Start: format(interval.Start),
+			End:   format(interval.End),
+		})
+	}
+
+	for day := now.In(schedule.Location); day.Before(end); day = day.AddDate(0, 0, 1) {
+		if override, ok := schedule.OverrideOn(day); ok {
+			response.Overrides = append(response.Overrides, previewOverride{
+				Date:   day.Format("2006-01-02"),
+				Label:  override.Label,
+				Closed: override.Closed,
+			})
+		}
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// previewHoursFromBody validates an unsaved support hours body, writing an error response if it is invalid
+func previewHoursFromBody(c *gin.Context, guildId uint64, panelId int) ([]database.PanelSupportHours, bool) {
+	var body supportHoursRequestBody
+	if err := c.ShouldBindJSON(&body); err != nil || (body.ScheduleId == nil && (body.Timezone == "" || body.Hours == nil)) {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("Invalid request body: timezone and hours are required"))
+		return nil, false
+	}
+
+	timezone := body.Timezone
+	var windows []dbclient.SupportHoursWindow
+	if body.ScheduleId != nil {
+		schedule, ok, err := dbclient.Dashboard.SupportHoursSchedules.Get(c, guildId, *body.ScheduleId)
+		if err != nil {
+			_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to process request"))
+			return nil, false
+		}
+
+		if !ok {
+			c.JSON(http.StatusNotFound, utils.ErrorStr("Support hours schedule not found: %d", *body.ScheduleId))
+			return nil, false
+		}
+
+		timezone = schedule.Timezone
+		windows = schedule.Hours
+	} else {
+		if !database.IsValidTimezone(body.Timezone) {
+			c.JSON(http.StatusBadRequest, utils.ErrorStr("Invalid timezone: %s", body.Timezone))
+			return nil, false
+		}
+
+		var err error
+		windows, err = parseSupportHours(body.Hours)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, utils.ErrorStr("%s", err.Error()))
+			return nil, false
+		}
+	}
+
+	hours := make([]database.PanelSupportHours, len(windows))
+	for i, window := range windows {
+		hours[i] = database.PanelSupportHours{
+			PanelId:   panelId,
+			DayOfWeek: window.DayOfWeek,
+			StartTime: window.StartTime,
+			EndTime:   window.EndTime,
+			Enabled:   window.Enabled,
+			Timezone:  timezone,
+		}
+	}
+
+	return hours, true
+}
@@ -0,0 +1,301 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/TicketsBot-cloud/dashboard/app"
+	"github.com/TicketsBot-cloud/dashboard/app/http/audit"
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/dashboard/utils"
+	"github.com/TicketsBot-cloud/database"
+	"github.com/gin-gonic/gin"
+)
+
+const maxSupportHoursSchedules = 25
+
+type supportHoursScheduleBody struct {
+	Name     string                `json:"name" binding:"required"`
+	Timezone string                `json:"timezone" binding:"required"`
+	Hours    []supportHoursPayload `json:"hours" binding:"required"`
+}
+
+type supportHoursScheduleResponse struct {
+	Id       int                      `json:"id"`
+	Name     string                   `json:"name"`
+	Timezone string                   `json:"timezone"`
+	Hours    []supportHoursHourConfig `json:"hours"`
+	PanelIds []int                    `json:"panel_ids"`
+}
+
+func newSupportHoursScheduleResponse(schedule dbclient.SupportHoursSchedule) supportHoursScheduleResponse {
+	hours := make([]supportHoursHourConfig, len(schedule.Hours))
+	for i, payload := range toSupportHoursPayloads(schedule.Hours) {
+		hours[i] = supportHoursHourConfig(payload)
+	}
+
+	return supportHoursScheduleResponse{
+		Id:       schedule.Id,
+		Name:     schedule.Name,
+		Timezone: schedule.Timezone,
+		Hours:    hours,
+		PanelIds: schedule.PanelIds,
+	}
+}
+
+// parseSupportHours validates the hours in a request, so that they can be saved all at once
+func parseSupportHours(payloads []supportHoursPayload) ([]dbclient.SupportHoursWindow, error) {
+	seen := make(map[int]bool)
+
+	windows := make([]dbclient.SupportHoursWindow, 0, len(payloads))
+	for _, req := range payloads {
+		// Validate day of week
+		if req.DayOfWeek < 0 || req.DayOfWeek > 6 {
+			return nil, errors.New("Invalid day of week")
+		}
+
+		if seen[req.DayOfWeek] {
+			return nil, errors.New("Each day of the week can only be configured once")
+		}
+
+		seen[req.DayOfWeek] = true
+
+		// Parse times - expecting HH:MM:SS format
+		startTime, err := time.Parse("15:04:05", req.StartTime)
+		if err != nil {
+			return nil, errors.New("Invalid start time format. Please try again.")
+		}
+
+		endTime, err := time.Parse("15:04:05", req.EndTime)
+		if err != nil {
+			return nil, errors.New("Invalid end time format. Please try again.")
+		}
+
+		windows = append(windows, dbclient.SupportHoursWindow{
+			DayOfWeek: req.DayOfWeek,
+			StartTime: startTime,
+			EndTime:   endTime,
+			Enabled:   req.Enabled,
+		})
+	}
+
+	return windows, nil
+}
+
+func toSupportHoursPayloads(windows []dbclient.SupportHoursWindow) []supportHoursPayload {
+	payloads := make([]supportHoursPayload, len(windows))
+	for i, window := range windows {
+		payloads[i] = supportHoursPayload{
+			DayOfWeek: window.DayOfWeek,
+			StartTime: window.StartTime.Format("15:04:05"),
+			EndTime:   window.EndTime.Format("15:04:05"),
+			Enabled:   window.Enabled,
+		}
+	}
+
+	return payloads
+}
+
+// toSchedule validates the request body, writing an error response if it is invalid
+func (b supportHoursScheduleBody) toSchedule(c *gin.Context, guildId uint64, id int) (dbclient.SupportHoursSchedule, bool) {
+	if len(b.Name) == 0 || len(b.Name) > 100 {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("Schedule name must be between 1 and 100 characters"))
+		return dbclient.SupportHoursSchedule{}, false
+	}
+
+	if !database.IsValidTimezone(b.Timezone) {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("Invalid timezone: %s", b.Timezone))
+		return dbclient.SupportHoursSchedule{}, false
+	}
+
+	windows, err := parseSupportHours(b.Hours)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("%s", err.Error()))
+		return dbclient.SupportHoursSchedule{}, false
+	}
+
+	exists, err := dbclient.Dashboard.SupportHoursSchedules.NameExists(c, guildId, b.Name, id)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to process request"))
+		return dbclient.SupportHoursSchedule{}, false
+	}
+
+	if exists {
+		c.JSON(http.StatusConflict, utils.ErrorStr("A schedule with this name already exists"))
+		return dbclient.SupportHoursSchedule{}, false
+	}
+
+	return dbclient.SupportHoursSchedule{
+		Id:       id,
+		GuildId:  guildId,
+		Name:     b.Name,
+		Timezone: b.Timezone,
+		Hours:    windows,
+	}, true
+}
+
+func ListSupportHoursSchedules(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+
+	schedules, err := dbclient.Dashboard.SupportHoursSchedules.GetByGuild(c, guildId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to process request"))
+		return
+	}
+
+	response := make([]supportHoursScheduleResponse, len(schedules))
+	for i, schedule := range schedules {
+		response[i] = newSupportHoursScheduleResponse(schedule)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func GetSupportHoursSchedule(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+
+	schedule, ok := getSupportHoursSchedule(c, guildId)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, newSupportHoursScheduleResponse(schedule))
+}
+
+func CreateSupportHoursSchedule(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+	userId := c.Keys["userid"].(uint64)
+
+	var body supportHoursScheduleBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("Invalid request body: name, timezone and hours are required"))
+		return
+	}
+
+	count, err := dbclient.Dashboard.SupportHoursSchedules.GetCount(c, guildId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to process request"))
+		return
+	}
+
+	if count >= maxSupportHoursSchedules {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("Servers can have at most %d support hours schedules", maxSupportHoursSchedules))
+		return
+	}
+
+	schedule, ok := body.toSchedule(c, guildId, 0)
+	if !ok {
+		return
+	}
+
+	schedule.Id, err = dbclient.Dashboard.SupportHoursSchedules.Create(c, schedule)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to process request"))
+		return
+	}
+
+	schedule.PanelIds = make([]int, 0)
+
+	audit.Log(audit.LogEntry{
+		GuildId:      audit.Uint64Ptr(guildId),
+		UserId:       userId,
+		ActionType:   audit.ActionSupportHoursScheduleCreate,
+		ResourceType: audit.ResourceSupportHoursSchedule,
+		ResourceId:   audit.StringPtr(strconv.Itoa(schedule.Id)),
+		NewData:      schedule,
+	})
+
+	c.JSON(http.StatusOK, newSupportHoursScheduleResponse(schedule))
+}
+
+// UpdateSupportHoursSchedule replaces the schedule, and with it the hours of every panel that follows it
+func UpdateSupportHoursSchedule(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+	userId := c.Keys["userid"].(uint64)
+
+	existing, ok := getSupportHoursSchedule(c, guildId)
+	if !ok {
+		return
+	}
+
+	var body supportHoursScheduleBody
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("Invalid request body: name, timezone and hours are required"))
+		return
+	}
+
+	schedule, ok := body.toSchedule(c, guildId, existing.Id)
+	if !ok {
+		return
+	}
+
+	panelIds, err := dbclient.Dashboard.SupportHoursSchedules.Update(c, schedule)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to process request"))
+		return
+	}
+
+	schedule.PanelIds = panelIds
+
+	audit.Log(audit.LogEntry{
+		GuildId:      audit.Uint64Ptr(guildId),
+		UserId:       userId,
+		ActionType:   audit.ActionSupportHoursScheduleUpdate,
+		ResourceType: audit.ResourceSupportHoursSchedule,
+		ResourceId:   audit.StringPtr(strconv.Itoa(schedule.Id)),
+		OldData:      existing,
+		NewData:      schedule,
+	})
+
+	c.JSON(http.StatusOK, newSupportHoursScheduleResponse(schedule))
+}
+
+// DeleteSupportHoursSchedule removes the schedule. Panels which followed it keep its hours as their own.
+func DeleteSupportHoursSchedule(c *gin.Context) {
+	guildId := c.Keys["guildid"].(uint64)
+	userId := c.Keys["userid"].(uint64)
+
+	existing, ok := getSupportHoursSchedule(c, guildId)
+	if !ok {
+		return
+	}
+
+	if err := dbclient.Dashboard.SupportHoursSchedules.Delete(c, guildId, existing.Id); err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to process request"))
+		return
+	}
+
+	audit.Log(audit.LogEntry{
+		GuildId:      audit.Uint64Ptr(guildId),
+		UserId:       userId,
+		ActionType:   audit.ActionSupportHoursScheduleDelete,
+		ResourceType: audit.ResourceSupportHoursSchedule,
+		ResourceId:   audit.StringPtr(strconv.Itoa(existing.Id)),
+		OldData:      existing,
+	})
+
+	c.JSON(http.StatusOK, utils.SuccessResponse)
+}
+
+func getSupportHoursSchedule(c *gin.Context, guildId uint64) (dbclient.SupportHoursSchedule, bool) {
+	scheduleId, err := strconv.Atoi(c.Param("scheduleid"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("Invalid schedule ID provided: %s", c.Param("scheduleid")))
+		return dbclient.SupportHoursSchedule{}, false
+	}
+
+	schedule, ok, err := dbclient.Dashboard.SupportHoursSchedules.Get(c, guildId, scheduleId)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to process request"))
+		return dbclient.SupportHoursSchedule{}, false
+	}
+
+	if !ok {
+		c.JSON(http.StatusNotFound, utils.ErrorStr("Support hours schedule not found: %d", scheduleId))
+		return dbclient.SupportHoursSchedule{}, false
+	}
+
+	return schedule, true
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 	"unicode/utf8"
@@ -20,6 +21,7 @@ import (
 	"github.com/TicketsBot-cloud/gdl/objects/channel"
 	"github.com/TicketsBot-cloud/gdl/objects/guild"
 	"github.com/TicketsBot-cloud/gdl/objects/interaction/component"
+	"github.com/go-playground/validator/v10"
 )
 
 func ApplyPanelDefaults(data *panelBody) {
@@ -56,29 +58,114 @@ func ValidatePanelBody(validationContext PanelValidationContext) error {
 	return validation.Validate(ctx, validationContext, panelValidators()...)
 }
 
+// validatePanelForCreate runs the custom and tag validation that a panel must pass before it is created, returning the
+// body with the ticket notification channel cleared if threads are not used. Invalid input is returned as a
+// *validation.InvalidInputError, and any other error, such as failing to reach Discord, as-is.
+func validatePanelForCreate(validationContext PanelValidationContext) (panelBody, error) {
+	if err := ValidatePanelBody(validationContext); err != nil {
+		return panelBody{}, err
+	}
+
+	data := validationContext.Data
+	if !data.UseThreads {
+		data.TicketNotificationChannel = nil
+	}
+
+	if err := validate.Struct(data); err != nil {
+		var validationErrors validator.ValidationErrors
+		if !errors.As(err, &validationErrors) {
+			return panelBody{}, err
+		}
+
+		return panelBody{}, validation.NewInvalidInputError("Your input contained the following errors:\n" + utils.FormatValidationErrors(validationErrors))
+	}
+
+	return data, nil
+}
+
+// collectPanelErrors runs every check that a panel must pass before it is created or updated, returning all input
+// errors at once, for dry runs. Any other error, such as failing to reach Discord, is returned as err.
+func collectPanelErrors(validationContext PanelValidationContext) ([]*validation.InvalidInputError, error) {
+	ctx, cancelFunc := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancelFunc()
+
+	errs, err := validation.ValidateAll(ctx, validationContext, panelValidators()...)
+	if err != nil {
+		return nil, err
+	}
+
+	data := validationContext.Data
+	if !data.UseThreads {
+		data.TicketNotificationChannel = nil
+	}
+
+	structErrs, err := structValidationErrors(validate.Struct(data))
+	if err != nil {
+		return nil, err
+	}
+
+	errs = append(errs, structErrs...)
+
+	for _, mention := range data.Mentions {
+		if mention == "user" || mention == "here" {
+			continue
+		}
+
+		if _, err := strconv.ParseUint(mention, 10, 64); err != nil {
+			errs = append(errs, validation.NewFieldError("mentions", fmt.Sprintf("Invalid role ID in mentions: %s", mention)))
+		}
+	}
+
+	return errs, nil
+}
+
+// structValidationErrors converts the result of validate.Struct into field errors
+func structValidationErrors(err error) ([]*validation.InvalidInputError, error) {
+	if err == nil {
+		return nil, nil
+	}
+
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil, err
+	}
+
+	errs := make([]*validation.InvalidInputError, len(validationErrors))
+	for i, fieldError := range validationErrors {
+		field := fieldError.Namespace()
+		if _, after, ok := strings.Cut(field, "."); ok {
+			field = after
+		}
+
+		errs[i] = validation.NewFieldError(field, utils.FormatValidationError(fieldError))
+	}
+
+	return errs, nil
+}
+
 func panelValidators() []validation.Validator[PanelValidationContext] {
 	return []validation.Validator[PanelValidationContext]{
-		validateTitle,
-		validateContent,
-		validateChannelId,
-		validateCategory,
-		validateEmoji,
-		validateImageUrl,
-		validateThumbnailUrl,
-		validateButtonStyle,
-		validateButtonLabel,
-		validateButtonLabelOrEmoji,
-		validateFormId,
-		validateExitSurveyFormId,
-		validateTeams,
-		validateNamingScheme,
-		validateWelcomeMessage,
-		validateAccessControlList,
-		validatePendingCategory,
-		validateTranscriptChannelId,
-		validateTicketNotificationChannel,
-		validateCooldownSeconds,
-		validateTicketLimit,
+		validation.Field("title", validateTitle),
+		validation.Field("content", validateContent),
+		validation.Field("channel_id", validateChannelId),
+		validation.Field("category_id", validateCategory),
+		validation.Field("emote", validateEmoji),
+		validation.Field("image_url", validateImageUrl),
+		validation.Field("thumbnail_url", validateThumbnailUrl),
+		validation.Field("button_style", validateButtonStyle),
+		validation.Field("button_label", validateButtonLabel),
+		validation.Field("button_label", validateButtonLabelOrEmoji),
+		validation.Field("form_id", validateFormId),
+		validation.Field("exit_survey_form_id", validateExitSurveyFormId),
+		validation.Field("teams", validateTeams),
+		validation.Field("naming_scheme", validateNamingScheme),
+		validation.Field("welcome_message", validateWelcomeMessage),
+		validation.Field("access_control_list", validateAccessControlList),
+		validation.Field("pending_category", validatePendingCategory),
+		validation.Field("transcript_channel_id", validateTranscriptChannelId),
+		validation.Field("ticket_notification_channel", validateTicketNotificationChannel),
+		validation.Field("cooldown_seconds", validateCooldownSeconds),
+		validation.Field("ticket_limit", validateTicketLimit),
 	}
 }
 
@@ -0,0 +1,209 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+
+	"github.com/TicketsBot-cloud/common/premium"
+	"github.com/TicketsBot-cloud/dashboard/app/http/audit"
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/dashboard/log"
+	"github.com/TicketsBot-cloud/dashboard/utils"
+	"github.com/TicketsBot-cloud/database"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const historyPageSize = 25
+
+type settingsVersionResponse struct {
+	dbclient.SettingsVersion
+	Changes []audit.FieldChange `json:"changes"`
+}
+
+// rollbackBody selects which side of the version to apply. By default, the settings saved by the version are
+// re-applied. If Before is set, the settings as they were before the version are applied instead, undoing it.
+type rollbackBody struct {
+	Before bool `json:"before"`
+}
+
+// RecordHistory stores a settings change, so that it can later be rolled back. The new settings are recorded as Apply
+// saved them, given its result: skipped fields, and colours for guilds without premium, keep their old values.
+func RecordHistory(ctx context.Context, guildId, userId uint64, old, new Settings, result SettingsApplyResult, premiumTier premium.PremiumTier, rollbackOf *int) (int, error) {
+	oldData, err := json.Marshal(old)
+	if err != nil {
+		return 0, err
+	}
+
+	newData, err := json.Marshal(savedSettings(old, new, result, premiumTier))
+	if err != nil {
+		return 0, err
+	}
+
+	return dbclient.Dashboard.SettingsHistory.Create(ctx, dbclient.SettingsVersion{
+		GuildId:    guildId,
+		UserId:     userId,
+		RollbackOf: rollbackOf,
+		OldData:    oldData,
+		NewData:    newData,
+	})
+}
+
+// savedSettings returns the settings as they were saved by Apply, which leaves fields that it skips unchanged
+func savedSettings(old, new Settings, result SettingsApplyResult, premiumTier premium.PremiumTier) Settings {
+	saved := new
+
+	if !result.WelcomeMessage {
+		saved.WelcomeMessage = old.WelcomeMessage
+	}
+
+	if !result.TicketLimit {
+		saved.TicketLimit = old.TicketLimit
+	}
+
+	if !result.ArchiveChannel {
+		saved.ArchiveChannel = old.ArchiveChannel
+	}
+
+	if !result.Category {
+		saved.Category = old.Category
+	}
+
+	if !result.NamingScheme {
+		saved.NamingScheme = old.NamingScheme
+	}
+
+	if premiumTier == premium.None {
+		saved.Colours = old.Colours
+	}
+
+	return saved
+}
+
+func GetSettingsHistory(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+
+	page, err := strconv.Atoi(ctx.Query("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	versions, err := dbclient.Dashboard.SettingsHistory.GetByGuild(ctx, guildId, historyPageSize, (page-1)*historyPageSize)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorStr("Failed to fetch settings history. Please try again."))
+		return
+	}
+
+	count, err := dbclient.Dashboard.SettingsHistory.GetCount(ctx, guildId)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorStr("Failed to fetch settings history. Please try again."))
+		return
+	}
+
+	entries := make([]settingsVersionResponse, len(versions))
+	for i, version := range versions {
+		entries[i] = settingsVersionResponse{
+			SettingsVersion: version,
+			Changes:         audit.DiffFields("", version.OldData, version.NewData),
+		}
+	}
+
+	totalPages := (count + historyPageSize - 1) / historyPageSize
+	if totalPages == 0 {
+		totalPages = 1
+	}
+
+	ctx.JSON(200, gin.H{
+		"versions":     entries,
+		"total_pages":  totalPages,
+		"current_page": page,
+	})
+}
+
+// RollbackSettings re-applies a previous version through the same validation and update path as
+// UpdateSettingsHandler. Validation is against the guild as it is now, so a version referencing a since deleted
+// channel may no longer be applied.
+func RollbackSettings(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+	userId := ctx.Keys["userid"].(uint64)
+
+	versionId, err := strconv.Atoi(ctx.Param("versionid"))
+	if err != nil {
+		ctx.JSON(400, utils.ErrorStr("Invalid version ID"))
+		return
+	}
+
+	var body rollbackBody
+	if err := ctx.ShouldBindJSON(&body); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(400, utils.ErrorStr("Invalid request data. Please check your input and try again."))
+		return
+	}
+
+	version, ok, err := dbclient.Dashboard.SettingsHistory.Get(ctx, guildId, versionId)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorStr("Failed to fetch settings history. Please try again."))
+		return
+	}
+
+	if !ok {
+		ctx.JSON(404, utils.ErrorStr("Settings version not found"))
+		return
+	}
+
+	data := version.NewData
+	if body.Before {
+		data = version.OldData
+	}
+
+	var settings Settings
+	if err := json.Unmarshal(data, &settings); err != nil {
+		ctx.JSON(500, utils.ErrorStr("Failed to read settings version. Please try again."))
+		return
+	}
+
+	if settings.Colours == nil {
+		settings.Colours = make(ColourMap)
+	}
+
+	channels, premiumTier, ok := fetchUpdateContext(ctx, guildId)
+	if !ok {
+		return
+	}
+
+	if err := settings.Validate(ctx, guildId, premiumTier); err != nil {
+		ctx.JSON(400, utils.ErrorStr("This version can no longer be applied: %v", err))
+		return
+	}
+
+	oldSettings, err := LoadSettings(ctx, guildId)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorStr("Failed to save settings. Please try again."))
+		return
+	}
+
+	result, err := settings.Apply(ctx, guildId, premiumTier, channels)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorStr("Failed to save settings. Please try again."))
+		return
+	}
+
+	if _, err := RecordHistory(ctx, guildId, userId, oldSettings, settings, result, premiumTier, &versionId); err != nil {
+		log.Logger.Error("Failed to record settings history", zap.Uint64("guild_id", guildId), zap.Error(err))
+	}
+
+	audit.Log(audit.LogEntry{
+		GuildId:      audit.Uint64Ptr(guildId),
+		UserId:       userId,
+		ActionType:   audit.ActionSettingsRollback,
+		ResourceType: database.AuditResourceSettings,
+		ResourceId:   audit.StringPtr(strconv.Itoa(versionId)),
+		OldData:      oldSettings,
+		NewData:      settings,
+		Metadata:     body,
+	})
+
+	ctx.JSON(200, result)
+}
@@ -0,0 +1,49 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/TicketsBot-cloud/common/premium"
+	"github.com/TicketsBot-cloud/dashboard/utils"
+	"github.com/TicketsBot-cloud/database"
+	"github.com/TicketsBot-cloud/worker/bot/customisation"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSavedSettings(t *testing.T) {
+	old := Settings{
+		WelcomeMessage: "Hello",
+		TicketLimit:    5,
+		Category:       1,
+		NamingScheme:   database.Id,
+		Colours:        ColourMap{customisation.Green: 0x00ff00},
+	}
+
+	new := Settings{
+		WelcomeMessage: "",
+		TicketLimit:    3,
+		Category:       2,
+		ArchiveChannel: utils.Ptr(uint64(3)),
+		NamingScheme:   database.Username,
+		Colours:        ColourMap{customisation.Green: 0x0000ff},
+	}
+
+	result := SettingsApplyResult{
+		WelcomeMessage: false,
+		TicketLimit:    true,
+		ArchiveChannel: false,
+		Category:       true,
+		NamingScheme:   true,
+	}
+
+	saved := savedSettings(old, new, result, premium.None)
+	assert.Equal(t, "Hello", saved.WelcomeMessage)
+	assert.Equal(t, uint8(3), saved.TicketLimit)
+	assert.Nil(t, saved.ArchiveChannel)
+	assert.Equal(t, uint64(2), saved.Category)
+	assert.Equal(t, database.Username, saved.NamingScheme)
+	assert.Equal(t, old.Colours, saved.Colours)
+
+	saved = savedSettings(old, new, result, premium.Premium)
+	assert.Equal(t, new.Colours, saved.Colours)
+}
@@ -42,7 +42,8 @@ type (
 	ColourMap map[customisation.Colour]utils.HexColour
 )
 
-func loadSettings(ctx context.Context, guildId uint64) (Settings, error) {
+// LoadSettings fetches all of the guild's settings, in the same shape as they are submitted to UpdateSettingsHandler
+func LoadSettings(ctx context.Context, guildId uint64) (Settings, error) {
 	var settings Settings
 
 	group, _ := errgroup.WithContext(ctx)
@@ -148,7 +149,7 @@ func loadSettings(ctx context.Context, guildId uint64) (Settings, error) {
 func GetSettingsHandler(ctx *gin.Context) {
 	guildId := ctx.Keys["guildid"].(uint64)
 
-	settings, err := loadSettings(ctx, guildId)
+	settings, err := LoadSettings(ctx, guildId)
 	if err != nil {
 		ctx.JSON(500, utils.ErrorStr("Failed to process request. Please try again."))
 		return
@@ -2,14 +2,15 @@ package api
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"time"
 
 	"github.com/TicketsBot-cloud/common/premium"
 	"github.com/TicketsBot-cloud/dashboard/app/http/audit"
+	"github.com/TicketsBot-cloud/dashboard/app/http/validation"
 	"github.com/TicketsBot-cloud/dashboard/botcontext"
 	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/dashboard/log"
 	"github.com/TicketsBot-cloud/dashboard/rpc"
 	"github.com/TicketsBot-cloud/dashboard/rpc/cache"
 	"github.com/TicketsBot-cloud/dashboard/utils"
@@ -18,6 +19,7 @@ import (
 	"github.com/TicketsBot-cloud/worker/bot/customisation"
 	"github.com/TicketsBot-cloud/worker/i18n"
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -31,87 +33,130 @@ func UpdateSettingsHandler(ctx *gin.Context) {
 		return
 	}
 
-	// Get a list of all channel IDs
+	channels, premiumTier, ok := fetchUpdateContext(ctx, guildId)
+	if !ok {
+		return
+	}
+
+	if ctx.Query("dry_run") == "true" {
+		errs, err := settings.collectErrors(ctx, guildId, premiumTier)
+		if err != nil {
+			ctx.JSON(500, utils.ErrorStr("Failed to validate settings. Please try again."))
+			return
+		}
+
+		result := validation.NewDryRunResult(errs).WithWarnings(settings.skippedFields(channels))
+		ctx.JSON(result.StatusCode(), result)
+		return
+	}
+
+	if err := settings.Validate(ctx, guildId, premiumTier); err != nil {
+		ctx.JSON(400, utils.ErrorStr("%v", err))
+		return
+	}
+
+	// Fetch current settings before mutation for audit diff
+	oldSettings, err := LoadSettings(ctx, guildId)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorStr("Failed to save settings. Please try again."))
+		return
+	}
+
+	result, err := settings.Apply(ctx, guildId, premiumTier, channels)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorStr("Failed to save settings. Please try again."))
+		return
+	}
+
+	if _, err := RecordHistory(ctx, guildId, userId, oldSettings, settings, result, premiumTier, nil); err != nil {
+		log.Logger.Error("Failed to record settings history", zap.Uint64("guild_id", guildId), zap.Error(err))
+	}
+
+	audit.Log(audit.LogEntry{
+		GuildId:      audit.Uint64Ptr(guildId),
+		UserId:       userId,
+		ActionType:   database.AuditActionSettingsUpdate,
+		ResourceType: database.AuditResourceSettings,
+		OldData:      oldSettings,
+		NewData:      settings,
+	})
+
+	ctx.JSON(200, result)
+}
+
+// fetchUpdateContext fetches the guild's channels and premium tier, which are needed to validate and apply settings.
+// If this fails, the error response has already been written.
+func fetchUpdateContext(ctx *gin.Context, guildId uint64) ([]channel.Channel, premium.PremiumTier, bool) {
 	botContext, err := botcontext.ContextForGuild(guildId)
 	if err != nil {
 		ctx.JSON(500, utils.ErrorStr("Unable to connect to Discord. Please try again later."))
-		return
+		return nil, premium.None, false
 	}
 
 	// TODO: Use proper context
 	channels, err := botContext.GetGuildChannels(context.Background(), guildId)
 	if err != nil {
 		ctx.JSON(500, utils.ErrorStr("Unable to fetch your server's channels. Please try again."))
-		return
+		return nil, premium.None, false
 	}
 
 	// Includes voting
 	premiumTier, err := rpc.PremiumClient.GetTierByGuildId(ctx, guildId, true, botContext.Token, botContext.RateLimiter)
 	if err != nil {
 		ctx.JSON(500, utils.ErrorStr("Unable to verify premium status. Please try again."))
-		return
+		return nil, premium.None, false
 	}
 
-	if err := settings.Validate(ctx, guildId, premiumTier); err != nil {
-		ctx.JSON(400, utils.ErrorStr("%v", err))
-		return
-	}
+	return channels, premiumTier, true
+}
 
-	// Fetch current settings before mutation for audit diff
-	oldSettings, err := loadSettings(ctx, guildId)
-	if err != nil {
-		ctx.JSON(500, utils.ErrorStr("Failed to save settings. Please try again."))
-		return
-	}
+// SettingsApplyResult reports whether each of the leniently validated settings was saved. An invalid value for one of
+// these is skipped, rather than failing the whole update.
+type SettingsApplyResult struct {
+	WelcomeMessage bool `json:"welcome_message"`
+	TicketLimit    bool `json:"ticket_limit"`
+	ArchiveChannel bool `json:"archive_channel"`
+	Category       bool `json:"category"`
+	NamingScheme   bool `json:"naming_scheme"`
+}
 
+// Apply saves the settings, which must already have been checked with Validate
+func (s *Settings) Apply(ctx context.Context, guildId uint64, premiumTier premium.PremiumTier, channels []channel.Channel) (SettingsApplyResult, error) {
 	group, _ := errgroup.WithContext(context.Background())
 
 	group.Go(func() error {
-		return settings.updateSettings(ctx, guildId)
+		return s.updateSettings(ctx, guildId)
 	})
 
 	group.Go(func() error {
-		return settings.updateClaimSettings(ctx, guildId)
+		return s.updateClaimSettings(ctx, guildId)
 	})
 
-	addToWaitGroup(group, guildId, settings.updateTicketPermissions)
-	addToWaitGroup(group, guildId, settings.updateLanguage)
-	addToWaitGroup(group, guildId, settings.updateAutoClose)
+	addToWaitGroup(group, guildId, s.updateTicketPermissions)
+	addToWaitGroup(group, guildId, s.updateLanguage)
+	addToWaitGroup(group, guildId, s.updateAutoClose)
 
 	if premiumTier > premium.None {
-		addToWaitGroup(group, guildId, settings.updateColours)
+		addToWaitGroup(group, guildId, s.updateColours)
 	}
 
 	if err := group.Wait(); err != nil {
-		ctx.JSON(500, utils.ErrorStr("Failed to save settings. Please try again."))
-		return
+		return SettingsApplyResult{}, err
 	}
 
-	validWelcomeMessage := settings.updateWelcomeMessage(guildId)
-	validTicketLimit := settings.updateTicketLimit(guildId)
-	validArchiveChannel := settings.updateArchiveChannel(channels, guildId)
-	validCategory := settings.updateCategory(channels, guildId)
-	validNamingScheme := settings.updateNamingScheme(guildId)
-	settings.updateUsersCanClose(guildId)
-	settings.updateCloseConfirmation(guildId)
-	settings.updateFeedbackEnabled(guildId)
+	result := SettingsApplyResult{
+		WelcomeMessage: s.updateWelcomeMessage(guildId),
+		TicketLimit:    s.updateTicketLimit(guildId),
+		ArchiveChannel: s.updateArchiveChannel(channels, guildId),
+		Category:       s.updateCategory(channels, guildId),
+		NamingScheme:   s.updateNamingScheme(guildId),
+	}
 
-	audit.Log(audit.LogEntry{
-		GuildId:      audit.Uint64Ptr(guildId),
-		UserId:       userId,
-		ActionType:   database.AuditActionSettingsUpdate,
-		ResourceType: database.AuditResourceSettings,
-		OldData:      oldSettings,
-		NewData:      settings,
-	})
+	s.updateUsersCanClose(guildId)
+	s.updateCloseConfirmation(guildId)
+	s.updateFeedbackEnabled(guildId)
 
-	ctx.JSON(200, gin.H{
-		"welcome_message": validWelcomeMessage,
-		"ticket_limit":    validTicketLimit,
-		"archive_channel": validArchiveChannel,
-		"category":        validCategory,
-		"naming_scheme":   validNamingScheme,
-	})
+	return result, nil
 }
 
 func (s *Settings) updateSettings(ctx context.Context, guildId uint64) error {
@@ -128,25 +173,44 @@ var (
 )
 
 func (s *Settings) Validate(ctx context.Context, guildId uint64, premiumTier premium.PremiumTier) error {
+	errs, err := s.collectErrors(ctx, guildId, premiumTier)
+	if err != nil {
+		return err
+	}
+
+	if len(errs) > 0 {
+		return errs[0]
+	}
+
+	return nil
+}
+
+// collectErrors runs every check in Validate, returning all input errors at once rather than only the first. Any
+// other error, such as failing to query the database, is returned as err. Like Validate, it normalises fields that
+// are ignored for the guild's premium tier, or by other settings.
+func (s *Settings) collectErrors(ctx context.Context, guildId uint64, premiumTier premium.PremiumTier) ([]*validation.InvalidInputError, error) {
+	errs := make([]*validation.InvalidInputError, 0)
+
 	// Sync checks
 	if s.ClaimSettings.SupportCanType && !s.ClaimSettings.SupportCanView {
-		return errors.New("Must be able to view channel to type")
+		errs = append(errs, validation.NewFieldError("claim_settings.support_can_type", "Must be able to view channel to type"))
 	}
 
 	if s.Settings.UseThreads && s.TicketNotificationChannel == nil {
-		return errors.New("You must select a ticket notification channel")
+		errs = append(errs, validation.NewFieldError("ticket_notification_channel", "You must select a ticket notification channel"))
 	}
 
 	// Check if any panels depend on global thread mode before disabling it
 	if !s.Settings.UseThreads {
 		panels, err := dbclient.Client.Panel.GetByGuild(ctx, guildId)
 		if err != nil {
-			return fmt.Errorf("Failed to check panel dependencies: %w", err)
+			return nil, fmt.Errorf("Failed to check panel dependencies: %w", err)
 		}
 
 		for _, panel := range panels {
 			if panel.UseThreads && panel.TicketNotificationChannel == nil {
-				return errors.New("Cannot disable global thread mode: panel \"" + panel.Title + "\" is using the global notification channel setting. Please configure a notification channel for this panel first, or disable thread mode on the panel.")
+				errs = append(errs, validation.NewFieldError("use_threads", "Cannot disable global thread mode: panel \""+panel.Title+"\" is using the global notification channel setting. Please configure a notification channel for this panel first, or disable thread mode on the panel."))
+				break
 			}
 		}
 
@@ -157,33 +221,35 @@ func (s *Settings) Validate(ctx context.Context, guildId uint64, premiumTier pre
 	if s.TicketNotificationChannel == nil && s.Settings.UseThreads {
 		panels, err := dbclient.Client.Panel.GetByGuild(ctx, guildId)
 		if err != nil {
-			return fmt.Errorf("Failed to check panel dependencies: %w", err)
+			return nil, fmt.Errorf("Failed to check panel dependencies: %w", err)
 		}
 
 		for _, panel := range panels {
 			if panel.UseThreads && panel.TicketNotificationChannel == nil {
-				return errors.New("Cannot remove global notification channel: panel \"" + panel.Title + "\" is using it. Please configure a notification channel for this panel first, or disable thread mode on the panel.")
+				errs = append(errs, validation.NewFieldError("ticket_notification_channel", "Cannot remove global notification channel: panel \""+panel.Title+"\" is using it. Please configure a notification channel for this panel first, or disable thread mode on the panel."))
+				break
 			}
 		}
 	}
 
 	if s.Language != nil {
 		if _, ok := i18n.MappedByIsoShortCode[*s.Language]; !ok {
-			return errors.New("Invalid language")
+			errs = append(errs, validation.NewFieldError("language", "Invalid language"))
 		}
 	}
 
 	// Validate colours
-	if len(s.Colours) > len(activeColours) {
-		return errors.New("Invalid colour")
-	}
-
+	validColours := len(s.Colours) <= len(activeColours)
 	for colour := range s.Colours {
 		if !utils.Exists(activeColours, colour) {
-			return errors.New("Invalid colour")
+			validColours = false
 		}
 	}
 
+	if !validColours {
+		errs = append(errs, validation.NewFieldError("colours", "Invalid colour"))
+	}
+
 	for _, colourCode := range activeColours {
 		if _, ok := s.Colours[colourCode]; !ok {
 			s.Colours[colourCode] = utils.HexColour(customisation.DefaultColours[colourCode])
@@ -212,10 +278,11 @@ func (s *Settings) Validate(ctx context.Context, guildId uint64, premiumTier pre
 
 	if s.AutoCloseSettings.SinceLastMessage > int64((time.Hour*24*60).Seconds()) ||
 		s.AutoCloseSettings.SinceOpenWithNoResponse > int64((time.Hour*24*60).Seconds()) {
-		return errors.New("Autoclose time period cannot be longer than 60 days")
+		errs = append(errs, validation.NewFieldError("auto_close", "Autoclose time period cannot be longer than 60 days"))
 	}
 
-	// Async checks
+	// Async checks, each writing to its own slot so that errors are returned in a stable order
+	var asyncErrs [4]*validation.InvalidInputError
 	group, _ := errgroup.WithContext(context.Background())
 
 	// Validate panel from same guild
@@ -229,7 +296,7 @@ func (s *Settings) Validate(ctx context.Context, guildId uint64, premiumTier pre
 			}
 
 			if guildId != panel.GuildId {
-				return fmt.Errorf("guild ID doesn't match")
+				asyncErrs[0] = validation.NewFieldError("context_menu_panel", "guild ID doesn't match")
 			}
 		}
 
@@ -246,7 +313,7 @@ func (s *Settings) Validate(ctx context.Context, guildId uint64, premiumTier pre
 		}
 
 		if !valid {
-			return fmt.Errorf("Invalid thread auto archive duration")
+			asyncErrs[1] = validation.NewFieldError("thread_archive_duration", "Invalid thread auto archive duration")
 		}
 
 		return nil
@@ -259,15 +326,11 @@ func (s *Settings) Validate(ctx context.Context, guildId uint64, premiumTier pre
 
 			ch, err := cache.Instance.GetChannel(ctx, *s.Settings.OverflowCategoryId)
 			if err != nil {
-				return fmt.Errorf("Invalid overflow category")
-			}
-
-			if ch.GuildId != guildId {
-				return fmt.Errorf("Overflow category guild ID does not match")
-			}
-
-			if ch.Type != channel.ChannelTypeGuildCategory {
-				return fmt.Errorf("Overflow category is not a category")
+				asyncErrs[2] = validation.NewFieldError("overflow_category_id", "Invalid overflow category")
+			} else if ch.GuildId != guildId {
+				asyncErrs[2] = validation.NewFieldError("overflow_category_id", "Overflow category guild ID does not match")
+			} else if ch.Type != channel.ChannelTypeGuildCategory {
+				asyncErrs[2] = validation.NewFieldError("overflow_category_id", "Overflow category is not a category")
 			}
 		}
 
@@ -281,22 +344,87 @@ func (s *Settings) Validate(ctx context.Context, guildId uint64, premiumTier pre
 
 			ch, err := cache.Instance.GetChannel(ctx, *s.Settings.TicketNotificationChannel)
 			if err != nil {
-				return fmt.Errorf("Invalid ticket notification channel")
-			}
-
-			if ch.GuildId != guildId {
-				return fmt.Errorf("Ticket notification channel guild ID does not match")
-			}
-
-			if ch.Type != channel.ChannelTypeGuildText {
-				return fmt.Errorf("Ticket notification channel is not a text channel")
+				asyncErrs[3] = validation.NewFieldError("ticket_notification_channel", "Invalid ticket notification channel")
+			} else if ch.GuildId != guildId {
+				asyncErrs[3] = validation.NewFieldError("ticket_notification_channel", "Ticket notification channel guild ID does not match")
+			} else if ch.Type != channel.ChannelTypeGuildText {
+				asyncErrs[3] = validation.NewFieldError("ticket_notification_channel", "Ticket notification channel is not a text channel")
 			}
 		}
 
 		return nil
 	})
 
-	return group.Wait()
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	for _, err := range asyncErrs {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs, nil
+}
+
+// skippedFields reports the fields that Apply would leave unchanged, as they are invalid. These don't fail the update,
+// so are reported as warnings.
+func (s *Settings) skippedFields(channels []channel.Channel) []*validation.InvalidInputError {
+	var warnings []*validation.InvalidInputError
+
+	if !s.isValidWelcomeMessage() {
+		warnings = append(warnings, validation.NewFieldError("welcome_message", "Welcome message must be between 1 and 4096 characters, so will not be saved"))
+	}
+
+	if !s.isValidTicketLimit() {
+		warnings = append(warnings, validation.NewFieldError("ticket_limit", "Ticket limit must be between 1 and 10, so will not be saved"))
+	}
+
+	if !s.isValidArchiveChannel(channels) {
+		warnings = append(warnings, validation.NewFieldError("archive_channel", "Archive channel must be a text channel, so will not be saved"))
+	}
+
+	if !s.isValidCategory(channels) {
+		warnings = append(warnings, validation.NewFieldError("category", "Ticket category must be a category channel, so will not be saved"))
+	}
+
+	if !s.isValidNamingScheme() {
+		warnings = append(warnings, validation.NewFieldError("naming_scheme", "Invalid naming scheme, so will not be saved"))
+	}
+
+	return warnings
+}
+
+func (s *Settings) isValidWelcomeMessage() bool {
+	return s.WelcomeMessage != "" && len(s.WelcomeMessage) <= 4096
+}
+
+func (s *Settings) isValidTicketLimit() bool {
+	return s.TicketLimit >= 1 && s.TicketLimit <= 10
+}
+
+// isValidArchiveChannel also accepts no archive channel, which disables archiving
+func (s *Settings) isValidArchiveChannel(channels []channel.Channel) bool {
+	return s.ArchiveChannel == nil || hasChannel(channels, *s.ArchiveChannel, channel.ChannelTypeGuildText)
+}
+
+func (s *Settings) isValidCategory(channels []channel.Channel) bool {
+	return hasChannel(channels, s.Category, channel.ChannelTypeGuildCategory)
+}
+
+func (s *Settings) isValidNamingScheme() bool {
+	return utils.Exists(validScheme, s.NamingScheme)
+}
+
+func hasChannel(channels []channel.Channel, channelId uint64, channelType channel.ChannelType) bool {
+	for _, ch := range channels {
+		if ch.Id == channelId && ch.Type == channelType {
+			return true
+		}
+	}
+
+	return false
 }
 
 func addToWaitGroup(group *errgroup.Group, guildId uint64, f func(uint64) error) {
@@ -306,7 +434,7 @@ func addToWaitGroup(group *errgroup.Group, guildId uint64, f func(uint64) error)
 }
 
 func (s *Settings) updateWelcomeMessage(guildId uint64) bool {
-	if s.WelcomeMessage == "" || len(s.WelcomeMessage) > 4096 {
+	if !s.isValidWelcomeMessage() {
 		return false
 	}
 
@@ -315,7 +443,7 @@ func (s *Settings) updateWelcomeMessage(guildId uint64) bool {
 }
 
 func (s *Settings) updateTicketLimit(guildId uint64) bool {
-	if s.TicketLimit > 10 || s.TicketLimit < 1 {
+	if !s.isValidTicketLimit() {
 		return false
 	}
 
@@ -324,15 +452,7 @@ func (s *Settings) updateTicketLimit(guildId uint64) bool {
 }
 
 func (s *Settings) updateCategory(channels []channel.Channel, guildId uint64) bool {
-	var valid bool
-	for _, ch := range channels {
-		if ch.Id == s.Category && ch.Type == channel.ChannelTypeGuildCategory {
-			valid = true
-			break
-		}
-	}
-
-	if !valid {
+	if !s.isValidCategory(channels) {
 		return false
 	}
 
@@ -341,20 +461,7 @@ func (s *Settings) updateCategory(channels []channel.Channel, guildId uint64) bo
 }
 
 func (s *Settings) updateArchiveChannel(channels []channel.Channel, guildId uint64) bool {
-	if s.ArchiveChannel == nil {
-		go dbclient.Client.ArchiveChannel.Set(context.Background(), guildId, nil)
-		return true
-	}
-
-	var valid bool
-	for _, ch := range channels {
-		if ch.Id == *s.ArchiveChannel && ch.Type == channel.ChannelTypeGuildText {
-			valid = true
-			break
-		}
-	}
-
-	if !valid {
+	if !s.isValidArchiveChannel(channels) {
 		return false
 	}
 
@@ -365,15 +472,7 @@ func (s *Settings) updateArchiveChannel(channels []channel.Channel, guildId uint
 var validScheme = []database.NamingScheme{database.Id, database.Username}
 
 func (s *Settings) updateNamingScheme(guildId uint64) bool {
-	var valid bool
-	for _, scheme := range validScheme {
-		if scheme == s.NamingScheme {
-			valid = true
-			break
-		}
-	}
-
-	if !valid {
+	if !s.isValidNamingScheme() {
 		return false
 	}
 
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/TicketsBot-cloud/dashboard/utils"
+	"github.com/TicketsBot-cloud/database"
+	"github.com/TicketsBot-cloud/gdl/objects/channel"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSkippedFields(t *testing.T) {
+	channels := []channel.Channel{
+		{Id: 1, Type: channel.ChannelTypeGuildCategory},
+		{Id: 2, Type: channel.ChannelTypeGuildText},
+	}
+
+	valid := Settings{
+		WelcomeMessage: "Hello",
+		TicketLimit:    5,
+		Category:       1,
+		ArchiveChannel: utils.Ptr(uint64(2)),
+		NamingScheme:   database.Id,
+	}
+
+	assert.Empty(t, valid.skippedFields(channels))
+
+	valid.ArchiveChannel = nil
+	assert.Empty(t, valid.skippedFields(channels))
+
+	invalid := Settings{
+		WelcomeMessage: "",
+		TicketLimit:    11,
+		Category:       2,
+		ArchiveChannel: utils.Ptr(uint64(1)),
+		NamingScheme:   "invalid",
+	}
+
+	var fields []string
+	for _, warning := range invalid.skippedFields(channels) {
+		fields = append(fields, warning.Field)
+	}
+
+	assert.Equal(t, []string{"welcome_message", "ticket_limit", "archive_channel", "category", "naming_scheme"}, fields)
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/TicketsBot-cloud/dashboard/app"
 	dbclient "github.com/TicketsBot-cloud/dashboard/database"
 	"github.com/TicketsBot-cloud/dashboard/rpc/cache"
+	"github.com/TicketsBot-cloud/dashboard/sla"
 	"github.com/TicketsBot-cloud/dashboard/utils"
 	"github.com/TicketsBot-cloud/database"
 	"github.com/TicketsBot-cloud/gdl/objects/user"
@@ -30,6 +31,8 @@ type (
 		OpenedAt            time.Time  `json:"opened_at"`
 		LastResponseTime    *time.Time `json:"last_response_time"`
 		LastResponseIsStaff *bool      `json:"last_response_is_staff"`
+		// Sla is nil if the ticket's panel does not have an SLA policy
+		Sla *sla.Status `json:"sla"`
 	}
 )
 
@@ -37,6 +40,12 @@ func GetTickets(c *gin.Context) {
 	userId := c.Keys["userid"].(uint64)
 	guildId := c.Keys["guildid"].(uint64)
 
+	slaFilter, err := parseSlaFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.ErrorStr("%s", err.Error()))
+		return
+	}
+
 	// Check if user is a panel team member only (not admin or guild-wide support)
 	isPanelTeamOnly, err := utils.IsPanelTeamMemberOnly(c, guildId, userId)
 	if err != nil {
@@ -68,13 +77,20 @@ func GetTickets(c *gin.Context) {
 				opts.FilterByPanelIds = panelIds
 			}
 
+			// SLA states are evaluated in memory, so every matching ticket must be fetched before filtering, rather
+			// than a page which may not contain any tickets in the requested states
+			if slaFilter != nil {
+				opts.Limit = 0
+				opts.Offset = 0
+			}
+
 			plainTickets, err := dbclient.Client.Tickets.GetByOptions(c, opts)
 			if err != nil {
 				_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to fetch filtered tickets from database"))
 				return
 			}
 
-			buildResponseFromPlainTickets(c, plainTickets, guildId, userId)
+			buildResponseFromPlainTickets(c, plainTickets, guildId, userId, slaFilter)
 			return
 		}
 	}
@@ -94,7 +110,7 @@ func GetTickets(c *gin.Context) {
 			return
 		}
 
-		buildResponseFromPlainTickets(c, plainTickets, guildId, userId)
+		buildResponseFromPlainTickets(c, plainTickets, guildId, userId, slaFilter)
 		return
 	}
 
@@ -143,6 +159,12 @@ func GetTickets(c *gin.Context) {
 		return
 	}
 
+	statuses, err := slaStatuses(c, guildId, tickets)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to calculate SLA status"))
+		return
+	}
+
 	data := make([]ticketData, len(tickets))
 	for i, ticket := range tickets {
 		data[i] = ticketData{
@@ -153,9 +175,12 @@ func GetTickets(c *gin.Context) {
 			OpenedAt:            ticket.OpenTime,
 			LastResponseTime:    ticket.LastMessageTime,
 			LastResponseIsStaff: ticket.UserIsStaff,
+			Sla:                 statuses[ticket.Id],
 		}
 	}
 
+	data = filterBySla(data, slaFilter)
+
 	c.JSON(200, listTicketsResponse{
 		Tickets:       data,
 		PanelTitles:   panelTitles,
@@ -165,7 +190,7 @@ func GetTickets(c *gin.Context) {
 	})
 }
 
-func buildResponseFromPlainTickets(c *gin.Context, plainTickets []database.Ticket, guildId, userId uint64) {
+func buildResponseFromPlainTickets(c *gin.Context, plainTickets []database.Ticket, guildId, userId uint64, slaFilter map[sla.State]bool) {
 	if len(plainTickets) == 0 {
 		c.JSON(200, listTicketsResponse{
 			Tickets:       []ticketData{},
@@ -238,6 +263,12 @@ func buildResponseFromPlainTickets(c *gin.Context, plainTickets []database.Ticke
 	}
 
 	// Build ticketData from tickets with metadata
+	statuses, err := slaStatuses(c, guildId, tickets)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusInternalServerError, app.NewError(err, "Failed to calculate SLA status"))
+		return
+	}
+
 	data := make([]ticketData, len(tickets))
 	for i, ticket := range tickets {
 		data[i] = ticketData{
@@ -248,9 +279,12 @@ func buildResponseFromPlainTickets(c *gin.Context, plainTickets []database.Ticke
 			OpenedAt:            ticket.OpenTime,
 			LastResponseTime:    ticket.LastMessageTime,
 			LastResponseIsStaff: ticket.UserIsStaff,
+			Sla:                 statuses[ticket.Id],
 		}
 	}
 
+	data = filterBySla(data, slaFilter)
+
 	c.JSON(200, listTicketsResponse{
 		Tickets:       data,
 		PanelTitles:   panelTitles,
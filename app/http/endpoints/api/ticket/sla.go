@@ -0,0 +1,99 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/dashboard/sla"
+	"github.com/TicketsBot-cloud/database"
+	"github.com/gin-gonic/gin"
+)
+
+// parseSlaFilter reads the sla query parameter, a comma separated list of states to restrict the tickets to, such as
+// "at_risk,breached". A nil filter matches every ticket.
+func parseSlaFilter(c *gin.Context) (map[sla.State]bool, error) {
+	raw := c.Query("sla")
+	if raw == "" {
+		return nil, nil
+	}
+
+	filter := make(map[sla.State]bool)
+	for _, state := range strings.Split(raw, ",") {
+		switch sla.State(state) {
+		case sla.StateMet, sla.StateOk, sla.StateAtRisk, sla.StateBreached:
+			filter[sla.State(state)] = true
+		default:
+			return nil, fmt.Errorf("Invalid SLA state: %s", state)
+		}
+	}
+
+	return filter, nil
+}
+
+// slaStatuses evaluates each ticket against its panel's SLA policy. Tickets opened from panels without a policy are
+// not included.
+func slaStatuses(c *gin.Context, guildId uint64, tickets []database.TicketWithMetadata) (map[int]*sla.Status, error) {
+	policies, err := sla.LoadPolicies(c, guildId)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make(map[int]*sla.Status)
+	if len(policies) == 0 {
+		return statuses, nil
+	}
+
+	ticketIds := make([]int, 0, len(tickets))
+	for _, ticket := range tickets {
+		if ticket.PanelId != nil {
+			ticketIds = append(ticketIds, ticket.Id)
+		}
+	}
+
+	firstResponses, err := dbclient.Dashboard.Analytics.GetFirstResponses(c, guildId, ticketIds)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for _, ticket := range tickets {
+		if ticket.PanelId == nil {
+			continue
+		}
+
+		slaTicket := dbclient.SlaTicket{
+			TicketId:  ticket.Id,
+			PanelId:   *ticket.PanelId,
+			OpenTime:  ticket.OpenTime,
+			CloseTime: ticket.CloseTime,
+		}
+
+		if respondedAt, ok := firstResponses[ticket.Id]; ok {
+			slaTicket.FirstResponseAt = &respondedAt
+		}
+
+		if status := policies.Evaluate(slaTicket, now); status != nil {
+			statuses[ticket.Id] = status
+		}
+	}
+
+	return statuses, nil
+}
+
+// filterBySla keeps the tickets whose SLA state is in the filter. Tickets without an SLA policy never match.
+func filterBySla(data []ticketData, filter map[sla.State]bool) []ticketData {
+	if filter == nil {
+		return data
+	}
+
+	filtered := make([]ticketData, 0, len(data))
+	for _, ticket := range data {
+		if ticket.Sla != nil && filter[ticket.Sla.State] {
+			filtered = append(filtered, ticket)
+		}
+	}
+
+	return filtered
+}
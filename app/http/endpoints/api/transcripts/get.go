@@ -51,8 +51,8 @@ func GetTranscriptHandler(ctx *gin.Context) {
 		}
 	}
 
-	// retrieve ticket messages from bucket
-	messages, err := utils.ArchiverClient.Get(ctx, guildId, ticketId)
+	// retrieve ticket messages from bucket, with any redactions applied
+	messages, err := getTranscript(ctx, guildId, ticketId)
 	if err != nil {
 		if errors.Is(err, archiverclient.ErrNotFound) {
 			ctx.JSON(404, utils.ErrorStr("Transcript not found"))
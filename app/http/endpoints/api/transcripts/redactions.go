@@ -0,0 +1,259 @@
+package api
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/TicketsBot-cloud/archiverclient"
+	"github.com/TicketsBot-cloud/dashboard/app/http/audit"
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/dashboard/log"
+	"github.com/TicketsBot-cloud/dashboard/redaction"
+	"github.com/TicketsBot-cloud/dashboard/redis"
+	"github.com/TicketsBot-cloud/dashboard/utils"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	maxRedactionsPerTicket   = 100
+	maxRedactionReasonLength = 512
+)
+
+type createRedactionBody struct {
+	MessageId *uint64 `json:"message_id,string"`
+	Pattern   *string `json:"pattern"`
+	Reason    string  `json:"reason"`
+}
+
+type redactionMetadata struct {
+	TicketId int `json:"ticket_id"`
+}
+
+func ListRedactionsHandler(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+
+	ticketId, err := strconv.Atoi(ctx.Param("ticketId"))
+	if err != nil {
+		ctx.JSON(400, utils.ErrorStr("Invalid ticket ID provided: %s", ctx.Param("ticketId")))
+		return
+	}
+
+	redactions, err := dbclient.Dashboard.TranscriptRedactions.GetByTicket(ctx, guildId, ticketId)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorStr("Failed to fetch redactions. Please try again."))
+		return
+	}
+
+	ctx.JSON(200, redactions)
+}
+
+func CreateRedactionHandler(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+	userId := ctx.Keys["userid"].(uint64)
+
+	ticketId, err := strconv.Atoi(ctx.Param("ticketId"))
+	if err != nil {
+		ctx.JSON(400, utils.ErrorStr("Invalid ticket ID provided: %s", ctx.Param("ticketId")))
+		return
+	}
+
+	var body createRedactionBody
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(400, utils.ErrorStr("Invalid request body."))
+		return
+	}
+
+	if len(body.Reason) == 0 || len(body.Reason) > maxRedactionReasonLength {
+		ctx.JSON(400, utils.ErrorStr("Reason must be between 1 and %d characters.", maxRedactionReasonLength))
+		return
+	}
+
+	if body.MessageId == nil && body.Pattern == nil {
+		ctx.JSON(400, utils.ErrorStr("Either a message or a pattern must be provided."))
+		return
+	}
+
+	if body.Pattern != nil {
+		if _, err := redaction.CompilePattern(*body.Pattern); err != nil {
+			ctx.JSON(400, utils.ErrorStr("%s", err.Error()))
+			return
+		}
+	}
+
+	ticket, err := dbclient.Client.Tickets.Get(ctx, ticketId, guildId)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorStr("Unable to load ticket. Please try again."))
+		return
+	}
+
+	if ticket.UserId == 0 || ticket.Open || !ticket.HasTranscript {
+		ctx.JSON(404, utils.ErrorStr("Transcript not found"))
+		return
+	}
+
+	existing, err := dbclient.Dashboard.TranscriptRedactions.GetByTicket(ctx, guildId, ticketId)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorStr("Failed to create redaction. Please try again."))
+		return
+	}
+
+	if len(existing) >= maxRedactionsPerTicket {
+		ctx.JSON(400, utils.ErrorStr("A transcript can have at most %d redactions.", maxRedactionsPerTicket))
+		return
+	}
+
+	// Make sure the message actually exists, so that the redaction isn't silently a no-op
+	if body.MessageId != nil {
+		transcript, err := utils.ArchiverClient.Get(ctx, guildId, ticketId)
+		if err != nil {
+			if errors.Is(err, archiverclient.ErrNotFound) {
+				ctx.JSON(404, utils.ErrorStr("Transcript not found"))
+			} else {
+				ctx.JSON(500, utils.ErrorStr("Failed to fetch records. Please try again."))
+			}
+
+			return
+		}
+
+		var found bool
+		for _, message := range transcript.Messages {
+			if message.Id == *body.MessageId {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			ctx.JSON(400, utils.ErrorStr("Message not found in transcript."))
+			return
+		}
+	}
+
+	created, err := dbclient.Dashboard.TranscriptRedactions.Create(ctx, dbclient.TranscriptRedaction{
+		GuildId:    guildId,
+		TicketId:   ticketId,
+		MessageId:  body.MessageId,
+		Pattern:    body.Pattern,
+		Reason:     body.Reason,
+		RedactedBy: userId,
+	})
+	if err != nil {
+		ctx.JSON(500, utils.ErrorStr("Failed to create redaction. Please try again."))
+		return
+	}
+
+	if err := redis.Client.InvalidateRenderedTranscript(ctx, guildId, ticketId); err != nil {
+		log.Logger.Error("Failed to invalidate transcript render", zap.Uint64("guild_id", guildId), zap.Int("ticket_id", ticketId), zap.Error(err))
+	}
+
+	audit.Log(audit.LogEntry{
+		GuildId:      audit.Uint64Ptr(guildId),
+		UserId:       userId,
+		ActionType:   audit.ActionTranscriptRedactionCreate,
+		ResourceType: audit.ResourceTranscriptRedaction,
+		ResourceId:   audit.StringPtr(strconv.Itoa(created.Id)),
+		NewData:      created,
+		Metadata:     redactionMetadata{TicketId: ticketId},
+	})
+
+	ctx.JSON(200, created)
+}
+
+func DeleteRedactionHandler(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+	userId := ctx.Keys["userid"].(uint64)
+
+	ticketId, err := strconv.Atoi(ctx.Param("ticketId"))
+	if err != nil {
+		ctx.JSON(400, utils.ErrorStr("Invalid ticket ID provided: %s", ctx.Param("ticketId")))
+		return
+	}
+
+	redactionId, err := strconv.Atoi(ctx.Param("redactionId"))
+	if err != nil {
+		ctx.JSON(400, utils.ErrorStr("Invalid redaction ID."))
+		return
+	}
+
+	existing, ok, err := dbclient.Dashboard.TranscriptRedactions.Get(ctx, guildId, ticketId, redactionId)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorStr("Failed to delete redaction. Please try again."))
+		return
+	}
+
+	if !ok {
+		ctx.JSON(404, utils.ErrorStr("Redaction not found."))
+		return
+	}
+
+	if err := dbclient.Dashboard.TranscriptRedactions.Delete(ctx, guildId, ticketId, redactionId); err != nil {
+		ctx.JSON(500, utils.ErrorStr("Failed to delete redaction. Please try again."))
+		return
+	}
+
+	if err := redis.Client.InvalidateRenderedTranscript(ctx, guildId, ticketId); err != nil {
+		log.Logger.Error("Failed to invalidate transcript render", zap.Uint64("guild_id", guildId), zap.Int("ticket_id", ticketId), zap.Error(err))
+	}
+
+	audit.Log(audit.LogEntry{
+		GuildId:      audit.Uint64Ptr(guildId),
+		UserId:       userId,
+		ActionType:   audit.ActionTranscriptRedactionDelete,
+		ResourceType: audit.ResourceTranscriptRedaction,
+		ResourceId:   audit.StringPtr(strconv.Itoa(redactionId)),
+		OldData:      existing,
+		Metadata:     redactionMetadata{TicketId: ticketId},
+	})
+
+	ctx.JSON(204, nil)
+}
+
+func GetRedactionPolicyHandler(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+
+	policy, err := dbclient.Dashboard.TranscriptRedactionPolicies.Get(ctx, guildId)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorStr("Failed to fetch redaction policy. Please try again."))
+		return
+	}
+
+	ctx.JSON(200, policy)
+}
+
+func SetRedactionPolicyHandler(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+	userId := ctx.Keys["userid"].(uint64)
+
+	var body dbclient.TranscriptRedactionPolicy
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(400, utils.ErrorStr("Invalid request body."))
+		return
+	}
+
+	existing, err := dbclient.Dashboard.TranscriptRedactionPolicies.Get(ctx, guildId)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorStr("Failed to update redaction policy. Please try again."))
+		return
+	}
+
+	if err := dbclient.Dashboard.TranscriptRedactionPolicies.Set(ctx, guildId, body); err != nil {
+		ctx.JSON(500, utils.ErrorStr("Failed to update redaction policy. Please try again."))
+		return
+	}
+
+	if err := redis.Client.InvalidateRenderedTranscripts(ctx, guildId); err != nil {
+		log.Logger.Error("Failed to invalidate transcript renders", zap.Uint64("guild_id", guildId), zap.Error(err))
+	}
+
+	audit.Log(audit.LogEntry{
+		GuildId:      audit.Uint64Ptr(guildId),
+		UserId:       userId,
+		ActionType:   audit.ActionTranscriptRedactionPolicy,
+		ResourceType: audit.ResourceTranscriptRedaction,
+		OldData:      existing,
+		NewData:      body,
+	})
+
+	ctx.JSON(200, body)
+}
@@ -1,15 +1,24 @@
 package api
 
 import (
-	"fmt"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
 	"strconv"
+	"strings"
 
 	"github.com/TicketsBot-cloud/archiverclient"
+	"github.com/TicketsBot-cloud/dashboard/botcontext"
 	"github.com/TicketsBot-cloud/dashboard/chatreplica"
 	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/dashboard/log"
+	"github.com/TicketsBot-cloud/dashboard/redis"
 	"github.com/TicketsBot-cloud/dashboard/utils"
 	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
 )
 
 func GetTranscriptRenderHandler(ctx *gin.Context) {
@@ -51,8 +60,18 @@ func GetTranscriptRenderHandler(ctx *gin.Context) {
 		}
 	}
 
-	// retrieve ticket messages from bucket
-	transcript, err := utils.ArchiverClient.Get(ctx, guildId, ticketId)
+	// Closed transcripts rarely change, so serve a cached render if there is one. The stamp must be read before the
+	// transcript is fetched, so that a redaction made while rendering prevents the render from being cached.
+	cached, stamp, ok, err := redis.Client.GetRenderedTranscript(ctx, guildId, ticketId, chatreplica.RendererVersion)
+	if err != nil {
+		log.Logger.Warn("Failed to fetch cached transcript render", zap.Uint64("guild_id", guildId), zap.Int("ticket_id", ticketId), zap.Error(err))
+	} else if ok {
+		serveRenderedTranscript(ctx, cached)
+		return
+	}
+
+	// retrieve ticket messages from bucket, with any redactions applied
+	archived, err := getArchivedTranscript(ctx, guildId, ticketId)
 	if err != nil {
 		if errors.Is(err, archiverclient.ErrNotFound) {
 			ctx.JSON(404, utils.ErrorStr("Transcript not found"))
@@ -64,12 +83,51 @@ func GetTranscriptRenderHandler(ctx *gin.Context) {
 	}
 
 	// Render
-	payload := chatreplica.FromTranscript(transcript, ticketId)
+	var payload chatreplica.Payload
+	if archived.V1Messages != nil {
+		// V1 transcripts don't store mentioned entities, so resolve them as best we can
+		var resolver chatreplica.EntityResolver
+		if botContext, err := botcontext.ContextForGuild(guildId); err == nil {
+			resolver = chatreplica.NewGuildResolver(guildId, botContext)
+		}
+
+		payload = chatreplica.FromArchiveMessages(ctx, archived.V1Messages, ticketId, resolver)
+	} else {
+		payload = chatreplica.FromTranscript(archived.Transcript, ticketId)
+	}
+
 	// html, err := chatreplica.Render(payload)
+	rendered, err := json.Marshal(payload)
 	if err != nil {
 		ctx.JSON(500, utils.ErrorStr("Failed to process request. Please try again."))
 		return
 	}
 
-	ctx.JSON(200, payload)
+	if stamp != "" {
+		if err := redis.Client.SetRenderedTranscript(ctx, guildId, ticketId, chatreplica.RendererVersion, stamp, rendered); err != nil {
+			log.Logger.Warn("Failed to cache transcript render", zap.Uint64("guild_id", guildId), zap.Int("ticket_id", ticketId), zap.Error(err))
+		}
+	}
+
+	serveRenderedTranscript(ctx, rendered)
+}
+
+// serveRenderedTranscript writes the rendered transcript with an ETag, so that clients re-opening a transcript they
+// already have can be answered with a 304.
+func serveRenderedTranscript(ctx *gin.Context, rendered []byte) {
+	hash := sha256.Sum256(rendered)
+	etag := fmt.Sprintf(`"%s"`, hex.EncodeToString(hash[:16]))
+
+	ctx.Header("ETag", etag)
+	ctx.Header("Cache-Control", "private, no-cache")
+
+	for _, candidate := range strings.Split(ctx.GetHeader("If-None-Match"), ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			ctx.Status(http.StatusNotModified)
+			return
+		}
+	}
+
+	ctx.Data(200, "application/json; charset=utf-8", rendered)
 }
@@ -0,0 +1,122 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/TicketsBot-cloud/dashboard/app/http/audit"
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/dashboard/retention"
+	"github.com/TicketsBot-cloud/dashboard/utils"
+	"github.com/TicketsBot-cloud/database"
+	"github.com/gin-gonic/gin"
+)
+
+const retentionDryRunLimit = 100
+
+// retentionDryRunResponse lists the transcripts that would be deleted by the next runs. TotalCount excludes transcripts
+// which failed to be deleted and are waiting to be retried, which are counted by AwaitingRetryCount instead.
+type retentionDryRunResponse struct {
+	TotalCount         int                          `json:"total_count"`
+	AwaitingRetryCount int                          `json:"awaiting_retry_count"`
+	Tickets            []dbclient.ExpiredTranscript `json:"tickets"`
+}
+
+func GetRetentionPolicyHandler(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+
+	policy, err := dbclient.Dashboard.TranscriptRetention.Get(ctx, guildId)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorStr("Failed to fetch retention policy. Please try again."))
+		return
+	}
+
+	ctx.JSON(200, policy)
+}
+
+func SetRetentionPolicyHandler(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+	userId := ctx.Keys["userid"].(uint64)
+
+	var body dbclient.TranscriptRetentionPolicy
+	if err := ctx.ShouldBindJSON(&body); err != nil {
+		ctx.JSON(400, utils.ErrorStr("Invalid request body."))
+		return
+	}
+
+	if body.PanelRetentionDays == nil {
+		body.PanelRetentionDays = make(map[int]int)
+	}
+
+	if body.RetentionDays != nil && !isValidRetentionPeriod(*body.RetentionDays) {
+		ctx.JSON(400, utils.ErrorStr("Retention period must be between %d and %d days.", retention.MinRetentionDays, retention.MaxRetentionDays))
+		return
+	}
+
+	panels, err := dbclient.Client.Panel.GetByGuild(ctx, guildId)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorStr("Failed to fetch panels. Please try again."))
+		return
+	}
+
+	for panelId, days := range body.PanelRetentionDays {
+		if !utils.ExistsMap(panels, panelId, func(panel database.Panel) int { return panel.PanelId }) {
+			ctx.JSON(400, utils.ErrorStr("Panel %d not found.", panelId))
+			return
+		}
+
+		if !isValidRetentionPeriod(days) {
+			ctx.JSON(400, utils.ErrorStr("Retention period must be between %d and %d days.", retention.MinRetentionDays, retention.MaxRetentionDays))
+			return
+		}
+	}
+
+	existing, err := dbclient.Dashboard.TranscriptRetention.Get(ctx, guildId)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorStr("Failed to update retention policy. Please try again."))
+		return
+	}
+
+	if err := dbclient.Dashboard.TranscriptRetention.Set(ctx, guildId, body); err != nil {
+		ctx.JSON(500, utils.ErrorStr("Failed to update retention policy. Please try again."))
+		return
+	}
+
+	audit.Log(audit.LogEntry{
+		GuildId:      audit.Uint64Ptr(guildId),
+		UserId:       userId,
+		ActionType:   audit.ActionTranscriptRetentionUpdate,
+		ResourceType: audit.ResourceTranscriptRetention,
+		ResourceId:   audit.StringPtr(strconv.FormatUint(guildId, 10)),
+		OldData:      existing,
+		NewData:      body,
+	})
+
+	ctx.JSON(200, body)
+}
+
+// RetentionDryRunHandler lists the transcripts that would be deleted by the retention policy, without deleting them
+func RetentionDryRunHandler(ctx *gin.Context) {
+	guildId := ctx.Keys["guildid"].(uint64)
+
+	totalCount, awaitingRetryCount, err := dbclient.Dashboard.TranscriptRetention.GetExpiredCount(ctx, guildId)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorStr("Failed to fetch expired transcripts. Please try again."))
+		return
+	}
+
+	expired, err := dbclient.Dashboard.TranscriptRetention.GetExpired(ctx, guildId, retentionDryRunLimit)
+	if err != nil {
+		ctx.JSON(500, utils.ErrorStr("Failed to fetch expired transcripts. Please try again."))
+		return
+	}
+
+	ctx.JSON(200, retentionDryRunResponse{
+		TotalCount:         totalCount,
+		AwaitingRetryCount: awaitingRetryCount,
+		Tickets:            expired,
+	})
+}
+
+func isValidRetentionPeriod(days int) bool {
+	return days >= retention.MinRetentionDays && days <= retention.MaxRetentionDays
+}
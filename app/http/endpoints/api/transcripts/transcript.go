@@ -0,0 +1,75 @@
+package api
+
+import (
+	"context"
+
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/dashboard/redaction"
+	"github.com/TicketsBot-cloud/dashboard/utils"
+	"github.com/TicketsBot-cloud/gdl/objects/channel/message"
+	v1 "github.com/TicketsBot-cloud/logarchiver/pkg/model/v1"
+	v2 "github.com/TicketsBot-cloud/logarchiver/pkg/model/v2"
+	"golang.org/x/sync/errgroup"
+)
+
+type archivedTranscript struct {
+	Transcript v2.Transcript
+	// V1Messages holds the original messages of transcripts stored in the V1 format, and is nil otherwise
+	V1Messages []message.Message
+}
+
+// getArchivedTranscript fetches an archived transcript with the guild's redaction policy and the ticket's redactions
+// applied. Anything returning transcript contents to a user must go through this, rather than reading from the
+// archiver directly.
+func getArchivedTranscript(ctx context.Context, guildId uint64, ticketId int) (archivedTranscript, error) {
+	var (
+		transcript v2.Transcript
+		v1Messages []message.Message
+		policy     dbclient.TranscriptRedactionPolicy
+		redactions []dbclient.TranscriptRedaction
+	)
+
+	group, _ := errgroup.WithContext(ctx)
+
+	group.Go(func() (err error) {
+		transcript, v1Messages, err = utils.GetArchivedTranscript(ctx, guildId, ticketId)
+		return
+	})
+
+	group.Go(func() (err error) {
+		policy, err = dbclient.Dashboard.TranscriptRedactionPolicies.Get(ctx, guildId)
+		return
+	})
+
+	group.Go(func() (err error) {
+		redactions, err = dbclient.Dashboard.TranscriptRedactions.GetByTicket(ctx, guildId, ticketId)
+		return
+	})
+
+	if err := group.Wait(); err != nil {
+		return archivedTranscript{}, err
+	}
+
+	if v1Messages != nil {
+		v1Messages = redaction.ApplyV1(v1Messages, policy, redactions)
+
+		return archivedTranscript{
+			Transcript: v1.ConvertToV2(v1Messages),
+			V1Messages: v1Messages,
+		}, nil
+	}
+
+	return archivedTranscript{
+		Transcript: redaction.Apply(transcript, policy, redactions),
+	}, nil
+}
+
+// getTranscript is getArchivedTranscript for callers which only need the V2 form of the transcript
+func getTranscript(ctx context.Context, guildId uint64, ticketId int) (v2.Transcript, error) {
+	archived, err := getArchivedTranscript(ctx, guildId, ticketId)
+	if err != nil {
+		return v2.Transcript{}, err
+	}
+
+	return archived.Transcript, nil
+}
@@ -7,7 +7,9 @@ import (
 	"github.com/TicketsBot-cloud/common/permission"
 	"github.com/TicketsBot-cloud/dashboard/app/http/endpoints/api"
 	"github.com/TicketsBot-cloud/dashboard/app/http/endpoints/api/admin/botstaff"
+	api_analytics "github.com/TicketsBot-cloud/dashboard/app/http/endpoints/api/analytics"
 	api_audit "github.com/TicketsBot-cloud/dashboard/app/http/endpoints/api/auditlog"
+	api_backup "github.com/TicketsBot-cloud/dashboard/app/http/endpoints/api/backup"
 	api_blacklist "github.com/TicketsBot-cloud/dashboard/app/http/endpoints/api/blacklist"
 	api_forms "github.com/TicketsBot-cloud/dashboard/app/http/endpoints/api/forms"
 	api_integrations "github.com/TicketsBot-cloud/dashboard/app/http/endpoints/api/integrations"
@@ -124,6 +126,14 @@ func StartServer(logger *zap.Logger, sm *livechat.SocketManager) *nethttp.Server
 		// Must be readable to load transcripts page
 		guildAuthApiSupport.GET("/settings", api_settings.GetSettingsHandler)
 		guildAuthApiAdmin.POST("/settings", api_settings.UpdateSettingsHandler)
+		guildAuthApiAdmin.GET("/settings/history", api_settings.GetSettingsHistory)
+		guildAuthApiAdmin.POST("/settings/history/:versionid/rollback", rl(middleware.RateLimitTypeGuild, 5, time.Minute), api_settings.RollbackSettings)
+
+		guildAuthApiAdmin.GET("/backups", api_backup.ListBackups)
+		guildAuthApiAdmin.POST("/backups", rl(middleware.RateLimitTypeGuild, 5, time.Minute), api_backup.CreateBackup)
+		guildAuthApiAdmin.POST("/backups/restore", rl(middleware.RateLimitTypeGuild, 5, time.Minute), api_backup.RestoreBackup)
+		guildAuthApiAdmin.GET("/backups/:backupid", api_backup.GetBackup)
+		guildAuthApiAdmin.DELETE("/backups/:backupid", api_backup.DeleteBackup)
 
 		guildAuthApiSupport.GET("/blacklist", api_blacklist.GetBlacklistHandler)
 		guildAuthApiSupport.POST("/blacklist", api_blacklist.AddBlacklistHandler)
@@ -133,9 +143,16 @@ func StartServer(logger *zap.Logger, sm *livechat.SocketManager) *nethttp.Server
 		// Must be readable to load transcripts page
 		guildAuthApiSupport.GET("/panels", api_panels.ListPanels)
 		guildAuthApiAdmin.POST("/panels", api_panels.CreatePanel)
+		guildAuthApiSupport.GET("/panels/health", rl(middleware.RateLimitTypeGuild, 5, time.Minute), api_panels.GetPanelHealth)
+		guildAuthApiAdmin.POST("/panels/health/repair", rl(middleware.RateLimitTypeGuild, 1, time.Minute), api_panels.RepairPanels)
+		guildAuthApiSupport.GET("/panels/usage", rl(middleware.RateLimitTypeGuild, 5, 10*time.Second), api_panels.GetPanelsUsage)
+		guildAuthApiAdmin.POST("/panels/preview", rl(middleware.RateLimitTypeGuild, 10, 10*time.Second), api_panels.PreviewPanel)
+		guildAuthApiAdmin.GET("/panels/export", api_panels.ExportPanels)
+		guildAuthApiAdmin.POST("/panels/import", rl(middleware.RateLimitTypeGuild, 2, time.Minute), api_panels.ImportPanels)
 		guildAuthApiAdmin.POST("/panels/:panelid", rl(middleware.RateLimitTypeGuild, 5, 5*time.Second), api_panels.ResendPanel)
 		guildAuthApiAdmin.PATCH("/panels/:panelid", api_panels.UpdatePanel)
 		guildAuthApiAdmin.DELETE("/panels/:panelid", api_panels.DeletePanel)
+		guildAuthApiAdmin.POST("/panels/:panelid/clone", rl(middleware.RateLimitTypeGuild, 5, time.Minute), api_panels.ClonePanel)
 
 		guildAuthApiAdmin.DELETE("/panels/:panelid/cooldowns", api_panels.ResetPanelCooldowns)
 
@@ -143,19 +160,56 @@ func StartServer(logger *zap.Logger, sm *livechat.SocketManager) *nethttp.Server
 		guildAuthApiSupport.GET("/panels/:panelid/support-hours", api_panels.GetSupportHours)
 		guildAuthApiAdmin.POST("/panels/:panelid/support-hours", api_panels.SetSupportHours)
 		guildAuthApiAdmin.DELETE("/panels/:panelid/support-hours", api_panels.DeleteSupportHours)
+		guildAuthApiSupport.GET("/panels/:panelid/support-hours/preview", api_panels.PreviewSupportHours)
+		guildAuthApiAdmin.POST("/panels/:panelid/support-hours/preview", api_panels.PreviewSupportHours)
+		guildAuthApiSupport.GET("/panels/:panelid/support-hours/overrides", api_panels.ListSupportHoursOverrides)
+		guildAuthApiAdmin.POST("/panels/:panelid/support-hours/overrides", api_panels.CreateSupportHoursOverride)
+		guildAuthApiAdmin.PUT("/panels/:panelid/support-hours/overrides/:overrideid", api_panels.UpdateSupportHoursOverride)
+		guildAuthApiAdmin.DELETE("/panels/:panelid/support-hours/overrides/:overrideid", api_panels.DeleteSupportHoursOverride)
+		guildAuthApiSupport.GET("/panels/:panelid/usage", api_panels.GetPanelUsage)
 		guildAuthApiSupport.GET("/panels/:panelid/is-active", api_panels.IsPanelActive)
 
+		// Scheduled panel enable/disable
+		guildAuthApiSupport.GET("/panels/schedules", api_panels.ListGuildPanelSchedules)
+		guildAuthApiSupport.GET("/panels/:panelid/schedules", api_panels.ListPanelSchedules)
+		guildAuthApiAdmin.POST("/panels/:panelid/schedules", api_panels.CreatePanelSchedule)
+		guildAuthApiAdmin.DELETE("/panels/:panelid/schedules/:scheduleid", api_panels.DeletePanelSchedule)
+
+		guildAuthApiSupport.GET("/support-hours/schedules", api_panels.ListSupportHoursSchedules)
+		guildAuthApiAdmin.POST("/support-hours/schedules", api_panels.CreateSupportHoursSchedule)
+		guildAuthApiSupport.GET("/support-hours/schedules/:scheduleid", api_panels.GetSupportHoursSchedule)
+		guildAuthApiAdmin.PUT("/support-hours/schedules/:scheduleid", api_panels.UpdateSupportHoursSchedule)
+		guildAuthApiAdmin.DELETE("/support-hours/schedules/:scheduleid", api_panels.DeleteSupportHoursSchedule)
+
+		// SLA policy endpoints
+		guildAuthApiSupport.GET("/panels/:panelid/sla", api_panels.GetSlaPolicy)
+		guildAuthApiAdmin.PUT("/panels/:panelid/sla", api_panels.SetSlaPolicy)
+		guildAuthApiAdmin.DELETE("/panels/:panelid/sla", api_panels.DeleteSlaPolicy)
+
 		guildAuthApiAdmin.GET("/multipanels", api_panels.MultiPanelList)
 		guildAuthApiAdmin.POST("/multipanels", api_panels.MultiPanelCreate)
+		guildAuthApiAdmin.POST("/multipanels/preview", rl(middleware.RateLimitTypeGuild, 10, 10*time.Second), api_panels.PreviewMultiPanel)
 		guildAuthApiAdmin.POST("/multipanels/:panelid", rl(middleware.RateLimitTypeGuild, 5, 5*time.Second), api_panels.MultiPanelResend)
 		guildAuthApiAdmin.PATCH("/multipanels/:panelid", api_panels.MultiPanelUpdate)
+		guildAuthApiAdmin.PUT("/multipanels/:panelid/layout", rl(middleware.RateLimitTypeGuild, 5, 5*time.Second), api_panels.UpdateMultiPanelLayout)
 		guildAuthApiAdmin.DELETE("/multipanels/:panelid", api_panels.MultiPanelDelete)
+		guildAuthApiAdmin.POST("/multipanels/:panelid/clone", rl(middleware.RateLimitTypeGuild, 5, time.Minute), api_panels.CloneMultiPanel)
 
 		guildAuthApiSupport.GET("/forms", api_forms.GetForms)
 		guildAuthApiAdmin.POST("/forms", rl(middleware.RateLimitTypeGuild, 30, time.Hour), api_forms.CreateForm)
+		guildAuthApiSupport.GET("/forms/templates", api_forms.ListTemplates)
+		guildAuthApiAdmin.POST("/forms/templates", rl(middleware.RateLimitTypeGuild, 30, time.Hour), api_forms.CreateTemplate)
+		guildAuthApiAdmin.DELETE("/forms/templates/:template_id", api_forms.DeleteTemplate)
 		guildAuthApiAdmin.PATCH("/forms/:form_id", rl(middleware.RateLimitTypeGuild, 30, time.Hour), api_forms.UpdateForm)
 		guildAuthApiAdmin.DELETE("/forms/:form_id", api_forms.DeleteForm)
+		guildAuthApiAdmin.POST("/forms/:form_id/clone", rl(middleware.RateLimitTypeGuild, 30, time.Hour), api_forms.CloneForm)
 		guildAuthApiAdmin.PATCH("/forms/:form_id/inputs", api_forms.UpdateInputs)
+		guildAuthApiAdmin.PUT("/forms/:form_id/inputs/order", api_forms.ReorderInputs)
+		guildAuthApiAdmin.POST("/forms/:form_id/inputs/:input_id/move", api_forms.MoveInput)
+		guildAuthApiSupport.GET("/forms/:form_id/branches", api_forms.GetBranches)
+		guildAuthApiAdmin.PUT("/forms/:form_id/branches", rl(middleware.RateLimitTypeGuild, 30, time.Hour), api_forms.UpdateBranches)
+		guildAuthApiAdmin.GET("/forms/:form_id/responses", api_forms.GetResponses)
+		guildAuthApiAdmin.GET("/forms/:form_id/responses/export", rl(middleware.RateLimitTypeGuild, 5, time.Minute), api_forms.ExportResponses)
 
 		// Should be a GET, but easier to take a body for development purposes
 		guildAuthApiSupport.POST("/transcripts",
@@ -168,6 +222,17 @@ func StartServer(logger *zap.Logger, sm *livechat.SocketManager) *nethttp.Server
 		guildApiNoAuth.GET("/transcripts/:ticketId", rl(middleware.RateLimitTypeGuild, 10, 10*time.Second), api_transcripts.GetTranscriptHandler)
 		guildApiNoAuth.GET("/transcripts/:ticketId/render", rl(middleware.RateLimitTypeGuild, 10, 10*time.Second), api_transcripts.GetTranscriptRenderHandler)
 
+		// Transcript redactions are an overlay, applied whenever a transcript is read
+		guildAuthApiAdmin.GET("/transcripts/redaction-policy", api_transcripts.GetRedactionPolicyHandler)
+		guildAuthApiAdmin.PUT("/transcripts/redaction-policy", api_transcripts.SetRedactionPolicyHandler)
+		guildAuthApiAdmin.GET("/transcripts/:ticketId/redactions", api_transcripts.ListRedactionsHandler)
+		guildAuthApiAdmin.POST("/transcripts/:ticketId/redactions", rl(middleware.RateLimitTypeGuild, 10, time.Minute), api_transcripts.CreateRedactionHandler)
+		guildAuthApiAdmin.DELETE("/transcripts/:ticketId/redactions/:redactionId", api_transcripts.DeleteRedactionHandler)
+
+		guildAuthApiAdmin.GET("/transcripts/retention", api_transcripts.GetRetentionPolicyHandler)
+		guildAuthApiAdmin.PUT("/transcripts/retention", api_transcripts.SetRetentionPolicyHandler)
+		guildAuthApiAdmin.GET("/transcripts/retention/dry-run", rl(middleware.RateLimitTypeGuild, 5, 10*time.Second), api_transcripts.RetentionDryRunHandler)
+
 		// Ticket label CRUD (admin-only for mutations, support-level for reads)
 		guildAuthApiSupport.GET("/ticket-labels", api_ticket.ListTicketLabels)
 		guildAuthApiAdmin.POST("/ticket-labels", rl(middleware.RateLimitTypeGuild, 10, time.Minute), api_ticket.CreateTicketLabel)
@@ -209,6 +274,14 @@ func StartServer(logger *zap.Logger, sm *livechat.SocketManager) *nethttp.Server
 
 		guildAuthApiAdmin.POST("/audit-logs", api_audit.GetAuditLogs)
 
+		guildAuthApiAdmin.GET("/analytics/volume", rl(middleware.RateLimitTypeGuild, 10, time.Minute), api_analytics.GetVolume)
+		guildAuthApiAdmin.GET("/analytics/response-times", rl(middleware.RateLimitTypeGuild, 10, time.Minute), api_analytics.GetResponseTimes)
+		guildAuthApiAdmin.GET("/analytics/breakdown", rl(middleware.RateLimitTypeGuild, 10, time.Minute), api_analytics.GetBreakdown)
+		guildAuthApiAdmin.GET("/analytics/staff", rl(middleware.RateLimitTypeGuild, 10, time.Minute), api_analytics.GetStaffLeaderboard)
+		guildAuthApiAdmin.GET("/analytics/satisfaction", rl(middleware.RateLimitTypeGuild, 10, time.Minute), api_analytics.GetSatisfaction)
+		guildAuthApiAdmin.GET("/analytics/satisfaction/feedback", rl(middleware.RateLimitTypeGuild, 10, time.Minute), api_analytics.GetSurveyFeedback)
+		guildAuthApiAdmin.GET("/analytics/sla", rl(middleware.RateLimitTypeGuild, 10, time.Minute), api_analytics.GetSlaReport)
+
 		guildAuthApiAdmin.GET("/integrations/available", api_integrations.ListIntegrationsHandler)
 		guildAuthApiAdmin.GET("/integrations/:integrationid", api_integrations.IsIntegrationActiveHandler)
 		guildAuthApiAdmin.POST("/integrations/:integrationid",
@@ -3,7 +3,8 @@ package validation
 import "fmt"
 
 type InvalidInputError struct {
-	Message string
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
 }
 
 func (e *InvalidInputError) Error() string {
@@ -17,3 +18,45 @@ func NewInvalidInputError(message string) *InvalidInputError {
 func NewInvalidInputErrorf(message string, args ...any) *InvalidInputError {
 	return &InvalidInputError{Message: fmt.Sprintf(message, args...)}
 }
+
+func NewFieldError(field, message string) *InvalidInputError {
+	return &InvalidInputError{Field: field, Message: message}
+}
+
+// DryRunResult is returned by endpoints called with ?dry_run=true, in place of performing the change. Warnings are
+// for fields which the change would skip, without failing.
+type DryRunResult struct {
+	Success  bool                 `json:"success"`
+	DryRun   bool                 `json:"dry_run"`
+	Errors   []*InvalidInputError `json:"errors"`
+	Warnings []*InvalidInputError `json:"warnings"`
+}
+
+func NewDryRunResult(errs []*InvalidInputError) DryRunResult {
+	if errs == nil {
+		errs = make([]*InvalidInputError, 0)
+	}
+
+	return DryRunResult{
+		Success:  len(errs) == 0,
+		DryRun:   true,
+		Errors:   errs,
+		Warnings: make([]*InvalidInputError, 0),
+	}
+}
+
+func (r DryRunResult) WithWarnings(warnings []*InvalidInputError) DryRunResult {
+	if warnings != nil {
+		r.Warnings = warnings
+	}
+
+	return r
+}
+
+func (r DryRunResult) StatusCode() int {
+	if r.Success {
+		return 200
+	}
+
+	return 400
+}
@@ -79,3 +79,29 @@ func TestDualFail(t *testing.T) {
 		t.Errorf("got wrong error message: %s", validationError.Message)
 	}
 }
+
+func TestValidateAllCollectsErrors(t *testing.T) {
+	ctx := testCtx{a: 0, b: ""}
+	errs, err := ValidateAll(context.Background(), ctx,
+		Field("b", validateStringNotEmpty),
+		Field("a", validateGreaterThanZero),
+	)
+
+	assert.NoError(t, err)
+	if assert.Len(t, errs, 2) {
+		assert.Equal(t, "a", errs[0].Field)
+		assert.Equal(t, "a must be greater than 0", errs[0].Message)
+		assert.Equal(t, "b", errs[1].Field)
+	}
+}
+
+func TestValidateAllReturnsOtherErrors(t *testing.T) {
+	failing := func(ctx testCtx) ValidationFunc {
+		return func() error {
+			return errors.New("connection refused")
+		}
+	}
+
+	_, err := ValidateAll(context.Background(), testCtx{a: 0}, validateGreaterThanZero, failing)
+	assert.EqualError(t, err, "connection refused")
+}
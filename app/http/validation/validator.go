@@ -2,6 +2,10 @@ package validation
 
 import (
 	"context"
+	"errors"
+	"sort"
+	"sync"
+
 	"golang.org/x/sync/errgroup"
 )
 
@@ -18,3 +22,62 @@ func Validate[T any](ctx context.Context, validationContext T, validators ...Val
 
 	return group.Wait()
 }
+
+// ValidateAll runs every validator, rather than stopping at the first failure. Input errors are collected and
+// returned sorted by field, while any other error, e.g. failing to reach Discord, is returned as err.
+func ValidateAll[T any](ctx context.Context, validationContext T, validators ...Validator[T]) ([]*InvalidInputError, error) {
+	group, _ := errgroup.WithContext(ctx)
+
+	var mu sync.Mutex
+	errs := make([]*InvalidInputError, 0)
+
+	for _, validator := range validators {
+		f := validator(validationContext)
+
+		group.Go(func() error {
+			err := f()
+			if err == nil {
+				return nil
+			}
+
+			var inputError *InvalidInputError
+			if !errors.As(err, &inputError) {
+				return err
+			}
+
+			mu.Lock()
+			errs = append(errs, inputError)
+			mu.Unlock()
+
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errs[i].Field < errs[j].Field
+	})
+
+	return errs, nil
+}
+
+// Field attributes any input error returned by the validator to the given field
+func Field[T any](field string, validator Validator[T]) Validator[T] {
+	return func(validationContext T) ValidationFunc {
+		f := validator(validationContext)
+
+		return func() error {
+			err := f()
+
+			var inputError *InvalidInputError
+			if errors.As(err, &inputError) && inputError.Field == "" {
+				return &InvalidInputError{Field: field, Message: inputError.Message}
+			}
+
+			return err
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package backup
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/TicketsBot-cloud/dashboard/app/http/audit"
+)
+
+// SectionDiff describes what restoring a section would change. Added and Removed contain item keys, such as tag IDs,
+// team and label names, or "list/ID" for the permission and blacklist sections. Changed fields of items within a
+// section are prefixed with the item's key, e.g. "Billing.permissions.send_messages".
+type SectionDiff struct {
+	Added   []string            `json:"added"`
+	Removed []string            `json:"removed"`
+	Changed []audit.FieldChange `json:"changed"`
+}
+
+func (d SectionDiff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// Diff reports what restoring each of the sections from target would change in current
+func Diff(current, target Document, sections []Section) map[Section]SectionDiff {
+	diffs := make(map[Section]SectionDiff)
+	for _, section := range sections {
+		var diff SectionDiff
+
+		switch section {
+		case SectionSettings:
+			diff.Changed = audit.DiffFields("", current.Settings, target.Settings)
+		case SectionTags:
+			diff = diffKeyed(current.Tags, target.Tags, func(tag Tag) string {
+				return tag.Id
+			})
+		case SectionTeams:
+			diff = diffKeyed(current.Teams, target.Teams, func(team Team) string {
+				return team.Name
+			})
+		case SectionPermissions:
+			diffIds(&diff, "admin_users", current.Permissions.AdminUsers, target.Permissions.AdminUsers)
+			diffIds(&diff, "admin_roles", current.Permissions.AdminRoles, target.Permissions.AdminRoles)
+			diffIds(&diff, "support_users", current.Permissions.SupportUsers, target.Permissions.SupportUsers)
+			diffIds(&diff, "support_roles", current.Permissions.SupportRoles, target.Permissions.SupportRoles)
+		case SectionLabels:
+			diff = diffKeyed(current.Labels, target.Labels, func(label Label) string {
+				return label.Name
+			})
+		case SectionBlacklist:
+			diffIds(&diff, "users", current.Blacklist.Users, target.Blacklist.Users)
+			diffIds(&diff, "roles", current.Blacklist.Roles, target.Blacklist.Roles)
+		case SectionIntegrations:
+			diff = diffKeyed(current.Integrations, target.Integrations, func(integration Integration) string {
+				return strconv.Itoa(integration.Id)
+			})
+		}
+
+		diffs[section] = diff
+	}
+
+	return diffs
+}
+
+func diffKeyed[T any](current, target []T, key func(T) string) (diff SectionDiff) {
+	currentByKey := make(map[string]T)
+	for _, item := range current {
+		currentByKey[key(item)] = item
+	}
+
+	targetKeys := make(map[string]bool)
+	for _, item := range target {
+		k := key(item)
+		targetKeys[k] = true
+
+		existing, ok := currentByKey[k]
+		if !ok {
+			diff.Added = append(diff.Added, k)
+			continue
+		}
+
+		diff.Changed = append(diff.Changed, audit.DiffFields(k, existing, item)...)
+	}
+
+	for _, item := range current {
+		if k := key(item); !targetKeys[k] {
+			diff.Removed = append(diff.Removed, k)
+		}
+	}
+
+	sort.Strings(diff.Added)
+	sort.Strings(diff.Removed)
+
+	return
+}
+
+func diffIds(diff *SectionDiff, list string, current, target []uint64) {
+	currentIds := make(map[uint64]bool)
+	for _, id := range current {
+		currentIds[id] = true
+	}
+
+	targetIds := make(map[uint64]bool)
+	for _, id := range target {
+		targetIds[id] = true
+
+		if !currentIds[id] {
+			diff.Added = append(diff.Added, list+"/"+strconv.FormatUint(id, 10))
+		}
+	}
+
+	for _, id := range current {
+		if !targetIds[id] {
+			diff.Removed = append(diff.Removed, list+"/"+strconv.FormatUint(id, 10))
+		}
+	}
+}
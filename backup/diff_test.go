@@ -0,0 +1,113 @@
+package backup
+
+import (
+	"testing"
+
+	"github.com/TicketsBot-cloud/dashboard/utils"
+	"github.com/TicketsBot-cloud/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffKeyedSections(t *testing.T) {
+	current := Document{
+		Tags: []Tag{
+			{Id: "rules", Content: utils.Ptr("Be nice")},
+			{Id: "old", Content: utils.Ptr("Removed")},
+		},
+		Teams: []Team{
+			{Name: "Billing", Roles: []uint64{1}, Permissions: database.SupportTeamPermissions{SendMessages: true}},
+		},
+	}
+
+	target := Document{
+		Tags: []Tag{
+			{Id: "rules", Content: utils.Ptr("Be kind")},
+			{Id: "new", Content: utils.Ptr("Added")},
+		},
+		Teams: []Team{
+			{Name: "Billing", Roles: []uint64{1}, Permissions: database.SupportTeamPermissions{SendMessages: false}},
+		},
+	}
+
+	diffs := Diff(current, target, []Section{SectionTags, SectionTeams})
+
+	tags := diffs[SectionTags]
+	assert.Equal(t, []string{"new"}, tags.Added)
+	assert.Equal(t, []string{"old"}, tags.Removed)
+	if assert.Len(t, tags.Changed, 1) {
+		assert.Equal(t, "rules.content", tags.Changed[0].Field)
+		assert.Equal(t, "Be nice", tags.Changed[0].Old)
+		assert.Equal(t, "Be kind", tags.Changed[0].New)
+	}
+
+	teams := diffs[SectionTeams]
+	assert.Empty(t, teams.Added)
+	assert.Empty(t, teams.Removed)
+	if assert.Len(t, teams.Changed, 1) {
+		assert.Equal(t, "Billing.permissions.send_messages", teams.Changed[0].Field)
+	}
+}
+
+func TestDiffIdSections(t *testing.T) {
+	current := Document{
+		Blacklist: Blacklist{Users: []uint64{1, 2}},
+	}
+
+	target := Document{
+		Blacklist: Blacklist{Users: []uint64{2, 3}, Roles: []uint64{4}},
+	}
+
+	diff := Diff(current, target, []Section{SectionBlacklist})[SectionBlacklist]
+	assert.Equal(t, []string{"users/3", "roles/4"}, diff.Added)
+	assert.Equal(t, []string{"users/1"}, diff.Removed)
+	assert.False(t, diff.Empty())
+
+	assert.True(t, Diff(target, target, []Section{SectionBlacklist})[SectionBlacklist].Empty())
+}
+
+func TestDiffIntegrations(t *testing.T) {
+	current := Document{
+		Integrations: []Integration{
+			{Id: 1, Name: "CRM", SecretNames: []string{"key"}},
+			{Id: 2, Name: "Status", SecretNames: []string{}},
+		},
+	}
+
+	target := Document{
+		Integrations: []Integration{
+			{Id: 1, Name: "CRM", SecretNames: []string{"key"}},
+			{Id: 3, Name: "Billing", SecretNames: []string{"token"}},
+		},
+	}
+
+	diff := Diff(current, target, []Section{SectionIntegrations})[SectionIntegrations]
+	assert.Equal(t, []string{"3"}, diff.Added)
+	assert.Equal(t, []string{"2"}, diff.Removed)
+	assert.Empty(t, diff.Changed)
+}
+
+func TestLevelChanges(t *testing.T) {
+	changes := levelChanges(
+		[]uint64{1}, []uint64{2, 3},
+		[]uint64{2}, []uint64{3, 4},
+	)
+
+	assert.Equal(t, map[uint64]int{
+		1: permissionLevelNone,
+		2: permissionLevelAdmin,
+		4: permissionLevelSupport,
+	}, changes)
+}
+
+func TestParseSections(t *testing.T) {
+	sections, err := ParseSections([]string{"labels", "settings"})
+	assert.NoError(t, err)
+	assert.Equal(t, []Section{SectionSettings, SectionLabels}, sections)
+
+	sections, err = ParseSections(nil)
+	assert.NoError(t, err)
+	assert.Equal(t, Sections, sections)
+
+	_, err = ParseSections([]string{"panels"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,346 @@
+package backup
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	api_settings "github.com/TicketsBot-cloud/dashboard/app/http/endpoints/api/settings"
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/dashboard/utils/types"
+	"github.com/TicketsBot-cloud/database"
+	"golang.org/x/sync/errgroup"
+)
+
+// DocumentVersion is bumped whenever the document changes in a way older dashboards could not restore
+const DocumentVersion = 1
+
+type Section string
+
+const (
+	SectionSettings     Section = "settings"
+	SectionTags         Section = "tags"
+	SectionTeams        Section = "teams"
+	SectionPermissions  Section = "permissions"
+	SectionLabels       Section = "labels"
+	SectionBlacklist    Section = "blacklist"
+	SectionIntegrations Section = "integrations"
+)
+
+// Sections lists every section, in the order they are restored
+var Sections = []Section{
+	SectionSettings,
+	SectionTags,
+	SectionTeams,
+	SectionPermissions,
+	SectionLabels,
+	SectionBlacklist,
+	SectionIntegrations,
+}
+
+// Document is a guild's configuration, excluding panels and forms, which are covered by the panel export. Teams and
+// labels are identified by name rather than ID, so that they can be recreated after being deleted.
+type Document struct {
+	Version      int                   `json:"version"`
+	GuildId      uint64                `json:"guild_id,string"`
+	CreatedAt    time.Time             `json:"created_at"`
+	Settings     api_settings.Settings `json:"settings"`
+	Tags         []Tag                 `json:"tags"`
+	Teams        []Team                `json:"teams"`
+	Permissions  Permissions           `json:"permissions"`
+	Labels       []Label               `json:"labels"`
+	Blacklist    Blacklist             `json:"blacklist"`
+	Integrations []Integration         `json:"integrations"`
+}
+
+type (
+	Tag struct {
+		Id              string             `json:"id"`
+		UseGuildCommand bool               `json:"use_guild_command"`
+		Content         *string            `json:"content"`
+		Embed           *types.CustomEmbed `json:"embed"`
+	}
+
+	Team struct {
+		Name        string                          `json:"name"`
+		Users       types.UInt64StringSlice         `json:"users"`
+		Roles       types.UInt64StringSlice         `json:"roles"`
+		Permissions database.SupportTeamPermissions `json:"permissions"`
+	}
+
+	// Permissions are the members of the default team, and the bot admins. Admins are implicitly support, so aren't
+	// repeated in the support lists.
+	Permissions struct {
+		AdminUsers   types.UInt64StringSlice `json:"admin_users"`
+		AdminRoles   types.UInt64StringSlice `json:"admin_roles"`
+		SupportUsers types.UInt64StringSlice `json:"support_users"`
+		SupportRoles types.UInt64StringSlice `json:"support_roles"`
+	}
+
+	Label struct {
+		Name   string `json:"name"`
+		Colour int32  `json:"colour"`
+	}
+
+	Blacklist struct {
+		Users types.UInt64StringSlice `json:"users"`
+		Roles types.UInt64StringSlice `json:"roles"`
+	}
+
+	// Integration is an activated integration. Only the names of its secrets are kept: the values must be provided
+	// again to restore it.
+	Integration struct {
+		Id          int      `json:"id"`
+		Name        string   `json:"name"`
+		SecretNames []string `json:"secret_names"`
+	}
+)
+
+const blacklistPageSize = 1000
+
+// Build captures the guild's current configuration
+func Build(ctx context.Context, guildId uint64) (Document, error) {
+	document := Document{
+		Version:   DocumentVersion,
+		GuildId:   guildId,
+		CreatedAt: time.Now(),
+	}
+
+	group, _ := errgroup.WithContext(ctx)
+
+	group.Go(func() (err error) {
+		document.Settings, err = api_settings.LoadSettings(ctx, guildId)
+		return
+	})
+
+	group.Go(func() (err error) {
+		document.Tags, err = buildTags(ctx, guildId)
+		return
+	})
+
+	group.Go(func() (err error) {
+		document.Teams, err = buildTeams(ctx, guildId)
+		return
+	})
+
+	group.Go(func() (err error) {
+		document.Permissions, err = buildPermissions(ctx, guildId)
+		return
+	})
+
+	group.Go(func() (err error) {
+		document.Labels, err = buildLabels(ctx, guildId)
+		return
+	})
+
+	group.Go(func() (err error) {
+		document.Blacklist, err = buildBlacklist(ctx, guildId)
+		return
+	})
+
+	group.Go(func() (err error) {
+		document.Integrations, err = buildIntegrations(ctx, guildId)
+		return
+	})
+
+	if err := group.Wait(); err != nil {
+		return Document{}, err
+	}
+
+	return document, nil
+}
+
+func buildTags(ctx context.Context, guildId uint64) ([]Tag, error) {
+	tags, err := dbclient.Client.Tag.GetByGuild(ctx, guildId)
+	if err != nil {
+		return nil, err
+	}
+
+	converted := make([]Tag, 0, len(tags))
+	for _, tag := range tags {
+		var embed *types.CustomEmbed
+		if tag.Embed != nil {
+			embed = types.NewCustomEmbed(tag.Embed.CustomEmbed, tag.Embed.Fields)
+		}
+
+		converted = append(converted, Tag{
+			Id:              tag.Id,
+			UseGuildCommand: tag.ApplicationCommandId != nil,
+			Content:         tag.Content,
+			Embed:           embed,
+		})
+	}
+
+	sort.Slice(converted, func(i, j int) bool {
+		return converted[i].Id < converted[j].Id
+	})
+
+	return converted, nil
+}
+
+func buildTeams(ctx context.Context, guildId uint64) ([]Team, error) {
+	teams, err := dbclient.Client.SupportTeam.Get(ctx, guildId)
+	if err != nil {
+		return nil, err
+	}
+
+	converted := make([]Team, len(teams))
+	for i, team := range teams {
+		users, err := dbclient.Client.SupportTeamMembers.Get(ctx, team.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		roles, err := dbclient.Client.SupportTeamRoles.Get(ctx, team.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		permissions, err := dbclient.Client.SupportTeamPermissions.Get(ctx, team.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		converted[i] = Team{
+			Name:        team.Name,
+			Users:       sortedIds(users),
+			Roles:       sortedIds(roles),
+			Permissions: permissions,
+		}
+	}
+
+	sort.Slice(converted, func(i, j int) bool {
+		return converted[i].Name < converted[j].Name
+	})
+
+	return converted, nil
+}
+
+func buildPermissions(ctx context.Context, guildId uint64) (permissions Permissions, err error) {
+	adminUsers, err := dbclient.Client.Permissions.GetAdmins(ctx, guildId)
+	if err != nil {
+		return
+	}
+
+	supportUsers, err := dbclient.Client.Permissions.GetSupportOnly(ctx, guildId)
+	if err != nil {
+		return
+	}
+
+	adminRoles, err := dbclient.Client.RolePermissions.GetAdminRoles(ctx, guildId)
+	if err != nil {
+		return
+	}
+
+	supportRoles, err := dbclient.Client.RolePermissions.GetSupportRolesOnly(ctx, guildId)
+	if err != nil {
+		return
+	}
+
+	return Permissions{
+		AdminUsers:   sortedIds(adminUsers),
+		AdminRoles:   sortedIds(adminRoles),
+		SupportUsers: sortedIds(supportUsers),
+		SupportRoles: sortedIds(supportRoles),
+	}, nil
+}
+
+func buildLabels(ctx context.Context, guildId uint64) ([]Label, error) {
+	labels, err := dbclient.Client.TicketLabels.GetByGuild(ctx, guildId)
+	if err != nil {
+		return nil, err
+	}
+
+	converted := make([]Label, len(labels))
+	for i, label := range labels {
+		converted[i] = Label{
+			Name:   label.Name,
+			Colour: label.Colour,
+		}
+	}
+
+	sort.Slice(converted, func(i, j int) bool {
+		return converted[i].Name < converted[j].Name
+	})
+
+	return converted, nil
+}
+
+func buildBlacklist(ctx context.Context, guildId uint64) (Blacklist, error) {
+	var users []uint64
+	for offset := 0; ; offset += blacklistPageSize {
+		page, err := dbclient.Client.Blacklist.GetBlacklistedUsers(ctx, guildId, blacklistPageSize, offset)
+		if err != nil {
+			return Blacklist{}, err
+		}
+
+		users = append(users, page...)
+		if len(page) < blacklistPageSize {
+			break
+		}
+	}
+
+	roles, err := dbclient.Client.RoleBlacklist.GetBlacklistedRoles(ctx, guildId)
+	if err != nil {
+		return Blacklist{}, err
+	}
+
+	return Blacklist{
+		Users: sortedIds(users),
+		Roles: sortedIds(roles),
+	}, nil
+}
+
+func buildIntegrations(ctx context.Context, guildId uint64) ([]Integration, error) {
+	integrations, err := dbclient.Client.CustomIntegrationGuilds.GetGuildIntegrations(ctx, guildId)
+	if err != nil {
+		return nil, err
+	}
+
+	converted := make([]Integration, len(integrations))
+	for i, integration := range integrations {
+		secretNames, err := integrationSecretNames(ctx, integration.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		converted[i] = Integration{
+			Id:          integration.Id,
+			Name:        integration.Name,
+			SecretNames: secretNames,
+		}
+	}
+
+	sort.Slice(converted, func(i, j int) bool {
+		return converted[i].Id < converted[j].Id
+	})
+
+	return converted, nil
+}
+
+func integrationSecretNames(ctx context.Context, integrationId int) ([]string, error) {
+	secrets, err := dbclient.Client.CustomIntegrationSecrets.GetByIntegration(ctx, integrationId)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, len(secrets))
+	for i, secret := range secrets {
+		names[i] = secret.Name
+	}
+
+	sort.Strings(names)
+	return names, nil
+}
+
+// sortedIds returns a sorted copy of the IDs, so that documents can be compared regardless of database ordering
+func sortedIds(ids []uint64) types.UInt64StringSlice {
+	sorted := make(types.UInt64StringSlice, len(ids))
+	copy(sorted, ids)
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+
+	return sorted
+}
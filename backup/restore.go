@@ -0,0 +1,779 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/TicketsBot-cloud/common/premium"
+	api_integrations "github.com/TicketsBot-cloud/dashboard/app/http/endpoints/api/integrations"
+	api_settings "github.com/TicketsBot-cloud/dashboard/app/http/endpoints/api/settings"
+	"github.com/TicketsBot-cloud/dashboard/app/http/validation"
+	"github.com/TicketsBot-cloud/dashboard/botcontext"
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/dashboard/log"
+	"github.com/TicketsBot-cloud/dashboard/rpc"
+	"github.com/TicketsBot-cloud/database"
+	"github.com/TicketsBot-cloud/gdl/objects/channel"
+	"github.com/TicketsBot-cloud/gdl/objects/interaction"
+	"github.com/TicketsBot-cloud/gdl/rest"
+	"go.uber.org/zap"
+)
+
+// Limits enforced by the endpoints that create each resource
+const (
+	maxTags   = 200
+	maxLabels = 50
+)
+
+var slashCommandRegex = regexp.MustCompile(`^[-_a-zA-Z0-9]{1,32}$`)
+
+// RestoreResult describes a restore. Applied lists the sections which were written, in order, so that if a section
+// fails, it is clear which of those before it have already been restored.
+type RestoreResult struct {
+	Sections []Section               `json:"sections"`
+	Applied  []Section               `json:"applied"`
+	DryRun   bool                    `json:"dry_run"`
+	Diff     map[Section]SectionDiff `json:"diff"`
+	Warnings []string                `json:"warnings"`
+}
+
+type restorer struct {
+	guildId     uint64
+	userId      uint64
+	botContext  *botcontext.BotContext
+	premiumTier premium.PremiumTier
+	channels    []channel.Channel
+	current     Document
+	target      Document
+	// integration ID -> secret name -> value, for integrations which are not active
+	secretValues map[int]map[string]string
+	integrations map[int]database.CustomIntegration
+	result       *RestoreResult
+}
+
+// ParseSections validates the requested section names, defaulting to every section
+func ParseSections(names []string) ([]Section, error) {
+	if len(names) == 0 {
+		return Sections, nil
+	}
+
+	requested := make(map[Section]bool)
+	for _, name := range names {
+		section := Section(name)
+
+		valid := false
+		for _, known := range Sections {
+			if section == known {
+				valid = true
+				break
+			}
+		}
+
+		if !valid {
+			return nil, validation.NewInvalidInputErrorf("Unknown backup section: %s", name)
+		}
+
+		requested[section] = true
+	}
+
+	// Keep the restore order
+	var sections []Section
+	for _, section := range Sections {
+		if requested[section] {
+			sections = append(sections, section)
+		}
+	}
+
+	return sections, nil
+}
+
+// Restore applies the given sections of the document to the guild. Every section is validated before anything is
+// written, and a *validation.InvalidInputError is returned if any are invalid. When dryRun is set, only the diff is
+// computed. Backups don't contain integration secret values, so secretValues holds the values for each integration
+// which is not already active, keyed by integration ID and then secret name.
+//
+// Sections are not written in a single transaction, as some also update Discord. If a section fails, the result is
+// returned alongside the error, listing the sections which were restored before it.
+func Restore(ctx context.Context, guildId, userId uint64, target Document, sections []Section, secretValues map[int]map[string]string, dryRun bool) (RestoreResult, error) {
+	if target.Version < 1 || target.Version > DocumentVersion {
+		return RestoreResult{}, validation.NewInvalidInputErrorf("Unsupported backup version: %d", target.Version)
+	}
+
+	botContext, err := botcontext.ContextForGuild(guildId)
+	if err != nil {
+		return RestoreResult{}, err
+	}
+
+	premiumTier, err := rpc.PremiumClient.GetTierByGuildId(ctx, guildId, true, botContext.Token, botContext.RateLimiter)
+	if err != nil {
+		return RestoreResult{}, err
+	}
+
+	channels, err := botContext.GetGuildChannels(ctx, guildId)
+	if err != nil {
+		return RestoreResult{}, err
+	}
+
+	current, err := Build(ctx, guildId)
+	if err != nil {
+		return RestoreResult{}, err
+	}
+
+	r := &restorer{
+		guildId:      guildId,
+		userId:       userId,
+		botContext:   botContext,
+		premiumTier:  premiumTier,
+		channels:     channels,
+		current:      current,
+		target:       target,
+		secretValues: secretValues,
+		integrations: make(map[int]database.CustomIntegration),
+		result: &RestoreResult{
+			Sections: sections,
+			Applied:  make([]Section, 0),
+			DryRun:   dryRun,
+			Warnings: make([]string, 0),
+		},
+	}
+
+	for _, section := range sections {
+		if err := r.validate(ctx, section); err != nil {
+			return RestoreResult{}, err
+		}
+	}
+
+	r.result.Diff = Diff(r.current, r.target, sections)
+
+	if dryRun {
+		return *r.result, nil
+	}
+
+	for _, section := range sections {
+		if r.result.Diff[section].Empty() {
+			continue
+		}
+
+		if err := r.apply(ctx, section); err != nil {
+			return *r.result, fmt.Errorf("failed to restore %s: %w", section, err)
+		}
+
+		r.result.Applied = append(r.result.Applied, section)
+	}
+
+	return *r.result, nil
+}
+
+func (r *restorer) warn(format string, args ...any) {
+	r.result.Warnings = append(r.result.Warnings, fmt.Sprintf(format, args...))
+}
+
+func (r *restorer) validate(ctx context.Context, section Section) error {
+	switch section {
+	case SectionSettings:
+		if r.target.Settings.Colours == nil {
+			r.target.Settings.Colours = make(api_settings.ColourMap)
+		}
+
+		// Validate also fills in defaults, so must run before the diff is computed
+		if err := r.target.Settings.Validate(ctx, r.guildId, r.premiumTier); err != nil {
+			return validation.NewInvalidInputErrorf("Settings: %s", err.Error())
+		}
+	case SectionTags:
+		return r.validateTags()
+	case SectionTeams:
+		return r.validateTeams()
+	case SectionLabels:
+		return r.validateLabels()
+	case SectionIntegrations:
+		return r.validateIntegrations(ctx)
+	}
+
+	return nil
+}
+
+func (r *restorer) apply(ctx context.Context, section Section) error {
+	switch section {
+	case SectionSettings:
+		return r.applySettings(ctx)
+	case SectionTags:
+		return r.applyTags(ctx)
+	case SectionTeams:
+		return r.applyTeams(ctx)
+	case SectionPermissions:
+		return r.applyPermissions(ctx)
+	case SectionLabels:
+		return r.applyLabels(ctx)
+	case SectionBlacklist:
+		return r.applyBlacklist(ctx)
+	case SectionIntegrations:
+		return r.applyIntegrations(ctx)
+	}
+
+	return nil
+}
+
+func (r *restorer) validateTags() error {
+	if len(r.target.Tags) > maxTags {
+		return validation.NewInvalidInputErrorf("Backups can contain at most %d tags", maxTags)
+	}
+
+	seen := make(map[string]bool)
+	for i := range r.target.Tags {
+		tag := &r.target.Tags[i]
+		tag.Id = strings.ToLower(tag.Id)
+
+		if len(tag.Id) == 0 || len(tag.Id) > 16 || strings.Contains(tag.Id, " ") {
+			return validation.NewInvalidInputErrorf("Invalid tag ID: %s", tag.Id)
+		}
+
+		if seen[tag.Id] {
+			return validation.NewInvalidInputErrorf("Duplicate tag: %s", tag.Id)
+		}
+
+		seen[tag.Id] = true
+
+		if tag.Content != nil && (len(*tag.Content) == 0 || len(*tag.Content) > 2000) {
+			return validation.NewInvalidInputErrorf("Tag %s: content must be between 1 and 2000 characters", tag.Id)
+		}
+
+		if tag.Content == nil && tag.Embed == nil {
+			return validation.NewInvalidInputErrorf("Tag %s has no content", tag.Id)
+		}
+
+		if tag.Embed != nil && tag.Embed.TotalCharacterCount() > 6000 {
+			return validation.NewInvalidInputErrorf("Tag %s: embed exceeds Discord's 6000 character limit", tag.Id)
+		}
+
+		if tag.UseGuildCommand && !slashCommandRegex.MatchString(tag.Id) {
+			return validation.NewInvalidInputErrorf("Tag %s cannot be used as a guild command", tag.Id)
+		}
+	}
+
+	return nil
+}
+
+func (r *restorer) validateTeams() error {
+	seen := make(map[string]bool)
+	for _, team := range r.target.Teams {
+		if len(team.Name) == 0 || len(team.Name) > 32 {
+			return validation.NewInvalidInputError("Team names must be between 1 and 32 characters")
+		}
+
+		if seen[team.Name] {
+			return validation.NewInvalidInputErrorf("Duplicate team: %s", team.Name)
+		}
+
+		seen[team.Name] = true
+	}
+
+	return nil
+}
+
+func (r *restorer) validateLabels() error {
+	if len(r.target.Labels) > maxLabels {
+		return validation.NewInvalidInputErrorf("Backups can contain at most %d labels", maxLabels)
+	}
+
+	seen := make(map[string]bool)
+	for _, label := range r.target.Labels {
+		if len(label.Name) < 1 || len(label.Name) > 32 {
+			return validation.NewInvalidInputError("Label names must be between 1 and 32 characters")
+		}
+
+		if label.Colour < 0 || label.Colour > 0xFFFFFF {
+			return validation.NewInvalidInputErrorf("Label %s has an invalid colour", label.Name)
+		}
+
+		if seen[label.Name] {
+			return validation.NewInvalidInputErrorf("Duplicate label: %s", label.Name)
+		}
+
+		seen[label.Name] = true
+	}
+
+	return nil
+}
+
+// validateIntegrations checks which integrations can be restored. Active integrations are kept as they are. Others
+// are only activated if their secret values have been provided, and are dropped with a warning otherwise, as are
+// integrations which have since been deleted, or which the restoring user may no longer activate. The values are
+// checked when the integration is activated.
+func (r *restorer) validateIntegrations(ctx context.Context) error {
+	if len(r.target.Integrations) > api_integrations.MaxActiveIntegrations {
+		return validation.NewInvalidInputErrorf("Backups can contain at most %d integrations", api_integrations.MaxActiveIntegrations)
+	}
+
+	active := make(map[int]Integration)
+	for _, integration := range r.current.Integrations {
+		active[integration.Id] = integration
+	}
+
+	var restorable []Integration
+	for _, integration := range r.target.Integrations {
+		if current, ok := active[integration.Id]; ok {
+			restorable = append(restorable, current)
+			continue
+		}
+
+		stored, ok, err := dbclient.Client.CustomIntegrations.Get(ctx, integration.Id)
+		if err != nil {
+			return err
+		}
+
+		if !ok {
+			r.warn("Integration %s no longer exists, and will not be restored", integration.Name)
+			continue
+		}
+
+		canActivate, err := dbclient.Client.CustomIntegrationGuilds.CanActivate(ctx, integration.Id, r.userId)
+		if err != nil {
+			return err
+		}
+
+		if !canActivate {
+			r.warn("You do not have permission to activate integration %s, so it will not be restored", integration.Name)
+			continue
+		}
+
+		secretNames, err := integrationSecretNames(ctx, integration.Id)
+		if err != nil {
+			return err
+		}
+
+		if len(secretNames) > 0 && len(r.secretValues[integration.Id]) == 0 {
+			r.warn("No secret values were provided for integration %s, so it will not be restored", integration.Name)
+			continue
+		}
+
+		r.integrations[integration.Id] = stored
+		restorable = append(restorable, Integration{
+			Id:          integration.Id,
+			Name:        stored.Name,
+			SecretNames: secretNames,
+		})
+	}
+
+	r.target.Integrations = restorable
+	return nil
+}
+
+func (r *restorer) applySettings(ctx context.Context) error {
+	result, err := r.target.Settings.Apply(ctx, r.guildId, r.premiumTier, r.channels)
+	if err != nil {
+		return err
+	}
+
+	if _, err := api_settings.RecordHistory(ctx, r.guildId, r.userId, r.current.Settings, r.target.Settings, result, r.premiumTier, nil); err != nil {
+		log.Logger.Error("Failed to record settings history", zap.Uint64("guild_id", r.guildId), zap.Error(err))
+	}
+
+	if !result.WelcomeMessage {
+		r.warn("The welcome message was invalid, and was not restored")
+	}
+
+	if !result.TicketLimit {
+		r.warn("The ticket limit was invalid, and was not restored")
+	}
+
+	if !result.ArchiveChannel {
+		r.warn("The archive channel no longer exists, and was not restored")
+	}
+
+	if !result.Category {
+		r.warn("The ticket category no longer exists, and was not restored")
+	}
+
+	if !result.NamingScheme {
+		r.warn("The naming scheme was invalid, and was not restored")
+	}
+
+	return nil
+}
+
+func (r *restorer) applyTags(ctx context.Context) error {
+	existing, err := dbclient.Client.Tag.GetByGuild(ctx, r.guildId)
+	if err != nil {
+		return err
+	}
+
+	currentById := make(map[string]Tag)
+	for _, tag := range r.current.Tags {
+		currentById[tag.Id] = tag
+	}
+
+	targetIds := make(map[string]bool)
+	for _, tag := range r.target.Tags {
+		targetIds[tag.Id] = true
+
+		if current, ok := currentById[tag.Id]; ok && reflect.DeepEqual(current, tag) {
+			continue
+		}
+
+		var applicationCommandId *uint64
+		if existingTag, ok := existing[tag.Id]; ok {
+			applicationCommandId = existingTag.ApplicationCommandId
+		}
+
+		if tag.UseGuildCommand && applicationCommandId == nil {
+			if r.premiumTier < premium.Premium {
+				r.warn("Premium is required to restore the guild command for tag %s", tag.Id)
+			} else {
+				cmd, err := r.botContext.CreateGuildCommand(ctx, r.guildId, rest.CreateCommandData{
+					Name:        tag.Id,
+					Description: fmt.Sprintf("Alias for /tag %s", tag.Id),
+					Options:     nil,
+					Type:        interaction.ApplicationCommandTypeChatInput,
+				})
+
+				if err != nil {
+					r.warn("Failed to create the guild command for tag %s", tag.Id)
+				} else {
+					applicationCommandId = &cmd.Id
+				}
+			}
+		} else if !tag.UseGuildCommand && applicationCommandId != nil {
+			if err := r.botContext.DeleteGuildCommand(ctx, r.guildId, *applicationCommandId); err != nil {
+				r.warn("Failed to delete the guild command for tag %s", tag.Id)
+			}
+
+			applicationCommandId = nil
+		}
+
+		var embed *database.CustomEmbedWithFields
+		if tag.Embed != nil {
+			customEmbed, fields := tag.Embed.IntoDatabaseStruct()
+			embed = &database.CustomEmbedWithFields{
+				CustomEmbed: customEmbed,
+				Fields:      fields,
+			}
+		}
+
+		if err := dbclient.Client.Tag.Set(ctx, database.Tag{
+			Id:                   tag.Id,
+			GuildId:              r.guildId,
+			Content:              tag.Content,
+			Embed:                embed,
+			ApplicationCommandId: applicationCommandId,
+		}); err != nil {
+			return err
+		}
+	}
+
+	for id, tag := range existing {
+		if targetIds[id] {
+			continue
+		}
+
+		if tag.ApplicationCommandId != nil {
+			if err := r.botContext.DeleteGuildCommand(ctx, r.guildId, *tag.ApplicationCommandId); err != nil {
+				r.warn("Failed to delete the guild command for tag %s", id)
+			}
+		}
+
+		if err := dbclient.Client.Tag.Delete(ctx, r.guildId, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *restorer) applyTeams(ctx context.Context) error {
+	existing, err := dbclient.Client.SupportTeam.Get(ctx, r.guildId)
+	if err != nil {
+		return err
+	}
+
+	idsByName := make(map[string]int)
+	for _, team := range existing {
+		idsByName[team.Name] = team.Id
+	}
+
+	currentByName := make(map[string]Team)
+	for _, team := range r.current.Teams {
+		currentByName[team.Name] = team
+	}
+
+	targetNames := make(map[string]bool)
+	for _, team := range r.target.Teams {
+		targetNames[team.Name] = true
+
+		current := currentByName[team.Name]
+
+		teamId, ok := idsByName[team.Name]
+		if !ok {
+			teamId, err = dbclient.Client.SupportTeam.Create(ctx, r.guildId, team.Name)
+			if err != nil {
+				return err
+			}
+		}
+
+		added, removed := idChanges(current.Users, team.Users)
+		for _, userId := range added {
+			if err := dbclient.Client.SupportTeamMembers.Add(ctx, teamId, userId); err != nil {
+				return err
+			}
+		}
+
+		for _, userId := range removed {
+			if err := dbclient.Client.SupportTeamMembers.Delete(ctx, teamId, userId); err != nil {
+				return err
+			}
+		}
+
+		added, removed = idChanges(current.Roles, team.Roles)
+		for _, roleId := range added {
+			if err := dbclient.Client.SupportTeamRoles.Add(ctx, teamId, roleId); err != nil {
+				return err
+			}
+		}
+
+		for _, roleId := range removed {
+			if err := dbclient.Client.SupportTeamRoles.Delete(ctx, teamId, roleId); err != nil {
+				return err
+			}
+		}
+
+		if !ok || current.Permissions != team.Permissions {
+			if err := dbclient.Client.SupportTeamPermissions.Set(ctx, teamId, team.Permissions); err != nil {
+				return err
+			}
+		}
+	}
+
+	for name, teamId := range idsByName {
+		if targetNames[name] {
+			continue
+		}
+
+		if err := dbclient.Client.SupportTeam.Delete(ctx, teamId); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+const (
+	permissionLevelNone = iota
+	permissionLevelSupport
+	permissionLevelAdmin
+)
+
+func (r *restorer) applyPermissions(ctx context.Context) error {
+	users := levelChanges(r.current.Permissions.AdminUsers, r.current.Permissions.SupportUsers,
+		r.target.Permissions.AdminUsers, r.target.Permissions.SupportUsers)
+
+	for userId, level := range users {
+		var err error
+		switch level {
+		case permissionLevelAdmin:
+			err = dbclient.Client.Permissions.AddAdmin(ctx, r.guildId, userId)
+		case permissionLevelSupport:
+			err = dbclient.Client.Permissions.AddSupport(ctx, r.guildId, userId)
+		default:
+			err = dbclient.Client.Permissions.RemoveSupport(ctx, r.guildId, userId)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	roles := levelChanges(r.current.Permissions.AdminRoles, r.current.Permissions.SupportRoles,
+		r.target.Permissions.AdminRoles, r.target.Permissions.SupportRoles)
+
+	for roleId, level := range roles {
+		var err error
+		switch level {
+		case permissionLevelAdmin:
+			err = dbclient.Client.RolePermissions.AddAdmin(ctx, r.guildId, roleId)
+		case permissionLevelSupport:
+			err = dbclient.Client.RolePermissions.AddSupport(ctx, r.guildId, roleId)
+		default:
+			err = dbclient.Client.RolePermissions.RemoveSupport(ctx, r.guildId, roleId)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *restorer) applyLabels(ctx context.Context) error {
+	existing, err := dbclient.Client.TicketLabels.GetByGuild(ctx, r.guildId)
+	if err != nil {
+		return err
+	}
+
+	byName := make(map[string]database.TicketLabel)
+	for _, label := range existing {
+		byName[label.Name] = label
+	}
+
+	targetNames := make(map[string]bool)
+	for _, label := range r.target.Labels {
+		targetNames[label.Name] = true
+
+		existingLabel, ok := byName[label.Name]
+		if !ok {
+			if _, err := dbclient.Client.TicketLabels.Create(ctx, r.guildId, label.Name, label.Colour); err != nil {
+				return err
+			}
+		} else if existingLabel.Colour != label.Colour {
+			if err := dbclient.Client.TicketLabels.Update(ctx, r.guildId, existingLabel.LabelId, label.Name, label.Colour); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, label := range existing {
+		if targetNames[label.Name] {
+			continue
+		}
+
+		if err := dbclient.Client.TicketLabels.Delete(ctx, r.guildId, label.LabelId); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *restorer) applyBlacklist(ctx context.Context) error {
+	added, removed := idChanges(r.current.Blacklist.Users, r.target.Blacklist.Users)
+	for _, userId := range added {
+		if err := dbclient.Client.Blacklist.Add(ctx, r.guildId, userId); err != nil {
+			return err
+		}
+	}
+
+	for _, userId := range removed {
+		if err := dbclient.Client.Blacklist.Remove(ctx, r.guildId, userId); err != nil {
+			return err
+		}
+	}
+
+	added, removed = idChanges(r.current.Blacklist.Roles, r.target.Blacklist.Roles)
+	for _, roleId := range added {
+		if err := dbclient.Client.RoleBlacklist.Add(ctx, r.guildId, roleId); err != nil {
+			return err
+		}
+	}
+
+	for _, roleId := range removed {
+		if err := dbclient.Client.RoleBlacklist.Remove(ctx, r.guildId, roleId); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// applyIntegrations removes integrations first, so that they don't count towards the limit checked when the others
+// are activated. Integrations which fail activation, e.g. because their secret values are rejected, are skipped with
+// a warning.
+func (r *restorer) applyIntegrations(ctx context.Context) error {
+	targetIds := make(map[int]bool)
+	for _, integration := range r.target.Integrations {
+		targetIds[integration.Id] = true
+	}
+
+	active := make(map[int]bool)
+	for _, integration := range r.current.Integrations {
+		active[integration.Id] = true
+
+		if targetIds[integration.Id] {
+			continue
+		}
+
+		if err := dbclient.Client.CustomIntegrationGuilds.RemoveFromGuild(ctx, integration.Id, r.guildId); err != nil {
+			return err
+		}
+	}
+
+	for _, integration := range r.target.Integrations {
+		if active[integration.Id] {
+			continue
+		}
+
+		err := api_integrations.Activate(ctx, r.integrations[integration.Id], r.guildId, r.userId, r.secretValues[integration.Id])
+		if err != nil {
+			var activationError *api_integrations.ActivationError
+			if !errors.As(err, &activationError) {
+				return err
+			}
+
+			r.warn("Integration %s could not be activated, so was not restored: %s", integration.Name, activationError.Message)
+		}
+	}
+
+	return nil
+}
+
+// idChanges returns the IDs which must be added to and removed from current for it to match target
+func idChanges(current, target []uint64) (added, removed []uint64) {
+	currentIds := make(map[uint64]bool)
+	for _, id := range current {
+		currentIds[id] = true
+	}
+
+	targetIds := make(map[uint64]bool)
+	for _, id := range target {
+		targetIds[id] = true
+
+		if !currentIds[id] {
+			added = append(added, id)
+		}
+	}
+
+	for _, id := range current {
+		if !targetIds[id] {
+			removed = append(removed, id)
+		}
+	}
+
+	return
+}
+
+// levelChanges returns the permission level each user or role must be set to for current to match target, omitting
+// those which are already correct
+func levelChanges(currentAdmins, currentSupport, targetAdmins, targetSupport []uint64) map[uint64]int {
+	current := permissionLevels(currentAdmins, currentSupport)
+	target := permissionLevels(targetAdmins, targetSupport)
+
+	changes := make(map[uint64]int)
+	for id, level := range target {
+		if current[id] != level {
+			changes[id] = level
+		}
+	}
+
+	for id := range current {
+		if _, ok := target[id]; !ok {
+			changes[id] = permissionLevelNone
+		}
+	}
+
+	return changes
+}
+
+func permissionLevels(admins, support []uint64) map[uint64]int {
+	levels := make(map[uint64]int)
+	for _, id := range support {
+		levels[id] = permissionLevelSupport
+	}
+
+	for _, id := range admins {
+		levels[id] = permissionLevelAdmin
+	}
+
+	return levels
+}
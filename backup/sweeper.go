@@ -0,0 +1,94 @@
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/dashboard/jobs"
+	"github.com/TicketsBot-cloud/dashboard/log"
+	"go.uber.org/zap"
+)
+
+// keepLatest is the number of automatic snapshots kept for each guild regardless of age, so that a guild whose
+// configuration rarely changes still has a copy from before its most recent change
+const keepLatest = 2
+
+// RunSweeper periodically snapshots the configuration of every guild which has changed since its last snapshot, and
+// deletes automatic snapshots older than the retention period. Only one API instance performs each run.
+func RunSweeper(interval, retention time.Duration) {
+	jobs.Run("config-backups", interval, func(ctx context.Context) {
+		sweep(ctx, interval, retention)
+	})
+}
+
+func sweep(ctx context.Context, interval, retention time.Duration) {
+	deleted, err := dbclient.Dashboard.ConfigBackups.DeleteExpiredAutomatic(ctx, time.Now().Add(-retention), keepLatest)
+	if err != nil {
+		log.Logger.Error("Failed to delete expired config backups", zap.Error(err))
+	} else if deleted > 0 {
+		log.Logger.Info("Deleted expired config backups", zap.Int64("count", deleted))
+	}
+
+	// Look back over two intervals, in case a previous run was missed
+	guildIds, err := dbclient.Dashboard.ConfigBackups.GetGuildsChangedSince(ctx, time.Now().Add(-interval*2))
+	if err != nil {
+		log.Logger.Error("Failed to fetch guilds with configuration changes", zap.Error(err))
+		return
+	}
+
+	for _, guildId := range guildIds {
+		if ctx.Err() != nil {
+			log.Logger.Warn("Config backup sweep timed out, remaining guilds will be snapshotted next run")
+			return
+		}
+
+		if err := snapshot(ctx, guildId); err != nil {
+			log.Logger.Error("Failed to snapshot guild configuration", zap.Uint64("guild_id", guildId), zap.Error(err))
+		}
+	}
+}
+
+// snapshot stores an automatic backup of the guild, unless nothing has changed since the previous one
+func snapshot(ctx context.Context, guildId uint64) error {
+	document, err := Build(ctx, guildId)
+	if err != nil {
+		return err
+	}
+
+	latest, ok, err := dbclient.Dashboard.ConfigBackups.GetLatestAutomatic(ctx, guildId)
+	if err != nil {
+		return err
+	}
+
+	if ok && latest.Version == DocumentVersion {
+		var previous Document
+		if err := json.Unmarshal(latest.Data, &previous); err == nil && isUnchanged(previous, document) {
+			return nil
+		}
+	}
+
+	_, err = Save(ctx, guildId, nil, document)
+	return err
+}
+
+// Save stores the document as a backup. Backups without a creator are automatic snapshots.
+func Save(ctx context.Context, guildId uint64, createdBy *uint64, document Document) (dbclient.ConfigBackup, error) {
+	data, err := json.Marshal(document)
+	if err != nil {
+		return dbclient.ConfigBackup{}, err
+	}
+
+	return dbclient.Dashboard.ConfigBackups.Create(ctx, guildId, createdBy, createdBy == nil, document.Version, data)
+}
+
+func isUnchanged(previous, current Document) bool {
+	for _, diff := range Diff(previous, current, Sections) {
+		if !diff.Empty() {
+			return false
+		}
+	}
+
+	return true
+}
@@ -1,44 +1,94 @@
 package chatreplica
 
 import (
+	"context"
 	"fmt"
+	"regexp"
 	"strconv"
 
+	"github.com/TicketsBot-cloud/gdl/objects/channel/embed"
 	"github.com/TicketsBot-cloud/gdl/objects/channel/message"
+	"github.com/TicketsBot-cloud/gdl/objects/user"
 )
 
-func FromArchiveMessages(messages []message.Message, ticketId int) Payload {
+var (
+	userMentionRegex    = regexp.MustCompile(`<@!?(\d{16,20})>`)
+	roleMentionRegex    = regexp.MustCompile(`<@&(\d{16,20})>`)
+	channelMentionRegex = regexp.MustCompile(`<#(\d{16,20})>`)
+)
+
+// FromArchiveMessages converts a transcript stored in the V1 format. V1 transcripts only store message authors, so
+// any other mentioned users, channels and roles are looked up through the resolver, which may be nil.
+func FromArchiveMessages(ctx context.Context, messages []message.Message, ticketId int, resolver EntityResolver) Payload {
 	users := make(map[string]User)
 	var wrappedMessages []Message // Cannot define length because of continue
 
+	mentions := newMentionSet()
+
 	for _, msg := range messages {
-		// If all 3 are missing, server will 400
-		if msg.Content == "" && len(msg.Embeds) == 0 && len(msg.Attachments) == 0 {
+		// If all 3 are missing, server will 400. System messages, such as pins, have no content, but should still be
+		// displayed.
+		if isUserMessage(msg.Type) && msg.Content == "" && len(msg.Embeds) == 0 && len(msg.Attachments) == 0 {
 			continue
 		}
 
-		wrappedMessages = append(wrappedMessages, Message{
+		wrapped := Message{
 			Id:          msg.Id,
 			Type:        msg.Type,
 			Author:      msg.Author.Id,
 			Time:        msg.Timestamp.UnixMilli(),
 			Content:     msg.Content,
 			Embeds:      msg.Embeds,
+			Components:  msg.Components,
 			Attachments: msg.Attachments,
-		})
+		}
+
+		if msg.MessageReference.MessageId != 0 {
+			wrapped.Reference = &msg.MessageReference.MessageId
+		}
+
+		wrappedMessages = append(wrappedMessages, wrapped)
 
 		// Add user to entities map
-		snowflake := strconv.FormatUint(msg.Author.Id, 10)
-		if _, ok := users[snowflake]; !ok {
-			var badge *Badge
-			if msg.Author.Bot {
-				badge = badgePtr(BadgeBot)
+		addUser(users, msg.Author)
+
+		// Mentioned users are sent with the message, so don't need to be resolved
+		for _, mentioned := range msg.Mentions {
+			addUser(users, mentioned.User)
+		}
+
+		mentions.addRoles(msg.MentionRoles...)
+		mentions.scan(msg.Content)
+		for _, e := range msg.Embeds {
+			mentions.scanEmbed(e)
+		}
+	}
+
+	channels := make(map[string]Channel)
+	roles := make(map[string]Role)
+
+	if resolver != nil {
+		var unresolvedUsers []uint64
+		for id := range mentions.users {
+			if _, ok := users[strconv.FormatUint(id, 10)]; !ok {
+				unresolvedUsers = append(unresolvedUsers, id)
 			}
+		}
+
+		for _, u := range resolver.ResolveUsers(ctx, unresolvedUsers) {
+			addUser(users, u)
+		}
 
-			users[snowflake] = User{
-				Avatar:   msg.Author.AvatarUrl(256),
-				Username: msg.Author.Username,
-				Badge:    badge,
+		for id, ch := range resolver.ResolveChannels(ctx, keys(mentions.channels)) {
+			channels[strconv.FormatUint(id, 10)] = Channel{
+				Name: ch.Name,
+			}
+		}
+
+		for id, role := range resolver.ResolveRoles(ctx, keys(mentions.roles)) {
+			roles[strconv.FormatUint(id, 10)] = Role{
+				Name:  role.Name,
+				Color: int(role.Color),
 			}
 		}
 	}
@@ -46,10 +96,91 @@ func FromArchiveMessages(messages []message.Message, ticketId int) Payload {
 	return Payload{
 		Entities: Entities{
 			Users:    users,
-			Channels: make(map[string]Channel),
-			Roles:    make(map[string]Role),
+			Channels: channels,
+			Roles:    roles,
 		},
 		Messages:    wrappedMessages,
 		ChannelName: fmt.Sprintf("ticket-%d", ticketId),
 	}
 }
+
+func isUserMessage(messageType message.MessageType) bool {
+	return messageType == message.MessageTypeDefault || messageType == message.MessageTypeReply
+}
+
+func addUser(users map[string]User, u user.User) {
+	snowflake := strconv.FormatUint(u.Id, 10)
+	if _, ok := users[snowflake]; ok {
+		return
+	}
+
+	var badge *Badge
+	if u.Bot {
+		badge = badgePtr(BadgeBot)
+	}
+
+	users[snowflake] = User{
+		Avatar:   u.AvatarUrl(256),
+		Username: u.Username,
+		Badge:    badge,
+	}
+}
+
+type mentionSet struct {
+	users    map[uint64]struct{}
+	channels map[uint64]struct{}
+	roles    map[uint64]struct{}
+}
+
+func newMentionSet() mentionSet {
+	return mentionSet{
+		users:    make(map[uint64]struct{}),
+		channels: make(map[uint64]struct{}),
+		roles:    make(map[uint64]struct{}),
+	}
+}
+
+func (m mentionSet) addRoles(ids ...uint64) {
+	for _, id := range ids {
+		m.roles[id] = struct{}{}
+	}
+}
+
+func (m mentionSet) scan(s string) {
+	addMatches(m.users, userMentionRegex, s)
+	addMatches(m.roles, roleMentionRegex, s)
+	addMatches(m.channels, channelMentionRegex, s)
+}
+
+func (m mentionSet) scanEmbed(e embed.Embed) {
+	m.scan(e.Title)
+	m.scan(e.Description)
+
+	if e.Footer != nil {
+		m.scan(e.Footer.Text)
+	}
+
+	for _, field := range e.Fields {
+		if field != nil {
+			m.scan(field.Name)
+			m.scan(field.Value)
+		}
+	}
+}
+
+func addMatches(set map[uint64]struct{}, re *regexp.Regexp, s string) {
+	for _, match := range re.FindAllStringSubmatch(s, -1) {
+		if id, err := strconv.ParseUint(match[1], 10, 64); err == nil {
+			set[id] = struct{}{}
+		}
+	}
+}
+
+func keys(set map[uint64]struct{}) []uint64 {
+	ids := make([]uint64, 0, len(set))
+	for id := range set {
+		ids = append(ids, id)
+	}
+
+	return ids
+}
@@ -0,0 +1,83 @@
+package chatreplica
+
+import (
+	"context"
+	"testing"
+
+	"github.com/TicketsBot-cloud/gdl/objects/channel"
+	"github.com/TicketsBot-cloud/gdl/objects/channel/message"
+	"github.com/TicketsBot-cloud/gdl/objects/guild"
+	"github.com/TicketsBot-cloud/gdl/objects/user"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockResolver struct{}
+
+func (mockResolver) ResolveUsers(_ context.Context, ids []uint64) map[uint64]user.User {
+	users := make(map[uint64]user.User)
+	for _, id := range ids {
+		users[id] = user.User{Id: id, Username: "resolved"}
+	}
+
+	return users
+}
+
+func (mockResolver) ResolveChannels(_ context.Context, ids []uint64) map[uint64]channel.Channel {
+	channels := make(map[uint64]channel.Channel)
+	for _, id := range ids {
+		channels[id] = channel.Channel{Id: id, Name: "general"}
+	}
+
+	return channels
+}
+
+func (mockResolver) ResolveRoles(_ context.Context, ids []uint64) map[uint64]guild.Role {
+	roles := make(map[uint64]guild.Role)
+	for _, id := range ids {
+		roles[id] = guild.Role{Id: id, Name: "Support"}
+	}
+
+	return roles
+}
+
+func TestFromArchiveMessagesResolvesMentions(t *testing.T) {
+	messages := []message.Message{
+		{
+			Id:           1,
+			Author:       user.User{Id: 100000000000000001, Username: "author"},
+			Content:      "<@200000000000000002> see <#300000000000000003>",
+			MentionRoles: []uint64{400000000000000004},
+		},
+		{
+			Id:               2,
+			Author:           user.User{Id: 100000000000000001, Username: "author"},
+			Type:             message.MessageTypeChannelPinnedMessage,
+			MessageReference: message.MessageReference{MessageId: 1},
+		},
+	}
+
+	payload := FromArchiveMessages(context.Background(), messages, 1, mockResolver{})
+
+	assert.Equal(t, "author", payload.Entities.Users["100000000000000001"].Username)
+	assert.Equal(t, "resolved", payload.Entities.Users["200000000000000002"].Username)
+	assert.Equal(t, "general", payload.Entities.Channels["300000000000000003"].Name)
+	assert.Equal(t, "Support", payload.Entities.Roles["400000000000000004"].Name)
+
+	// Pins have no content, but should not be dropped
+	if assert.Len(t, payload.Messages, 2) {
+		assert.Equal(t, message.MessageTypeChannelPinnedMessage, payload.Messages[1].Type)
+		assert.Equal(t, uint64(1), *payload.Messages[1].Reference)
+	}
+}
+
+func TestFromArchiveMessagesWithoutResolver(t *testing.T) {
+	messages := []message.Message{
+		{Id: 1, Author: user.User{Id: 1, Username: "author"}, Content: "<#300000000000000003>"},
+		{Id: 2, Author: user.User{Id: 1, Username: "author"}},
+	}
+
+	payload := FromArchiveMessages(context.Background(), messages, 1, nil)
+
+	assert.Len(t, payload.Messages, 1)
+	assert.Empty(t, payload.Entities.Channels)
+}
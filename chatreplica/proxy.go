@@ -12,6 +12,9 @@ import (
 	"github.com/getsentry/sentry-go"
 )
 
+// RendererVersion must be incremented whenever the rendered output changes, to invalidate cached renders
+const RendererVersion = 2
+
 var client = &http.Client{
 	Transport: &http.Transport{
 		TLSHandshakeTimeout: time.Second * 3, // We're not using TLS anyway
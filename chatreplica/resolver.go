@@ -0,0 +1,121 @@
+package chatreplica
+
+import (
+	"context"
+	"errors"
+
+	"github.com/TicketsBot-cloud/dashboard/botcontext"
+	"github.com/TicketsBot-cloud/dashboard/rpc/cache"
+	"github.com/TicketsBot-cloud/dashboard/utils"
+	"github.com/TicketsBot-cloud/gdl/objects/channel"
+	"github.com/TicketsBot-cloud/gdl/objects/guild"
+	"github.com/TicketsBot-cloud/gdl/objects/user"
+)
+
+// EntityResolver looks up entities that are mentioned in a transcript, but which the transcript itself does not store.
+// Resolution is best effort: entities which can't be found are omitted from the returned maps.
+type EntityResolver interface {
+	ResolveUsers(ctx context.Context, ids []uint64) map[uint64]user.User
+	ResolveChannels(ctx context.Context, ids []uint64) map[uint64]channel.Channel
+	ResolveRoles(ctx context.Context, ids []uint64) map[uint64]guild.Role
+}
+
+// Users missing from the cache are fetched individually over REST, so cap how many we are willing to fetch
+const maxUserRestLookups = 10
+
+type guildResolver struct {
+	guildId    uint64
+	botContext *botcontext.BotContext
+}
+
+var _ EntityResolver = (*guildResolver)(nil)
+
+// NewGuildResolver returns a resolver which looks up entities through the cache, falling back to the bot context
+func NewGuildResolver(guildId uint64, botContext *botcontext.BotContext) EntityResolver {
+	return &guildResolver{
+		guildId:    guildId,
+		botContext: botContext,
+	}
+}
+
+func (r *guildResolver) ResolveUsers(ctx context.Context, ids []uint64) map[uint64]user.User {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	users, err := cache.Instance.GetUsers(ctx, ids)
+	if err != nil || users == nil {
+		users = make(map[uint64]user.User)
+	}
+
+	lookups := 0
+	for _, id := range ids {
+		if _, ok := users[id]; ok {
+			continue
+		}
+
+		if lookups >= maxUserRestLookups {
+			break
+		}
+
+		lookups++
+
+		// GetUser stores the user in the cache, so the next render doesn't need to fetch it again
+		u, err := r.botContext.GetUser(ctx, id)
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+				break
+			}
+
+			continue
+		}
+
+		users[id] = u
+	}
+
+	return users
+}
+
+func (r *guildResolver) ResolveChannels(ctx context.Context, ids []uint64) map[uint64]channel.Channel {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	channels, err := r.botContext.GetGuildChannels(ctx, r.guildId)
+	if err != nil {
+		return nil
+	}
+
+	wanted := utils.ToSet(ids)
+
+	resolved := make(map[uint64]channel.Channel)
+	for _, ch := range channels {
+		if wanted.Contains(ch.Id) {
+			resolved[ch.Id] = ch
+		}
+	}
+
+	return resolved
+}
+
+func (r *guildResolver) ResolveRoles(ctx context.Context, ids []uint64) map[uint64]guild.Role {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	roles, err := r.botContext.GetGuildRoles(ctx, r.guildId)
+	if err != nil {
+		return nil
+	}
+
+	wanted := utils.ToSet(ids)
+
+	resolved := make(map[uint64]guild.Role)
+	for _, role := range roles {
+		if wanted.Contains(role.Id) {
+			resolved[role.Id] = role
+		}
+	}
+
+	return resolved
+}
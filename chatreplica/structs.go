@@ -48,6 +48,7 @@ type (
 		Embeds      []embed.Embed         `json:"embeds,omitempty"`
 		Components  []component.Component `json:"components,omitempty"`
 		Attachments []channel.Attachment  `json:"attachments,omitempty"`
+		Reference   *uint64               `json:"reference,string,omitempty"` // The message replied to, or pinned
 	}
 )
 
@@ -18,12 +18,18 @@ import (
 	"github.com/TicketsBot-cloud/common/secureproxy"
 	app "github.com/TicketsBot-cloud/dashboard/app/http"
 	"github.com/TicketsBot-cloud/dashboard/app/http/endpoints/api/ticket/livechat"
+	"github.com/TicketsBot-cloud/dashboard/backup"
 	"github.com/TicketsBot-cloud/dashboard/config"
 	"github.com/TicketsBot-cloud/dashboard/database"
 	"github.com/TicketsBot-cloud/dashboard/log"
+	"github.com/TicketsBot-cloud/dashboard/panelhealth"
+	"github.com/TicketsBot-cloud/dashboard/panelschedule"
 	"github.com/TicketsBot-cloud/dashboard/redis"
+	"github.com/TicketsBot-cloud/dashboard/retention"
 	"github.com/TicketsBot-cloud/dashboard/rpc"
 	"github.com/TicketsBot-cloud/dashboard/rpc/cache"
+	"github.com/TicketsBot-cloud/dashboard/sla"
+	"github.com/TicketsBot-cloud/dashboard/supporthours"
 	"github.com/TicketsBot-cloud/dashboard/utils"
 	"github.com/TicketsBot-cloud/gdl/rest/request"
 	"github.com/TicketsBot-cloud/worker/i18n"
@@ -88,7 +94,8 @@ func main() {
 	cache.Instance = cache.NewCache()
 
 	logger.Info("Initialising microservice clients")
-	utils.ArchiverClient = archiverclient.NewArchiverClient(archiverclient.NewProxyRetriever(config.Conf.Bot.ObjectStore), []byte(config.Conf.Bot.AesKey))
+	utils.ArchiverRetriever = archiverclient.NewProxyRetriever(config.Conf.Bot.ObjectStore)
+	utils.ArchiverClient = archiverclient.NewArchiverClient(utils.ArchiverRetriever, []byte(config.Conf.Bot.AesKey))
 	utils.SecureProxyClient = secureproxy.NewSecureProxy(config.Conf.SecureProxyUrl)
 
 	i18n.Init()
@@ -105,6 +112,13 @@ func main() {
 
 	go ListenChat(redis.Client, socketManager)
 
+	go retention.RunSweeper(config.Conf.Jobs.TranscriptRetentionInterval)
+	go sla.RunSweeper(config.Conf.Jobs.SlaBreachInterval)
+	go backup.RunSweeper(config.Conf.Jobs.ConfigBackupInterval, config.Conf.Jobs.ConfigBackupRetention)
+	go panelhealth.RunSweeper(config.Conf.Jobs.PanelHealthInterval)
+	go panelschedule.RunSweeper(config.Conf.Jobs.PanelScheduleInterval)
+	go supporthours.RunSweeper(config.Conf.Jobs.SupportHoursOverrideInterval)
+
 	if !config.Conf.Debug {
 		rpc.PremiumClient = premium.NewPremiumLookupClient(
 			redis.Client.Client,
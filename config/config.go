@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"time"
 
 	"github.com/BurntSushi/toml"
 	"github.com/caarlos0/env/v11"
@@ -60,6 +61,15 @@ type Config struct {
 		Uri string `env:"URI,required"`
 	} `envPrefix:"CACHE_"`
 	SecureProxyUrl string `env:"SECURE_PROXY_URL"`
+	Jobs           struct {
+		TranscriptRetentionInterval  time.Duration `env:"TRANSCRIPT_RETENTION_INTERVAL" envDefault:"1h"`
+		SlaBreachInterval            time.Duration `env:"SLA_BREACH_INTERVAL" envDefault:"1m"`
+		ConfigBackupInterval         time.Duration `env:"CONFIG_BACKUP_INTERVAL" envDefault:"24h"`
+		ConfigBackupRetention        time.Duration `env:"CONFIG_BACKUP_RETENTION" envDefault:"672h"`
+		PanelHealthInterval          time.Duration `env:"PANEL_HEALTH_INTERVAL" envDefault:"10m"`
+		PanelScheduleInterval        time.Duration `env:"PANEL_SCHEDULE_INTERVAL" envDefault:"1m"`
+		SupportHoursOverrideInterval time.Duration `env:"SUPPORT_HOURS_OVERRIDE_INTERVAL" envDefault:"1m"`
+	} `envPrefix:"JOBS_"`
 }
 
 // TODO: Don't use a global variable
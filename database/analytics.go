@@ -0,0 +1,205 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// Analytics holds read-only aggregate queries over the shared ticket tables. It does not own any tables itself.
+type Analytics struct {
+	*pgxpool.Pool
+}
+
+type DailyCount struct {
+	Day   string `json:"day"`
+	Count int    `json:"count"`
+}
+
+// ResponseTimeStats durations are in seconds, and are nil if there were no samples
+type ResponseTimeStats struct {
+	FirstResponseMedian  *float64 `json:"first_response_median"`
+	FirstResponseP90     *float64 `json:"first_response_p90"`
+	FirstResponseSamples int      `json:"first_response_samples"`
+	TimeToCloseMedian    *float64 `json:"time_to_close_median"`
+	TimeToCloseP90       *float64 `json:"time_to_close_p90"`
+	TimeToCloseSamples   int      `json:"time_to_close_samples"`
+}
+
+// BreakdownRow holds the statistics for the tickets opened with a single panel or label. Id is nil for tickets that
+// were not opened from a panel.
+type BreakdownRow struct {
+	Id                  *int     `json:"id"`
+	Opened              int      `json:"opened"`
+	Closed              int      `json:"closed"`
+	FirstResponseMedian *float64 `json:"first_response_median"`
+	TimeToCloseMedian   *float64 `json:"time_to_close_median"`
+}
+
+func newAnalytics(db *pgxpool.Pool) *Analytics {
+	return &Analytics{
+		db,
+	}
+}
+
+// Some legacy tickets were closed without recording a close time. Treat them as closed when they were opened, so that
+// they don't count towards the backlog forever.
+const effectiveCloseTime = `COALESCE(tickets."close_time", CASE WHEN tickets."open" THEN NULL ELSE tickets."open_time" END)`
+
+func (a *Analytics) GetOpenedPerDay(ctx context.Context, guildId uint64, start, end time.Time, timezone string) ([]DailyCount, error) {
+	query := `
+SELECT to_char((tickets."open_time" AT TIME ZONE $4)::date, 'YYYY-MM-DD') AS day, COUNT(*)
+FROM tickets
+WHERE tickets."guild_id" = $1 AND tickets."open_time" >= $2 AND tickets."open_time" < $3
+GROUP BY day
+ORDER BY day ASC;`
+
+	return a.dailyCounts(ctx, query, guildId, start, end, timezone)
+}
+
+func (a *Analytics) GetClosedPerDay(ctx context.Context, guildId uint64, start, end time.Time, timezone string) ([]DailyCount, error) {
+	query := `
+SELECT to_char((` + effectiveCloseTime + ` AT TIME ZONE $4)::date, 'YYYY-MM-DD') AS day, COUNT(*)
+FROM tickets
+WHERE tickets."guild_id" = $1 AND ` + effectiveCloseTime + ` >= $2 AND ` + effectiveCloseTime + ` < $3
+GROUP BY day
+ORDER BY day ASC;`
+
+	return a.dailyCounts(ctx, query, guildId, start, end, timezone)
+}
+
+func (a *Analytics) dailyCounts(ctx context.Context, query string, args ...any) ([]DailyCount, error) {
+	rows, err := a.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make([]DailyCount, 0)
+	for rows.Next() {
+		var count DailyCount
+		if err := rows.Scan(&count.Day, &count.Count); err != nil {
+			return nil, err
+		}
+
+		counts = append(counts, count)
+	}
+
+	return counts, rows.Err()
+}
+
+// GetBacklogAt returns the number of tickets that were open at the given time
+func (a *Analytics) GetBacklogAt(ctx context.Context, guildId uint64, at time.Time) (int, error) {
+	query := `
+SELECT COUNT(*)
+FROM tickets
+WHERE tickets."guild_id" = $1
+	AND tickets."open_time" < $2
+	AND (` + effectiveCloseTime + ` IS NULL OR ` + effectiveCloseTime + ` >= $2);`
+
+	var count int
+	err := a.QueryRow(ctx, query, guildId, at).Scan(&count)
+	return count, err
+}
+
+// GetResponseTimes returns first response times for tickets opened in the range, and time to close for tickets closed
+// in the range
+func (a *Analytics) GetResponseTimes(ctx context.Context, guildId uint64, start, end time.Time) (ResponseTimeStats, error) {
+	var stats ResponseTimeStats
+
+	firstResponseQuery := `
+SELECT
+	percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM first_response_time."response_time")::float8),
+	percentile_cont(0.9) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM first_response_time."response_time")::float8),
+	COUNT(*)
+FROM first_response_time
+INNER JOIN tickets
+	ON tickets."guild_id" = first_response_time."guild_id" AND tickets."id" = first_response_time."ticket_id"
+WHERE tickets."guild_id" = $1 AND tickets."open_time" >= $2 AND tickets."open_time" < $3;`
+
+	if err := a.QueryRow(ctx, firstResponseQuery, guildId, start, end).Scan(
+		&stats.FirstResponseMedian,
+		&stats.FirstResponseP90,
+		&stats.FirstResponseSamples,
+	); err != nil {
+		return ResponseTimeStats{}, err
+	}
+
+	timeToCloseQuery := `
+SELECT
+	percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM tickets."close_time" - tickets."open_time")::float8),
+	percentile_cont(0.9) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM tickets."close_time" - tickets."open_time")::float8),
+	COUNT(*)
+FROM tickets
+WHERE tickets."guild_id" = $1 AND tickets."close_time" >= $2 AND tickets."close_time" < $3;`
+
+	if err := a.QueryRow(ctx, timeToCloseQuery, guildId, start, end).Scan(
+		&stats.TimeToCloseMedian,
+		&stats.TimeToCloseP90,
+		&stats.TimeToCloseSamples,
+	); err != nil {
+		return ResponseTimeStats{}, err
+	}
+
+	return stats, nil
+}
+
+// GetPanelBreakdown groups the tickets opened in the range by the panel they were opened from
+func (a *Analytics) GetPanelBreakdown(ctx context.Context, guildId uint64, start, end time.Time) ([]BreakdownRow, error) {
+	query := `
+SELECT
+	tickets."panel_id",
+	COUNT(*),
+	COUNT(tickets."close_time"),
+	percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM first_response_time."response_time")::float8),
+	percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM tickets."close_time" - tickets."open_time")::float8)
+FROM tickets
+LEFT OUTER JOIN first_response_time
+	ON first_response_time."guild_id" = tickets."guild_id" AND first_response_time."ticket_id" = tickets."id"
+WHERE tickets."guild_id" = $1 AND tickets."open_time" >= $2 AND tickets."open_time" < $3
+GROUP BY tickets."panel_id";`
+
+	return a.breakdown(ctx, query, guildId, start, end)
+}
+
+// GetLabelBreakdown groups the tickets opened in the range by their labels. Tickets with several labels are counted
+// once for each label, and tickets without labels are omitted.
+func (a *Analytics) GetLabelBreakdown(ctx context.Context, guildId uint64, start, end time.Time) ([]BreakdownRow, error) {
+	query := `
+SELECT
+	ticket_label_assignments."label_id",
+	COUNT(*),
+	COUNT(tickets."close_time"),
+	percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM first_response_time."response_time")::float8),
+	percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM tickets."close_time" - tickets."open_time")::float8)
+FROM tickets
+INNER JOIN ticket_label_assignments
+	ON ticket_label_assignments."guild_id" = tickets."guild_id" AND ticket_label_assignments."ticket_id" = tickets."id"
+LEFT OUTER JOIN first_response_time
+	ON first_response_time."guild_id" = tickets."guild_id" AND first_response_time."ticket_id" = tickets."id"
+WHERE tickets."guild_id" = $1 AND tickets."open_time" >= $2 AND tickets."open_time" < $3
+GROUP BY ticket_label_assignments."label_id";`
+
+	return a.breakdown(ctx, query, guildId, start, end)
+}
+
+func (a *Analytics) breakdown(ctx context.Context, query string, args ...any) ([]BreakdownRow, error) {
+	rows, err := a.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	breakdown := make([]BreakdownRow, 0)
+	for rows.Next() {
+		var row BreakdownRow
+		if err := rows.Scan(&row.Id, &row.Opened, &row.Closed, &row.FirstResponseMedian, &row.TimeToCloseMedian); err != nil {
+			return nil, err
+		}
+
+		breakdown = append(breakdown, row)
+	}
+
+	return breakdown, rows.Err()
+}
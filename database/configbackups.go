@@ -0,0 +1,218 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// ConfigBackup is a snapshot of a guild's configuration. Data holds the versioned backup document, and is only
+// populated when fetching a single backup.
+type ConfigBackup struct {
+	Id        int             `json:"id"`
+	GuildId   uint64          `json:"-"`
+	CreatedAt time.Time       `json:"created_at"`
+	CreatedBy *uint64         `json:"created_by,string"`
+	Automatic bool            `json:"automatic"`
+	Version   int             `json:"version"`
+	Data      json.RawMessage `json:"data,omitempty"`
+}
+
+type ConfigBackupsTable struct {
+	*pgxpool.Pool
+}
+
+func newConfigBackupsTable(db *pgxpool.Pool) *ConfigBackupsTable {
+	return &ConfigBackupsTable{
+		db,
+	}
+}
+
+func (t ConfigBackupsTable) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS guild_config_backups(
+	"id" SERIAL NOT NULL UNIQUE,
+	"guild_id" int8 NOT NULL,
+	"created_at" timestamptz NOT NULL DEFAULT NOW(),
+	"created_by" int8 DEFAULT NULL,
+	"automatic" bool NOT NULL,
+	"version" int4 NOT NULL,
+	"data" jsonb NOT NULL,
+	PRIMARY KEY("id")
+);
+CREATE INDEX IF NOT EXISTS guild_config_backups_guild_id_created_at ON guild_config_backups("guild_id", "created_at" DESC);
+-- Backups used to store integration secret values, which are now left out
+UPDATE guild_config_backups
+SET "data" = jsonb_set("data", '{integrations}', (
+	SELECT COALESCE(jsonb_agg(integration - 'secrets'), '[]'::jsonb)
+	FROM jsonb_array_elements("data"->'integrations') AS integration
+))
+WHERE jsonb_path_exists("data", '$.integrations[*].secrets');
+`
+}
+
+// GetByGuild returns the guild's backups, newest first, without their data
+func (t *ConfigBackupsTable) GetByGuild(ctx context.Context, guildId uint64) ([]ConfigBackup, error) {
+	query := `
+SELECT "id", "created_at", "created_by", "automatic", "version"
+FROM guild_config_backups
+WHERE "guild_id" = $1
+ORDER BY "created_at" DESC, "id" DESC;`
+
+	rows, err := t.Query(ctx, query, guildId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	backups := make([]ConfigBackup, 0)
+	for rows.Next() {
+		backup := ConfigBackup{GuildId: guildId}
+		if err := rows.Scan(&backup.Id, &backup.CreatedAt, &backup.CreatedBy, &backup.Automatic, &backup.Version); err != nil {
+			return nil, err
+		}
+
+		backups = append(backups, backup)
+	}
+
+	return backups, rows.Err()
+}
+
+func (t *ConfigBackupsTable) Get(ctx context.Context, guildId uint64, id int) (ConfigBackup, bool, error) {
+	query := `
+SELECT "created_at", "created_by", "automatic", "version", "data"
+FROM guild_config_backups
+WHERE "guild_id" = $1 AND "id" = $2;`
+
+	backup := ConfigBackup{Id: id, GuildId: guildId}
+	err := t.QueryRow(ctx, query, guildId, id).Scan(&backup.CreatedAt, &backup.CreatedBy, &backup.Automatic, &backup.Version, &backup.Data)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return ConfigBackup{}, false, nil
+		}
+
+		return ConfigBackup{}, false, err
+	}
+
+	return backup, true, nil
+}
+
+// GetLatestAutomatic returns the guild's most recent automatic snapshot, including its data
+func (t *ConfigBackupsTable) GetLatestAutomatic(ctx context.Context, guildId uint64) (ConfigBackup, bool, error) {
+	query := `
+SELECT "id", "created_at", "version", "data"
+FROM guild_config_backups
+WHERE "guild_id" = $1 AND "automatic" = true
+ORDER BY "created_at" DESC, "id" DESC
+LIMIT 1;`
+
+	backup := ConfigBackup{GuildId: guildId, Automatic: true}
+	err := t.QueryRow(ctx, query, guildId).Scan(&backup.Id, &backup.CreatedAt, &backup.Version, &backup.Data)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return ConfigBackup{}, false, nil
+		}
+
+		return ConfigBackup{}, false, err
+	}
+
+	return backup, true, nil
+}
+
+func (t *ConfigBackupsTable) GetManualCount(ctx context.Context, guildId uint64) (count int, err error) {
+	query := `SELECT COUNT(*) FROM guild_config_backups WHERE "guild_id" = $1 AND "automatic" = false;`
+	err = t.QueryRow(ctx, query, guildId).Scan(&count)
+	return
+}
+
+func (t *ConfigBackupsTable) Create(ctx context.Context, guildId uint64, createdBy *uint64, automatic bool, version int, data json.RawMessage) (ConfigBackup, error) {
+	query := `
+INSERT INTO guild_config_backups("guild_id", "created_by", "automatic", "version", "data")
+VALUES($1, $2, $3, $4, $5)
+RETURNING "id", "created_at";`
+
+	backup := ConfigBackup{
+		GuildId:   guildId,
+		CreatedBy: createdBy,
+		Automatic: automatic,
+		Version:   version,
+	}
+
+	if err := t.QueryRow(ctx, query, guildId, createdBy, automatic, version, data).Scan(&backup.Id, &backup.CreatedAt); err != nil {
+		return ConfigBackup{}, err
+	}
+
+	return backup, nil
+}
+
+func (t *ConfigBackupsTable) Delete(ctx context.Context, guildId uint64, id int) (bool, error) {
+	query := `DELETE FROM guild_config_backups WHERE "guild_id" = $1 AND "id" = $2;`
+
+	res, err := t.Exec(ctx, query, guildId, id)
+	if err != nil {
+		return false, err
+	}
+
+	return res.RowsAffected() > 0, nil
+}
+
+// GetGuildsChangedSince returns the guilds with audit log entries after both since, and their latest automatic
+// snapshot
+func (t *ConfigBackupsTable) GetGuildsChangedSince(ctx context.Context, since time.Time) ([]uint64, error) {
+	query := `
+SELECT DISTINCT audit_logs."guild_id"
+FROM audit_logs
+WHERE audit_logs."guild_id" IS NOT NULL
+	AND audit_logs."created_at" > $1
+	AND audit_logs."created_at" > COALESCE(
+		(
+			SELECT MAX(guild_config_backups."created_at")
+			FROM guild_config_backups
+			WHERE guild_config_backups."guild_id" = audit_logs."guild_id" AND guild_config_backups."automatic" = true
+		),
+		'-infinity'::timestamptz
+	);`
+
+	rows, err := t.Query(ctx, query, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var guildIds []uint64
+	for rows.Next() {
+		var guildId uint64
+		if err := rows.Scan(&guildId); err != nil {
+			return nil, err
+		}
+
+		guildIds = append(guildIds, guildId)
+	}
+
+	return guildIds, rows.Err()
+}
+
+// DeleteExpiredAutomatic deletes automatic snapshots created before the cutoff, always keeping each guild's latest
+// keep snapshots so that a guild whose configuration rarely changes still has a copy from before its last change
+func (t *ConfigBackupsTable) DeleteExpiredAutomatic(ctx context.Context, cutoff time.Time, keep int) (int64, error) {
+	query := `
+DELETE FROM guild_config_backups
+WHERE "id" IN (
+	SELECT "id" FROM (
+		SELECT "id", "created_at", ROW_NUMBER() OVER (PARTITION BY "guild_id" ORDER BY "created_at" DESC, "id" DESC) AS "rank"
+		FROM guild_config_backups
+		WHERE "automatic" = true
+	) ranked
+	WHERE ranked."created_at" < $1 AND ranked."rank" > $2
+);`
+
+	res, err := t.Exec(ctx, query, cutoff, keep)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected(), nil
+}
@@ -0,0 +1,89 @@
+package database
+
+import (
+	"context"
+
+	"github.com/TicketsBot-cloud/database"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// DashboardTables contains the tables owned by the dashboard, which are not part of the shared database module
+type DashboardTables struct {
+	pool                        *pgxpool.Pool
+	TranscriptRedactions        *TranscriptRedactionsTable
+	TranscriptRedactionPolicies *TranscriptRedactionPoliciesTable
+	TranscriptRetention         *TranscriptRetentionTable
+	TicketMessageCounts         *TicketMessageCountsTable
+	SlaPolicies                 *SlaPoliciesTable
+	SlaBreaches                 *SlaBreachesTable
+	SupportHoursOverrides       *SupportHoursOverridesTable
+	SupportHoursSchedules       *SupportHoursSchedulesTable
+	AppliedHoursOverrides       *AppliedSupportHoursOverridesTable
+	ConfigBackups               *ConfigBackupsTable
+	SettingsHistory             *SettingsHistoryTable
+	PanelHealth                 *PanelHealthTable
+	PanelStateSchedules         *PanelStateSchedulesTable
+	MultiPanelRows              *MultiPanelRowsTable
+	FormInputConditions         *FormInputConditionsTable
+	FormBranches                *FormBranchesTable
+	FormResponses               *FormResponses
+	FormTemplates               *FormTemplatesTable
+	FormInputPositions          *FormInputPositions
+	Analytics                   *Analytics
+}
+
+func newDashboardTables(pool *pgxpool.Pool) *DashboardTables {
+	return &DashboardTables{
+		pool:                        pool,
+		TranscriptRedactions:        newTranscriptRedactionsTable(pool),
+		TranscriptRedactionPolicies: newTranscriptRedactionPoliciesTable(pool),
+		TranscriptRetention:         newTranscriptRetentionTable(pool),
+		TicketMessageCounts:         newTicketMessageCountsTable(pool),
+		SlaPolicies:                 newSlaPoliciesTable(pool),
+		SlaBreaches:                 newSlaBreachesTable(pool),
+		SupportHoursOverrides:       newSupportHoursOverridesTable(pool),
+		SupportHoursSchedules:       newSupportHoursSchedulesTable(pool),
+		AppliedHoursOverrides:       newAppliedSupportHoursOverridesTable(pool),
+		ConfigBackups:               newConfigBackupsTable(pool),
+		SettingsHistory:             newSettingsHistoryTable(pool),
+		PanelHealth:                 newPanelHealthTable(pool),
+		PanelStateSchedules:         newPanelStateSchedulesTable(pool),
+		MultiPanelRows:              newMultiPanelRowsTable(pool),
+		FormInputConditions:         newFormInputConditionsTable(pool),
+		FormBranches:                newFormBranchesTable(pool),
+		FormResponses:               newFormResponses(pool),
+		FormTemplates:               newFormTemplatesTable(pool),
+		FormInputPositions:          newFormInputPositions(pool),
+		Analytics:                   newAnalytics(pool),
+	}
+}
+
+func (d *DashboardTables) CreateTables(ctx context.Context) {
+	mustCreate(ctx, d.pool,
+		d.TranscriptRedactions,
+		d.TranscriptRedactionPolicies,
+		d.TranscriptRetention,
+		d.TicketMessageCounts,
+		d.SlaPolicies,
+		d.SlaBreaches,
+		d.SupportHoursOverrides,
+		d.SupportHoursSchedules,
+		d.AppliedHoursOverrides,
+		d.ConfigBackups,
+		d.SettingsHistory,
+		d.PanelHealth,
+		d.PanelStateSchedules,
+		d.MultiPanelRows,
+		d.FormInputConditions,
+		d.FormBranches,
+		d.FormTemplates,
+	)
+}
+
+func mustCreate(ctx context.Context, pool *pgxpool.Pool, tables ...database.Table) {
+	for _, table := range tables {
+		if _, err := pool.Exec(ctx, table.Schema()); err != nil {
+			panic(err)
+		}
+	}
+}
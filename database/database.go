@@ -15,6 +15,9 @@ import (
 
 var Client *database.Database
 
+// Dashboard holds the tables owned by the dashboard itself
+var Dashboard *DashboardTables
+
 func ConnectToDatabase() {
 	config, err := pgxpool.ParseConfig(config.Conf.Database.Uri)
 	if err != nil {
@@ -38,4 +41,7 @@ func ConnectToDatabase() {
 	}
 
 	Client = database.NewDatabase(pool)
+
+	Dashboard = newDashboardTables(pool)
+	Dashboard.CreateTables(context.Background())
 }
@@ -0,0 +1,126 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// FormBranch sends the user on to a follow-up form once they have submitted a form, if the input has any of the given
+// values. A form's branches are evaluated in order, and only the first to match is followed.
+type FormBranch struct {
+	FormId       int      `json:"form_id"`
+	InputId      int      `json:"input_id"`
+	Values       []string `json:"values"`
+	TargetFormId int      `json:"target_form_id"`
+}
+
+type FormBranchesTable struct {
+	*pgxpool.Pool
+}
+
+func newFormBranchesTable(db *pgxpool.Pool) *FormBranchesTable {
+	return &FormBranchesTable{
+		db,
+	}
+}
+
+func (t FormBranchesTable) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS form_branches(
+	"form_id" int4 NOT NULL,
+	"position" int2 NOT NULL,
+	"input_id" int4 NOT NULL,
+	"values" text[] NOT NULL,
+	"target_form_id" int4 NOT NULL,
+	FOREIGN KEY("form_id") REFERENCES forms("form_id") ON DELETE CASCADE,
+	FOREIGN KEY("input_id") REFERENCES form_input("id") ON DELETE CASCADE,
+	FOREIGN KEY("target_form_id") REFERENCES forms("form_id") ON DELETE CASCADE,
+	PRIMARY KEY("form_id", "position")
+);
+CREATE INDEX IF NOT EXISTS form_branches_target_form_id ON form_branches("target_form_id");
+`
+}
+
+// GetByForm returns the form's branches in the order they are evaluated
+func (t *FormBranchesTable) GetByForm(ctx context.Context, formId int) ([]FormBranch, error) {
+	query := `
+SELECT "form_id", "input_id", "values", "target_form_id"
+FROM form_branches
+WHERE "form_id" = $1
+ORDER BY "position" ASC;`
+
+	rows, err := t.Query(ctx, query, formId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	branches := make([]FormBranch, 0)
+	for rows.Next() {
+		branch, err := scanFormBranch(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		branches = append(branches, branch)
+	}
+
+	return branches, rows.Err()
+}
+
+// GetByGuild returns the branches of each of the guild's forms, keyed by form ID, in the order they are evaluated
+func (t *FormBranchesTable) GetByGuild(ctx context.Context, guildId uint64) (map[int][]FormBranch, error) {
+	query := `
+SELECT form_branches."form_id", form_branches."input_id", form_branches."values", form_branches."target_form_id"
+FROM form_branches
+INNER JOIN forms ON forms."form_id" = form_branches."form_id"
+WHERE forms."guild_id" = $1
+ORDER BY form_branches."form_id" ASC, form_branches."position" ASC;`
+
+	rows, err := t.Query(ctx, query, guildId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	branches := make(map[int][]FormBranch)
+	for rows.Next() {
+		branch, err := scanFormBranch(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		branches[branch.FormId] = append(branches[branch.FormId], branch)
+	}
+
+	return branches, rows.Err()
+}
+
+// Replace sets the form's branches, in the order they are given
+func (t *FormBranchesTable) Replace(ctx context.Context, formId int, branches []FormBranch) error {
+	return t.BeginFunc(ctx, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `DELETE FROM form_branches WHERE "form_id" = $1;`, formId); err != nil {
+			return err
+		}
+
+		for i, branch := range branches {
+			query := `
+INSERT INTO form_branches("form_id", "position", "input_id", "values", "target_form_id")
+VALUES($1, $2, $3, $4, $5);`
+
+			if _, err := tx.Exec(ctx, query, formId, i+1, branch.InputId, branch.Values, branch.TargetFormId); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func scanFormBranch(row pgx.Row) (FormBranch, error) {
+	var branch FormBranch
+	err := row.Scan(&branch.FormId, &branch.InputId, &branch.Values, &branch.TargetFormId)
+	return branch, err
+}
@@ -0,0 +1,104 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// FormInputCondition only shows an input if the source input, an earlier select, radio group or checkbox group in
+// the same form, has any of the given values
+type FormInputCondition struct {
+	InputId       int      `json:"input_id"`
+	SourceInputId int      `json:"source_input_id"`
+	Values        []string `json:"values"`
+}
+
+type FormInputConditionsTable struct {
+	*pgxpool.Pool
+}
+
+func newFormInputConditionsTable(db *pgxpool.Pool) *FormInputConditionsTable {
+	return &FormInputConditionsTable{
+		db,
+	}
+}
+
+func (t FormInputConditionsTable) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS form_input_conditions(
+	"form_input_id" int4 NOT NULL,
+	"source_input_id" int4 NOT NULL,
+	"values" text[] NOT NULL,
+	FOREIGN KEY("form_input_id") REFERENCES form_input("id") ON DELETE CASCADE,
+	FOREIGN KEY("source_input_id") REFERENCES form_input("id") ON DELETE CASCADE,
+	PRIMARY KEY("form_input_id")
+);
+CREATE INDEX IF NOT EXISTS form_input_conditions_source_input_id ON form_input_conditions("source_input_id");
+`
+}
+
+// GetByForm returns the conditions of the form's inputs, keyed by input ID
+func (t *FormInputConditionsTable) GetByForm(ctx context.Context, formId int) (map[int]FormInputCondition, error) {
+	query := `
+SELECT form_input_conditions."form_input_id", form_input_conditions."source_input_id", form_input_conditions."values"
+FROM form_input_conditions
+INNER JOIN form_input ON form_input."id" = form_input_conditions."form_input_id"
+WHERE form_input."form_id" = $1;`
+
+	return t.query(ctx, query, formId)
+}
+
+// GetByGuild returns the conditions of all of the guild's form inputs, keyed by input ID
+func (t *FormInputConditionsTable) GetByGuild(ctx context.Context, guildId uint64) (map[int]FormInputCondition, error) {
+	query := `
+SELECT form_input_conditions."form_input_id", form_input_conditions."source_input_id", form_input_conditions."values"
+FROM form_input_conditions
+INNER JOIN form_input ON form_input."id" = form_input_conditions."form_input_id"
+INNER JOIN forms ON forms."form_id" = form_input."form_id"
+WHERE forms."guild_id" = $1;`
+
+	return t.query(ctx, query, guildId)
+}
+
+// ReplaceTx sets the conditions of the form's inputs, removing any which are not given
+func (t *FormInputConditionsTable) ReplaceTx(ctx context.Context, tx pgx.Tx, formId int, conditions []FormInputCondition) error {
+	query := `
+DELETE FROM form_input_conditions
+USING form_input
+WHERE form_input."id" = form_input_conditions."form_input_id" AND form_input."form_id" = $1;`
+
+	if _, err := tx.Exec(ctx, query, formId); err != nil {
+		return err
+	}
+
+	for _, condition := range conditions {
+		query := `INSERT INTO form_input_conditions("form_input_id", "source_input_id", "values") VALUES($1, $2, $3);`
+		if _, err := tx.Exec(ctx, query, condition.InputId, condition.SourceInputId, condition.Values); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (t *FormInputConditionsTable) query(ctx context.Context, query string, args ...interface{}) (map[int]FormInputCondition, error) {
+	rows, err := t.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	conditions := make(map[int]FormInputCondition)
+	for rows.Next() {
+		var condition FormInputCondition
+		if err := rows.Scan(&condition.InputId, &condition.SourceInputId, &condition.Values); err != nil {
+			return nil, err
+		}
+
+		conditions[condition.InputId] = condition
+	}
+
+	return conditions, rows.Err()
+}
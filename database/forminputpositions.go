@@ -0,0 +1,64 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// FormInputPositions holds the writes used to reorder inputs, and to move them between forms, which the shared
+// form_input table does not provide. It does not own any tables itself. The ("form_id", "position") constraint is
+// deferred, so positions may overlap part way through a transaction.
+type FormInputPositions struct {
+	*pgxpool.Pool
+}
+
+func newFormInputPositions(db *pgxpool.Pool) *FormInputPositions {
+	return &FormInputPositions{
+		db,
+	}
+}
+
+// ReorderTx sets the position of each input to its index in inputIds, starting from 1
+func (p *FormInputPositions) ReorderTx(ctx context.Context, tx pgx.Tx, formId int, inputIds []int) error {
+	query := `
+UPDATE form_input
+SET "position" = new_positions."position"
+FROM UNNEST($2::int4[]) WITH ORDINALITY AS new_positions("id", "position")
+WHERE form_input."id" = new_positions."id" AND form_input."form_id" = $1;`
+
+	_, err := tx.Exec(ctx, query, formId, inputIds)
+	return err
+}
+
+// MoveTx moves the input to the given position in another form, closing the gap it leaves in its current form and
+// shifting the inputs at or after the position down by one. Exit survey answers to the input are moved along with it,
+// so that they are listed with the form the input now belongs to.
+func (p *FormInputPositions) MoveTx(ctx context.Context, tx pgx.Tx, inputId, formId, targetFormId, position int) error {
+	query := `
+WITH moved AS (
+	SELECT "position" FROM form_input WHERE "id" = $1 AND "form_id" = $2
+)
+UPDATE form_input
+SET "position" = "position" - 1
+WHERE "form_id" = $2 AND "position" > (SELECT "position" FROM moved);`
+
+	if _, err := tx.Exec(ctx, query, inputId, formId); err != nil {
+		return err
+	}
+
+	query = `UPDATE form_input SET "position" = "position" + 1 WHERE "form_id" = $1 AND "position" >= $2;`
+	if _, err := tx.Exec(ctx, query, targetFormId, position); err != nil {
+		return err
+	}
+
+	query = `UPDATE form_input SET "form_id" = $3, "position" = $4 WHERE "id" = $1 AND "form_id" = $2;`
+	if _, err := tx.Exec(ctx, query, inputId, formId, targetFormId, position); err != nil {
+		return err
+	}
+
+	query = `UPDATE exit_survey_responses SET "form_id" = $3 WHERE "question_id" = $1 AND "form_id" = $2;`
+	_, err := tx.Exec(ctx, query, inputId, formId, targetFormId)
+	return err
+}
@@ -0,0 +1,151 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// FormResponses holds the queries over the answers given to forms used as exit surveys, which are recorded by the bot
+// in the shared exit_survey_responses table. It does not own any tables itself. The answers given to a form when a
+// ticket is opened are only posted in the ticket by the bot, and are not stored anywhere, so they are not covered.
+type FormResponses struct {
+	*pgxpool.Pool
+}
+
+// FormResponse is a single submission of a form. Answers are keyed by input ID. Exit surveys don't record when they
+// were submitted, so the ticket's close time is used instead.
+type FormResponse struct {
+	TicketId    int
+	UserId      uint64
+	SubmittedAt time.Time
+	Answers     map[int]string
+}
+
+// FormResponseQuery filters a form's responses. From and To bound the submission time as [From, To). If Answer is
+// set, only responses where an answer contains it, ignoring case, are returned, optionally restricted to InputId.
+type FormResponseQuery struct {
+	GuildId uint64
+	FormId  int
+	From    *time.Time
+	To      *time.Time
+	InputId *int
+	Answer  *string
+	Limit   int
+	Offset  int
+}
+
+func newFormResponses(db *pgxpool.Pool) *FormResponses {
+	return &FormResponses{
+		db,
+	}
+}
+
+// IsExitSurvey reports whether the form is used as the exit survey of any of the guild's panels, or has been in the past
+// and still has answers recorded
+func (t *FormResponses) IsExitSurvey(ctx context.Context, guildId uint64, formId int) (bool, error) {
+	query := `
+SELECT EXISTS(SELECT 1 FROM panels WHERE "guild_id" = $1 AND "exit_survey_form_id" = $2)
+	OR EXISTS(SELECT 1 FROM exit_survey_responses WHERE "guild_id" = $1 AND "form_id" = $2);`
+
+	var isExitSurvey bool
+	if err := t.QueryRow(ctx, query, guildId, formId).Scan(&isExitSurvey); err != nil {
+		return false, err
+	}
+
+	return isExitSurvey, nil
+}
+
+// Search returns the form's responses matching the query, most recent first, along with the total number of matches
+func (t *FormResponses) Search(ctx context.Context, q FormResponseQuery) ([]FormResponse, int, error) {
+	args := []interface{}{q.GuildId, q.FormId}
+	arg := func(value interface{}) string {
+		args = append(args, value)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	var having []string
+	if q.From != nil {
+		having = append(having, fmt.Sprintf(`MAX(responses."submitted_at") >= %s`, arg(*q.From)))
+	}
+
+	if q.To != nil {
+		having = append(having, fmt.Sprintf(`MAX(responses."submitted_at") < %s`, arg(*q.To)))
+	}
+
+	if q.Answer != nil {
+		condition := fmt.Sprintf(`responses."response" ILIKE %s ESCAPE '\'`, arg("%"+escapeLike(*q.Answer)+"%"))
+		if q.InputId != nil {
+			condition = fmt.Sprintf(`responses."input_id" = %s AND %s`, arg(*q.InputId), condition)
+		}
+
+		having = append(having, fmt.Sprintf(`bool_or(%s)`, condition))
+	}
+
+	havingClause := ""
+	if len(having) > 0 {
+		havingClause = "HAVING " + strings.Join(having, " AND ")
+	}
+
+	cte := `
+WITH responses AS (
+	SELECT exit_survey_responses."ticket_id", exit_survey_responses."question_id" AS input_id, COALESCE(exit_survey_responses."response", '') AS response, COALESCE(tickets."close_time", tickets."open_time") AS submitted_at
+	FROM exit_survey_responses
+	INNER JOIN tickets ON tickets."guild_id" = exit_survey_responses."guild_id" AND tickets."id" = exit_survey_responses."ticket_id"
+	WHERE exit_survey_responses."guild_id" = $1 AND exit_survey_responses."form_id" = $2 AND exit_survey_responses."question_id" IS NOT NULL
+), submissions AS (
+	SELECT responses."ticket_id", MAX(responses."submitted_at") AS submitted_at, jsonb_object_agg(responses."input_id", responses."response") AS answers
+	FROM responses
+	GROUP BY responses."ticket_id"
+	` + havingClause + `
+)`
+
+	var total int
+	if err := t.QueryRow(ctx, cte+` SELECT COUNT(*) FROM submissions;`, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := cte + `
+SELECT submissions."ticket_id", tickets."user_id", submissions."submitted_at", submissions."answers"
+FROM submissions
+INNER JOIN tickets ON tickets."guild_id" = $1 AND tickets."id" = submissions."ticket_id"
+ORDER BY submissions."submitted_at" DESC, submissions."ticket_id" DESC
+LIMIT ` + arg(q.Limit) + ` OFFSET ` + arg(q.Offset) + `;`
+
+	rows, err := t.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	responses := make([]FormResponse, 0)
+	for rows.Next() {
+		var response FormResponse
+		var answers map[string]string
+		if err := rows.Scan(&response.TicketId, &response.UserId, &response.SubmittedAt, &answers); err != nil {
+			return nil, 0, err
+		}
+
+		response.Answers = make(map[int]string, len(answers))
+		for key, answer := range answers {
+			inputId, err := strconv.Atoi(key)
+			if err != nil {
+				return nil, 0, err
+			}
+
+			response.Answers[inputId] = answer
+		}
+
+		responses = append(responses, response)
+	}
+
+	return responses, total, rows.Err()
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
@@ -0,0 +1,144 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// FormTemplate is a form saved by a guild so that it can be created again in one call. Inputs holds the form's input
+// definitions as JSON, in the format used by panel exports.
+type FormTemplate struct {
+	Id        int             `json:"id"`
+	GuildId   uint64          `json:"guild_id,string"`
+	Name      string          `json:"name"`
+	Title     string          `json:"title"`
+	Inputs    json.RawMessage `json:"inputs"`
+	CreatedBy uint64          `json:"created_by,string"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type FormTemplatesTable struct {
+	*pgxpool.Pool
+}
+
+func newFormTemplatesTable(db *pgxpool.Pool) *FormTemplatesTable {
+	return &FormTemplatesTable{
+		db,
+	}
+}
+
+func (t FormTemplatesTable) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS form_templates(
+	"id" SERIAL NOT NULL UNIQUE,
+	"guild_id" int8 NOT NULL,
+	"name" varchar(45) NOT NULL,
+	"title" varchar(45) NOT NULL,
+	"inputs" jsonb NOT NULL,
+	"created_by" int8 NOT NULL,
+	"created_at" timestamptz NOT NULL DEFAULT NOW(),
+	UNIQUE("guild_id", "name"),
+	PRIMARY KEY("id")
+);
+CREATE INDEX IF NOT EXISTS form_templates_guild_id ON form_templates("guild_id");
+`
+}
+
+// GetByGuild returns the guild's saved templates, ordered by name
+func (t *FormTemplatesTable) GetByGuild(ctx context.Context, guildId uint64) ([]FormTemplate, error) {
+	query := `
+SELECT "id", "guild_id", "name", "title", "inputs", "created_by", "created_at"
+FROM form_templates
+WHERE "guild_id" = $1
+ORDER BY "name" ASC;`
+
+	rows, err := t.Query(ctx, query, guildId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	templates := make([]FormTemplate, 0)
+	for rows.Next() {
+		template, err := scanFormTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		templates = append(templates, template)
+	}
+
+	return templates, rows.Err()
+}
+
+func (t *FormTemplatesTable) Get(ctx context.Context, guildId uint64, id int) (FormTemplate, bool, error) {
+	query := `
+SELECT "id", "guild_id", "name", "title", "inputs", "created_by", "created_at"
+FROM form_templates
+WHERE "guild_id" = $1 AND "id" = $2;`
+
+	template, err := scanFormTemplate(t.QueryRow(ctx, query, guildId, id))
+	if err == nil {
+		return template, true, nil
+	} else if err == pgx.ErrNoRows {
+		return FormTemplate{}, false, nil
+	} else {
+		return FormTemplate{}, false, err
+	}
+}
+
+func (t *FormTemplatesTable) GetCount(ctx context.Context, guildId uint64) (count int, err error) {
+	query := `SELECT COUNT(*) FROM form_templates WHERE "guild_id" = $1;`
+	err = t.QueryRow(ctx, query, guildId).Scan(&count)
+	return
+}
+
+// Create inserts the template and returns it with its ID and creation time. It returns false if the guild already has
+// a template with the same name.
+func (t *FormTemplatesTable) Create(ctx context.Context, template FormTemplate) (FormTemplate, bool, error) {
+	query := `
+INSERT INTO form_templates("guild_id", "name", "title", "inputs", "created_by")
+VALUES($1, $2, $3, $4, $5)
+ON CONFLICT("guild_id", "name") DO NOTHING
+RETURNING "id", "created_at";`
+
+	err := t.QueryRow(ctx, query,
+		template.GuildId,
+		template.Name,
+		template.Title,
+		template.Inputs,
+		template.CreatedBy,
+	).Scan(&template.Id, &template.CreatedAt)
+
+	if err == pgx.ErrNoRows {
+		return FormTemplate{}, false, nil
+	} else if err != nil {
+		return FormTemplate{}, false, err
+	}
+
+	return template, true, nil
+}
+
+func (t *FormTemplatesTable) Delete(ctx context.Context, guildId uint64, id int) error {
+	_, err := t.Exec(ctx, `DELETE FROM form_templates WHERE "guild_id" = $1 AND "id" = $2;`, guildId, id)
+	return err
+}
+
+func scanFormTemplate(row pgx.Row) (FormTemplate, error) {
+	var template FormTemplate
+	err := row.Scan(
+		&template.Id,
+		&template.GuildId,
+		&template.Name,
+		&template.Title,
+		&template.Inputs,
+		&template.CreatedBy,
+		&template.CreatedAt,
+	)
+
+	return template, err
+}
@@ -0,0 +1,111 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// MultiPanelRowsTable holds the action row each sub-panel's button is placed in. Multi-panels without any rows set
+// place their buttons 5 to a row, in order of position.
+type MultiPanelRowsTable struct {
+	*pgxpool.Pool
+}
+
+func newMultiPanelRowsTable(db *pgxpool.Pool) *MultiPanelRowsTable {
+	return &MultiPanelRowsTable{
+		db,
+	}
+}
+
+func (t MultiPanelRowsTable) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS multi_panel_rows(
+	"multi_panel_id" int4 NOT NULL,
+	"panel_id" int4 NOT NULL,
+	"row" int2 NOT NULL,
+	FOREIGN KEY("multi_panel_id") REFERENCES multi_panels("id") ON DELETE CASCADE,
+	FOREIGN KEY("panel_id") REFERENCES panels("panel_id") ON DELETE CASCADE,
+	PRIMARY KEY("multi_panel_id", "panel_id")
+);
+`
+}
+
+// Get returns the row of each sub-panel, keyed by panel ID
+func (t *MultiPanelRowsTable) Get(ctx context.Context, multiPanelId int) (map[int]int, error) {
+	query := `SELECT "panel_id", "row" FROM multi_panel_rows WHERE "multi_panel_id" = $1;`
+
+	rows, err := t.Query(ctx, query, multiPanelId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	panelRows := make(map[int]int)
+	for rows.Next() {
+		var panelId, row int
+		if err := rows.Scan(&panelId, &row); err != nil {
+			return nil, err
+		}
+
+		panelRows[panelId] = row
+	}
+
+	return panelRows, rows.Err()
+}
+
+// MultiPanelTarget is a sub-panel of a multi-panel, as stored in the shared multi_panel_targets table
+type MultiPanelTarget struct {
+	PanelId         int
+	CustomLabel     *string
+	Description     *string
+	CustomEmojiName *string
+	CustomEmojiId   *uint64
+}
+
+// Replace sets the rows of the multi-panel's sub-panels, keyed by panel ID. An empty map removes them.
+func (t *MultiPanelRowsTable) Replace(ctx context.Context, multiPanelId int, panelRows map[int]int) error {
+	return t.BeginFunc(ctx, func(tx pgx.Tx) error {
+		return replaceRows(ctx, tx, multiPanelId, panelRows)
+	})
+}
+
+// ReplaceLayout sets the multi-panel's sub-panels, in order of position, along with their rows, in a single
+// transaction. The sub-panels are written here rather than through the MultiPanelTargets table, which cannot take part
+// in a transaction.
+func (t *MultiPanelRowsTable) ReplaceLayout(ctx context.Context, multiPanelId int, targets []MultiPanelTarget, panelRows map[int]int) error {
+	return t.BeginFunc(ctx, func(tx pgx.Tx) error {
+		// Positions are unique, so the existing targets must be removed before any are inserted
+		if _, err := tx.Exec(ctx, `DELETE FROM multi_panel_targets WHERE "multi_panel_id" = $1;`, multiPanelId); err != nil {
+			return err
+		}
+
+		query := `
+INSERT INTO multi_panel_targets("multi_panel_id", "panel_id", "position", "custom_label", "description", "custom_emoji_name", "custom_emoji_id")
+VALUES ($1, $2, $3, $4, $5, $6, $7);`
+
+		for position, target := range targets {
+			if _, err := tx.Exec(ctx, query, multiPanelId, target.PanelId, position, target.CustomLabel, target.Description, target.CustomEmojiName, target.CustomEmojiId); err != nil {
+				return err
+			}
+		}
+
+		return replaceRows(ctx, tx, multiPanelId, panelRows)
+	})
+}
+
+func replaceRows(ctx context.Context, tx pgx.Tx, multiPanelId int, panelRows map[int]int) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM multi_panel_rows WHERE "multi_panel_id" = $1;`, multiPanelId); err != nil {
+		return err
+	}
+
+	for panelId, row := range panelRows {
+		query := `INSERT INTO multi_panel_rows("multi_panel_id", "panel_id", "row") VALUES($1, $2, $3);`
+		if _, err := tx.Exec(ctx, query, multiPanelId, panelId, row); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,135 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+const (
+	PanelKindPanel      = "panel"
+	PanelKindMultiPanel = "multipanel"
+)
+
+// PanelHealth is the result of checking a single panel or multi-panel message against its stored configuration.
+// Problems lists the parts of the message which did not match, e.g. "message" or "embed.title".
+type PanelHealth struct {
+	Kind      string   `json:"kind"`
+	PanelId   int      `json:"panel_id"`
+	ChannelId uint64   `json:"channel_id,string"`
+	MessageId uint64   `json:"message_id,string"`
+	Status    string   `json:"status"`
+	Problems  []string `json:"problems"`
+}
+
+// PanelHealthReport holds the results of the most recent check of a guild's panels
+type PanelHealthReport struct {
+	CheckedAt time.Time     `json:"checked_at"`
+	Panels    []PanelHealth `json:"panels"`
+}
+
+type PanelHealthTable struct {
+	*pgxpool.Pool
+}
+
+func newPanelHealthTable(db *pgxpool.Pool) *PanelHealthTable {
+	return &PanelHealthTable{
+		db,
+	}
+}
+
+func (t PanelHealthTable) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS panel_health(
+	"guild_id" int8 NOT NULL,
+	"checked_at" timestamptz NOT NULL,
+	"results" jsonb NOT NULL,
+	PRIMARY KEY("guild_id")
+);
+CREATE INDEX IF NOT EXISTS panel_health_checked_at ON panel_health("checked_at");
+`
+}
+
+func (t *PanelHealthTable) Get(ctx context.Context, guildId uint64) (PanelHealthReport, bool, error) {
+	query := `SELECT "checked_at", "results" FROM panel_health WHERE "guild_id" = $1;`
+
+	var report PanelHealthReport
+	var results []byte
+	if err := t.QueryRow(ctx, query, guildId).Scan(&report.CheckedAt, &results); err != nil {
+		if err == pgx.ErrNoRows {
+			return PanelHealthReport{}, false, nil
+		}
+
+		return PanelHealthReport{}, false, err
+	}
+
+	if err := json.Unmarshal(results, &report.Panels); err != nil {
+		return PanelHealthReport{}, false, err
+	}
+
+	return report, true, nil
+}
+
+func (t *PanelHealthTable) Set(ctx context.Context, guildId uint64, report PanelHealthReport) error {
+	results, err := json.Marshal(report.Panels)
+	if err != nil {
+		return err
+	}
+
+	query := `
+INSERT INTO panel_health("guild_id", "checked_at", "results")
+VALUES($1, $2, $3)
+ON CONFLICT("guild_id") DO UPDATE SET "checked_at" = $2, "results" = $3;`
+
+	_, err = t.Exec(ctx, query, guildId, report.CheckedAt, results)
+	return err
+}
+
+// Touch marks the guild as checked without changing its results, so that a guild which can't be checked is not
+// retried every run
+func (t *PanelHealthTable) Touch(ctx context.Context, guildId uint64, checkedAt time.Time) error {
+	query := `
+INSERT INTO panel_health("guild_id", "checked_at", "results")
+VALUES($1, $2, '[]')
+ON CONFLICT("guild_id") DO UPDATE SET "checked_at" = $2;`
+
+	_, err := t.Exec(ctx, query, guildId, checkedAt)
+	return err
+}
+
+// GetGuildsDueForCheck returns guilds with at least one panel or multi-panel which have not been checked since the
+// given time, least recently checked first
+func (t *PanelHealthTable) GetGuildsDueForCheck(ctx context.Context, checkedBefore time.Time, limit int) ([]uint64, error) {
+	query := `
+SELECT guilds."guild_id"
+FROM (
+	SELECT "guild_id" FROM panels
+	UNION
+	SELECT "guild_id" FROM multi_panels
+) AS guilds
+LEFT JOIN panel_health ON panel_health."guild_id" = guilds."guild_id"
+WHERE panel_health."checked_at" IS NULL OR panel_health."checked_at" < $1
+ORDER BY panel_health."checked_at" ASC NULLS FIRST
+LIMIT $2;`
+
+	rows, err := t.Query(ctx, query, checkedBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var guildIds []uint64
+	for rows.Next() {
+		var guildId uint64
+		if err := rows.Scan(&guildId); err != nil {
+			return nil, err
+		}
+
+		guildIds = append(guildIds, guildId)
+	}
+
+	return guildIds, rows.Err()
+}
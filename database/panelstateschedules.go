@@ -0,0 +1,183 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// PanelStateSchedule enables or disables a panel at RunAt. Schedules are deleted once they have been applied.
+type PanelStateSchedule struct {
+	Id        int       `json:"id"`
+	GuildId   uint64    `json:"guild_id,string"`
+	PanelId   int       `json:"panel_id"`
+	Disabled  bool      `json:"disabled"`
+	RunAt     time.Time `json:"run_at"`
+	CreatedBy uint64    `json:"created_by,string"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type PanelStateSchedulesTable struct {
+	*pgxpool.Pool
+}
+
+func newPanelStateSchedulesTable(db *pgxpool.Pool) *PanelStateSchedulesTable {
+	return &PanelStateSchedulesTable{
+		db,
+	}
+}
+
+func (t PanelStateSchedulesTable) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS panel_state_schedules(
+	"id" SERIAL NOT NULL UNIQUE,
+	"guild_id" int8 NOT NULL,
+	"panel_id" int4 NOT NULL,
+	"disabled" bool NOT NULL,
+	"run_at" timestamptz NOT NULL,
+	"created_by" int8 NOT NULL,
+	"created_at" timestamptz NOT NULL DEFAULT NOW(),
+	FOREIGN KEY("panel_id") REFERENCES panels("panel_id") ON DELETE CASCADE,
+	UNIQUE("panel_id", "run_at"),
+	PRIMARY KEY("id")
+);
+CREATE INDEX IF NOT EXISTS panel_state_schedules_run_at ON panel_state_schedules("run_at");
+CREATE INDEX IF NOT EXISTS panel_state_schedules_guild_id ON panel_state_schedules("guild_id");
+`
+}
+
+// GetByGuild returns the pending schedules for all of the guild's panels, soonest first
+func (t *PanelStateSchedulesTable) GetByGuild(ctx context.Context, guildId uint64) ([]PanelStateSchedule, error) {
+	query := `
+SELECT "id", "guild_id", "panel_id", "disabled", "run_at", "created_by", "created_at"
+FROM panel_state_schedules
+WHERE "guild_id" = $1
+ORDER BY "run_at" ASC;`
+
+	return t.query(ctx, query, guildId)
+}
+
+// GetByPanel returns the pending schedules for a panel, soonest first
+func (t *PanelStateSchedulesTable) GetByPanel(ctx context.Context, panelId int) ([]PanelStateSchedule, error) {
+	query := `
+SELECT "id", "guild_id", "panel_id", "disabled", "run_at", "created_by", "created_at"
+FROM panel_state_schedules
+WHERE "panel_id" = $1
+ORDER BY "run_at" ASC;`
+
+	return t.query(ctx, query, panelId)
+}
+
+// GetDue returns the schedules which should have run by the given time, earliest first
+func (t *PanelStateSchedulesTable) GetDue(ctx context.Context, before time.Time, limit int) ([]PanelStateSchedule, error) {
+	query := `
+SELECT "id", "guild_id", "panel_id", "disabled", "run_at", "created_by", "created_at"
+FROM panel_state_schedules
+WHERE "run_at" <= $1
+ORDER BY "run_at" ASC
+LIMIT $2;`
+
+	return t.query(ctx, query, before, limit)
+}
+
+func (t *PanelStateSchedulesTable) Get(ctx context.Context, panelId, id int) (PanelStateSchedule, bool, error) {
+	query := `
+SELECT "id", "guild_id", "panel_id", "disabled", "run_at", "created_by", "created_at"
+FROM panel_state_schedules
+WHERE "panel_id" = $1 AND "id" = $2;`
+
+	schedule, err := scanPanelStateSchedule(t.QueryRow(ctx, query, panelId, id))
+	if err == nil {
+		return schedule, true, nil
+	} else if err == pgx.ErrNoRows {
+		return PanelStateSchedule{}, false, nil
+	} else {
+		return PanelStateSchedule{}, false, err
+	}
+}
+
+func (t *PanelStateSchedulesTable) GetCount(ctx context.Context, panelId int) (count int, err error) {
+	query := `SELECT COUNT(*) FROM panel_state_schedules WHERE "panel_id" = $1;`
+	err = t.QueryRow(ctx, query, panelId).Scan(&count)
+	return
+}
+
+// Create inserts the schedule and returns it with its ID and creation time. It returns false if the panel already has
+// a schedule at the same time.
+func (t *PanelStateSchedulesTable) Create(ctx context.Context, schedule PanelStateSchedule) (PanelStateSchedule, bool, error) {
+	query := `
+INSERT INTO panel_state_schedules("guild_id", "panel_id", "disabled", "run_at", "created_by")
+VALUES($1, $2, $3, $4, $5)
+ON CONFLICT("panel_id", "run_at") DO NOTHING
+RETURNING "id", "created_at";`
+
+	err := t.QueryRow(ctx, query,
+		schedule.GuildId,
+		schedule.PanelId,
+		schedule.Disabled,
+		schedule.RunAt,
+		schedule.CreatedBy,
+	).Scan(&schedule.Id, &schedule.CreatedAt)
+
+	if err == pgx.ErrNoRows {
+		return PanelStateSchedule{}, false, nil
+	} else if err != nil {
+		return PanelStateSchedule{}, false, err
+	}
+
+	return schedule, true, nil
+}
+
+func (t *PanelStateSchedulesTable) Delete(ctx context.Context, panelId, id int) error {
+	_, err := t.Exec(ctx, `DELETE FROM panel_state_schedules WHERE "panel_id" = $1 AND "id" = $2;`, panelId, id)
+	return err
+}
+
+// SetPanelDisabled writes only the "disabled" column of the shared panels table, so that a schedule can't overwrite
+// other changes made to the panel since it was read. Panels disabled for exceeding the free panel limit are skipped.
+func (t *PanelStateSchedulesTable) SetPanelDisabled(ctx context.Context, panelId int, disabled bool) error {
+	query := `
+UPDATE panels
+SET "disabled" = $2
+WHERE "panel_id" = $1 AND NOT "force_disabled";`
+
+	_, err := t.Exec(ctx, query, panelId, disabled)
+	return err
+}
+
+func (t *PanelStateSchedulesTable) query(ctx context.Context, query string, args ...interface{}) ([]PanelStateSchedule, error) {
+	rows, err := t.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schedules := make([]PanelStateSchedule, 0)
+	for rows.Next() {
+		schedule, err := scanPanelStateSchedule(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		schedules = append(schedules, schedule)
+	}
+
+	return schedules, rows.Err()
+}
+
+func scanPanelStateSchedule(row pgx.Row) (PanelStateSchedule, error) {
+	var schedule PanelStateSchedule
+	err := row.Scan(
+		&schedule.Id,
+		&schedule.GuildId,
+		&schedule.PanelId,
+		&schedule.Disabled,
+		&schedule.RunAt,
+		&schedule.CreatedBy,
+		&schedule.CreatedAt,
+	)
+
+	return schedule, err
+}
@@ -0,0 +1,101 @@
+package database
+
+import (
+	"context"
+	"time"
+)
+
+// PanelTicketStats holds the tickets opened with a single panel. AverageResolution is in seconds, and is nil if none
+// of the tickets have been closed.
+type PanelTicketStats struct {
+	PanelId           int
+	Opened            int
+	Closed            int
+	AverageResolution *float64
+}
+
+// GetPanelTicketStats returns statistics for the tickets opened with each of the guild's panels in the range. Panels
+// without any tickets are omitted.
+func (a *Analytics) GetPanelTicketStats(ctx context.Context, guildId uint64, start, end time.Time) (map[int]PanelTicketStats, error) {
+	query := `
+SELECT
+	tickets."panel_id",
+	COUNT(*),
+	COUNT(tickets."close_time"),
+	AVG(EXTRACT(EPOCH FROM tickets."close_time" - tickets."open_time"))::float8
+FROM tickets
+WHERE tickets."guild_id" = $1 AND tickets."panel_id" IS NOT NULL AND tickets."open_time" >= $2 AND tickets."open_time" < $3
+GROUP BY tickets."panel_id";`
+
+	rows, err := a.Query(ctx, query, guildId, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := make(map[int]PanelTicketStats)
+	for rows.Next() {
+		var row PanelTicketStats
+		if err := rows.Scan(&row.PanelId, &row.Opened, &row.Closed, &row.AverageResolution); err != nil {
+			return nil, err
+		}
+
+		stats[row.PanelId] = row
+	}
+
+	return stats, rows.Err()
+}
+
+// GetPanelOpenTimes returns the times at which the tickets opened with the panel in the range were opened
+func (a *Analytics) GetPanelOpenTimes(ctx context.Context, guildId uint64, panelId int, start, end time.Time) ([]time.Time, error) {
+	query := `
+SELECT tickets."open_time"
+FROM tickets
+WHERE tickets."guild_id" = $1 AND tickets."panel_id" = $2 AND tickets."open_time" >= $3 AND tickets."open_time" < $4;`
+
+	rows, err := a.Query(ctx, query, guildId, panelId, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var times []time.Time
+	for rows.Next() {
+		var openTime time.Time
+		if err := rows.Scan(&openTime); err != nil {
+			return nil, err
+		}
+
+		times = append(times, openTime)
+	}
+
+	return times, rows.Err()
+}
+
+// GetOpenTimesByPanel returns the times at which the tickets opened with each of the guild's panels in the range were
+// opened, keyed by panel ID
+func (a *Analytics) GetOpenTimesByPanel(ctx context.Context, guildId uint64, start, end time.Time) (map[int][]time.Time, error) {
+	query := `
+SELECT tickets."panel_id", tickets."open_time"
+FROM tickets
+WHERE tickets."guild_id" = $1 AND tickets."panel_id" IS NOT NULL AND tickets."open_time" >= $2 AND tickets."open_time" < $3;`
+
+	rows, err := a.Query(ctx, query, guildId, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	times := make(map[int][]time.Time)
+	for rows.Next() {
+		var panelId int
+		var openTime time.Time
+		if err := rows.Scan(&panelId, &openTime); err != nil {
+			return nil, err
+		}
+
+		times[panelId] = append(times[panelId], openTime)
+	}
+
+	return times, rows.Err()
+}
@@ -0,0 +1,263 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/TicketsBot-cloud/database"
+)
+
+// LowRatingThreshold is the highest rating that is considered a low rating
+const LowRatingThreshold = 2
+
+type RatingCount struct {
+	Rating int `json:"rating"`
+	Count  int `json:"count"`
+}
+
+// DailyRating Average is nil on days with no ratings
+type DailyRating struct {
+	Day     string   `json:"day"`
+	Average *float64 `json:"average"`
+	Count   int      `json:"count"`
+}
+
+// RatingBreakdownRow holds the ratings for the tickets opened with a single panel, label or claimer. Id is a panel or
+// label ID, or a user ID for claimers, and is nil for tickets without one. Like BreakdownRow, Id is encoded as a
+// number, so claimer IDs must be wrapped to be encoded as strings.
+type RatingBreakdownRow struct {
+	Id      *uint64 `json:"id"`
+	Average float64 `json:"average"`
+	Count   int     `json:"count"`
+	Low     int     `json:"low"`
+}
+
+type SurveyFeedback struct {
+	TicketId  int                         `json:"ticket_id"`
+	PanelId   *int                        `json:"panel_id"`
+	UserId    uint64                      `json:"user_id,string"`
+	ClaimedBy *uint64                     `json:"claimed_by,string"`
+	CloseTime time.Time                   `json:"close_time"`
+	Rating    *int                        `json:"rating"`
+	Low       bool                        `json:"low"`
+	Responses []database.QuestionResponse `json:"responses"`
+}
+
+// GetRatingDistribution counts ratings for tickets closed in the range, as ratings are given when the ticket is closed.
+// The same applies to the other rating queries.
+func (a *Analytics) GetRatingDistribution(ctx context.Context, guildId uint64, start, end time.Time) ([]RatingCount, error) {
+	query := `
+SELECT service_ratings."rating", COUNT(*)
+FROM service_ratings
+INNER JOIN tickets ON tickets."guild_id" = service_ratings."guild_id" AND tickets."id" = service_ratings."ticket_id"
+WHERE tickets."guild_id" = $1 AND tickets."close_time" >= $2 AND tickets."close_time" < $3
+GROUP BY service_ratings."rating"
+ORDER BY service_ratings."rating" ASC;`
+
+	rows, err := a.Query(ctx, query, guildId, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make([]RatingCount, 0)
+	for rows.Next() {
+		var row RatingCount
+		if err := rows.Scan(&row.Rating, &row.Count); err != nil {
+			return nil, err
+		}
+
+		counts = append(counts, row)
+	}
+
+	return counts, rows.Err()
+}
+
+func (a *Analytics) GetRatingsPerDay(ctx context.Context, guildId uint64, start, end time.Time, timezone string) ([]DailyRating, error) {
+	query := `
+SELECT to_char((tickets."close_time" AT TIME ZONE $4)::date, 'YYYY-MM-DD') AS day, AVG(service_ratings."rating")::float8, COUNT(*)
+FROM service_ratings
+INNER JOIN tickets ON tickets."guild_id" = service_ratings."guild_id" AND tickets."id" = service_ratings."ticket_id"
+WHERE tickets."guild_id" = $1 AND tickets."close_time" >= $2 AND tickets."close_time" < $3
+GROUP BY day
+ORDER BY day ASC;`
+
+	rows, err := a.Query(ctx, query, guildId, start, end, timezone)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ratings := make([]DailyRating, 0)
+	for rows.Next() {
+		var row DailyRating
+		if err := rows.Scan(&row.Day, &row.Average, &row.Count); err != nil {
+			return nil, err
+		}
+
+		ratings = append(ratings, row)
+	}
+
+	return ratings, rows.Err()
+}
+
+func (a *Analytics) GetRatingsByPanel(ctx context.Context, guildId uint64, start, end time.Time) ([]RatingBreakdownRow, error) {
+	query := `
+SELECT tickets."panel_id"::int8, AVG(service_ratings."rating")::float8, COUNT(*), COUNT(*) FILTER (WHERE service_ratings."rating" <= $4)
+FROM service_ratings
+INNER JOIN tickets ON tickets."guild_id" = service_ratings."guild_id" AND tickets."id" = service_ratings."ticket_id"
+WHERE tickets."guild_id" = $1 AND tickets."close_time" >= $2 AND tickets."close_time" < $3
+GROUP BY tickets."panel_id";`
+
+	return a.ratingBreakdown(ctx, query, guildId, start, end, LowRatingThreshold)
+}
+
+// GetRatingsByLabel counts tickets with multiple labels once for each label, and those without any labels under nil
+func (a *Analytics) GetRatingsByLabel(ctx context.Context, guildId uint64, start, end time.Time) ([]RatingBreakdownRow, error) {
+	query := `
+SELECT ticket_label_assignments."label_id"::int8, AVG(service_ratings."rating")::float8, COUNT(*), COUNT(*) FILTER (WHERE service_ratings."rating" <= $4)
+FROM service_ratings
+INNER JOIN tickets ON tickets."guild_id" = service_ratings."guild_id" AND tickets."id" = service_ratings."ticket_id"
+LEFT OUTER JOIN ticket_label_assignments
+	ON ticket_label_assignments."guild_id" = tickets."guild_id" AND ticket_label_assignments."ticket_id" = tickets."id"
+WHERE tickets."guild_id" = $1 AND tickets."close_time" >= $2 AND tickets."close_time" < $3
+GROUP BY ticket_label_assignments."label_id";`
+
+	return a.ratingBreakdown(ctx, query, guildId, start, end, LowRatingThreshold)
+}
+
+func (a *Analytics) GetRatingsByClaimer(ctx context.Context, guildId uint64, start, end time.Time) ([]RatingBreakdownRow, error) {
+	query := `
+SELECT ticket_claims."user_id", AVG(service_ratings."rating")::float8, COUNT(*), COUNT(*) FILTER (WHERE service_ratings."rating" <= $4)
+FROM service_ratings
+INNER JOIN tickets ON tickets."guild_id" = service_ratings."guild_id" AND tickets."id" = service_ratings."ticket_id"
+LEFT OUTER JOIN ticket_claims ON ticket_claims."guild_id" = tickets."guild_id" AND ticket_claims."ticket_id" = tickets."id"
+WHERE tickets."guild_id" = $1 AND tickets."close_time" >= $2 AND tickets."close_time" < $3
+GROUP BY ticket_claims."user_id";`
+
+	return a.ratingBreakdown(ctx, query, guildId, start, end, LowRatingThreshold)
+}
+
+func (a *Analytics) ratingBreakdown(ctx context.Context, query string, args ...any) ([]RatingBreakdownRow, error) {
+	rows, err := a.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	breakdown := make([]RatingBreakdownRow, 0)
+	for rows.Next() {
+		var row RatingBreakdownRow
+		if err := rows.Scan(&row.Id, &row.Average, &row.Count, &row.Low); err != nil {
+			return nil, err
+		}
+
+		breakdown = append(breakdown, row)
+	}
+
+	return breakdown, rows.Err()
+}
+
+// SurveyFeedbackFilter narrows the exit survey browser. MaxRating only matches rated tickets.
+type SurveyFeedbackFilter struct {
+	PanelId   *int
+	MaxRating *int
+	Limit     int
+	Offset    int
+}
+
+// GetSurveyFeedback returns the exit survey responses for tickets closed in the range, newest first, along with the
+// total number of matching tickets. Only responses to the panel's current exit survey form are included.
+func (a *Analytics) GetSurveyFeedback(ctx context.Context, guildId uint64, start, end time.Time, filter SurveyFeedbackFilter) ([]SurveyFeedback, int, error) {
+	query := `
+WITH responded AS (
+	SELECT DISTINCT tickets."id", tickets."panel_id", tickets."user_id", tickets."close_time", service_ratings."rating"
+	FROM exit_survey_responses
+	INNER JOIN tickets ON tickets."guild_id" = exit_survey_responses."guild_id" AND tickets."id" = exit_survey_responses."ticket_id"
+	INNER JOIN panels ON panels."panel_id" = tickets."panel_id" AND panels."exit_survey_form_id" = exit_survey_responses."form_id"
+	LEFT OUTER JOIN service_ratings ON service_ratings."guild_id" = tickets."guild_id" AND service_ratings."ticket_id" = tickets."id"
+	WHERE tickets."guild_id" = $1 AND tickets."close_time" >= $2 AND tickets."close_time" < $3
+		AND ($4::int4 IS NULL OR tickets."panel_id" = $4)
+		AND ($5::int2 IS NULL OR service_ratings."rating" <= $5)
+)
+SELECT responded."id", responded."panel_id", responded."user_id", ticket_claims."user_id", responded."close_time", responded."rating", COUNT(*) OVER ()
+FROM responded
+LEFT OUTER JOIN ticket_claims ON ticket_claims."guild_id" = $1 AND ticket_claims."ticket_id" = responded."id"
+ORDER BY responded."close_time" DESC, responded."id" DESC
+LIMIT $6 OFFSET $7;`
+
+	rows, err := a.Query(ctx, query, guildId, start, end, filter.PanelId, filter.MaxRating, filter.Limit, filter.Offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var total int
+	feedback := make([]SurveyFeedback, 0)
+	for rows.Next() {
+		var row SurveyFeedback
+		if err := rows.Scan(&row.TicketId, &row.PanelId, &row.UserId, &row.ClaimedBy, &row.CloseTime, &row.Rating, &total); err != nil {
+			return nil, 0, err
+		}
+
+		row.Low = row.Rating != nil && *row.Rating <= LowRatingThreshold
+		feedback = append(feedback, row)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	if len(feedback) == 0 {
+		return feedback, 0, nil
+	}
+
+	ticketIds := make([]int, len(feedback))
+	for i, row := range feedback {
+		ticketIds[i] = row.TicketId
+	}
+
+	responses, err := a.getSurveyResponses(ctx, guildId, ticketIds)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for i, row := range feedback {
+		feedback[i].Responses = responses[row.TicketId]
+		if feedback[i].Responses == nil {
+			feedback[i].Responses = make([]database.QuestionResponse, 0)
+		}
+	}
+
+	return feedback, total, nil
+}
+
+func (a *Analytics) getSurveyResponses(ctx context.Context, guildId uint64, ticketIds []int) (map[int][]database.QuestionResponse, error) {
+	query := `
+SELECT exit_survey_responses."ticket_id", exit_survey_responses."question_id", form_input."label", exit_survey_responses."response"
+FROM exit_survey_responses
+INNER JOIN tickets ON tickets."guild_id" = exit_survey_responses."guild_id" AND tickets."id" = exit_survey_responses."ticket_id"
+INNER JOIN panels ON panels."panel_id" = tickets."panel_id" AND panels."exit_survey_form_id" = exit_survey_responses."form_id"
+INNER JOIN form_input ON form_input."id" = exit_survey_responses."question_id"
+WHERE exit_survey_responses."guild_id" = $1 AND exit_survey_responses."ticket_id" = ANY($2)
+ORDER BY form_input."position" ASC;`
+
+	rows, err := a.Query(ctx, query, guildId, ticketIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	responses := make(map[int][]database.QuestionResponse)
+	for rows.Next() {
+		var ticketId int
+		var response database.QuestionResponse
+		if err := rows.Scan(&ticketId, &response.QuestionId, &response.Question, &response.Response); err != nil {
+			return nil, err
+		}
+
+		responses[ticketId] = append(responses[ticketId], response)
+	}
+
+	return responses, rows.Err()
+}
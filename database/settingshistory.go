@@ -0,0 +1,137 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// settingsHistoryLimit is the number of versions kept for each guild
+const settingsHistoryLimit = 100
+
+// SettingsVersion records a single change to a guild's settings. OldData and NewData hold the settings in the same
+// shape as they are submitted to the settings endpoint. RollbackOf is set if the change rolled back to an earlier
+// version.
+type SettingsVersion struct {
+	Id         int             `json:"id"`
+	GuildId    uint64          `json:"-"`
+	UserId     uint64          `json:"user_id,string"`
+	CreatedAt  time.Time       `json:"created_at"`
+	RollbackOf *int            `json:"rollback_of"`
+	OldData    json.RawMessage `json:"-"`
+	NewData    json.RawMessage `json:"-"`
+}
+
+type SettingsHistoryTable struct {
+	*pgxpool.Pool
+}
+
+func newSettingsHistoryTable(db *pgxpool.Pool) *SettingsHistoryTable {
+	return &SettingsHistoryTable{
+		db,
+	}
+}
+
+func (t SettingsHistoryTable) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS settings_history(
+	"id" SERIAL NOT NULL UNIQUE,
+	"guild_id" int8 NOT NULL,
+	"user_id" int8 NOT NULL,
+	"created_at" timestamptz NOT NULL DEFAULT NOW(),
+	"rollback_of" int4 DEFAULT NULL,
+	"old_data" jsonb NOT NULL,
+	"new_data" jsonb NOT NULL,
+	PRIMARY KEY("id")
+);
+CREATE INDEX IF NOT EXISTS settings_history_guild_id_created_at ON settings_history("guild_id", "created_at" DESC);
+`
+}
+
+// GetByGuild returns a page of the guild's versions, newest first
+func (t *SettingsHistoryTable) GetByGuild(ctx context.Context, guildId uint64, limit, offset int) ([]SettingsVersion, error) {
+	query := `
+SELECT "id", "user_id", "created_at", "rollback_of", "old_data", "new_data"
+FROM settings_history
+WHERE "guild_id" = $1
+ORDER BY "created_at" DESC, "id" DESC
+LIMIT $2 OFFSET $3;`
+
+	rows, err := t.Query(ctx, query, guildId, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := make([]SettingsVersion, 0)
+	for rows.Next() {
+		version := SettingsVersion{GuildId: guildId}
+		if err := rows.Scan(&version.Id, &version.UserId, &version.CreatedAt, &version.RollbackOf, &version.OldData, &version.NewData); err != nil {
+			return nil, err
+		}
+
+		versions = append(versions, version)
+	}
+
+	return versions, rows.Err()
+}
+
+func (t *SettingsHistoryTable) GetCount(ctx context.Context, guildId uint64) (count int, err error) {
+	query := `SELECT COUNT(*) FROM settings_history WHERE "guild_id" = $1;`
+	err = t.QueryRow(ctx, query, guildId).Scan(&count)
+	return
+}
+
+func (t *SettingsHistoryTable) Get(ctx context.Context, guildId uint64, id int) (SettingsVersion, bool, error) {
+	query := `
+SELECT "user_id", "created_at", "rollback_of", "old_data", "new_data"
+FROM settings_history
+WHERE "guild_id" = $1 AND "id" = $2;`
+
+	version := SettingsVersion{Id: id, GuildId: guildId}
+	err := t.QueryRow(ctx, query, guildId, id).Scan(&version.UserId, &version.CreatedAt, &version.RollbackOf, &version.OldData, &version.NewData)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return SettingsVersion{}, false, nil
+		}
+
+		return SettingsVersion{}, false, err
+	}
+
+	return version, true, nil
+}
+
+// Create records a version, deleting the guild's oldest versions beyond the limit
+func (t *SettingsHistoryTable) Create(ctx context.Context, version SettingsVersion) (int, error) {
+	tx, err := t.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	defer tx.Rollback(ctx)
+
+	query := `
+INSERT INTO settings_history("guild_id", "user_id", "rollback_of", "old_data", "new_data")
+VALUES($1, $2, $3, $4, $5)
+RETURNING "id";`
+
+	var id int
+	if err := tx.QueryRow(ctx, query, version.GuildId, version.UserId, version.RollbackOf, version.OldData, version.NewData).Scan(&id); err != nil {
+		return 0, err
+	}
+
+	query = `
+DELETE FROM settings_history
+WHERE "guild_id" = $1 AND "id" NOT IN (
+	SELECT "id" FROM settings_history WHERE "guild_id" = $1 ORDER BY "created_at" DESC, "id" DESC LIMIT $2
+);`
+
+	if _, err := tx.Exec(ctx, query, version.GuildId, settingsHistoryLimit); err != nil {
+		return 0, err
+	}
+
+	return id, tx.Commit(ctx)
+}
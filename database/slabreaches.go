@@ -0,0 +1,128 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+type SlaTarget string
+
+const (
+	SlaTargetFirstResponse SlaTarget = "first_response"
+	SlaTargetResolution    SlaTarget = "resolution"
+)
+
+// SlaBreach records a ticket missing one of its panel's SLA targets. BreachedAt is when the target was due, and
+// TargetMinutes is the target at the time, as the policy may since have changed.
+type SlaBreach struct {
+	GuildId       uint64    `json:"-"`
+	TicketId      int       `json:"ticket_id"`
+	PanelId       int       `json:"panel_id"`
+	Target        SlaTarget `json:"target"`
+	TargetMinutes int       `json:"target_minutes"`
+	BreachedAt    time.Time `json:"breached_at"`
+}
+
+type SlaBreachCount struct {
+	PanelId       int `json:"panel_id"`
+	FirstResponse int `json:"first_response"`
+	Resolution    int `json:"resolution"`
+}
+
+type SlaBreachesTable struct {
+	*pgxpool.Pool
+}
+
+func newSlaBreachesTable(db *pgxpool.Pool) *SlaBreachesTable {
+	return &SlaBreachesTable{
+		db,
+	}
+}
+
+func (t SlaBreachesTable) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS sla_breaches(
+	"guild_id" int8 NOT NULL,
+	"ticket_id" int4 NOT NULL,
+	"panel_id" int4 NOT NULL,
+	"target" varchar(32) NOT NULL,
+	"target_minutes" int4 NOT NULL,
+	"breached_at" timestamptz NOT NULL,
+	FOREIGN KEY("ticket_id", "guild_id") REFERENCES tickets("id", "guild_id") ON DELETE CASCADE,
+	PRIMARY KEY("guild_id", "ticket_id", "target")
+);
+CREATE INDEX IF NOT EXISTS sla_breaches_guild_id_breached_at ON sla_breaches("guild_id", "breached_at");
+`
+}
+
+// Record stores a breach, unless one has already been recorded for the same ticket and target
+func (t *SlaBreachesTable) Record(ctx context.Context, breach SlaBreach) error {
+	query := `
+INSERT INTO sla_breaches("guild_id", "ticket_id", "panel_id", "target", "target_minutes", "breached_at")
+VALUES($1, $2, $3, $4, $5, $6)
+ON CONFLICT("guild_id", "ticket_id", "target") DO NOTHING;`
+
+	_, err := t.Exec(ctx, query, breach.GuildId, breach.TicketId, breach.PanelId, breach.Target, breach.TargetMinutes, breach.BreachedAt)
+	return err
+}
+
+// GetByGuild returns the breaches which occurred in the range, most recent first
+func (t *SlaBreachesTable) GetByGuild(ctx context.Context, guildId uint64, start, end time.Time, limit int) ([]SlaBreach, error) {
+	query := `
+SELECT "ticket_id", "panel_id", "target", "target_minutes", "breached_at"
+FROM sla_breaches
+WHERE "guild_id" = $1 AND "breached_at" >= $2 AND "breached_at" < $3
+ORDER BY "breached_at" DESC
+LIMIT $4;`
+
+	rows, err := t.Query(ctx, query, guildId, start, end, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	breaches := make([]SlaBreach, 0)
+	for rows.Next() {
+		breach := SlaBreach{GuildId: guildId}
+		if err := rows.Scan(&breach.TicketId, &breach.PanelId, &breach.Target, &breach.TargetMinutes, &breach.BreachedAt); err != nil {
+			return nil, err
+		}
+
+		breaches = append(breaches, breach)
+	}
+
+	return breaches, rows.Err()
+}
+
+// GetCounts returns the number of breaches of each target in the range, per panel
+func (t *SlaBreachesTable) GetCounts(ctx context.Context, guildId uint64, start, end time.Time) ([]SlaBreachCount, error) {
+	query := `
+SELECT
+	"panel_id",
+	COUNT(*) FILTER (WHERE "target" = $4),
+	COUNT(*) FILTER (WHERE "target" = $5)
+FROM sla_breaches
+WHERE "guild_id" = $1 AND "breached_at" >= $2 AND "breached_at" < $3
+GROUP BY "panel_id"
+ORDER BY "panel_id" ASC;`
+
+	rows, err := t.Query(ctx, query, guildId, start, end, SlaTargetFirstResponse, SlaTargetResolution)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make([]SlaBreachCount, 0)
+	for rows.Next() {
+		var count SlaBreachCount
+		if err := rows.Scan(&count.PanelId, &count.FirstResponse, &count.Resolution); err != nil {
+			return nil, err
+		}
+
+		counts = append(counts, count)
+	}
+
+	return counts, rows.Err()
+}
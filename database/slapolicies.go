@@ -0,0 +1,121 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// SlaPolicy holds the response targets for tickets opened from a panel. Either target may be nil if it is not tracked.
+// If SupportHoursOnly is set, only time within the panel's support hours counts towards the targets.
+type SlaPolicy struct {
+	PanelId              int  `json:"panel_id"`
+	FirstResponseMinutes *int `json:"first_response_minutes"`
+	ResolutionHours      *int `json:"resolution_hours"`
+	SupportHoursOnly     bool `json:"support_hours_only"`
+}
+
+type SlaPoliciesTable struct {
+	*pgxpool.Pool
+}
+
+func newSlaPoliciesTable(db *pgxpool.Pool) *SlaPoliciesTable {
+	return &SlaPoliciesTable{
+		db,
+	}
+}
+
+func (t SlaPoliciesTable) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS sla_policies(
+	"panel_id" int4 NOT NULL,
+	"guild_id" int8 NOT NULL,
+	"first_response_minutes" int4 DEFAULT NULL,
+	"resolution_hours" int4 DEFAULT NULL,
+	"support_hours_only" bool NOT NULL DEFAULT 'f',
+	FOREIGN KEY("panel_id") REFERENCES panels("panel_id") ON DELETE CASCADE,
+	PRIMARY KEY("panel_id")
+);
+CREATE INDEX IF NOT EXISTS sla_policies_guild_id ON sla_policies("guild_id");
+`
+}
+
+func (t *SlaPoliciesTable) Get(ctx context.Context, panelId int) (SlaPolicy, bool, error) {
+	query := `
+SELECT "panel_id", "first_response_minutes", "resolution_hours", "support_hours_only"
+FROM sla_policies
+WHERE "panel_id" = $1;`
+
+	var policy SlaPolicy
+	err := t.QueryRow(ctx, query, panelId).Scan(&policy.PanelId, &policy.FirstResponseMinutes, &policy.ResolutionHours, &policy.SupportHoursOnly)
+	if err == nil {
+		return policy, true, nil
+	} else if err == pgx.ErrNoRows {
+		return SlaPolicy{}, false, nil
+	} else {
+		return SlaPolicy{}, false, err
+	}
+}
+
+// GetByGuild returns the policies for every panel in the guild that has one, keyed by panel ID
+func (t *SlaPoliciesTable) GetByGuild(ctx context.Context, guildId uint64) (map[int]SlaPolicy, error) {
+	query := `
+SELECT "panel_id", "first_response_minutes", "resolution_hours", "support_hours_only"
+FROM sla_policies
+WHERE "guild_id" = $1;`
+
+	rows, err := t.Query(ctx, query, guildId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	policies := make(map[int]SlaPolicy)
+	for rows.Next() {
+		var policy SlaPolicy
+		if err := rows.Scan(&policy.PanelId, &policy.FirstResponseMinutes, &policy.ResolutionHours, &policy.SupportHoursOnly); err != nil {
+			return nil, err
+		}
+
+		policies[policy.PanelId] = policy
+	}
+
+	return policies, rows.Err()
+}
+
+// GetGuilds returns the IDs of guilds with at least one SLA policy
+func (t *SlaPoliciesTable) GetGuilds(ctx context.Context) ([]uint64, error) {
+	rows, err := t.Query(ctx, `SELECT DISTINCT "guild_id" FROM sla_policies;`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var guildIds []uint64
+	for rows.Next() {
+		var guildId uint64
+		if err := rows.Scan(&guildId); err != nil {
+			return nil, err
+		}
+
+		guildIds = append(guildIds, guildId)
+	}
+
+	return guildIds, rows.Err()
+}
+
+func (t *SlaPoliciesTable) Set(ctx context.Context, guildId uint64, policy SlaPolicy) error {
+	query := `
+INSERT INTO sla_policies("panel_id", "guild_id", "first_response_minutes", "resolution_hours", "support_hours_only")
+VALUES($1, $2, $3, $4, $5)
+ON CONFLICT("panel_id") DO UPDATE SET "first_response_minutes" = $3, "resolution_hours" = $4, "support_hours_only" = $5;`
+
+	_, err := t.Exec(ctx, query, policy.PanelId, guildId, policy.FirstResponseMinutes, policy.ResolutionHours, policy.SupportHoursOnly)
+	return err
+}
+
+func (t *SlaPoliciesTable) Delete(ctx context.Context, panelId int) error {
+	_, err := t.Exec(ctx, `DELETE FROM sla_policies WHERE "panel_id" = $1;`, panelId)
+	return err
+}
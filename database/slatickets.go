@@ -0,0 +1,74 @@
+package database
+
+import (
+	"context"
+	"time"
+)
+
+// SlaTicket holds the timestamps needed to evaluate a ticket against its panel's SLA policy
+type SlaTicket struct {
+	TicketId        int
+	PanelId         int
+	OpenTime        time.Time
+	FirstResponseAt *time.Time
+	CloseTime       *time.Time
+}
+
+// GetSlaTickets returns the tickets opened from the given panels that are either still open, or were closed after
+// closedSince.
+func (a *Analytics) GetSlaTickets(ctx context.Context, guildId uint64, panelIds []int, closedSince time.Time) ([]SlaTicket, error) {
+	query := `
+SELECT tickets."id", tickets."panel_id", tickets."open_time", tickets."open_time" + first_response_time."response_time", tickets."close_time"
+FROM tickets
+LEFT OUTER JOIN first_response_time
+	ON first_response_time."guild_id" = tickets."guild_id" AND first_response_time."ticket_id" = tickets."id"
+WHERE tickets."guild_id" = $1
+	AND tickets."panel_id" = ANY($2)
+	AND (tickets."open" = true OR tickets."close_time" >= $3);`
+
+	rows, err := a.Query(ctx, query, guildId, panelIds, closedSince)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tickets []SlaTicket
+	for rows.Next() {
+		var ticket SlaTicket
+		if err := rows.Scan(&ticket.TicketId, &ticket.PanelId, &ticket.OpenTime, &ticket.FirstResponseAt, &ticket.CloseTime); err != nil {
+			return nil, err
+		}
+
+		tickets = append(tickets, ticket)
+	}
+
+	return tickets, rows.Err()
+}
+
+// GetFirstResponses returns when staff first responded to each of the tickets, for those which have had a response
+func (a *Analytics) GetFirstResponses(ctx context.Context, guildId uint64, ticketIds []int) (map[int]time.Time, error) {
+	query := `
+SELECT tickets."id", tickets."open_time" + first_response_time."response_time"
+FROM first_response_time
+INNER JOIN tickets ON tickets."guild_id" = first_response_time."guild_id" AND tickets."id" = first_response_time."ticket_id"
+WHERE first_response_time."guild_id" = $1 AND first_response_time."ticket_id" = ANY($2);`
+
+	rows, err := a.Query(ctx, query, guildId, ticketIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	responses := make(map[int]time.Time)
+	for rows.Next() {
+		var ticketId int
+		var respondedAt time.Time
+		if err := rows.Scan(&ticketId, &respondedAt); err != nil {
+			return nil, err
+		}
+
+		responses[ticketId] = respondedAt
+	}
+
+	return responses, rows.Err()
+}
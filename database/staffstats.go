@@ -0,0 +1,108 @@
+package database
+
+import (
+	"context"
+	"time"
+)
+
+// StaffStats holds the activity of a single staff member over a date range. FirstResponseMedian is in seconds, and
+// AverageRating and FirstResponseMedian are nil if there were no samples.
+type StaffStats struct {
+	UserId              uint64   `json:"user_id,string"`
+	Claimed             int      `json:"claimed"`
+	Closed              int      `json:"closed"`
+	Messages            int      `json:"messages"`
+	AverageRating       *float64 `json:"average_rating"`
+	Ratings             int      `json:"ratings"`
+	FirstResponseMedian *float64 `json:"first_response_median"`
+}
+
+// GetStaffStats returns per-staff statistics. Claims and first responses are counted for tickets opened in the range,
+// while closes, messages and ratings are counted for tickets closed in the range. Ratings are credited to the claimer,
+// falling back to whoever closed the ticket. Users closing or messaging in their own tickets are not counted, and users
+// who only sent messages do not appear, as they may not be staff.
+func (a *Analytics) GetStaffStats(ctx context.Context, guildId uint64, start, end time.Time) ([]StaffStats, error) {
+	query := `
+WITH claimed AS (
+	SELECT ticket_claims."user_id", COUNT(*) AS count
+	FROM ticket_claims
+	INNER JOIN tickets ON tickets."guild_id" = ticket_claims."guild_id" AND tickets."id" = ticket_claims."ticket_id"
+	WHERE tickets."guild_id" = $1 AND tickets."open_time" >= $2 AND tickets."open_time" < $3
+	GROUP BY ticket_claims."user_id"
+), closed AS (
+	SELECT close_reason."closed_by" AS user_id, COUNT(*) AS count
+	FROM close_reason
+	INNER JOIN tickets ON tickets."guild_id" = close_reason."guild_id" AND tickets."id" = close_reason."ticket_id"
+	WHERE tickets."guild_id" = $1 AND tickets."close_time" >= $2 AND tickets."close_time" < $3
+		AND close_reason."closed_by" IS NOT NULL AND close_reason."closed_by" != tickets."user_id"
+	GROUP BY close_reason."closed_by"
+), messages AS (
+	SELECT ticket_message_counts."user_id", SUM(ticket_message_counts."message_count") AS count
+	FROM ticket_message_counts
+	INNER JOIN tickets ON tickets."guild_id" = ticket_message_counts."guild_id" AND tickets."id" = ticket_message_counts."ticket_id"
+	WHERE tickets."guild_id" = $1 AND tickets."close_time" >= $2 AND tickets."close_time" < $3
+		AND ticket_message_counts."user_id" != tickets."user_id"
+	GROUP BY ticket_message_counts."user_id"
+), ratings AS (
+	SELECT COALESCE(ticket_claims."user_id", close_reason."closed_by") AS user_id, AVG(service_ratings."rating")::float8 AS average, COUNT(*) AS count
+	FROM service_ratings
+	INNER JOIN tickets ON tickets."guild_id" = service_ratings."guild_id" AND tickets."id" = service_ratings."ticket_id"
+	LEFT OUTER JOIN ticket_claims ON ticket_claims."guild_id" = tickets."guild_id" AND ticket_claims."ticket_id" = tickets."id"
+	LEFT OUTER JOIN close_reason ON close_reason."guild_id" = tickets."guild_id" AND close_reason."ticket_id" = tickets."id"
+		AND close_reason."closed_by" != tickets."user_id"
+	WHERE tickets."guild_id" = $1 AND tickets."close_time" >= $2 AND tickets."close_time" < $3
+	GROUP BY 1
+), first_response AS (
+	SELECT first_response_time."user_id", percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM first_response_time."response_time")::float8) AS median
+	FROM first_response_time
+	INNER JOIN tickets ON tickets."guild_id" = first_response_time."guild_id" AND tickets."id" = first_response_time."ticket_id"
+	WHERE tickets."guild_id" = $1 AND tickets."open_time" >= $2 AND tickets."open_time" < $3
+	GROUP BY first_response_time."user_id"
+), staff AS (
+	SELECT "user_id" FROM claimed
+	UNION SELECT "user_id" FROM closed
+	UNION SELECT "user_id" FROM ratings
+	UNION SELECT "user_id" FROM first_response
+)
+SELECT
+	staff."user_id",
+	COALESCE(claimed.count, 0),
+	COALESCE(closed.count, 0),
+	COALESCE(messages.count, 0),
+	ratings.average,
+	COALESCE(ratings.count, 0),
+	first_response.median
+FROM staff
+LEFT OUTER JOIN claimed ON claimed."user_id" = staff."user_id"
+LEFT OUTER JOIN closed ON closed."user_id" = staff."user_id"
+LEFT OUTER JOIN messages ON messages."user_id" = staff."user_id"
+LEFT OUTER JOIN ratings ON ratings."user_id" = staff."user_id"
+LEFT OUTER JOIN first_response ON first_response."user_id" = staff."user_id"
+WHERE staff."user_id" IS NOT NULL;`
+
+	rows, err := a.Query(ctx, query, guildId, start, end)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := make([]StaffStats, 0)
+	for rows.Next() {
+		var row StaffStats
+		if err := rows.Scan(
+			&row.UserId,
+			&row.Claimed,
+			&row.Closed,
+			&row.Messages,
+			&row.AverageRating,
+			&row.Ratings,
+			&row.FirstResponseMedian,
+		); err != nil {
+			return nil, err
+		}
+
+		stats = append(stats, row)
+	}
+
+	return stats, rows.Err()
+}
@@ -0,0 +1,160 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/TicketsBot-cloud/database"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// AppliedSupportHoursOverridesTable holds the weekly support hours of panels which currently have an override in effect.
+// The bot only reads panel_support_hours, so while an override is in effect, the row for that day of the week is
+// rewritten with the override's hours, and the weekly hours are kept here so that they can be restored afterwards.
+type AppliedSupportHoursOverridesTable struct {
+	*pgxpool.Pool
+}
+
+func newAppliedSupportHoursOverridesTable(db *pgxpool.Pool) *AppliedSupportHoursOverridesTable {
+	return &AppliedSupportHoursOverridesTable{
+		db,
+	}
+}
+
+func (t AppliedSupportHoursOverridesTable) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS panel_support_hours_applied_overrides(
+	"panel_id" int4 NOT NULL,
+	"weekly_hours" jsonb NOT NULL,
+	"applied_at" timestamptz NOT NULL DEFAULT NOW(),
+	FOREIGN KEY("panel_id") REFERENCES panels("panel_id") ON DELETE CASCADE,
+	PRIMARY KEY("panel_id")
+);
+`
+}
+
+// GetPanelsToCheck returns the panels which either have an override within a day of the given date, which may be in
+// effect in the panel's timezone, or have an override applied which may need to be removed
+func (t *AppliedSupportHoursOverridesTable) GetPanelsToCheck(ctx context.Context, now time.Time) ([]int, error) {
+	query := `
+SELECT "panel_id" FROM panel_support_hours_overrides
+WHERE ("recurring" = false AND "date" BETWEEN $1::date - 1 AND $1::date + 1)
+	OR ("recurring" = true AND to_char("date", 'MM-DD') IN (to_char($1::date - 1, 'MM-DD'), to_char($1::date, 'MM-DD'), to_char($1::date + 1, 'MM-DD')))
+UNION
+SELECT "panel_id" FROM panel_support_hours_applied_overrides;`
+
+	rows, err := t.Query(ctx, query, now.UTC().Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var panelIds []int
+	for rows.Next() {
+		var panelId int
+		if err := rows.Scan(&panelId); err != nil {
+			return nil, err
+		}
+
+		panelIds = append(panelIds, panelId)
+	}
+
+	return panelIds, rows.Err()
+}
+
+// GetWeeklyHours returns the panel's weekly support hours, which are the hours kept by the applied override if there
+// is one, rather than the rewritten hours seen by the bot. It should be used in place of PanelSupportHours.GetByPanelId
+// wherever the configured hours are shown or copied.
+func (t *AppliedSupportHoursOverridesTable) GetWeeklyHours(ctx context.Context, panelId int) ([]database.PanelSupportHours, error) {
+	hours, ok, err := t.Get(ctx, panelId)
+	if err != nil {
+		return nil, err
+	}
+
+	if ok {
+		return hours, nil
+	}
+
+	return Client.PanelSupportHours.GetByPanelId(ctx, panelId)
+}
+
+// Get returns the weekly hours kept for the panel, if it has an override applied
+func (t *AppliedSupportHoursOverridesTable) Get(ctx context.Context, panelId int) ([]database.PanelSupportHours, bool, error) {
+	var encoded []byte
+	if err := t.QueryRow(ctx, `SELECT "weekly_hours" FROM panel_support_hours_applied_overrides WHERE "panel_id" = $1;`, panelId).Scan(&encoded); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, false, nil
+		}
+
+		return nil, false, err
+	}
+
+	var hours []database.PanelSupportHours
+	if err := json.Unmarshal(encoded, &hours); err != nil {
+		return nil, false, err
+	}
+
+	return hours, true, nil
+}
+
+// Apply replaces the hours seen by the bot, keeping the weekly hours unless they are already kept from an earlier
+// override
+func (t *AppliedSupportHoursOverridesTable) Apply(ctx context.Context, panelId int, weeklyHours, hours []database.PanelSupportHours) error {
+	encoded, err := json.Marshal(weeklyHours)
+	if err != nil {
+		return err
+	}
+
+	return t.BeginFunc(ctx, func(tx pgx.Tx) error {
+		query := `
+INSERT INTO panel_support_hours_applied_overrides("panel_id", "weekly_hours")
+VALUES($1, $2)
+ON CONFLICT("panel_id") DO NOTHING;`
+
+		if _, err := tx.Exec(ctx, query, panelId, encoded); err != nil {
+			return err
+		}
+
+		return replacePanelHours(ctx, tx, panelId, hours)
+	})
+}
+
+// Restore puts the panel's weekly hours back once its override is no longer in effect
+func (t *AppliedSupportHoursOverridesTable) Restore(ctx context.Context, panelId int, weeklyHours []database.PanelSupportHours) error {
+	return t.BeginFunc(ctx, func(tx pgx.Tx) error {
+		if err := clearAppliedOverride(ctx, tx, panelId); err != nil {
+			return err
+		}
+
+		return replacePanelHours(ctx, tx, panelId, weeklyHours)
+	})
+}
+
+// Clear forgets the kept weekly hours, after the panel's hours have been replaced by an admin. The override is applied
+// to the new hours on the next run.
+func (t *AppliedSupportHoursOverridesTable) Clear(ctx context.Context, panelId int) error {
+	_, err := t.Exec(ctx, `DELETE FROM panel_support_hours_applied_overrides WHERE "panel_id" = $1;`, panelId)
+	return err
+}
+
+func clearAppliedOverride(ctx context.Context, tx pgx.Tx, panelId int) error {
+	_, err := tx.Exec(ctx, `DELETE FROM panel_support_hours_applied_overrides WHERE "panel_id" = $1;`, panelId)
+	return err
+}
+
+func replacePanelHours(ctx context.Context, tx pgx.Tx, panelId int, hours []database.PanelSupportHours) error {
+	if err := Client.PanelSupportHours.DeleteByPanelIdWithTx(ctx, tx, panelId); err != nil {
+		return err
+	}
+
+	for _, h := range hours {
+		h.PanelId = panelId
+		if _, err := Client.PanelSupportHours.UpsertWithTx(ctx, tx, h); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,181 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// SupportHoursOverride replaces a panel's weekly support hours on a single date. If Closed is false, StartTime and
+// EndTime hold the custom hours for the day. Recurring overrides apply on the same date every year.
+type SupportHoursOverride struct {
+	Id        int        `json:"id"`
+	PanelId   int        `json:"panel_id"`
+	Date      time.Time  `json:"date"`
+	Recurring bool       `json:"recurring"`
+	Closed    bool       `json:"closed"`
+	StartTime *time.Time `json:"start_time"`
+	EndTime   *time.Time `json:"end_time"`
+	Label     string     `json:"label"`
+	Message   *string    `json:"message"`
+}
+
+type SupportHoursOverridesTable struct {
+	*pgxpool.Pool
+}
+
+func newSupportHoursOverridesTable(db *pgxpool.Pool) *SupportHoursOverridesTable {
+	return &SupportHoursOverridesTable{
+		db,
+	}
+}
+
+func (t SupportHoursOverridesTable) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS panel_support_hours_overrides(
+	"id" SERIAL NOT NULL UNIQUE,
+	"panel_id" int4 NOT NULL,
+	"date" date NOT NULL,
+	"recurring" bool NOT NULL DEFAULT 'f',
+	"closed" bool NOT NULL,
+	"start_time" time DEFAULT NULL,
+	"end_time" time DEFAULT NULL,
+	"label" varchar(100) NOT NULL,
+	"message" varchar(500) DEFAULT NULL,
+	FOREIGN KEY("panel_id") REFERENCES panels("panel_id") ON DELETE CASCADE,
+	UNIQUE("panel_id", "date"),
+	PRIMARY KEY("id")
+);
+CREATE INDEX IF NOT EXISTS panel_support_hours_overrides_panel_id ON panel_support_hours_overrides("panel_id");
+`
+}
+
+func (t *SupportHoursOverridesTable) GetByPanel(ctx context.Context, panelId int) ([]SupportHoursOverride, error) {
+	query := `
+SELECT "id", "panel_id", "date", "recurring", "closed", "start_time", "end_time", "label", "message"
+FROM panel_support_hours_overrides
+WHERE "panel_id" = $1
+ORDER BY "date" ASC;`
+
+	rows, err := t.Query(ctx, query, panelId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	overrides := make([]SupportHoursOverride, 0)
+	for rows.Next() {
+		override, err := scanSupportHoursOverride(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		overrides = append(overrides, override)
+	}
+
+	return overrides, rows.Err()
+}
+
+func (t *SupportHoursOverridesTable) Get(ctx context.Context, panelId, id int) (SupportHoursOverride, bool, error) {
+	query := `
+SELECT "id", "panel_id", "date", "recurring", "closed", "start_time", "end_time", "label", "message"
+FROM panel_support_hours_overrides
+WHERE "panel_id" = $1 AND "id" = $2;`
+
+	override, err := scanSupportHoursOverride(t.QueryRow(ctx, query, panelId, id))
+	if err == nil {
+		return override, true, nil
+	} else if err == pgx.ErrNoRows {
+		return SupportHoursOverride{}, false, nil
+	} else {
+		return SupportHoursOverride{}, false, err
+	}
+}
+
+func (t *SupportHoursOverridesTable) GetCount(ctx context.Context, panelId int) (count int, err error) {
+	query := `SELECT COUNT(*) FROM panel_support_hours_overrides WHERE "panel_id" = $1;`
+	err = t.QueryRow(ctx, query, panelId).Scan(&count)
+	return
+}
+
+// Create inserts the override and returns its ID. It returns false if the panel already has an override on that date.
+func (t *SupportHoursOverridesTable) Create(ctx context.Context, override SupportHoursOverride) (int, bool, error) {
+	query := `
+INSERT INTO panel_support_hours_overrides("panel_id", "date", "recurring", "closed", "start_time", "end_time", "label", "message")
+VALUES($1, $2, $3, $4, $5, $6, $7, $8)
+ON CONFLICT("panel_id", "date") DO NOTHING
+RETURNING "id";`
+
+	var id int
+	err := t.QueryRow(ctx, query,
+		override.PanelId,
+		override.Date,
+		override.Recurring,
+		override.Closed,
+		override.StartTime,
+		override.EndTime,
+		override.Label,
+		override.Message,
+	).Scan(&id)
+
+	if err == pgx.ErrNoRows {
+		return 0, false, nil
+	} else if err != nil {
+		return 0, false, err
+	}
+
+	return id, true, nil
+}
+
+// Update replaces an existing override. It returns false if another override already exists on the new date.
+func (t *SupportHoursOverridesTable) Update(ctx context.Context, override SupportHoursOverride) (bool, error) {
+	query := `
+UPDATE panel_support_hours_overrides
+SET "date" = $3, "recurring" = $4, "closed" = $5, "start_time" = $6, "end_time" = $7, "label" = $8, "message" = $9
+WHERE "panel_id" = $1 AND "id" = $2
+AND NOT EXISTS(
+	SELECT 1 FROM panel_support_hours_overrides AS other
+	WHERE other."panel_id" = $1 AND other."date" = $3 AND other."id" != $2
+);`
+
+	res, err := t.Exec(ctx, query,
+		override.PanelId,
+		override.Id,
+		override.Date,
+		override.Recurring,
+		override.Closed,
+		override.StartTime,
+		override.EndTime,
+		override.Label,
+		override.Message,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return res.RowsAffected() > 0, nil
+}
+
+func (t *SupportHoursOverridesTable) Delete(ctx context.Context, panelId, id int) error {
+	_, err := t.Exec(ctx, `DELETE FROM panel_support_hours_overrides WHERE "panel_id" = $1 AND "id" = $2;`, panelId, id)
+	return err
+}
+
+func scanSupportHoursOverride(row pgx.Row) (SupportHoursOverride, error) {
+	var override SupportHoursOverride
+	err := row.Scan(
+		&override.Id,
+		&override.PanelId,
+		&override.Date,
+		&override.Recurring,
+		&override.Closed,
+		&override.StartTime,
+		&override.EndTime,
+		&override.Label,
+		&override.Message,
+	)
+
+	return override, err
+}
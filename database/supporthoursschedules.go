@@ -0,0 +1,348 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/TicketsBot-cloud/database"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// SupportHoursSchedule is a named weekly schedule shared by several panels. The bot only reads panel_support_hours,
+// so the schedule's hours are copied to every linked panel whenever either changes.
+type SupportHoursSchedule struct {
+	Id       int                  `json:"id"`
+	GuildId  uint64               `json:"-"`
+	Name     string               `json:"name"`
+	Timezone string               `json:"timezone"`
+	Hours    []SupportHoursWindow `json:"hours"`
+	PanelIds []int                `json:"panel_ids"`
+}
+
+type SupportHoursWindow struct {
+	DayOfWeek int       `json:"day_of_week"`
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	Enabled   bool      `json:"enabled"`
+}
+
+type SupportHoursSchedulesTable struct {
+	*pgxpool.Pool
+}
+
+func newSupportHoursSchedulesTable(db *pgxpool.Pool) *SupportHoursSchedulesTable {
+	return &SupportHoursSchedulesTable{
+		db,
+	}
+}
+
+func (t SupportHoursSchedulesTable) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS support_hours_schedules(
+	"id" SERIAL NOT NULL UNIQUE,
+	"guild_id" int8 NOT NULL,
+	"name" varchar(100) NOT NULL,
+	"timezone" varchar(50) NOT NULL,
+	UNIQUE("guild_id", "name"),
+	PRIMARY KEY("id")
+);
+CREATE INDEX IF NOT EXISTS support_hours_schedules_guild_id ON support_hours_schedules("guild_id");
+CREATE TABLE IF NOT EXISTS support_hours_schedule_hours(
+	"schedule_id" int4 NOT NULL,
+	"day_of_week" int4 NOT NULL CHECK ("day_of_week" >= 0 AND "day_of_week" <= 6),
+	"start_time" time NOT NULL,
+	"end_time" time NOT NULL,
+	"enabled" bool NOT NULL DEFAULT 't',
+	FOREIGN KEY("schedule_id") REFERENCES support_hours_schedules("id") ON DELETE CASCADE,
+	PRIMARY KEY("schedule_id", "day_of_week")
+);
+CREATE TABLE IF NOT EXISTS panel_support_hours_schedules(
+	"panel_id" int4 NOT NULL,
+	"schedule_id" int4 NOT NULL,
+	FOREIGN KEY("panel_id") REFERENCES panels("panel_id") ON DELETE CASCADE,
+	FOREIGN KEY("schedule_id") REFERENCES support_hours_schedules("id") ON DELETE CASCADE,
+	PRIMARY KEY("panel_id")
+);
+CREATE INDEX IF NOT EXISTS panel_support_hours_schedules_schedule_id ON panel_support_hours_schedules("schedule_id");
+`
+}
+
+func (t *SupportHoursSchedulesTable) GetByGuild(ctx context.Context, guildId uint64) ([]SupportHoursSchedule, error) {
+	query := `SELECT "id", "name", "timezone" FROM support_hours_schedules WHERE "guild_id" = $1 ORDER BY "id" ASC;`
+
+	rows, err := t.Query(ctx, query, guildId)
+	if err != nil {
+		return nil, err
+	}
+
+	schedules := make([]SupportHoursSchedule, 0)
+	for rows.Next() {
+		schedule := SupportHoursSchedule{GuildId: guildId}
+		if err := rows.Scan(&schedule.Id, &schedule.Name, &schedule.Timezone); err != nil {
+			rows.Close()
+			return nil, err
+		}
+
+		schedules = append(schedules, schedule)
+	}
+
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for i := range schedules {
+		if err := t.populate(ctx, &schedules[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return schedules, nil
+}
+
+func (t *SupportHoursSchedulesTable) Get(ctx context.Context, guildId uint64, id int) (SupportHoursSchedule, bool, error) {
+	query := `SELECT "name", "timezone" FROM support_hours_schedules WHERE "guild_id" = $1 AND "id" = $2;`
+
+	schedule := SupportHoursSchedule{Id: id, GuildId: guildId}
+	if err := t.QueryRow(ctx, query, guildId, id).Scan(&schedule.Name, &schedule.Timezone); err != nil {
+		if err == pgx.ErrNoRows {
+			return SupportHoursSchedule{}, false, nil
+		}
+
+		return SupportHoursSchedule{}, false, err
+	}
+
+	if err := t.populate(ctx, &schedule); err != nil {
+		return SupportHoursSchedule{}, false, err
+	}
+
+	return schedule, true, nil
+}
+
+// populate fetches the schedule's hours and the panels linked to it
+func (t *SupportHoursSchedulesTable) populate(ctx context.Context, schedule *SupportHoursSchedule) error {
+	query := `
+SELECT "day_of_week", "start_time", "end_time", "enabled"
+FROM support_hours_schedule_hours
+WHERE "schedule_id" = $1
+ORDER BY "day_of_week" ASC;`
+
+	rows, err := t.Query(ctx, query, schedule.Id)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	schedule.Hours = make([]SupportHoursWindow, 0)
+	for rows.Next() {
+		var window SupportHoursWindow
+		if err := rows.Scan(&window.DayOfWeek, &window.StartTime, &window.EndTime, &window.Enabled); err != nil {
+			return err
+		}
+
+		schedule.Hours = append(schedule.Hours, window)
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	schedule.PanelIds, err = t.getPanelIds(ctx, t.Pool, schedule.Id)
+	return err
+}
+
+func (t *SupportHoursSchedulesTable) GetCount(ctx context.Context, guildId uint64) (count int, err error) {
+	query := `SELECT COUNT(*) FROM support_hours_schedules WHERE "guild_id" = $1;`
+	err = t.QueryRow(ctx, query, guildId).Scan(&count)
+	return
+}
+
+// NameExists reports whether another schedule in the guild already uses the name
+func (t *SupportHoursSchedulesTable) NameExists(ctx context.Context, guildId uint64, name string, excludeId int) (exists bool, err error) {
+	query := `SELECT EXISTS(SELECT 1 FROM support_hours_schedules WHERE "guild_id" = $1 AND "name" = $2 AND "id" != $3);`
+	err = t.QueryRow(ctx, query, guildId, name, excludeId).Scan(&exists)
+	return
+}
+
+func (t *SupportHoursSchedulesTable) Create(ctx context.Context, schedule SupportHoursSchedule) (int, error) {
+	tx, err := t.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	defer tx.Rollback(ctx)
+
+	query := `
+INSERT INTO support_hours_schedules("guild_id", "name", "timezone")
+VALUES($1, $2, $3)
+RETURNING "id";`
+
+	var id int
+	if err := tx.QueryRow(ctx, query, schedule.GuildId, schedule.Name, schedule.Timezone).Scan(&id); err != nil {
+		return 0, err
+	}
+
+	if err := setScheduleHours(ctx, tx, id, schedule.Hours); err != nil {
+		return 0, err
+	}
+
+	return id, tx.Commit(ctx)
+}
+
+// Update replaces the schedule's name, timezone and hours, and copies the new hours to every linked panel. It returns
+// the IDs of the linked panels.
+func (t *SupportHoursSchedulesTable) Update(ctx context.Context, schedule SupportHoursSchedule) ([]int, error) {
+	tx, err := t.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	defer tx.Rollback(ctx)
+
+	query := `UPDATE support_hours_schedules SET "name" = $3, "timezone" = $4 WHERE "guild_id" = $1 AND "id" = $2;`
+	if _, err := tx.Exec(ctx, query, schedule.GuildId, schedule.Id, schedule.Name, schedule.Timezone); err != nil {
+		return nil, err
+	}
+
+	if err := setScheduleHours(ctx, tx, schedule.Id, schedule.Hours); err != nil {
+		return nil, err
+	}
+
+	panelIds, err := t.getPanelIds(ctx, tx, schedule.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, panelId := range panelIds {
+		if err := copyScheduleHours(ctx, tx, panelId, schedule); err != nil {
+			return nil, err
+		}
+	}
+
+	return panelIds, tx.Commit(ctx)
+}
+
+// Delete removes the schedule. Linked panels keep a copy of its hours as their own.
+func (t *SupportHoursSchedulesTable) Delete(ctx context.Context, guildId uint64, id int) error {
+	_, err := t.Exec(ctx, `DELETE FROM support_hours_schedules WHERE "guild_id" = $1 AND "id" = $2;`, guildId, id)
+	return err
+}
+
+// GetPanelSchedule returns the ID of the schedule the panel uses, or nil if its hours are configured inline
+func (t *SupportHoursSchedulesTable) GetPanelSchedule(ctx context.Context, panelId int) (*int, error) {
+	query := `SELECT "schedule_id" FROM panel_support_hours_schedules WHERE "panel_id" = $1;`
+
+	var scheduleId int
+	if err := t.QueryRow(ctx, query, panelId).Scan(&scheduleId); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &scheduleId, nil
+}
+
+// LinkPanel makes the panel use the schedule, replacing its hours with the schedule's
+func (t *SupportHoursSchedulesTable) LinkPanel(ctx context.Context, panelId int, schedule SupportHoursSchedule) error {
+	tx, err := t.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(ctx)
+
+	query := `
+INSERT INTO panel_support_hours_schedules("panel_id", "schedule_id")
+VALUES($1, $2)
+ON CONFLICT("panel_id") DO UPDATE SET "schedule_id" = $2;`
+
+	if _, err := tx.Exec(ctx, query, panelId, schedule.Id); err != nil {
+		return err
+	}
+
+	if err := copyScheduleHours(ctx, tx, panelId, schedule); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// UnlinkPanel stops the panel from following a schedule. Its current hours are left in place.
+func (t *SupportHoursSchedulesTable) UnlinkPanel(ctx context.Context, panelId int) error {
+	_, err := t.Exec(ctx, `DELETE FROM panel_support_hours_schedules WHERE "panel_id" = $1;`, panelId)
+	return err
+}
+
+type querier interface {
+	Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error)
+}
+
+func (t *SupportHoursSchedulesTable) getPanelIds(ctx context.Context, q querier, scheduleId int) ([]int, error) {
+	query := `SELECT "panel_id" FROM panel_support_hours_schedules WHERE "schedule_id" = $1 ORDER BY "panel_id" ASC;`
+
+	rows, err := q.Query(ctx, query, scheduleId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	panelIds := make([]int, 0)
+	for rows.Next() {
+		var panelId int
+		if err := rows.Scan(&panelId); err != nil {
+			return nil, err
+		}
+
+		panelIds = append(panelIds, panelId)
+	}
+
+	return panelIds, rows.Err()
+}
+
+func setScheduleHours(ctx context.Context, tx pgx.Tx, scheduleId int, hours []SupportHoursWindow) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM support_hours_schedule_hours WHERE "schedule_id" = $1;`, scheduleId); err != nil {
+		return err
+	}
+
+	query := `
+INSERT INTO support_hours_schedule_hours("schedule_id", "day_of_week", "start_time", "end_time", "enabled")
+VALUES($1, $2, $3, $4, $5);`
+
+	for _, window := range hours {
+		if _, err := tx.Exec(ctx, query, scheduleId, window.DayOfWeek, window.StartTime, window.EndTime, window.Enabled); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// copyScheduleHours replaces the panel's own support hours with the schedule's, so that the bot sees the same hours.
+// Any override in effect is applied to the new hours on the next run of the sweeper.
+func copyScheduleHours(ctx context.Context, tx pgx.Tx, panelId int, schedule SupportHoursSchedule) error {
+	if err := clearAppliedOverride(ctx, tx, panelId); err != nil {
+		return err
+	}
+
+	if err := Client.PanelSupportHours.DeleteByPanelIdWithTx(ctx, tx, panelId); err != nil {
+		return err
+	}
+
+	for _, window := range schedule.Hours {
+		if _, err := Client.PanelSupportHours.UpsertWithTx(ctx, tx, database.PanelSupportHours{
+			PanelId:   panelId,
+			DayOfWeek: window.DayOfWeek,
+			StartTime: window.StartTime,
+			EndTime:   window.EndTime,
+			Enabled:   window.Enabled,
+			Timezone:  schedule.Timezone,
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
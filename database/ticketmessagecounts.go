@@ -0,0 +1,122 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// TicketMessageCountsTable stores the number of messages each user sent in a ticket. The counts are derived from
+// archived transcripts, which are indexed lazily, so ticket_message_counts_indexed records which tickets have been
+// counted, including those with no messages at all.
+type TicketMessageCountsTable struct {
+	*pgxpool.Pool
+}
+
+func newTicketMessageCountsTable(db *pgxpool.Pool) *TicketMessageCountsTable {
+	return &TicketMessageCountsTable{
+		db,
+	}
+}
+
+func (t TicketMessageCountsTable) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS ticket_message_counts_indexed(
+	"guild_id" int8 NOT NULL,
+	"ticket_id" int4 NOT NULL,
+	"indexed_at" timestamptz NOT NULL DEFAULT NOW(),
+	FOREIGN KEY("ticket_id", "guild_id") REFERENCES tickets("id", "guild_id") ON DELETE CASCADE,
+	PRIMARY KEY("guild_id", "ticket_id")
+);
+CREATE TABLE IF NOT EXISTS ticket_message_counts(
+	"guild_id" int8 NOT NULL,
+	"ticket_id" int4 NOT NULL,
+	"user_id" int8 NOT NULL,
+	"message_count" int4 NOT NULL,
+	FOREIGN KEY("guild_id", "ticket_id") REFERENCES ticket_message_counts_indexed("guild_id", "ticket_id") ON DELETE CASCADE,
+	PRIMARY KEY("guild_id", "ticket_id", "user_id")
+);
+`
+}
+
+// GetUnindexed returns tickets closed in the range which have a transcript, but whose messages have not been counted
+func (t *TicketMessageCountsTable) GetUnindexed(ctx context.Context, guildId uint64, start, end time.Time, limit int) ([]int, error) {
+	query := `
+SELECT tickets."id"
+FROM tickets
+LEFT OUTER JOIN ticket_message_counts_indexed
+	ON ticket_message_counts_indexed."guild_id" = tickets."guild_id" AND ticket_message_counts_indexed."ticket_id" = tickets."id"
+WHERE tickets."guild_id" = $1
+	AND tickets."close_time" >= $2
+	AND tickets."close_time" < $3
+	AND tickets."has_transcript" = true
+	AND ticket_message_counts_indexed."ticket_id" IS NULL
+ORDER BY tickets."close_time" DESC
+LIMIT $4;`
+
+	rows, err := t.Query(ctx, query, guildId, start, end, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ticketIds []int
+	for rows.Next() {
+		var ticketId int
+		if err := rows.Scan(&ticketId); err != nil {
+			return nil, err
+		}
+
+		ticketIds = append(ticketIds, ticketId)
+	}
+
+	return ticketIds, rows.Err()
+}
+
+// GetCoverage returns how many of the tickets closed in the range with a transcript have had their messages counted
+func (t *TicketMessageCountsTable) GetCoverage(ctx context.Context, guildId uint64, start, end time.Time) (indexed, total int, err error) {
+	query := `
+SELECT COUNT(ticket_message_counts_indexed."ticket_id"), COUNT(*)
+FROM tickets
+LEFT OUTER JOIN ticket_message_counts_indexed
+	ON ticket_message_counts_indexed."guild_id" = tickets."guild_id" AND ticket_message_counts_indexed."ticket_id" = tickets."id"
+WHERE tickets."guild_id" = $1
+	AND tickets."close_time" >= $2
+	AND tickets."close_time" < $3
+	AND (tickets."has_transcript" = true OR ticket_message_counts_indexed."ticket_id" IS NOT NULL);`
+
+	err = t.QueryRow(ctx, query, guildId, start, end).Scan(&indexed, &total)
+	return
+}
+
+func (t *TicketMessageCountsTable) Set(ctx context.Context, guildId uint64, ticketId int, counts map[uint64]int) error {
+	tx, err := t.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(ctx)
+
+	query := `
+INSERT INTO ticket_message_counts_indexed("guild_id", "ticket_id")
+VALUES($1, $2)
+ON CONFLICT("guild_id", "ticket_id") DO UPDATE SET "indexed_at" = NOW();`
+
+	if _, err := tx.Exec(ctx, query, guildId, ticketId); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM ticket_message_counts WHERE "guild_id" = $1 AND "ticket_id" = $2;`, guildId, ticketId); err != nil {
+		return err
+	}
+
+	for userId, count := range counts {
+		query := `INSERT INTO ticket_message_counts("guild_id", "ticket_id", "user_id", "message_count") VALUES($1, $2, $3, $4);`
+		if _, err := tx.Exec(ctx, query, guildId, ticketId, userId, count); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,58 @@
+package database
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// TranscriptRedactionPolicy controls which common patterns are redacted automatically from every transcript in a guild
+type TranscriptRedactionPolicy struct {
+	Emails      bool `json:"emails"`
+	CardNumbers bool `json:"card_numbers"`
+	Tokens      bool `json:"tokens"`
+}
+
+type TranscriptRedactionPoliciesTable struct {
+	*pgxpool.Pool
+}
+
+func newTranscriptRedactionPoliciesTable(db *pgxpool.Pool) *TranscriptRedactionPoliciesTable {
+	return &TranscriptRedactionPoliciesTable{
+		db,
+	}
+}
+
+func (t TranscriptRedactionPoliciesTable) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS transcript_redaction_policies(
+	"guild_id" int8 NOT NULL,
+	"emails" bool NOT NULL DEFAULT 'f',
+	"card_numbers" bool NOT NULL DEFAULT 'f',
+	"tokens" bool NOT NULL DEFAULT 'f',
+	PRIMARY KEY("guild_id")
+);
+`
+}
+
+func (t *TranscriptRedactionPoliciesTable) Get(ctx context.Context, guildId uint64) (TranscriptRedactionPolicy, error) {
+	query := `SELECT "emails", "card_numbers", "tokens" FROM transcript_redaction_policies WHERE "guild_id" = $1;`
+
+	var policy TranscriptRedactionPolicy
+	if err := t.QueryRow(ctx, query, guildId).Scan(&policy.Emails, &policy.CardNumbers, &policy.Tokens); err != nil && err != pgx.ErrNoRows {
+		return TranscriptRedactionPolicy{}, err
+	}
+
+	return policy, nil
+}
+
+func (t *TranscriptRedactionPoliciesTable) Set(ctx context.Context, guildId uint64, policy TranscriptRedactionPolicy) error {
+	query := `
+INSERT INTO transcript_redaction_policies("guild_id", "emails", "card_numbers", "tokens")
+VALUES($1, $2, $3, $4)
+ON CONFLICT("guild_id") DO UPDATE SET "emails" = $2, "card_numbers" = $3, "tokens" = $4;`
+
+	_, err := t.Exec(ctx, query, guildId, policy.Emails, policy.CardNumbers, policy.Tokens)
+	return err
+}
@@ -0,0 +1,145 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// TranscriptRedaction is an overlay applied on top of an archived transcript. If Pattern is nil, the whole message
+// identified by MessageId is redacted. Otherwise, spans matching Pattern are redacted, either in the message identified
+// by MessageId, or in every message of the transcript if MessageId is nil.
+type TranscriptRedaction struct {
+	Id         int       `json:"id"`
+	GuildId    uint64    `json:"guild_id,string"`
+	TicketId   int       `json:"ticket_id"`
+	MessageId  *uint64   `json:"message_id,string,omitempty"`
+	Pattern    *string   `json:"pattern,omitempty"`
+	Reason     string    `json:"reason"`
+	RedactedBy uint64    `json:"redacted_by,string"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type TranscriptRedactionsTable struct {
+	*pgxpool.Pool
+}
+
+func newTranscriptRedactionsTable(db *pgxpool.Pool) *TranscriptRedactionsTable {
+	return &TranscriptRedactionsTable{
+		db,
+	}
+}
+
+func (t TranscriptRedactionsTable) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS transcript_redactions(
+	"id" SERIAL PRIMARY KEY,
+	"guild_id" int8 NOT NULL,
+	"ticket_id" int4 NOT NULL,
+	"message_id" int8 DEFAULT NULL,
+	"pattern" varchar(256) DEFAULT NULL,
+	"reason" varchar(512) NOT NULL,
+	"redacted_by" int8 NOT NULL,
+	"created_at" timestamptz NOT NULL DEFAULT NOW(),
+	FOREIGN KEY("ticket_id", "guild_id") REFERENCES tickets("id", "guild_id") ON DELETE CASCADE,
+	CHECK("message_id" IS NOT NULL OR "pattern" IS NOT NULL)
+);
+CREATE INDEX IF NOT EXISTS transcript_redactions_guild_ticket_idx ON transcript_redactions("guild_id", "ticket_id");
+`
+}
+
+func (t *TranscriptRedactionsTable) GetByTicket(ctx context.Context, guildId uint64, ticketId int) ([]TranscriptRedaction, error) {
+	query := `
+SELECT "id", "guild_id", "ticket_id", "message_id", "pattern", "reason", "redacted_by", "created_at"
+FROM transcript_redactions
+WHERE "guild_id" = $1 AND "ticket_id" = $2
+ORDER BY "id" ASC;`
+
+	rows, err := t.Query(ctx, query, guildId, ticketId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	redactions := make([]TranscriptRedaction, 0)
+	for rows.Next() {
+		var redaction TranscriptRedaction
+		if err := rows.Scan(
+			&redaction.Id,
+			&redaction.GuildId,
+			&redaction.TicketId,
+			&redaction.MessageId,
+			&redaction.Pattern,
+			&redaction.Reason,
+			&redaction.RedactedBy,
+			&redaction.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		redactions = append(redactions, redaction)
+	}
+
+	return redactions, rows.Err()
+}
+
+func (t *TranscriptRedactionsTable) Get(ctx context.Context, guildId uint64, ticketId, id int) (TranscriptRedaction, bool, error) {
+	query := `
+SELECT "id", "guild_id", "ticket_id", "message_id", "pattern", "reason", "redacted_by", "created_at"
+FROM transcript_redactions
+WHERE "guild_id" = $1 AND "ticket_id" = $2 AND "id" = $3;`
+
+	var redaction TranscriptRedaction
+	if err := t.QueryRow(ctx, query, guildId, ticketId, id).Scan(
+		&redaction.Id,
+		&redaction.GuildId,
+		&redaction.TicketId,
+		&redaction.MessageId,
+		&redaction.Pattern,
+		&redaction.Reason,
+		&redaction.RedactedBy,
+		&redaction.CreatedAt,
+	); err != nil {
+		if err == pgx.ErrNoRows {
+			return TranscriptRedaction{}, false, nil
+		}
+
+		return TranscriptRedaction{}, false, err
+	}
+
+	return redaction, true, nil
+}
+
+func (t *TranscriptRedactionsTable) Create(ctx context.Context, redaction TranscriptRedaction) (TranscriptRedaction, error) {
+	query := `
+INSERT INTO transcript_redactions("guild_id", "ticket_id", "message_id", "pattern", "reason", "redacted_by")
+VALUES($1, $2, $3, $4, $5, $6)
+RETURNING "id", "created_at";`
+
+	if err := t.QueryRow(ctx, query,
+		redaction.GuildId,
+		redaction.TicketId,
+		redaction.MessageId,
+		redaction.Pattern,
+		redaction.Reason,
+		redaction.RedactedBy,
+	).Scan(&redaction.Id, &redaction.CreatedAt); err != nil {
+		return TranscriptRedaction{}, err
+	}
+
+	return redaction, nil
+}
+
+func (t *TranscriptRedactionsTable) Delete(ctx context.Context, guildId uint64, ticketId, id int) error {
+	query := `DELETE FROM transcript_redactions WHERE "guild_id" = $1 AND "ticket_id" = $2 AND "id" = $3;`
+	_, err := t.Exec(ctx, query, guildId, ticketId, id)
+	return err
+}
+
+func (t *TranscriptRedactionsTable) DeleteByTicket(ctx context.Context, guildId uint64, ticketId int) error {
+	query := `DELETE FROM transcript_redactions WHERE "guild_id" = $1 AND "ticket_id" = $2;`
+	_, err := t.Exec(ctx, query, guildId, ticketId)
+	return err
+}
@@ -0,0 +1,233 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+// TranscriptRetentionPolicy controls how long transcripts are kept after a ticket is closed. A nil RetentionDays
+// means transcripts are kept forever, unless the ticket was opened from a panel with its own retention period.
+type TranscriptRetentionPolicy struct {
+	RetentionDays      *int        `json:"retention_days"`
+	PanelRetentionDays map[int]int `json:"panel_retention_days"`
+}
+
+type ExpiredTranscript struct {
+	TicketId  int       `json:"ticket_id"`
+	PanelId   *int      `json:"panel_id"`
+	CloseTime time.Time `json:"close_time"`
+}
+
+type TranscriptRetentionTable struct {
+	*pgxpool.Pool
+}
+
+func newTranscriptRetentionTable(db *pgxpool.Pool) *TranscriptRetentionTable {
+	return &TranscriptRetentionTable{
+		db,
+	}
+}
+
+func (t TranscriptRetentionTable) Schema() string {
+	return `
+CREATE TABLE IF NOT EXISTS transcript_retention_policies(
+	"guild_id" int8 NOT NULL,
+	"retention_days" int4 NOT NULL,
+	PRIMARY KEY("guild_id")
+);
+CREATE TABLE IF NOT EXISTS transcript_panel_retention_policies(
+	"panel_id" int4 NOT NULL,
+	"guild_id" int8 NOT NULL,
+	"retention_days" int4 NOT NULL,
+	FOREIGN KEY("panel_id") REFERENCES panels("panel_id") ON DELETE CASCADE,
+	PRIMARY KEY("panel_id")
+);
+CREATE INDEX IF NOT EXISTS transcript_panel_retention_policies_guild_id ON transcript_panel_retention_policies("guild_id");
+CREATE TABLE IF NOT EXISTS transcript_retention_failures(
+	"guild_id" int8 NOT NULL,
+	"ticket_id" int4 NOT NULL,
+	"attempts" int4 NOT NULL,
+	"last_attempt" timestamptz NOT NULL,
+	FOREIGN KEY("guild_id", "ticket_id") REFERENCES tickets("guild_id", "id") ON DELETE CASCADE,
+	PRIMARY KEY("guild_id", "ticket_id")
+);
+`
+}
+
+func (t *TranscriptRetentionTable) Get(ctx context.Context, guildId uint64) (TranscriptRetentionPolicy, error) {
+	policy := TranscriptRetentionPolicy{
+		PanelRetentionDays: make(map[int]int),
+	}
+
+	var retentionDays int
+	if err := t.QueryRow(ctx, `SELECT "retention_days" FROM transcript_retention_policies WHERE "guild_id" = $1;`, guildId).Scan(&retentionDays); err != nil {
+		if err != pgx.ErrNoRows {
+			return TranscriptRetentionPolicy{}, err
+		}
+	} else {
+		policy.RetentionDays = &retentionDays
+	}
+
+	rows, err := t.Query(ctx, `SELECT "panel_id", "retention_days" FROM transcript_panel_retention_policies WHERE "guild_id" = $1;`, guildId)
+	if err != nil {
+		return TranscriptRetentionPolicy{}, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var panelId, days int
+		if err := rows.Scan(&panelId, &days); err != nil {
+			return TranscriptRetentionPolicy{}, err
+		}
+
+		policy.PanelRetentionDays[panelId] = days
+	}
+
+	return policy, rows.Err()
+}
+
+func (t *TranscriptRetentionTable) Set(ctx context.Context, guildId uint64, policy TranscriptRetentionPolicy) error {
+	tx, err := t.Begin(ctx)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback(ctx)
+
+	if policy.RetentionDays == nil {
+		if _, err := tx.Exec(ctx, `DELETE FROM transcript_retention_policies WHERE "guild_id" = $1;`, guildId); err != nil {
+			return err
+		}
+	} else {
+		query := `
+INSERT INTO transcript_retention_policies("guild_id", "retention_days")
+VALUES($1, $2)
+ON CONFLICT("guild_id") DO UPDATE SET "retention_days" = $2;`
+
+		if _, err := tx.Exec(ctx, query, guildId, *policy.RetentionDays); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM transcript_panel_retention_policies WHERE "guild_id" = $1;`, guildId); err != nil {
+		return err
+	}
+
+	for panelId, days := range policy.PanelRetentionDays {
+		query := `INSERT INTO transcript_panel_retention_policies("panel_id", "guild_id", "retention_days") VALUES($1, $2, $3);`
+		if _, err := tx.Exec(ctx, query, panelId, guildId, days); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetGuilds returns the IDs of all guilds with a guild-wide or panel retention period configured
+func (t *TranscriptRetentionTable) GetGuilds(ctx context.Context) ([]uint64, error) {
+	query := `
+SELECT "guild_id" FROM transcript_retention_policies
+UNION
+SELECT "guild_id" FROM transcript_panel_retention_policies;`
+
+	rows, err := t.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var guildIds []uint64
+	for rows.Next() {
+		var guildId uint64
+		if err := rows.Scan(&guildId); err != nil {
+			return nil, err
+		}
+
+		guildIds = append(guildIds, guildId)
+	}
+
+	return guildIds, rows.Err()
+}
+
+const expiredTranscriptsFilter = `
+FROM tickets
+LEFT JOIN transcript_panel_retention_policies
+	ON transcript_panel_retention_policies."panel_id" = tickets."panel_id"
+LEFT JOIN transcript_retention_policies
+	ON transcript_retention_policies."guild_id" = tickets."guild_id"
+WHERE tickets."guild_id" = $1
+	AND tickets."open" = false
+	AND tickets."has_transcript" = true
+	AND COALESCE(tickets."close_time", tickets."open_time") < NOW() - make_interval(days => COALESCE(transcript_panel_retention_policies."retention_days", transcript_retention_policies."retention_days"))`
+
+// awaitingRetryCondition matches tickets whose transcripts failed to be deleted too recently to be retried yet
+const awaitingRetryCondition = `EXISTS (
+		SELECT 1 FROM transcript_retention_failures
+		WHERE transcript_retention_failures."guild_id" = tickets."guild_id"
+			AND transcript_retention_failures."ticket_id" = tickets."id"
+			AND transcript_retention_failures."last_attempt" > NOW() - make_interval(hours => LEAST(POWER(2, transcript_retention_failures."attempts")::int, 168))
+	)`
+
+// GetExpired returns the oldest closed tickets whose transcripts have outlived the applicable retention period.
+// Tickets whose transcripts failed to be deleted are retried after a delay which doubles with each failure, up to a
+// week, and only once tickets which have not failed have been returned, so that they can't fill every batch.
+func (t *TranscriptRetentionTable) GetExpired(ctx context.Context, guildId uint64, limit int) ([]ExpiredTranscript, error) {
+	query := `
+SELECT tickets."id", tickets."panel_id", COALESCE(tickets."close_time", tickets."open_time")` + expiredTranscriptsFilter + `
+	AND NOT ` + awaitingRetryCondition + `
+ORDER BY (
+	SELECT COALESCE(MAX(transcript_retention_failures."attempts"), 0) FROM transcript_retention_failures
+	WHERE transcript_retention_failures."guild_id" = tickets."guild_id" AND transcript_retention_failures."ticket_id" = tickets."id"
+) ASC, COALESCE(tickets."close_time", tickets."open_time") ASC
+LIMIT $2;`
+
+	rows, err := t.Query(ctx, query, guildId, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	expired := make([]ExpiredTranscript, 0)
+	for rows.Next() {
+		var transcript ExpiredTranscript
+		if err := rows.Scan(&transcript.TicketId, &transcript.PanelId, &transcript.CloseTime); err != nil {
+			return nil, err
+		}
+
+		expired = append(expired, transcript)
+	}
+
+	return expired, rows.Err()
+}
+
+// GetExpiredCount returns the number of expired transcripts which GetExpired would return, given no limit, and
+// separately the number which are waiting to be retried after failing to be deleted
+func (t *TranscriptRetentionTable) GetExpiredCount(ctx context.Context, guildId uint64) (int, int, error) {
+	query := `
+SELECT
+	COUNT(*) FILTER (WHERE NOT ` + awaitingRetryCondition + `),
+	COUNT(*) FILTER (WHERE ` + awaitingRetryCondition + `)` + expiredTranscriptsFilter + `;`
+
+	var due, awaitingRetry int
+	err := t.QueryRow(ctx, query, guildId).Scan(&due, &awaitingRetry)
+	return due, awaitingRetry, err
+}
+
+// RecordFailure counts a failed attempt to delete the ticket's transcript, delaying the next attempt
+func (t *TranscriptRetentionTable) RecordFailure(ctx context.Context, guildId uint64, ticketId int) error {
+	query := `
+INSERT INTO transcript_retention_failures("guild_id", "ticket_id", "attempts", "last_attempt")
+VALUES($1, $2, 1, NOW())
+ON CONFLICT("guild_id", "ticket_id") DO UPDATE SET "attempts" = transcript_retention_failures."attempts" + 1, "last_attempt" = NOW();`
+
+	_, err := t.Exec(ctx, query, guildId, ticketId)
+	return err
+}
+
+func (t *TranscriptRetentionTable) ClearFailure(ctx context.Context, guildId uint64, ticketId int) error {
+	_, err := t.Exec(ctx, `DELETE FROM transcript_retention_failures WHERE "guild_id" = $1 AND "ticket_id" = $2;`, guildId, ticketId)
+	return err
+}
@@ -0,0 +1,44 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/TicketsBot-cloud/dashboard/log"
+	"github.com/TicketsBot-cloud/dashboard/redis"
+	"go.uber.org/zap"
+)
+
+// Run calls f every interval, on whichever API instance takes the job's lock first. f is given half of the interval
+// to complete, so that a slow run finishes before the next one starts. A job with an interval which is not positive
+// is never run.
+func Run(name string, interval time.Duration, f func(ctx context.Context)) {
+	if interval <= 0 {
+		log.Logger.Error("Background job has an invalid interval and will not run", zap.String("job", name), zap.Duration("interval", interval))
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		runOnce(name, interval, f)
+	}
+}
+
+func runOnce(name string, interval time.Duration, f func(ctx context.Context)) {
+	ctx, cancel := context.WithTimeout(context.Background(), interval/2)
+	defer cancel()
+
+	ok, err := redis.Client.TakeJobLock(ctx, name, interval/2)
+	if err != nil {
+		log.Logger.Error("Failed to take background job lock", zap.String("job", name), zap.Error(err))
+		return
+	}
+
+	if !ok {
+		return
+	}
+
+	f(ctx)
+}
@@ -0,0 +1,62 @@
+package panelhealth
+
+import (
+	"context"
+	"time"
+
+	api_panels "github.com/TicketsBot-cloud/dashboard/app/http/endpoints/api/panel"
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/dashboard/jobs"
+	"github.com/TicketsBot-cloud/dashboard/log"
+	"go.uber.org/zap"
+)
+
+// recheckAfter is how long the results of a check are kept before the guild is checked again
+const recheckAfter = 24 * time.Hour
+
+// batchSize is the number of guilds checked each run, to limit the requests made to Discord
+const batchSize = 50
+
+// RunSweeper periodically checks the panel messages of the guilds which were least recently checked. Only one API
+// instance performs each run.
+func RunSweeper(interval time.Duration) {
+	jobs.Run("panel-health", interval, sweep)
+}
+
+func sweep(ctx context.Context) {
+	guildIds, err := dbclient.Dashboard.PanelHealth.GetGuildsDueForCheck(ctx, time.Now().Add(-recheckAfter), batchSize)
+	if err != nil {
+		log.Logger.Error("Failed to fetch guilds due for a panel health check", zap.Error(err))
+		return
+	}
+
+	for _, guildId := range guildIds {
+		if ctx.Err() != nil {
+			log.Logger.Warn("Panel health sweep timed out, remaining guilds will be checked next run")
+			return
+		}
+
+		report, err := api_panels.CheckHealth(ctx, guildId)
+		if err != nil {
+			log.Logger.Error("Failed to check panel health", zap.Uint64("guild_id", guildId), zap.Error(err))
+
+			// Don't retry the guild every run, e.g. if the bot has been removed from it
+			if err := dbclient.Dashboard.PanelHealth.Touch(ctx, guildId, time.Now()); err != nil {
+				log.Logger.Error("Failed to mark panel health as checked", zap.Uint64("guild_id", guildId), zap.Error(err))
+			}
+
+			continue
+		}
+
+		var unhealthy int
+		for _, health := range report.Panels {
+			if health.Status != api_panels.PanelHealthOk {
+				unhealthy++
+			}
+		}
+
+		if unhealthy > 0 {
+			log.Logger.Info("Found unhealthy panels", zap.Uint64("guild_id", guildId), zap.Int("count", unhealthy))
+		}
+	}
+}
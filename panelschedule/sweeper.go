@@ -0,0 +1,46 @@
+package panelschedule
+
+import (
+	"context"
+	"time"
+
+	api_panels "github.com/TicketsBot-cloud/dashboard/app/http/endpoints/api/panel"
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/dashboard/jobs"
+	"github.com/TicketsBot-cloud/dashboard/log"
+	"go.uber.org/zap"
+)
+
+// batchSize is the number of schedules applied each run, to limit the requests made to Discord
+const batchSize = 100
+
+// RunSweeper periodically enables or disables panels whose schedules are due. Only one API instance performs each
+// run.
+func RunSweeper(interval time.Duration) {
+	jobs.Run("panel-schedules", interval, sweep)
+}
+
+func sweep(ctx context.Context) {
+	schedules, err := dbclient.Dashboard.PanelStateSchedules.GetDue(ctx, time.Now(), batchSize)
+	if err != nil {
+		log.Logger.Error("Failed to fetch due panel schedules", zap.Error(err))
+		return
+	}
+
+	for _, schedule := range schedules {
+		if ctx.Err() != nil {
+			log.Logger.Warn("Panel schedule sweep timed out, remaining schedules will be applied next run")
+			return
+		}
+
+		if err := api_panels.ApplyPanelSchedule(ctx, schedule); err != nil {
+			log.Logger.Error(
+				"Failed to apply panel schedule",
+				zap.Uint64("guild_id", schedule.GuildId),
+				zap.Int("panel_id", schedule.PanelId),
+				zap.Int("schedule_id", schedule.Id),
+				zap.Error(err),
+			)
+		}
+	}
+}
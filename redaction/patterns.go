@@ -0,0 +1,79 @@
+package redaction
+
+import (
+	"errors"
+	"regexp"
+)
+
+const maxPatternLength = 256
+
+var (
+	emailPattern      = regexp.MustCompile(`[a-zA-Z0-9._%+\-]+@[a-zA-Z0-9\-]+(?:\.[a-zA-Z0-9\-]+)*\.[a-zA-Z]{2,}`)
+	cardNumberPattern = regexp.MustCompile(`\b(?:\d[ \-]?){12,18}\d\b`)
+
+	tokenPatterns = []*regexp.Regexp{
+		// Discord bot tokens
+		regexp.MustCompile(`[MNO][a-zA-Z\d_\-]{23,25}\.[a-zA-Z\d_\-]{6}\.[a-zA-Z\d_\-]{27,38}`),
+		// JSON web tokens
+		regexp.MustCompile(`eyJ[a-zA-Z\d_\-]+\.eyJ[a-zA-Z\d_\-]+\.[a-zA-Z\d_\-]+`),
+		// GitHub tokens
+		regexp.MustCompile(`gh[pousr]_[a-zA-Z\d]{36,}`),
+		// Stripe style secret keys
+		regexp.MustCompile(`(?:sk|rk)_(?:live|test)_[a-zA-Z\d]{16,}`),
+		// AWS access key IDs
+		regexp.MustCompile(`AKIA[A-Z\d]{16}`),
+	}
+)
+
+// CompilePattern compiles a user supplied redaction pattern, rejecting patterns which are too long, or which would
+// match the empty string, and hence insert the placeholder between every character.
+func CompilePattern(pattern string) (*regexp.Regexp, error) {
+	if len(pattern) == 0 {
+		return nil, errors.New("Pattern must not be empty")
+	}
+
+	if len(pattern) > maxPatternLength {
+		return nil, errors.New("Pattern must be 256 characters or less")
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, errors.New("Pattern is not a valid regular expression")
+	}
+
+	if re.MatchString("") {
+		return nil, errors.New("Pattern must not match empty text")
+	}
+
+	return re, nil
+}
+
+// isValidCardNumber performs the Luhn check on a candidate card number, ignoring spaces and dashes, to avoid
+// redacting other long numbers such as Discord IDs.
+func isValidCardNumber(s string) bool {
+	var digits []int
+	for _, c := range s {
+		if c >= '0' && c <= '9' {
+			digits = append(digits, int(c-'0'))
+		}
+	}
+
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		digit := digits[i]
+		if (len(digits)-1-i)%2 == 1 {
+			digit *= 2
+			if digit > 9 {
+				digit -= 9
+			}
+		}
+
+		sum += digit
+	}
+
+	return sum%10 == 0
+}
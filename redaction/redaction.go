@@ -0,0 +1,261 @@
+package redaction
+
+import (
+	"regexp"
+	"strings"
+
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/gdl/objects/channel/embed"
+	"github.com/TicketsBot-cloud/gdl/objects/channel/message"
+	v2 "github.com/TicketsBot-cloud/logarchiver/pkg/model/v2"
+)
+
+const (
+	Placeholder    = "[REDACTED]"
+	RedactedNotice = "*This message has been redacted*"
+)
+
+type rule struct {
+	messageId *uint64
+	replace   func(string) string
+}
+
+// overlay is the compiled form of a guild's redaction policy and a ticket's redactions
+type overlay struct {
+	rules            []rule
+	redactedMessages map[uint64]struct{}
+}
+
+func newOverlay(policy dbclient.TranscriptRedactionPolicy, redactions []dbclient.TranscriptRedaction) overlay {
+	o := overlay{
+		rules:            policyRules(policy),
+		redactedMessages: make(map[uint64]struct{}),
+	}
+
+	for _, redaction := range redactions {
+		if redaction.Pattern == nil {
+			if redaction.MessageId != nil {
+				o.redactedMessages[*redaction.MessageId] = struct{}{}
+			}
+
+			continue
+		}
+
+		// Patterns are validated on creation, but skip rather than fail if one is no longer accepted
+		re, err := CompilePattern(*redaction.Pattern)
+		if err != nil {
+			continue
+		}
+
+		o.rules = append(o.rules, rule{
+			messageId: redaction.MessageId,
+			replace:   replaceAll(re),
+		})
+	}
+
+	return o
+}
+
+func (o overlay) isEmpty() bool {
+	return len(o.rules) == 0 && len(o.redactedMessages) == 0
+}
+
+func (o overlay) isMessageRedacted(messageId uint64) bool {
+	_, ok := o.redactedMessages[messageId]
+	return ok
+}
+
+// redactorFor returns a function applying every rule relevant to the message, or nil if there are none
+func (o overlay) redactorFor(messageId uint64) func(string) string {
+	var replacers []func(string) string
+	for _, r := range o.rules {
+		if r.messageId == nil || *r.messageId == messageId {
+			replacers = append(replacers, r.replace)
+		}
+	}
+
+	if len(replacers) == 0 {
+		return nil
+	}
+
+	return func(s string) string {
+		for _, replace := range replacers {
+			s = replace(s)
+		}
+
+		return s
+	}
+}
+
+// Apply applies the guild's automatic redaction policy and the ticket's manual redactions to the transcript. The
+// archived transcript itself is never modified, so redactions can be removed again.
+func Apply(transcript v2.Transcript, policy dbclient.TranscriptRedactionPolicy, redactions []dbclient.TranscriptRedaction) v2.Transcript {
+	o := newOverlay(policy, redactions)
+	if o.isEmpty() {
+		return transcript
+	}
+
+	messages := make([]v2.Message, len(transcript.Messages))
+	for i, msg := range transcript.Messages {
+		if o.isMessageRedacted(msg.Id) {
+			messages[i] = v2.Message{
+				Id:        msg.Id,
+				AuthorId:  msg.AuthorId,
+				Content:   RedactedNotice,
+				Timestamp: msg.Timestamp,
+			}
+
+			continue
+		}
+
+		if redact := o.redactorFor(msg.Id); redact != nil {
+			msg.Content = redact(msg.Content)
+			msg.Embeds = redactEmbeds(msg.Embeds, redact)
+		}
+
+		messages[i] = msg
+	}
+
+	transcript.Messages = messages
+	return transcript
+}
+
+// ApplyV1 is the equivalent of Apply for transcripts stored in the V1 format
+func ApplyV1(messages []message.Message, policy dbclient.TranscriptRedactionPolicy, redactions []dbclient.TranscriptRedaction) []message.Message {
+	o := newOverlay(policy, redactions)
+	if o.isEmpty() {
+		return messages
+	}
+
+	redacted := make([]message.Message, len(messages))
+	for i, msg := range messages {
+		if o.isMessageRedacted(msg.Id) {
+			redacted[i] = message.Message{
+				Id:               msg.Id,
+				ChannelId:        msg.ChannelId,
+				GuildId:          msg.GuildId,
+				Author:           msg.Author,
+				Content:          RedactedNotice,
+				Timestamp:        msg.Timestamp,
+				Type:             msg.Type,
+				MessageReference: msg.MessageReference,
+			}
+
+			continue
+		}
+
+		if redact := o.redactorFor(msg.Id); redact != nil {
+			msg.Content = redact(msg.Content)
+			msg.Embeds = redactEmbeds(msg.Embeds, redact)
+		}
+
+		redacted[i] = msg
+	}
+
+	return redacted
+}
+
+func policyRules(policy dbclient.TranscriptRedactionPolicy) []rule {
+	var rules []rule
+
+	if policy.Emails {
+		rules = append(rules, rule{replace: replaceAll(emailPattern)})
+	}
+
+	if policy.CardNumbers {
+		rules = append(rules, rule{replace: replaceCardNumbers})
+	}
+
+	if policy.Tokens {
+		for _, re := range tokenPatterns {
+			rules = append(rules, rule{replace: replaceAll(re)})
+		}
+	}
+
+	return rules
+}
+
+func replaceAll(re *regexp.Regexp) func(string) string {
+	return func(s string) string {
+		return re.ReplaceAllLiteralString(s, Placeholder)
+	}
+}
+
+func replaceCardNumbers(s string) string {
+	matches := cardNumberPattern.FindAllStringIndex(s, -1)
+	if len(matches) == 0 {
+		return s
+	}
+
+	var sb strings.Builder
+	last := 0
+	for _, match := range matches {
+		start, end := match[0], match[1]
+
+		// Don't redact snowflakes inside of mentions and custom emojis, e.g. <@123>, <#123> or <:name:123>
+		if start > 0 && strings.ContainsRune("@#&!:", rune(s[start-1])) {
+			continue
+		}
+
+		if !isValidCardNumber(s[start:end]) {
+			continue
+		}
+
+		sb.WriteString(s[last:start])
+		sb.WriteString(Placeholder)
+		last = end
+	}
+
+	sb.WriteString(s[last:])
+	return sb.String()
+}
+
+func redactEmbeds(embeds []embed.Embed, redact func(string) string) []embed.Embed {
+	if len(embeds) == 0 {
+		return embeds
+	}
+
+	redacted := make([]embed.Embed, len(embeds))
+	for i, e := range embeds {
+		redacted[i] = redactEmbed(e, redact)
+	}
+
+	return redacted
+}
+
+// redactEmbed returns a redacted copy of the embed. Pointer fields are copied rather than modified, as they may be
+// shared with the original transcript.
+func redactEmbed(e embed.Embed, redact func(string) string) embed.Embed {
+	e.Title = redact(e.Title)
+	e.Description = redact(e.Description)
+
+	if e.Footer != nil {
+		footer := *e.Footer
+		footer.Text = redact(footer.Text)
+		e.Footer = &footer
+	}
+
+	if e.Author != nil {
+		author := *e.Author
+		author.Name = redact(author.Name)
+		e.Author = &author
+	}
+
+	if len(e.Fields) > 0 {
+		fields := make([]*embed.EmbedField, len(e.Fields))
+		for i, field := range e.Fields {
+			if field == nil {
+				continue
+			}
+
+			copied := *field
+			copied.Name = redact(copied.Name)
+			copied.Value = redact(copied.Value)
+			fields[i] = &copied
+		}
+
+		e.Fields = fields
+	}
+
+	return e
+}
@@ -0,0 +1,66 @@
+package redaction
+
+import (
+	"testing"
+
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	v2 "github.com/TicketsBot-cloud/logarchiver/pkg/model/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func transcriptOf(contents ...string) v2.Transcript {
+	var messages []v2.Message
+	for i, content := range contents {
+		messages = append(messages, v2.Message{Id: uint64(i + 1), Content: content})
+	}
+
+	return v2.Transcript{Messages: messages}
+}
+
+func TestPolicyEmails(t *testing.T) {
+	transcript := transcriptOf("contact me at someone@example.com please")
+	redacted := Apply(transcript, dbclient.TranscriptRedactionPolicy{Emails: true}, nil)
+
+	assert.Equal(t, "contact me at [REDACTED] please", redacted.Messages[0].Content)
+	assert.Equal(t, "contact me at someone@example.com please", transcript.Messages[0].Content)
+}
+
+func TestPolicyCardNumbers(t *testing.T) {
+	transcript := transcriptOf(
+		"card 4242 4242 4242 4242 exp 12/30",
+		"not a card 1234567890123",
+		"<@508391840525975551> <#508391840525975551>",
+	)
+
+	redacted := Apply(transcript, dbclient.TranscriptRedactionPolicy{CardNumbers: true}, nil)
+
+	assert.Equal(t, "card [REDACTED] exp 12/30", redacted.Messages[0].Content)
+	assert.Equal(t, "not a card 1234567890123", redacted.Messages[1].Content)
+	assert.Equal(t, "<@508391840525975551> <#508391840525975551>", redacted.Messages[2].Content)
+}
+
+func TestManualRedactions(t *testing.T) {
+	pattern := `secret-\d+`
+	messageId := uint64(2)
+
+	transcript := transcriptOf("secret-1", "secret-2 and more", "secret-3")
+	redacted := Apply(transcript, dbclient.TranscriptRedactionPolicy{}, []dbclient.TranscriptRedaction{
+		{Pattern: &pattern},
+		{MessageId: &messageId},
+	})
+
+	assert.Equal(t, "[REDACTED]", redacted.Messages[0].Content)
+	assert.Equal(t, RedactedNotice, redacted.Messages[1].Content)
+	assert.Equal(t, "[REDACTED]", redacted.Messages[2].Content)
+}
+
+func TestCompilePattern(t *testing.T) {
+	_, err := CompilePattern("a*")
+	assert.Error(t, err)
+
+	_, err = CompilePattern("(")
+	assert.Error(t, err)
+
+	_, err = CompilePattern(`\d{4}`)
+	assert.NoError(t, err)
+}
@@ -0,0 +1,20 @@
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// TakeJobLock ensures that a background job is only run by a single API instance at a time. The lock is not released
+// explicitly; it expires after ttl, which should be shorter than the interval the job runs at.
+func (c *RedisClient) TakeJobLock(ctx context.Context, job string, ttl time.Duration) (bool, error) {
+	key := fmt.Sprintf("tickets:dashboard:joblock:%s", job)
+
+	res, err := c.SetNX(ctx, key, "1", ttl).Result()
+	if err != nil {
+		return false, err
+	}
+
+	return res, nil
+}
@@ -0,0 +1,112 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// Closed transcripts rarely change, but renders are bounded by a TTL so that the cache doesn't grow indefinitely
+const transcriptRenderTTL = 24 * time.Hour
+
+// Rendered transcripts are stored in a hash per ticket, so that all versions can be invalidated with a single DEL. The
+// field includes the renderer version and two cache generations: the guild's, which is bumped to invalidate every
+// render for a guild at once, e.g. when the redaction policy changes, and the ticket's, which is bumped whenever the
+// transcript's own renders are invalidated. The field is read before the transcript is fetched, and the render is
+// only stored if neither generation has changed since, so that an invalidation made during a render isn't undone.
+func transcriptRenderKey(guildId uint64, ticketId int) string {
+	return fmt.Sprintf("tickets:dashboard:transcriptrender:%d:%d", guildId, ticketId)
+}
+
+func transcriptRenderGenerationKey(guildId uint64) string {
+	return fmt.Sprintf("tickets:dashboard:transcriptrender:generation:%d", guildId)
+}
+
+func transcriptRenderTicketGenerationKey(guildId uint64, ticketId int) string {
+	return fmt.Sprintf("tickets:dashboard:transcriptrender:generation:%d:%d", guildId, ticketId)
+}
+
+// setRenderedTranscriptScript stores the render under ARGV[1] only if that is still the current field
+var setRenderedTranscriptScript = redis.NewScript(`
+local guildGeneration = redis.call("GET", KEYS[2]) or "0"
+local ticketGeneration = redis.call("GET", KEYS[3]) or "0"
+
+if ARGV[1] ~= "v" .. ARGV[2] .. ":g" .. guildGeneration .. ":t" .. ticketGeneration then
+	return 0
+end
+
+redis.call("DEL", KEYS[1])
+redis.call("HSET", KEYS[1], ARGV[1], ARGV[3])
+redis.call("EXPIRE", KEYS[1], ARGV[4])
+
+return 1
+`)
+
+func (c *RedisClient) transcriptRenderField(ctx context.Context, guildId uint64, ticketId, rendererVersion int) (string, error) {
+	generations, err := c.MGet(ctx, transcriptRenderGenerationKey(guildId), transcriptRenderTicketGenerationKey(guildId, ticketId)).Result()
+	if err != nil {
+		return "", err
+	}
+
+	for i, generation := range generations {
+		if generation == nil {
+			generations[i] = "0"
+		}
+	}
+
+	return fmt.Sprintf("v%d:g%s:t%s", rendererVersion, generations[0], generations[1]), nil
+}
+
+// GetRenderedTranscript returns the cached render, if there is one, along with a stamp which must be passed to
+// SetRenderedTranscript when a fresh render is stored
+func (c *RedisClient) GetRenderedTranscript(ctx context.Context, guildId uint64, ticketId, rendererVersion int) ([]byte, string, bool, error) {
+	field, err := c.transcriptRenderField(ctx, guildId, ticketId, rendererVersion)
+	if err != nil {
+		return nil, "", false, err
+	}
+
+	data, err := c.HGet(ctx, transcriptRenderKey(guildId, ticketId), field).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, field, false, nil
+		}
+
+		return nil, "", false, err
+	}
+
+	return data, field, true, nil
+}
+
+// SetRenderedTranscript replaces any renders from older versions or generations, unless the transcript's renders have
+// been invalidated since the stamp was returned by GetRenderedTranscript, in which case nothing is stored
+func (c *RedisClient) SetRenderedTranscript(ctx context.Context, guildId uint64, ticketId, rendererVersion int, stamp string, data []byte) error {
+	keys := []string{
+		transcriptRenderKey(guildId, ticketId),
+		transcriptRenderGenerationKey(guildId),
+		transcriptRenderTicketGenerationKey(guildId, ticketId),
+	}
+
+	return setRenderedTranscriptScript.Run(ctx, c, keys, stamp, rendererVersion, data, int(transcriptRenderTTL.Seconds())).Err()
+}
+
+// InvalidateRenderedTranscript removes the cached renders of a single transcript, e.g. after it is redacted or deleted
+func (c *RedisClient) InvalidateRenderedTranscript(ctx context.Context, guildId uint64, ticketId int) error {
+	generationKey := transcriptRenderTicketGenerationKey(guildId, ticketId)
+
+	// The generation only needs to outlive renders that were in progress when it was bumped
+	pipe := c.TxPipeline()
+	pipe.Incr(ctx, generationKey)
+	pipe.Expire(ctx, generationKey, transcriptRenderTTL)
+	pipe.Del(ctx, transcriptRenderKey(guildId, ticketId))
+
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// InvalidateRenderedTranscripts invalidates the cached renders of every transcript in a guild
+func (c *RedisClient) InvalidateRenderedTranscripts(ctx context.Context, guildId uint64) error {
+	return c.Incr(ctx, transcriptRenderGenerationKey(guildId)).Err()
+}
@@ -0,0 +1,103 @@
+package retention
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/TicketsBot-cloud/dashboard/app/http/audit"
+	"github.com/TicketsBot-cloud/dashboard/config"
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/dashboard/log"
+	"github.com/TicketsBot-cloud/dashboard/redis"
+	"github.com/TicketsBot-cloud/dashboard/utils"
+	"go.uber.org/zap"
+)
+
+const (
+	MinRetentionDays = 1
+	MaxRetentionDays = 3650
+
+	// Maximum number of transcripts deleted per guild in a single run, to avoid hammering the archiver. Any remaining
+	// transcripts are picked up by the next run.
+	batchSize = 100
+)
+
+type PurgeResult struct {
+	Deleted []int `json:"deleted"`
+	Failed  []int `json:"failed"`
+}
+
+// Purge deletes a batch of transcripts that have outlived the guild's retention policy from the archiver, and marks
+// the tickets as no longer having a transcript. An audit log entry is recorded for each run that deletes anything.
+func Purge(ctx context.Context, guildId uint64) (PurgeResult, error) {
+	expired, err := dbclient.Dashboard.TranscriptRetention.GetExpired(ctx, guildId, batchSize)
+	if err != nil {
+		return PurgeResult{}, err
+	}
+
+	result := PurgeResult{
+		Deleted: make([]int, 0),
+		Failed:  make([]int, 0),
+	}
+
+	for _, transcript := range expired {
+		if err := deleteTranscript(ctx, guildId, transcript.TicketId); err != nil {
+			log.Logger.Warn(
+				"Failed to delete expired transcript",
+				zap.Uint64("guild_id", guildId),
+				zap.Int("ticket_id", transcript.TicketId),
+				zap.Error(err),
+			)
+
+			if err := dbclient.Dashboard.TranscriptRetention.RecordFailure(ctx, guildId, transcript.TicketId); err != nil {
+				log.Logger.Error("Failed to record transcript deletion failure", zap.Uint64("guild_id", guildId), zap.Int("ticket_id", transcript.TicketId), zap.Error(err))
+			}
+
+			result.Failed = append(result.Failed, transcript.TicketId)
+			continue
+		}
+
+		if err := dbclient.Dashboard.TranscriptRetention.ClearFailure(ctx, guildId, transcript.TicketId); err != nil {
+			log.Logger.Error("Failed to clear transcript deletion failure", zap.Uint64("guild_id", guildId), zap.Int("ticket_id", transcript.TicketId), zap.Error(err))
+		}
+
+		result.Deleted = append(result.Deleted, transcript.TicketId)
+	}
+
+	if len(expired) > 0 {
+		policy, err := dbclient.Dashboard.TranscriptRetention.Get(ctx, guildId)
+		if err != nil {
+			return result, err
+		}
+
+		audit.Log(audit.LogEntry{
+			GuildId:      audit.Uint64Ptr(guildId),
+			UserId:       config.Conf.Bot.Id,
+			ActionType:   audit.ActionTranscriptRetentionPurge,
+			ResourceType: audit.ResourceTranscriptRetention,
+			ResourceId:   audit.StringPtr(strconv.FormatUint(guildId, 10)),
+			OldData:      policy,
+			Metadata:     result,
+		})
+	}
+
+	return result, nil
+}
+
+func deleteTranscript(ctx context.Context, guildId uint64, ticketId int) error {
+	if err := utils.ArchiverRetriever.DeleteTicket(ctx, guildId, ticketId); err != nil {
+		return err
+	}
+
+	if err := dbclient.Client.Tickets.SetHasTranscript(ctx, guildId, ticketId, false); err != nil {
+		return err
+	}
+
+	// The cached render expires by itself, so don't fail the purge if it can't be invalidated
+	if err := redis.Client.InvalidateRenderedTranscript(ctx, guildId, ticketId); err != nil {
+		log.Logger.Error("Failed to invalidate transcript render", zap.Uint64("guild_id", guildId), zap.Int("ticket_id", ticketId), zap.Error(err))
+	}
+
+	// Redactions may themselves contain the data being redacted, so should not outlive the transcript
+	return dbclient.Dashboard.TranscriptRedactions.DeleteByTicket(ctx, guildId, ticketId)
+}
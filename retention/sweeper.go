@@ -0,0 +1,47 @@
+package retention
+
+import (
+	"context"
+	"time"
+
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/dashboard/jobs"
+	"github.com/TicketsBot-cloud/dashboard/log"
+	"go.uber.org/zap"
+)
+
+// RunSweeper periodically purges expired transcripts for every guild with a retention policy. Only one API instance
+// performs each run.
+func RunSweeper(interval time.Duration) {
+	jobs.Run("transcript-retention", interval, sweep)
+}
+
+func sweep(ctx context.Context) {
+	guildIds, err := dbclient.Dashboard.TranscriptRetention.GetGuilds(ctx)
+	if err != nil {
+		log.Logger.Error("Failed to fetch guilds with transcript retention policies", zap.Error(err))
+		return
+	}
+
+	for _, guildId := range guildIds {
+		if ctx.Err() != nil {
+			log.Logger.Warn("Transcript retention sweep timed out, remaining guilds will be swept next run")
+			return
+		}
+
+		result, err := Purge(ctx, guildId)
+		if err != nil {
+			log.Logger.Error("Failed to purge expired transcripts", zap.Uint64("guild_id", guildId), zap.Error(err))
+			continue
+		}
+
+		if len(result.Deleted) > 0 || len(result.Failed) > 0 {
+			log.Logger.Info(
+				"Purged expired transcripts",
+				zap.Uint64("guild_id", guildId),
+				zap.Int("deleted", len(result.Deleted)),
+				zap.Int("failed", len(result.Failed)),
+			)
+		}
+	}
+}
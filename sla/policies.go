@@ -0,0 +1,63 @@
+package sla
+
+import (
+	"context"
+	"time"
+
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/dashboard/supporthours"
+)
+
+type panelPolicy struct {
+	policy   dbclient.SlaPolicy
+	schedule supporthours.Schedule
+}
+
+// GuildPolicies holds the SLA policies for a guild's panels, along with the schedules they are measured against
+type GuildPolicies map[int]panelPolicy
+
+// LoadPolicies fetches the SLA policies for every panel in the guild, and the support hours of those that need them
+func LoadPolicies(ctx context.Context, guildId uint64) (GuildPolicies, error) {
+	policies, err := dbclient.Dashboard.SlaPolicies.GetByGuild(ctx, guildId)
+	if err != nil {
+		return nil, err
+	}
+
+	guildPolicies := make(GuildPolicies, len(policies))
+	for panelId, policy := range policies {
+		schedule := supporthours.AlwaysOpen()
+		if policy.SupportHoursOnly {
+			schedule, err = supporthours.Load(ctx, panelId)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		guildPolicies[panelId] = panelPolicy{
+			policy:   policy,
+			schedule: schedule,
+		}
+	}
+
+	return guildPolicies, nil
+}
+
+func (g GuildPolicies) PanelIds() []int {
+	panelIds := make([]int, 0, len(g))
+	for panelId := range g {
+		panelIds = append(panelIds, panelId)
+	}
+
+	return panelIds
+}
+
+// Evaluate returns the ticket's SLA status, or nil if the panel it was opened from does not have a policy
+func (g GuildPolicies) Evaluate(ticket dbclient.SlaTicket, now time.Time) *Status {
+	policy, ok := g[ticket.PanelId]
+	if !ok {
+		return nil
+	}
+
+	status := Evaluate(policy.policy, policy.schedule, ticket, now)
+	return &status
+}
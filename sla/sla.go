@@ -0,0 +1,112 @@
+package sla
+
+import (
+	"time"
+
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/dashboard/supporthours"
+)
+
+type State string
+
+const (
+	// StateMet means the target was reached in time
+	StateMet State = "met"
+	// StateOk means the target has not been reached yet, but there is plenty of time left
+	StateOk       State = "ok"
+	StateAtRisk   State = "at_risk"
+	StateBreached State = "breached"
+)
+
+// AtRiskRatio is the proportion of a target that must have elapsed before a ticket is considered at risk
+const AtRiskRatio = 0.75
+
+// severity orders the states, so that a ticket's overall state is that of its worst target
+var severity = map[State]int{
+	StateMet:      0,
+	StateOk:       1,
+	StateAtRisk:   2,
+	StateBreached: 3,
+}
+
+type TargetStatus struct {
+	State          State `json:"state"`
+	TargetSeconds  int64 `json:"target_seconds"`
+	ElapsedSeconds int64 `json:"elapsed_seconds"`
+	// DueAt is nil if the schedule does not have enough support hours for the target to ever be reached
+	DueAt *time.Time `json:"due_at"`
+}
+
+type Status struct {
+	State         State         `json:"state"`
+	FirstResponse *TargetStatus `json:"first_response"`
+	Resolution    *TargetStatus `json:"resolution"`
+}
+
+// Evaluate works out a ticket's SLA status at now. schedule should be the panel's support hours if the policy only
+// counts support hours, and supporthours.AlwaysOpen() otherwise. A ticket closed without a staff response stops the
+// first response clock when it is closed.
+func Evaluate(policy dbclient.SlaPolicy, schedule supporthours.Schedule, ticket dbclient.SlaTicket, now time.Time) Status {
+	var status Status
+
+	if policy.FirstResponseMinutes != nil {
+		target := time.Duration(*policy.FirstResponseMinutes) * time.Minute
+		status.FirstResponse = evaluateTarget(schedule, target, ticket.OpenTime, firstNonNil(ticket.FirstResponseAt, ticket.CloseTime), now)
+	}
+
+	if policy.ResolutionHours != nil {
+		target := time.Duration(*policy.ResolutionHours) * time.Hour
+		status.Resolution = evaluateTarget(schedule, target, ticket.OpenTime, ticket.CloseTime, now)
+	}
+
+	status.State = StateMet
+	for _, target := range []*TargetStatus{status.FirstResponse, status.Resolution} {
+		if target != nil && severity[target.State] > severity[status.State] {
+			status.State = target.State
+		}
+	}
+
+	return status
+}
+
+// evaluateTarget measures the time from start until completedAt, or now if the target has not been completed yet
+func evaluateTarget(schedule supporthours.Schedule, target time.Duration, start time.Time, completedAt *time.Time, now time.Time) *TargetStatus {
+	end := now
+	if completedAt != nil {
+		end = *completedAt
+	}
+
+	elapsed := schedule.OpenDuration(start, end)
+
+	status := &TargetStatus{
+		TargetSeconds:  int64(target / time.Second),
+		ElapsedSeconds: int64(elapsed / time.Second),
+	}
+
+	if due, ok := schedule.AddOpenDuration(start, target); ok {
+		status.DueAt = &due
+	}
+
+	switch {
+	case elapsed > target:
+		status.State = StateBreached
+	case completedAt != nil:
+		status.State = StateMet
+	case float64(elapsed) >= float64(target)*AtRiskRatio:
+		status.State = StateAtRisk
+	default:
+		status.State = StateOk
+	}
+
+	return status
+}
+
+func firstNonNil(times ...*time.Time) *time.Time {
+	for _, t := range times {
+		if t != nil {
+			return t
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,57 @@
+package sla
+
+import (
+	"testing"
+	"time"
+
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/dashboard/supporthours"
+	"github.com/TicketsBot-cloud/dashboard/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+var opened = time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC)
+
+func policy() dbclient.SlaPolicy {
+	return dbclient.SlaPolicy{
+		FirstResponseMinutes: utils.Ptr(60),
+		ResolutionHours:      utils.Ptr(24),
+	}
+}
+
+func TestEvaluateOpenTicket(t *testing.T) {
+	ticket := dbclient.SlaTicket{OpenTime: opened}
+
+	status := Evaluate(policy(), supporthours.AlwaysOpen(), ticket, opened.Add(30*time.Minute))
+	assert.Equal(t, StateOk, status.State)
+
+	status = Evaluate(policy(), supporthours.AlwaysOpen(), ticket, opened.Add(50*time.Minute))
+	assert.Equal(t, StateAtRisk, status.State)
+	assert.Equal(t, StateAtRisk, status.FirstResponse.State)
+	assert.Equal(t, StateOk, status.Resolution.State)
+
+	status = Evaluate(policy(), supporthours.AlwaysOpen(), ticket, opened.Add(61*time.Minute))
+	assert.Equal(t, StateBreached, status.State)
+	if assert.NotNil(t, status.FirstResponse.DueAt) {
+		assert.True(t, status.FirstResponse.DueAt.Equal(opened.Add(time.Hour)))
+	}
+}
+
+func TestEvaluateRespondedTicket(t *testing.T) {
+	ticket := dbclient.SlaTicket{
+		OpenTime:        opened,
+		FirstResponseAt: utils.Ptr(opened.Add(10 * time.Minute)),
+	}
+
+	// The first response target stays met, however long the ticket is then left open
+	status := Evaluate(policy(), supporthours.AlwaysOpen(), ticket, opened.Add(20*time.Hour))
+	assert.Equal(t, StateMet, status.FirstResponse.State)
+	assert.Equal(t, StateAtRisk, status.State)
+}
+
+func TestEvaluateNoTargets(t *testing.T) {
+	status := Evaluate(dbclient.SlaPolicy{}, supporthours.AlwaysOpen(), dbclient.SlaTicket{OpenTime: opened}, opened.Add(time.Hour))
+	assert.Equal(t, StateMet, status.State)
+	assert.Nil(t, status.FirstResponse)
+	assert.Nil(t, status.Resolution)
+}
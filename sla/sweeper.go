@@ -0,0 +1,89 @@
+package sla
+
+import (
+	"context"
+	"time"
+
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/dashboard/jobs"
+	"github.com/TicketsBot-cloud/dashboard/log"
+	"go.uber.org/zap"
+)
+
+// closedLookback is how long after a ticket is closed it is still checked for breaches, to catch tickets which were
+// closed late between two runs
+const closedLookback = 24 * time.Hour
+
+// RunSweeper periodically records SLA breaches for every guild with an SLA policy. Only one API instance performs
+// each run.
+func RunSweeper(interval time.Duration) {
+	jobs.Run("sla-breaches", interval, sweep)
+}
+
+func sweep(ctx context.Context) {
+	guildIds, err := dbclient.Dashboard.SlaPolicies.GetGuilds(ctx)
+	if err != nil {
+		log.Logger.Error("Failed to fetch guilds with SLA policies", zap.Error(err))
+		return
+	}
+
+	for _, guildId := range guildIds {
+		if ctx.Err() != nil {
+			log.Logger.Warn("SLA breach sweep timed out, remaining guilds will be checked next run")
+			return
+		}
+
+		if err := RecordBreaches(ctx, guildId, time.Now()); err != nil {
+			log.Logger.Error("Failed to record SLA breaches", zap.Uint64("guild_id", guildId), zap.Error(err))
+		}
+	}
+}
+
+// RecordBreaches evaluates the guild's open and recently closed tickets, and records any breached targets. Breaches
+// which have already been recorded are left untouched.
+func RecordBreaches(ctx context.Context, guildId uint64, now time.Time) error {
+	policies, err := LoadPolicies(ctx, guildId)
+	if err != nil {
+		return err
+	}
+
+	if len(policies) == 0 {
+		return nil
+	}
+
+	tickets, err := dbclient.Dashboard.Analytics.GetSlaTickets(ctx, guildId, policies.PanelIds(), now.Add(-closedLookback))
+	if err != nil {
+		return err
+	}
+
+	for _, ticket := range tickets {
+		status := policies.Evaluate(ticket, now)
+		if status == nil {
+			continue
+		}
+
+		targets := map[dbclient.SlaTarget]*TargetStatus{
+			dbclient.SlaTargetFirstResponse: status.FirstResponse,
+			dbclient.SlaTargetResolution:    status.Resolution,
+		}
+
+		for target, targetStatus := range targets {
+			if targetStatus == nil || targetStatus.State != StateBreached || targetStatus.DueAt == nil {
+				continue
+			}
+
+			if err := dbclient.Dashboard.SlaBreaches.Record(ctx, dbclient.SlaBreach{
+				GuildId:       guildId,
+				TicketId:      ticket.TicketId,
+				PanelId:       ticket.PanelId,
+				Target:        target,
+				TargetMinutes: int(targetStatus.TargetSeconds / 60),
+				BreachedAt:    *targetStatus.DueAt,
+			}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
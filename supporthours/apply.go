@@ -0,0 +1,89 @@
+package supporthours
+
+import (
+	"time"
+
+	"github.com/TicketsBot-cloud/database"
+)
+
+// OverrideHours returns the hours which the bot should see while the override is in effect on the given day of the
+// week: the weekly hours, with that day replaced by the override's hours, or disabled if the override closes it. A
+// panel without weekly hours is always open, so it is given hours which are open all day on every other day.
+func OverrideHours(weekly []database.PanelSupportHours, day time.Weekday, override Override) []database.PanelSupportHours {
+	hours := make([]database.PanelSupportHours, 0, 7)
+
+	timezone := "UTC"
+	if len(weekly) == 0 {
+		for d := time.Sunday; d <= time.Saturday; d++ {
+			hours = append(hours, database.PanelSupportHours{
+				DayOfWeek: int(d),
+				StartTime: clockTime(0),
+				EndTime:   clockTime(endOfDay),
+				Enabled:   true,
+				Timezone:  timezone,
+			})
+		}
+	} else {
+		timezone = weekly[0].Timezone
+		hours = append(hours, weekly...)
+	}
+
+	replaced := database.PanelSupportHours{
+		DayOfWeek: int(day),
+		StartTime: clockTime(0),
+		EndTime:   clockTime(endOfDay),
+		Enabled:   false,
+		Timezone:  timezone,
+	}
+
+	if !override.Closed {
+		replaced.StartTime = clockTime(override.Start)
+		replaced.EndTime = clockTime(min(override.End, endOfDay))
+		replaced.Enabled = true
+	}
+
+	for i, h := range hours {
+		if h.DayOfWeek == int(day) {
+			replaced.Id = h.Id
+			hours[i] = replaced
+			return hours
+		}
+	}
+
+	return append(hours, replaced)
+}
+
+// SameHours reports whether a and b configure the same hours, ignoring row IDs and order
+func SameHours(a, b []database.PanelSupportHours) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	type key struct {
+		day        int
+		start, end string
+		enabled    bool
+		timezone   string
+	}
+
+	keys := make(map[key]int)
+	for _, h := range a {
+		keys[key{h.DayOfWeek, h.StartTime.Format("15:04:05"), h.EndTime.Format("15:04:05"), h.Enabled, h.Timezone}]++
+	}
+
+	for _, h := range b {
+		k := key{h.DayOfWeek, h.StartTime.Format("15:04:05"), h.EndTime.Format("15:04:05"), h.Enabled, h.Timezone}
+		if keys[k] == 0 {
+			return false
+		}
+
+		keys[k]--
+	}
+
+	return true
+}
+
+// clockTime converts an offset from midnight to a time of day, in the form used for TIME columns
+func clockTime(offset time.Duration) time.Time {
+	return time.Date(0, 1, 1, 0, 0, int(offset/time.Second), 0, time.UTC)
+}
@@ -0,0 +1,84 @@
+package supporthours
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TicketsBot-cloud/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func weekdayHours(timezone string) []database.PanelSupportHours {
+	var hours []database.PanelSupportHours
+	for day := 1; day <= 5; day++ {
+		hours = append(hours, database.PanelSupportHours{
+			Id:        day,
+			DayOfWeek: day,
+			StartTime: clock("09:00:00"),
+			EndTime:   clock("17:00:00"),
+			Enabled:   true,
+			Timezone:  timezone,
+		})
+	}
+
+	return hours
+}
+
+func TestOverrideHoursClosed(t *testing.T) {
+	weekly := weekdayHours("Europe/London")
+
+	hours := OverrideHours(weekly, time.Monday, Override{Closed: true})
+	assert.Len(t, hours, 5)
+	assert.Equal(t, database.PanelSupportHours{
+		Id:        1,
+		DayOfWeek: 1,
+		StartTime: clock("00:00:00"),
+		EndTime:   clock("23:59:59"),
+		Enabled:   false,
+		Timezone:  "Europe/London",
+	}, hours[0])
+	assert.Equal(t, weekly[1:], hours[1:])
+
+	// The weekly hours are left untouched, so that they can be restored
+	assert.True(t, weekly[0].Enabled)
+}
+
+func TestOverrideHoursCustom(t *testing.T) {
+	hours := OverrideHours(weekdayHours("UTC"), time.Tuesday, Override{Start: 10 * time.Hour, End: 24 * time.Hour})
+	assert.Equal(t, clock("10:00:00"), hours[1].StartTime)
+	assert.Equal(t, clock("23:59:59"), hours[1].EndTime)
+	assert.True(t, hours[1].Enabled)
+}
+
+func TestOverrideHoursMissingDay(t *testing.T) {
+	hours := OverrideHours(weekdayHours("UTC"), time.Saturday, Override{Start: 9 * time.Hour, End: 12 * time.Hour})
+	assert.Len(t, hours, 6)
+	assert.Equal(t, int(time.Saturday), hours[5].DayOfWeek)
+	assert.Equal(t, clock("12:00:00"), hours[5].EndTime)
+	assert.True(t, hours[5].Enabled)
+}
+
+func TestOverrideHoursWithoutWeeklyHours(t *testing.T) {
+	hours := OverrideHours(nil, time.Sunday, Override{Closed: true})
+	assert.Len(t, hours, 7)
+
+	schedule := FromPanelHours(hours)
+	assert.False(t, schedule.IsOpen(time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC))) // Sunday
+	assert.True(t, schedule.IsOpen(time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)))
+	assert.True(t, schedule.IsOpen(time.Date(2025, 6, 7, 23, 59, 0, 0, time.UTC)))
+}
+
+func TestSameHours(t *testing.T) {
+	a := weekdayHours("UTC")
+
+	b := weekdayHours("UTC")
+	b[0], b[4] = b[4], b[0]
+	for i := range b {
+		b[i].Id = 0
+	}
+
+	assert.True(t, SameHours(a, b))
+	assert.False(t, SameHours(a, b[1:]))
+	assert.False(t, SameHours(a, OverrideHours(a, time.Monday, Override{Closed: true})))
+	assert.False(t, SameHours(a, weekdayHours("Europe/London")))
+}
@@ -0,0 +1,44 @@
+package supporthours
+
+import (
+	"context"
+
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+)
+
+// Load fetches a panel's weekly support hours, rather than any override applied for the bot, and its date-specific
+// overrides
+func Load(ctx context.Context, panelId int) (Schedule, error) {
+	hours, err := dbclient.Dashboard.AppliedHoursOverrides.GetWeeklyHours(ctx, panelId)
+	if err != nil {
+		return Schedule{}, err
+	}
+
+	overrides, err := dbclient.Dashboard.SupportHoursOverrides.GetByPanel(ctx, panelId)
+	if err != nil {
+		return Schedule{}, err
+	}
+
+	return FromPanelHours(hours).WithOverrides(FromOverrideRows(overrides)), nil
+}
+
+func FromOverrideRows(rows []dbclient.SupportHoursOverride) []Override {
+	overrides := make([]Override, len(rows))
+	for i, row := range rows {
+		overrides[i] = Override{
+			Id:        row.Id,
+			Date:      row.Date,
+			Recurring: row.Recurring,
+			Closed:    row.Closed,
+			Label:     row.Label,
+			Message:   row.Message,
+		}
+
+		if !row.Closed && row.StartTime != nil && row.EndTime != nil {
+			overrides[i].Start = sinceMidnight(*row.StartTime)
+			overrides[i].End = endOffset(*row.EndTime)
+		}
+	}
+
+	return overrides
+}
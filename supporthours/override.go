@@ -0,0 +1,55 @@
+package supporthours
+
+import "time"
+
+// Override replaces the weekly hours on a single date, either closing for the whole day or opening for custom hours.
+// Recurring overrides apply on the same month and day every year from Date onwards, such as public holidays. A
+// recurring override on the 29th of February only applies in leap years.
+type Override struct {
+	Id        int
+	Date      time.Time
+	Recurring bool
+	Closed    bool
+	// Start and End are offsets from midnight, and are only used if the override is not closed
+	Start   time.Duration
+	End     time.Duration
+	Label   string
+	Message *string
+}
+
+// WithOverrides returns a copy of the schedule with the overrides applied. A one-off override takes precedence over
+// a recurring override on the same date.
+func (s Schedule) WithOverrides(overrides []Override) Schedule {
+	s.overrides = overrides
+	return s
+}
+
+// OverrideOn returns the override in effect on the calendar day containing t, in the schedule's timezone, if any
+func (s Schedule) OverrideOn(t time.Time) (Override, bool) {
+	t = t.In(s.Location)
+
+	var recurring *Override
+	for i, override := range s.overrides {
+		if !override.Recurring {
+			if sameDay(override.Date, t) {
+				return override, true
+			}
+
+			continue
+		}
+
+		if recurring == nil && override.Date.Month() == t.Month() && override.Date.Day() == t.Day() && t.Year() >= override.Date.Year() {
+			recurring = &s.overrides[i]
+		}
+	}
+
+	if recurring != nil {
+		return *recurring, true
+	}
+
+	return Override{}, false
+}
+
+func sameDay(date, t time.Time) bool {
+	return date.Year() == t.Year() && date.Month() == t.Month() && date.Day() == t.Day()
+}
@@ -0,0 +1,292 @@
+package supporthours
+
+import (
+	"time"
+
+	"github.com/TicketsBot-cloud/database"
+)
+
+// maxSearchDays bounds how far ahead AddOpenDuration looks for open time, so that a schedule with very little open
+// time cannot loop forever.
+const maxSearchDays = 400
+
+const endOfDay = 23*time.Hour + 59*time.Minute + 59*time.Second
+
+// Schedule is a weekly support hours schedule in a single timezone, with optional date-specific overrides. A panel
+// with no hours configured is always open, unless an override closes it.
+type Schedule struct {
+	Location   *time.Location
+	alwaysOpen bool
+	days       [7][]window
+	overrides  []Override
+}
+
+// window is an open period within a day, as offsets from midnight. The end is exclusive.
+type window struct {
+	start time.Duration
+	end   time.Duration
+}
+
+// AlwaysOpen returns a schedule with no closed periods, used when support hours should not be taken into account
+func AlwaysOpen() Schedule {
+	return Schedule{
+		Location:   time.UTC,
+		alwaysOpen: true,
+	}
+}
+
+// FromPanelHours builds a schedule from a panel's configured hours. An end time of 23:59:59 is treated as midnight, as
+// it is the only way to configure support running until the end of the day.
+func FromPanelHours(hours []database.PanelSupportHours) Schedule {
+	if len(hours) == 0 {
+		return AlwaysOpen()
+	}
+
+	location, err := time.LoadLocation(hours[0].Timezone)
+	if err != nil {
+		location = time.UTC
+	}
+
+	schedule := Schedule{
+		Location: location,
+	}
+
+	for _, h := range hours {
+		if !h.Enabled || h.DayOfWeek < 0 || h.DayOfWeek > 6 {
+			continue
+		}
+
+		start := sinceMidnight(h.StartTime)
+		end := endOffset(h.EndTime)
+		if end <= start {
+			continue
+		}
+
+		schedule.days[h.DayOfWeek] = append(schedule.days[h.DayOfWeek], window{start: start, end: end})
+	}
+
+	return schedule
+}
+
+// IsAlwaysOpen reports whether the schedule never closes. Overrides are not taken into account, as they only affect
+// individual days.
+func (s Schedule) IsAlwaysOpen() bool {
+	return s.alwaysOpen && len(s.overrides) == 0
+}
+
+// IsOpen reports whether support is available at t
+func (s Schedule) IsOpen(t time.Time) bool {
+	if s.IsAlwaysOpen() {
+		return true
+	}
+
+	t = t.In(s.Location)
+	for _, interval := range s.intervalsOn(t) {
+		if !t.Before(interval.Start) && t.Before(interval.End) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// OpenDuration returns how much of the period between from and to falls within support hours
+func (s Schedule) OpenDuration(from, to time.Time) time.Duration {
+	if !to.After(from) {
+		return 0
+	}
+
+	if s.IsAlwaysOpen() {
+		return to.Sub(from)
+	}
+
+	var total time.Duration
+	for day := startOfDay(from.In(s.Location)); day.Before(to); day = nextDay(day) {
+		for _, interval := range s.intervalsOn(day) {
+			start, end := maxTime(interval.Start, from), minTime(interval.End, to)
+			if end.After(start) {
+				total += end.Sub(start)
+			}
+		}
+	}
+
+	return total
+}
+
+// AddOpenDuration returns the time at which d of support hours will have elapsed since from. It returns false if the
+// schedule does not have enough open time in the foreseeable future.
+func (s Schedule) AddOpenDuration(from time.Time, d time.Duration) (time.Time, bool) {
+	if s.IsAlwaysOpen() {
+		return from.Add(d), true
+	}
+
+	remaining := d
+	day := startOfDay(from.In(s.Location))
+	for i := 0; i < maxSearchDays; i++ {
+		for _, interval := range s.intervalsOn(day) {
+			start := maxTime(interval.Start, from)
+			if !interval.End.After(start) {
+				continue
+			}
+
+			available := interval.End.Sub(start)
+			if available >= remaining {
+				return start.Add(remaining), true
+			}
+
+			remaining -= available
+		}
+
+		day = nextDay(day)
+	}
+
+	return time.Time{}, false
+}
+
+// Interval is a period during which support is available. The end is exclusive.
+type Interval struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Intervals returns the open intervals between from and to, clipped to the range. Intervals which meet, such as
+// across midnight, are merged.
+func (s Schedule) Intervals(from, to time.Time) []Interval {
+	intervals := make([]Interval, 0)
+	if !to.After(from) {
+		return intervals
+	}
+
+	if s.IsAlwaysOpen() {
+		return append(intervals, Interval{Start: from, End: to})
+	}
+
+	for day := startOfDay(from.In(s.Location)); day.Before(to); day = nextDay(day) {
+		for _, interval := range s.intervalsOn(day) {
+			start, end := maxTime(interval.Start, from), minTime(interval.End, to)
+			if !end.After(start) {
+				continue
+			}
+
+			if last := len(intervals) - 1; last >= 0 && !start.After(intervals[last].End) {
+				intervals[last].End = maxTime(intervals[last].End, end)
+				continue
+			}
+
+			intervals = append(intervals, Interval{Start: start, End: end})
+		}
+	}
+
+	return intervals
+}
+
+// NextOpen returns the next time after t that support opens. It returns false if the schedule never closes, or does
+// not open again in the foreseeable future.
+func (s Schedule) NextOpen(t time.Time) (time.Time, bool) {
+	if s.IsAlwaysOpen() {
+		return time.Time{}, false
+	}
+
+	for _, interval := range s.Intervals(t, t.AddDate(0, 0, maxSearchDays)) {
+		if interval.Start.After(t) {
+			return interval.Start, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// NextClose returns the next time after t that support closes. It returns false if the schedule never closes, or is
+// not open in the foreseeable future.
+func (s Schedule) NextClose(t time.Time) (time.Time, bool) {
+	if s.IsAlwaysOpen() {
+		return time.Time{}, false
+	}
+
+	horizon := t.AddDate(0, 0, maxSearchDays)
+	for _, interval := range s.Intervals(t, horizon) {
+		if interval.End.After(t) && interval.End.Before(horizon) {
+			return interval.End, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// intervalsOn returns the open intervals on the calendar day containing t, in the schedule's timezone
+func (s Schedule) intervalsOn(t time.Time) []Interval {
+	t = t.In(s.Location)
+
+	windows := s.windowsOn(t)
+	intervals := make([]Interval, 0, len(windows))
+	for _, w := range windows {
+		intervals = append(intervals, Interval{
+			Start: atOffset(t, w.start),
+			End:   atOffset(t, w.end),
+		})
+	}
+
+	return intervals
+}
+
+// windowsOn returns the open windows on t's day, taking overrides into account
+func (s Schedule) windowsOn(t time.Time) []window {
+	if override, ok := s.OverrideOn(t); ok {
+		if override.Closed || override.End <= override.Start {
+			return nil
+		}
+
+		return []window{{start: override.Start, end: override.End}}
+	}
+
+	if s.alwaysOpen {
+		return []window{{start: 0, end: 24 * time.Hour}}
+	}
+
+	return s.days[t.Weekday()]
+}
+
+func sinceMidnight(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+}
+
+// endOffset converts an end time to an offset from midnight, treating 23:59:59 as the end of the day
+func endOffset(t time.Time) time.Duration {
+	offset := sinceMidnight(t)
+	if offset == endOfDay {
+		return 24 * time.Hour
+	}
+
+	return offset
+}
+
+// atOffset returns the wall clock time offset from midnight on t's day. Going through time.Date rather than adding to
+// midnight keeps the wall clock time correct on days where daylight saving starts or ends.
+func atOffset(t time.Time, offset time.Duration) time.Time {
+	seconds := int(offset / time.Second)
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, seconds, 0, t.Location())
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func nextDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+}
+
+func maxTime(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+
+	return b
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+
+	return b
+}
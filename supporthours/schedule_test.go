@@ -0,0 +1,138 @@
+package supporthours
+
+import (
+	"testing"
+	"time"
+
+	"github.com/TicketsBot-cloud/database"
+	"github.com/stretchr/testify/assert"
+)
+
+func clock(s string) time.Time {
+	t, _ := time.Parse("15:04:05", s)
+	return t
+}
+
+// weekdays returns a Monday to Friday, 09:00 to 17:00 schedule
+func weekdays(timezone string) Schedule {
+	var hours []database.PanelSupportHours
+	for day := 1; day <= 5; day++ {
+		hours = append(hours, database.PanelSupportHours{
+			DayOfWeek: day,
+			StartTime: clock("09:00:00"),
+			EndTime:   clock("17:00:00"),
+			Enabled:   true,
+			Timezone:  timezone,
+		})
+	}
+
+	return FromPanelHours(hours)
+}
+
+func TestIsOpen(t *testing.T) {
+	s := weekdays("UTC")
+
+	assert.True(t, s.IsOpen(time.Date(2025, 6, 2, 9, 0, 0, 0, time.UTC))) // Monday
+	assert.False(t, s.IsOpen(time.Date(2025, 6, 2, 8, 59, 0, 0, time.UTC)))
+	assert.False(t, s.IsOpen(time.Date(2025, 6, 7, 12, 0, 0, 0, time.UTC))) // Saturday
+	assert.True(t, FromPanelHours(nil).IsOpen(time.Date(2025, 6, 7, 12, 0, 0, 0, time.UTC)))
+}
+
+func TestOpenDurationOverWeekend(t *testing.T) {
+	s := weekdays("UTC")
+
+	// Friday 16:00 to Monday 10:00 is one hour on Friday and one on Monday
+	from := time.Date(2025, 6, 6, 16, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 6, 9, 10, 0, 0, 0, time.UTC)
+	assert.Equal(t, 2*time.Hour, s.OpenDuration(from, to))
+}
+
+func TestAddOpenDuration(t *testing.T) {
+	s := weekdays("Europe/London")
+	london, _ := time.LoadLocation("Europe/London")
+
+	// 90 minutes from Friday 16:30 runs out at Monday 10:00
+	due, ok := s.AddOpenDuration(time.Date(2025, 6, 6, 16, 30, 0, 0, london), 90*time.Minute)
+	if assert.True(t, ok) {
+		assert.True(t, due.Equal(time.Date(2025, 6, 9, 10, 0, 0, 0, london)))
+	}
+
+	_, ok = Schedule{Location: time.UTC}.AddOpenDuration(time.Now(), time.Minute)
+	assert.False(t, ok)
+}
+
+func TestOverrides(t *testing.T) {
+	s := weekdays("UTC").WithOverrides([]Override{
+		{Date: time.Date(2020, 12, 25, 0, 0, 0, 0, time.UTC), Recurring: true, Closed: true},
+		// Extended hours on a Saturday
+		{Date: time.Date(2025, 6, 7, 0, 0, 0, 0, time.UTC), Start: 10 * time.Hour, End: 14 * time.Hour},
+	})
+
+	// Christmas 2025 is a Thursday
+	assert.False(t, s.IsOpen(time.Date(2025, 12, 25, 12, 0, 0, 0, time.UTC)))
+	assert.True(t, s.IsOpen(time.Date(2025, 12, 24, 12, 0, 0, 0, time.UTC)))
+
+	// Recurring overrides do not apply before the year they were created
+	assert.True(t, s.IsOpen(time.Date(2019, 12, 25, 12, 0, 0, 0, time.UTC)))
+
+	assert.True(t, s.IsOpen(time.Date(2025, 6, 7, 12, 0, 0, 0, time.UTC)))
+	assert.False(t, s.IsOpen(time.Date(2025, 6, 7, 15, 0, 0, 0, time.UTC)))
+
+	// Only the 24th and 26th are open, as Christmas Day is closed
+	from := time.Date(2025, 12, 24, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 12, 27, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, 16*time.Hour, s.OpenDuration(from, to))
+}
+
+func TestOneOffOverrideBeatsRecurring(t *testing.T) {
+	s := FromPanelHours(nil).WithOverrides([]Override{
+		{Date: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC), Recurring: true, Closed: true},
+		{Date: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), Start: 9 * time.Hour, End: 12 * time.Hour},
+	})
+
+	assert.False(t, s.IsAlwaysOpen())
+	assert.True(t, s.IsOpen(time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)))
+	assert.False(t, s.IsOpen(time.Date(2026, 1, 1, 10, 0, 0, 0, time.UTC)))
+	assert.True(t, s.IsOpen(time.Date(2026, 1, 2, 10, 0, 0, 0, time.UTC)))
+}
+
+func TestIntervalsAcrossDst(t *testing.T) {
+	london, _ := time.LoadLocation("Europe/London")
+	s := FromPanelHours([]database.PanelSupportHours{
+		{DayOfWeek: 6, StartTime: clock("09:00:00"), EndTime: clock("23:59:59"), Enabled: true, Timezone: "Europe/London"},
+		{DayOfWeek: 0, StartTime: clock("00:00:00"), EndTime: clock("17:00:00"), Enabled: true, Timezone: "Europe/London"},
+	})
+
+	// Clocks go forward at 01:00 on Sunday 30th March 2025
+	from := time.Date(2025, 3, 29, 0, 0, 0, 0, london)
+	intervals := s.Intervals(from, from.AddDate(0, 0, 2))
+
+	// Saturday and Sunday meet at midnight, so are merged
+	if assert.Len(t, intervals, 1) {
+		assert.True(t, intervals[0].Start.Equal(time.Date(2025, 3, 29, 9, 0, 0, 0, london)))
+		assert.True(t, intervals[0].End.Equal(time.Date(2025, 3, 30, 17, 0, 0, 0, london)))
+
+		// 15 hours on Saturday, and 16 on Sunday as an hour is skipped
+		assert.Equal(t, 31*time.Hour, intervals[0].End.Sub(intervals[0].Start))
+	}
+}
+
+func TestNextOpenAndClose(t *testing.T) {
+	s := weekdays("UTC")
+
+	// Friday afternoon
+	now := time.Date(2025, 6, 6, 15, 0, 0, 0, time.UTC)
+
+	next, ok := s.NextClose(now)
+	if assert.True(t, ok) {
+		assert.True(t, next.Equal(time.Date(2025, 6, 6, 17, 0, 0, 0, time.UTC)))
+	}
+
+	next, ok = s.NextOpen(now)
+	if assert.True(t, ok) {
+		assert.True(t, next.Equal(time.Date(2025, 6, 9, 9, 0, 0, 0, time.UTC)))
+	}
+
+	_, ok = AlwaysOpen().NextClose(now)
+	assert.False(t, ok)
+}
@@ -0,0 +1,84 @@
+package supporthours
+
+import (
+	"context"
+	"time"
+
+	dbclient "github.com/TicketsBot-cloud/dashboard/database"
+	"github.com/TicketsBot-cloud/dashboard/jobs"
+	"github.com/TicketsBot-cloud/dashboard/log"
+	"go.uber.org/zap"
+)
+
+// RunSweeper periodically applies the overrides which have come into effect to the hours read by the bot, and restores
+// the weekly hours of panels whose overrides have ended. Only one API instance performs each run.
+func RunSweeper(interval time.Duration) {
+	jobs.Run("support-hours-overrides", interval, sweep)
+}
+
+func sweep(ctx context.Context) {
+	now := time.Now()
+
+	panelIds, err := dbclient.Dashboard.AppliedHoursOverrides.GetPanelsToCheck(ctx, now)
+	if err != nil {
+		log.Logger.Error("Failed to fetch panels with support hours overrides", zap.Error(err))
+		return
+	}
+
+	for _, panelId := range panelIds {
+		if ctx.Err() != nil {
+			log.Logger.Warn("Support hours override sweep timed out, remaining panels will be checked next run")
+			return
+		}
+
+		if err := ApplyOverride(ctx, panelId, now); err != nil {
+			log.Logger.Error("Failed to apply support hours override", zap.Int("panel_id", panelId), zap.Error(err))
+		}
+	}
+}
+
+// ApplyOverride brings the hours read by the bot in line with the override in effect for the panel at the given time,
+// if any. It is called by the sweeper, and after an override is changed so that it takes effect immediately.
+func ApplyOverride(ctx context.Context, panelId int, now time.Time) error {
+	weekly, applied, err := dbclient.Dashboard.AppliedHoursOverrides.Get(ctx, panelId)
+	if err != nil {
+		return err
+	}
+
+	if !applied {
+		weekly, err = dbclient.Client.PanelSupportHours.GetByPanelId(ctx, panelId)
+		if err != nil {
+			return err
+		}
+	}
+
+	overrides, err := dbclient.Dashboard.SupportHoursOverrides.GetByPanel(ctx, panelId)
+	if err != nil {
+		return err
+	}
+
+	schedule := FromPanelHours(weekly).WithOverrides(FromOverrideRows(overrides))
+
+	override, ok := schedule.OverrideOn(now)
+	if !ok {
+		if applied {
+			return dbclient.Dashboard.AppliedHoursOverrides.Restore(ctx, panelId, weekly)
+		}
+
+		return nil
+	}
+
+	hours := OverrideHours(weekly, now.In(schedule.Location).Weekday(), override)
+	if applied {
+		current, err := dbclient.Client.PanelSupportHours.GetByPanelId(ctx, panelId)
+		if err != nil {
+			return err
+		}
+
+		if SameHours(current, hours) {
+			return nil
+		}
+	}
+
+	return dbclient.Dashboard.AppliedHoursOverrides.Apply(ctx, panelId, weekly, hours)
+}
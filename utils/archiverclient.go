@@ -1,5 +1,73 @@
 package utils
 
-import "github.com/TicketsBot-cloud/archiverclient"
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/TicketsBot-cloud/archiverclient"
+	"github.com/TicketsBot-cloud/dashboard/config"
+	"github.com/TicketsBot-cloud/gdl/objects/channel/message"
+	"github.com/TicketsBot-cloud/logarchiver/pkg/model"
+	v1 "github.com/TicketsBot-cloud/logarchiver/pkg/model/v1"
+	v2 "github.com/TicketsBot-cloud/logarchiver/pkg/model/v2"
+	"github.com/TicketsBot/common/encryption"
+)
 
 var ArchiverClient *archiverclient.ArchiverClient
+
+// ArchiverRetriever is the retriever backing ArchiverClient, for operations the client does not expose, such as deletion
+var ArchiverRetriever archiverclient.Retriever
+
+// GetArchivedTranscript fetches a transcript like ArchiverClient.Get, but if it was stored in the V1 format, also
+// returns the original messages. The conversion to V2 drops message types, replies and mentions, which the original
+// messages still contain. For V2 transcripts, the returned messages are nil.
+func GetArchivedTranscript(ctx context.Context, guildId uint64, ticketId int) (v2.Transcript, []message.Message, error) {
+	body, err := ArchiverRetriever.GetTicket(ctx, guildId, ticketId)
+	if err != nil {
+		return v2.Transcript{}, nil, err
+	}
+
+	return decodeArchivedTranscript([]byte(config.Conf.Bot.AesKey), body)
+}
+
+// decodeArchivedTranscript must decode transcripts exactly as ArchiverClient.Get does, which does not expose the
+// original V1 messages. The tests check the two against each other, so that a change to the archiver client's storage
+// format is caught.
+func decodeArchivedTranscript(key, body []byte) (v2.Transcript, []message.Message, error) {
+	// Older transcripts are compressed before being encrypted
+	decrypted, decryptErr := encryption.Decrypt(key, body)
+	if decryptErr == nil {
+		body = decrypted
+	} else {
+		decompressed, decompressErr := encryption.Decompress(body)
+		if decompressErr != nil {
+			return v2.Transcript{}, nil, fmt.Errorf("failed to decrypt directly and decompress failed: decrypt_err=%v, decompress_err=%v", decryptErr, decompressErr)
+		}
+
+		var err error
+		body, err = encryption.Decrypt(key, decompressed)
+		if err != nil {
+			return v2.Transcript{}, nil, fmt.Errorf("decompression succeeded but decryption failed: %w", err)
+		}
+	}
+
+	switch version := model.GetVersion(body); version {
+	case model.V1:
+		var messages []message.Message
+		if err := json.Unmarshal(body, &messages); err != nil {
+			return v2.Transcript{}, nil, err
+		}
+
+		return v1.ConvertToV2(messages), messages, nil
+	case model.V2:
+		var transcript v2.Transcript
+		if err := json.Unmarshal(body, &transcript); err != nil {
+			return v2.Transcript{}, nil, err
+		}
+
+		return transcript, nil, nil
+	default:
+		return v2.Transcript{}, nil, fmt.Errorf("unknown version %d", version)
+	}
+}
@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/TicketsBot-cloud/archiverclient"
+	"github.com/TicketsBot-cloud/gdl/objects/channel/message"
+	"github.com/TicketsBot-cloud/gdl/objects/user"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryRetriever map[int][]byte
+
+func (r memoryRetriever) GetTicket(_ context.Context, _ uint64, ticketId int) ([]byte, error) {
+	data, ok := r[ticketId]
+	if !ok {
+		return nil, archiverclient.ErrNotFound
+	}
+
+	return data, nil
+}
+
+func (r memoryRetriever) StoreTicket(_ context.Context, _ uint64, ticketId int, data []byte) error {
+	r[ticketId] = data
+	return nil
+}
+
+func (r memoryRetriever) DeleteTicket(_ context.Context, _ uint64, ticketId int) error {
+	delete(r, ticketId)
+	return nil
+}
+
+// The archiver client's storage format is mirrored by decodeArchivedTranscript, so check that both agree on
+// transcripts written by the client
+func TestDecodeArchivedTranscriptMatchesClient(t *testing.T) {
+	ctx := context.Background()
+	key := []byte("0123456789abcdef0123456789abcdef")
+
+	retriever := make(memoryRetriever)
+	client := archiverclient.NewArchiverClient(retriever, key)
+
+	messages := []message.Message{
+		{
+			Id:        1,
+			Type:      message.MessageTypeReply,
+			Author:    user.User{Id: 10, Username: "user"},
+			Content:   "Hello <@20>",
+			Timestamp: time.Unix(1700000000, 0).UTC(),
+			Mentions:  []message.MessageMentionedUser{{User: user.User{Id: 20, Username: "staff"}}},
+		},
+	}
+
+	require.NoError(t, client.Store(ctx, 1, 1, messages))
+
+	v1Body, err := json.Marshal(messages)
+	require.NoError(t, err)
+	require.NoError(t, client.ImportTranscript(ctx, 1, 2, v1Body))
+
+	var v1Messages []message.Message
+	require.NoError(t, json.Unmarshal(v1Body, &v1Messages))
+
+	for ticketId, isV1 := range map[int]bool{1: false, 2: true} {
+		expected, err := client.Get(ctx, 1, ticketId)
+		require.NoError(t, err)
+
+		transcript, original, err := decodeArchivedTranscript(key, retriever[ticketId])
+		require.NoError(t, err)
+		assert.Equal(t, expected, transcript)
+
+		if isV1 {
+			assert.Equal(t, v1Messages, original)
+		} else {
+			assert.Nil(t, original)
+		}
+	}
+}